@@ -0,0 +1,49 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAlignWindows(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC))
+	mr, rl := testLimiter(t, "align", WithDuration(time.Hour), WithBlockTimes(10), WithClock(clock),
+		WithWindows(Window{Duration: 24 * time.Hour, Limit: 100}), WithAlignedWindows(nil), WithClientTimestamps())
+	if _, err := rl.Check("a"); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("align:a"); ttl != 45*time.Minute {
+		t.Fatalf("hour window ends in %v, want 45m", ttl)
+	}
+	if ttl := mr.TTL("align:a:86400000"); ttl != 13*time.Hour+45*time.Minute {
+		t.Fatalf("day window ends in %v, want at midnight", ttl)
+	}
+
+	loc := time.FixedZone("IST", 5*3600+1800)
+	_, rl = testLimiter(t, "align", WithDuration(24*time.Hour), WithClock(clock), WithAlignedWindows(loc), WithClientTimestamps())
+	// 10:15 UTC is 15:45 in loc, 8h15m before its midnight
+	if ttl := rl.windowTTL(rl.primaryWindow("a")); ttl != (8*time.Hour + 15*time.Minute).Milliseconds() {
+		t.Fatalf("day window in %v ends in %v", loc, time.Duration(ttl)*time.Millisecond)
+	}
+
+	_, rl = testLimiter(t, "align", WithDuration(time.Hour), WithClock(clock))
+	if ttl := rl.windowTTL(rl.primaryWindow("a")); ttl != time.Hour.Milliseconds() {
+		t.Fatalf("unaligned window ends in %v", time.Duration(ttl)*time.Millisecond)
+	}
+}
+
+func TestAlignWindowsRedisTime(t *testing.T) {
+	mr, rl := testLimiter(t, "align", WithDuration(time.Hour), WithBlockTimes(10),
+		WithWindows(Window{Duration: 24 * time.Hour, Limit: 100}), WithAlignedWindows(nil))
+	// the local clock is ignored, Redis decides where windows start
+	mr.SetTime(time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC))
+	if _, err := rl.Check("a"); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("align:a"); ttl != 45*time.Minute {
+		t.Fatalf("hour window ends in %v, want 45m", ttl)
+	}
+	if ttl := mr.TTL("align:a:86400000"); ttl != 13*time.Hour+45*time.Minute {
+		t.Fatalf("day window ends in %v, want at midnight", ttl)
+	}
+}
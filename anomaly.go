@@ -0,0 +1,181 @@
+package rateLimiter
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	AnomalySpike   = "spike"   //the id used its window far more than it usually does
+	AnomalyCeiling = "ceiling" //the id used nearly all of several windows in a row
+
+	DefaultAnomalySpikeFactor    = 5.0
+	DefaultAnomalyCeilingRatio   = 0.9
+	DefaultAnomalyCeilingWindows = 3
+	DefaultAnomalyMinRequests    = 10
+
+	anomalyWarmupWindows = 3   //past windows needed before spikes are judged
+	anomalyAlpha         = 0.3 //weight of the last window in the average
+	anomalyIdleWindows   = 10  //ids idle this many windows are forgotten
+)
+
+// AnomalyDetector flags ids whose use of their primary window looks abusive
+// before they reach its limit. Zero fields take the defaults. It watches the
+// counts returned by Redis, so every instance sees the whole traffic of an id,
+// but each reports it on its own.
+type AnomalyDetector struct {
+	SpikeFactor    float64       //flag windows used this many times more than the average of the id
+	CeilingRatio   float64       //windows used at least this part of the limit ride the ceiling
+	CeilingWindows int           //flag the id once this many windows in a row ride the ceiling
+	MinRequests    int           //never flag windows used less than this
+	BlockDuration  time.Duration //add flagged ids to the block list this long, 0=only report them
+	OnAnomaly      func(Anomaly) //called once per flagged id and window, must not block
+}
+
+type Anomaly struct {
+	Id       string
+	Kind     string //AnomalySpike or AnomalyCeiling
+	Used     int    //count of the window when it was flagged
+	Limit    int
+	Baseline float64 //average count of the previous windows of the id
+}
+
+type anomalyState struct {
+	resetAt time.Time //end of the window being counted
+	window  time.Duration
+	used    int
+	mean    float64 //moving average of the counts of past windows
+	windows int     //past windows seen, up to anomalyWarmupWindows
+	streak  int     //past windows in a row riding the ceiling
+	flagged bool    //the current window was reported
+}
+
+type anomalyTracker struct {
+	mu     sync.Mutex
+	states map[string]*anomalyState
+}
+
+func (d *AnomalyDetector) spikeFactor() float64 {
+	if d.SpikeFactor == 0 {
+		return DefaultAnomalySpikeFactor
+	}
+	return d.SpikeFactor
+}
+
+func (d *AnomalyDetector) ceilingRatio() float64 {
+	if d.CeilingRatio == 0 {
+		return DefaultAnomalyCeilingRatio
+	}
+	return d.CeilingRatio
+}
+
+func (d *AnomalyDetector) ceilingWindows() int {
+	if d.CeilingWindows == 0 {
+		return DefaultAnomalyCeilingWindows
+	}
+	return d.CeilingWindows
+}
+
+func (d *AnomalyDetector) minRequests() int {
+	if d.MinRequests == 0 {
+		return DefaultAnomalyMinRequests
+	}
+	return d.MinRequests
+}
+
+// observe records an allowed result of id and returns the anomaly it reveals, if
+// the current window of id wasn't reported yet. It tracks at most max ids.
+func (t *anomalyTracker) observe(id string, res *CheckResult, d *AnomalyDetector, max int) (Anomaly, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.states[id]
+	if s == nil || s.window != res.Window {
+		if s == nil && len(t.states) >= max {
+			return Anomaly{}, false
+		}
+		if t.states == nil {
+			t.states = make(map[string]*anomalyState)
+		}
+		s = &anomalyState{resetAt: res.ResetAt, window: res.Window}
+		t.states[id] = s
+	}
+	ceiling := d.ceilingRatio() * float64(res.Limit)
+	if res.Used < s.used || res.ResetAt.After(s.resetAt.Add(s.window/2)) {
+		// a new window; the ones in between, if any, weren't used
+		s.finish(s.used, ceiling)
+		for i := time.Duration(1); i < anomalyIdleWindows && s.resetAt.Add(i*s.window+s.window/2).Before(res.ResetAt); i++ {
+			s.finish(0, ceiling)
+		}
+		s.resetAt, s.used, s.flagged = res.ResetAt, 0, false
+	}
+	if res.Used > s.used {
+		s.used = res.Used
+	}
+	if s.flagged || s.used < d.minRequests() {
+		return Anomaly{}, false
+	}
+	kind := ""
+	switch {
+	case s.windows >= anomalyWarmupWindows && float64(s.used) > d.spikeFactor()*s.mean:
+		kind = AnomalySpike
+	case float64(s.used) >= ceiling && s.streak+1 >= d.ceilingWindows():
+		kind = AnomalyCeiling
+	default:
+		return Anomaly{}, false
+	}
+	s.flagged = true
+	return Anomaly{Id: id, Kind: kind, Used: s.used, Limit: res.Limit, Baseline: s.mean}, true
+}
+
+// finish accounts for a past window used times.
+func (s *anomalyState) finish(used int, ceiling float64) {
+	if float64(used) >= ceiling {
+		s.streak++
+	} else {
+		s.streak = 0
+	}
+	if s.windows == 0 {
+		s.mean = float64(used)
+	} else {
+		s.mean += anomalyAlpha * (float64(used) - s.mean)
+	}
+	if s.windows < anomalyWarmupWindows {
+		s.windows++
+	}
+}
+
+// sweep forgets the ids idle for anomalyIdleWindows windows.
+func (t *anomalyTracker) sweep(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, s := range t.states {
+		if now.After(s.resetAt.Add(anomalyIdleWindows * s.window)) {
+			delete(t.states, id)
+		}
+	}
+}
+
+// detectAnomaly feeds an allowed result to Config.Anomaly. A flagged id is
+// reported and, unless DryRun is set, queued to be blocked in the background.
+func (rl *RateLimiter) detectAnomaly(id string, res *CheckResult) {
+	if rl.Anomaly == nil || id == globalId || res == nil || !res.Allowed || res.DryRunBlocked || res.FailedOpen ||
+		res.Limit <= 0 || res.ResetAt.IsZero() {
+		return
+	}
+	a, ok := rl.anomalies.observe(id, res, rl.Anomaly, rl.maxCacheEntries())
+	if !ok {
+		return
+	}
+	rl.Logger.Warn("anomaly detected", rl.fields("id", id, "kind", a.Kind, "used", a.Used, "baseline", a.Baseline)...)
+	if rl.Anomaly.OnAnomaly != nil {
+		rl.Anomaly.OnAnomaly(a)
+	}
+	rl.emit(Event{Type: EventAnomaly, Id: id, Result: res, Anomaly: &a})
+	if rl.Anomaly.BlockDuration <= 0 || rl.DryRun {
+		return
+	}
+	// the block outlives the check, so it goes through the block queue rather
+	// than the request's context
+	entry := ListEntry{Id: id, Reason: "anomaly: " + a.Kind, Operator: AutoBlockOperator, CreatedAt: rl.Clock.Now()}
+	rl.blockQueue.push(entry, rl.Anomaly.BlockDuration)
+}
@@ -0,0 +1,94 @@
+package rateLimiter
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAnomalyTracker(t *testing.T) {
+	var tr anomalyTracker
+	d := &AnomalyDetector{}
+	t0 := time.Now()
+	// feed runs the checks of the window of index w up to used and returns the
+	// count at which an anomaly was flagged, 0 if none
+	feed := func(id string, w, used int) (int, string) {
+		flagged, kind := 0, ""
+		for u := 1; u <= used; u++ {
+			res := &CheckResult{Allowed: true, Used: u, Limit: 100, Window: time.Minute,
+				ResetAt: t0.Add(time.Duration(w+1) * time.Minute)}
+			if a, ok := tr.observe(id, res, d, 10); ok {
+				if flagged != 0 {
+					t.Fatalf("window %d of %s flagged twice", w, id)
+				}
+				flagged, kind = u, a.Kind
+			}
+		}
+		return flagged, kind
+	}
+	for w := 0; w < 3; w++ {
+		if u, _ := feed("spike", w, 10); u != 0 {
+			t.Fatalf("usual window %d flagged at %d", w, u)
+		}
+	}
+	if u, kind := feed("spike", 3, 60); u != 51 || kind != AnomalySpike {
+		t.Fatalf("spike flagged at %d as %q, want 51", u, kind)
+	}
+
+	for w := 0; w < 2; w++ {
+		if u, _ := feed("ceiling", w, 95); u != 0 {
+			t.Fatalf("window %d at the ceiling flagged at %d", w, u)
+		}
+	}
+	if u, kind := feed("ceiling", 2, 95); u != 90 || kind != AnomalyCeiling {
+		t.Fatalf("third window at the ceiling flagged at %d as %q, want 90", u, kind)
+	}
+	// an idle window in between breaks the streak
+	if u, _ := feed("ceiling", 4, 95); u != 0 {
+		t.Fatalf("window after an idle one flagged at %d", u)
+	}
+
+	tr.sweep(t0.Add(20 * time.Minute))
+	if len(tr.states) != 0 {
+		t.Fatalf("idle ids kept: %d", len(tr.states))
+	}
+}
+
+func TestAnomalyDetector(t *testing.T) {
+	var mu sync.Mutex
+	var got []Anomaly
+	mr, rl := testLimiter(t, "anomaly", WithDuration(time.Minute), WithBlockTimes(10),
+		WithAnomalyDetector(AnomalyDetector{CeilingWindows: 1, MinRequests: 5, BlockDuration: time.Hour,
+			OnAnomaly: func(a Anomaly) {
+				mu.Lock()
+				got = append(got, a)
+				mu.Unlock()
+			}}))
+	for i := 0; i < 9; i++ {
+		if _, err := rl.Check("a"); err != nil {
+			t.Fatalf("check %d: %v", i+1, err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || got[0].Id != "a" || got[0].Kind != AnomalyCeiling || got[0].Used != 9 {
+		t.Fatalf("anomalies %+v", got)
+	}
+	// the block is added by the block queue
+	deadline := time.Now().Add(2 * time.Second)
+	for !rl.blockIds.has("a") {
+		if time.Now().After(deadline) {
+			t.Fatal("anomalous id not blocked")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, err := rl.Check("a"); !IsBlocked(err) {
+		t.Fatalf("check after the anomaly: %v", err)
+	}
+	if at, ok := rl.blockIds.expiry.get("a"); !ok || time.Until(at) > time.Hour || time.Until(at) < 59*time.Minute {
+		t.Fatalf("anomalous id blocked until %v", at)
+	}
+	if ok, _ := mr.SIsMember(rl.blockListKey, "a"); !ok {
+		t.Fatal("anomalous id not on the Redis block list")
+	}
+}
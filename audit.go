@@ -0,0 +1,137 @@
+package rateLimiter
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const DefaultAuditLogSize = 10000
+
+const (
+	AuditAddWhiteList      = "add_white_list"
+	AuditRemoveWhiteList   = "remove_white_list"
+	AuditAddBlockList      = "add_block_list"
+	AuditRemoveBlockList   = "remove_block_list"
+	AuditClearBlockList    = "clear_block_list"
+	AuditClearWhiteList    = "clear_white_list"
+	AuditAddTrustedList    = "add_trusted_list"
+	AuditRemoveTrustedList = "remove_trusted_list"
+)
+
+type AuditRecord struct {
+	StreamId string
+	Op       string
+	Id       string
+	Operator string
+	Instance string
+	Time     time.Time
+	TTL      time.Duration //0 for permanent entries
+}
+
+type operatorKey struct{}
+
+type replicatedKey struct{}
+
+// WithOperator attaches the operator recorded in the audit log to ctx.
+func WithOperator(ctx context.Context, operator string) context.Context {
+	return context.WithValue(ctx, operatorKey{}, operator)
+}
+
+func operatorFrom(ctx context.Context) string {
+	operator, _ := ctx.Value(operatorKey{}).(string)
+	return operator
+}
+
+// replicated marks changes applied from a sync message, which the originating
+// instance has already audited.
+func replicated(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicatedKey{}, true)
+}
+
+func isReplicated(ctx context.Context) bool {
+	r, _ := ctx.Value(replicatedKey{}).(bool)
+	return r
+}
+
+func defaultInstanceId() string {
+	host, _ := os.Hostname()
+	return host + "-" + strconv.Itoa(os.Getpid())
+}
+
+func (rl *RateLimiter) audit(ctx context.Context, op, id string, ttl time.Duration) {
+	if rl.AuditLogSize == 0 || isReplicated(ctx) {
+		return
+	}
+	rl.Redis.XAdd(ctx, rl.auditArgs(ctx, op, id, ttl))
+}
+
+func (rl *RateLimiter) auditBatch(ctx context.Context, op string, ids []string) {
+	if rl.AuditLogSize == 0 || isReplicated(ctx) {
+		return
+	}
+	pipe := rl.Redis.Pipeline()
+	for _, id := range ids {
+		pipe.XAdd(ctx, rl.auditArgs(ctx, op, id, 0))
+	}
+	pipe.Exec(ctx)
+}
+
+func (rl *RateLimiter) auditArgs(ctx context.Context, op, id string, ttl time.Duration) *goredis.XAddArgs {
+	return &goredis.XAddArgs{
+		Stream: rl.auditKey,
+		MaxLen: rl.AuditLogSize,
+		Approx: true,
+		Values: map[string]interface{}{
+			"op":       op,
+			"id":       id,
+			"operator": operatorFrom(ctx),
+			"instance": rl.InstanceId,
+			"time":     rl.Clock.Now().UnixMilli(),
+			"ttl":      ttl.Milliseconds(),
+		},
+	}
+}
+
+func (rl *RateLimiter) GetAuditLog(since time.Time, count int64) ([]AuditRecord, error) {
+	return rl.GetAuditLogCtx(context.Background(), since, count)
+}
+
+// GetAuditLogCtx returns up to count records (0=all) written at or after since, oldest first.
+func (rl *RateLimiter) GetAuditLogCtx(ctx context.Context, since time.Time, count int64) ([]AuditRecord, error) {
+	start := "-"
+	if !since.IsZero() {
+		start = strconv.FormatInt(since.UnixMilli(), 10)
+	}
+	var msgs []goredis.XMessage
+	var err error
+	if count > 0 {
+		msgs, err = rl.Redis.XRangeN(ctx, rl.auditKey, start, "+", count).Result()
+	} else {
+		msgs, err = rl.Redis.XRange(ctx, rl.auditKey, start, "+").Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+	records := make([]AuditRecord, 0, len(msgs))
+	for _, msg := range msgs {
+		r := AuditRecord{StreamId: msg.ID}
+		r.Op, _ = msg.Values["op"].(string)
+		r.Id, _ = msg.Values["id"].(string)
+		r.Operator, _ = msg.Values["operator"].(string)
+		r.Instance, _ = msg.Values["instance"].(string)
+		if s, ok := msg.Values["time"].(string); ok {
+			ms, _ := strconv.ParseInt(s, 10, 64)
+			r.Time = time.UnixMilli(ms)
+		}
+		if s, ok := msg.Values["ttl"].(string); ok {
+			ms, _ := strconv.ParseInt(s, 10, 64)
+			r.TTL = time.Duration(ms) * time.Millisecond
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
@@ -0,0 +1,47 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAuditLog(t *testing.T) {
+	clock := NewFakeClock(time.UnixMilli(time.Now().UnixMilli()))
+	_, rl := testLimiter(t, "audit", WithDuration(time.Minute), WithAuditLog(100), WithClock(clock))
+	rl.InstanceId = "node-1"
+	ctx := WithOperator(context.Background(), "alice")
+	if err := rl.AddBlockListTTLCtx(ctx, "a", time.Hour, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.RemoveBlockListCtx(ctx, "a", false); err != nil {
+		t.Fatal(err)
+	}
+	// changes replicated from another instance were audited there
+	if err := rl.Sub("rb-b"); err != nil {
+		t.Fatal(err)
+	}
+	records, err := rl.GetAuditLog(time.Time{}, 0)
+	if err != nil || len(records) != 2 {
+		t.Fatalf("records %+v, %v", records, err)
+	}
+	add, remove := records[0], records[1]
+	if add.Op != AuditAddBlockList || add.Id != "a" || add.Operator != "alice" || add.Instance != "node-1" ||
+		add.TTL != time.Hour || !add.Time.Equal(clock.Now()) {
+		t.Fatalf("add record %+v", add)
+	}
+	if remove.Op != AuditRemoveBlockList || remove.TTL != 0 {
+		t.Fatalf("remove record %+v", remove)
+	}
+	if records, _ := rl.GetAuditLog(time.Time{}, 1); len(records) != 1 {
+		t.Fatalf("count ignored: %d records", len(records))
+	}
+}
+
+func TestAuditLogDisabled(t *testing.T) {
+	mr, rl := testLimiter(t, "audit", WithDuration(time.Minute))
+	rl.AddBlockListTTL("a", time.Hour, false)
+	if mr.Exists("audit-audit") {
+		t.Fatal("audit stream written without AuditLogSize")
+	}
+}
@@ -0,0 +1,223 @@
+package rateLimiter
+
+import (
+	"context"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// batch sync ops carry several ids at once.
+const (
+	batchAddWhiteList    = "aws"
+	batchRemoveWhiteList = "rws"
+	batchAddBlockList    = "abs"
+	batchRemoveBlockList = "rbs"
+)
+
+func isBatchOp(op string) bool {
+	switch op {
+	case batchAddWhiteList, batchRemoveWhiteList, batchAddBlockList, batchRemoveBlockList:
+		return true
+	default:
+		return false
+	}
+}
+
+func (rl *RateLimiter) sanitizeIds(ids []string) ([]string, error) {
+	return sanitizeAll(ids, rl.sanitizeId)
+}
+
+func (rl *RateLimiter) sanitizeStoredIds(ids []string) ([]string, error) {
+	return sanitizeAll(ids, rl.sanitizeStoredId)
+}
+
+func sanitizeAll(ids []string, sanitize func(string) (string, error)) ([]string, error) {
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		id, err := sanitize(id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+func (rl *RateLimiter) AddWhiteListBatch(ids []string, pub bool) error {
+	return rl.AddWhiteListBatchCtx(context.Background(), ids, pub)
+}
+
+func (rl *RateLimiter) AddWhiteListBatchCtx(ctx context.Context, ids []string, pub bool) error {
+	ctx, cancel := rl.listContext(ctx)
+	defer cancel()
+	ids, err := rl.sanitizeIds(ids)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	members := make([]interface{}, len(ids))
+	for i, id := range ids {
+		members[i] = id
+	}
+	pipe := rl.Redis.TxPipeline()
+	pipe.SAdd(ctx, rl.whiteListKey, members...)
+	pipe.ZRem(ctx, rl.whiteTTLKey, members...)
+	changed, err := execBatch(ctx, rl.whiteIds, pipe, rl.whiteIds.addAll, ids)
+	if err != nil {
+		return err
+	}
+	rl.whiteListChanged(ctx, ListAdd, changed, 0)
+	rl.auditBatch(ctx, AuditAddWhiteList, ids)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: batchAddWhiteList, Ids: ids})
+	}
+	return nil
+}
+
+func (rl *RateLimiter) RemoveWhiteListBatch(ids []string, pub bool) error {
+	return rl.RemoveWhiteListBatchCtx(context.Background(), ids, pub)
+}
+
+func (rl *RateLimiter) RemoveWhiteListBatchCtx(ctx context.Context, ids []string, pub bool) error {
+	ctx, cancel := rl.listContext(ctx)
+	defer cancel()
+	ids, err := rl.sanitizeStoredIds(ids)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	members := make([]interface{}, len(ids))
+	for i, id := range ids {
+		members[i] = id
+	}
+	pipe := rl.Redis.TxPipeline()
+	pipe.SRem(ctx, rl.whiteListKey, members...)
+	pipe.ZRem(ctx, rl.whiteTTLKey, members...)
+	pipe.HDel(ctx, rl.whiteMetaKey, ids...)
+	changed, err := execBatch(ctx, rl.whiteIds, pipe, rl.whiteIds.removeAll, ids)
+	if err != nil {
+		return err
+	}
+	rl.whiteListChanged(ctx, ListRemove, changed, 0)
+	rl.auditBatch(ctx, AuditRemoveWhiteList, ids)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: batchRemoveWhiteList, Ids: ids})
+	}
+	return nil
+}
+
+func (rl *RateLimiter) AddBlockListBatch(ids []string, pub bool) error {
+	return rl.AddBlockListBatchCtx(context.Background(), ids, pub)
+}
+
+func (rl *RateLimiter) AddBlockListBatchCtx(ctx context.Context, ids []string, pub bool) error {
+	ctx, cancel := rl.listContext(ctx)
+	defer cancel()
+	ids, err := rl.sanitizeIds(ids)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	var changed []string
+	if rl.BloomBlockList {
+		changed, err = rl.addBloomBlockListBatch(ctx, ids)
+	} else {
+		members := make([]interface{}, len(ids))
+		for i, id := range ids {
+			members[i] = id
+		}
+		pipe := rl.Redis.TxPipeline()
+		pipe.SAdd(ctx, rl.blockListKey, members...)
+		pipe.ZRem(ctx, rl.blockTTLKey, members...)
+		changed, err = execBatch(ctx, rl.blockIds, pipe, rl.blockIds.addAll, ids)
+	}
+	if err != nil {
+		return err
+	}
+	rl.blockListChanged(ctx, ListAdd, changed, 0)
+	rl.auditBatch(ctx, AuditAddBlockList, ids)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: batchAddBlockList, Ids: ids})
+	}
+	return nil
+}
+
+func (rl *RateLimiter) RemoveBlockListBatch(ids []string, pub bool) error {
+	return rl.RemoveBlockListBatchCtx(context.Background(), ids, pub)
+}
+
+// RemoveBlockListBatchCtx unblocks ids and resets their counters, like RemoveBlockList.
+func (rl *RateLimiter) RemoveBlockListBatchCtx(ctx context.Context, ids []string, pub bool) error {
+	ctx, cancel := rl.listContext(ctx)
+	defer cancel()
+	ids, err := rl.sanitizeStoredIds(ids)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	members := make([]interface{}, len(ids))
+	counterKeys := make([]string, 0, len(ids))
+	for i, id := range ids {
+		members[i] = id
+		counterKeys = append(counterKeys, rl.allCounterKeys(id)...)
+	}
+	pipe := rl.Redis.TxPipeline()
+	pipe.SRem(ctx, rl.blockListKey, members...)
+	pipe.ZRem(ctx, rl.blockTTLKey, members...)
+	pipe.HDel(ctx, rl.blockMetaKey, ids...)
+	pipe.Del(ctx, counterKeys...)
+	changed, err := execBatch(ctx, rl.blockIds, pipe, rl.blockIds.removeAll, ids)
+	if err != nil {
+		return err
+	}
+	rl.blockListChanged(ctx, ListRemove, changed, 0)
+	for _, id := range ids {
+		rl.resetLocal(id)
+	}
+	rl.auditBatch(ctx, AuditRemoveBlockList, ids)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: batchRemoveBlockList, Ids: ids})
+	}
+	return nil
+}
+
+// execBatch runs pipe and then change(ids) as one mutation of l, see idList.apply,
+// and returns the ids change reports.
+func execBatch(ctx context.Context, l *idList, pipe goredis.Pipeliner, change func([]string) []string, ids []string) ([]string, error) {
+	var changed []string
+	err := l.apply(func() error {
+		if pipe != nil {
+			if _, err := pipe.Exec(ctx); err != nil {
+				return err
+			}
+		}
+		changed = change(ids)
+		return nil
+	})
+	return changed, err
+}
+
+// syncBatch applies a batch change made by another instance to the local lists.
+func (rl *RateLimiter) syncBatch(ctx context.Context, op string, ids []string) error {
+	switch op {
+	case batchAddWhiteList:
+		changed, _ := execBatch(ctx, rl.whiteIds, nil, rl.whiteIds.addAll, ids)
+		rl.whiteListChanged(ctx, ListAdd, changed, 0)
+	case batchRemoveWhiteList:
+		changed, _ := execBatch(ctx, rl.whiteIds, nil, rl.whiteIds.removeAll, ids)
+		rl.whiteListChanged(ctx, ListRemove, changed, 0)
+	case batchAddBlockList:
+		if rl.BloomBlockList {
+			if err := rl.loadBloomBlockList(ctx); err != nil {
+				return err
+			}
+			rl.blockListChanged(ctx, ListAdd, ids, 0)
+			return nil
+		}
+		changed, _ := execBatch(ctx, rl.blockIds, nil, rl.blockIds.addAll, ids)
+		rl.blockListChanged(ctx, ListAdd, changed, 0)
+	case batchRemoveBlockList:
+		changed, _ := execBatch(ctx, rl.blockIds, nil, rl.blockIds.removeAll, ids)
+		rl.blockListChanged(ctx, ListRemove, changed, 0)
+		for _, id := range ids {
+			rl.resetLocal(id)
+		}
+	}
+	return nil
+}
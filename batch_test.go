@@ -0,0 +1,124 @@
+package rateLimiter
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+)
+
+func TestBlockListBatch(t *testing.T) {
+	mr, r := testRedis(t)
+	var msgs []string
+	pub := func(channel, msg string) error {
+		msgs = append(msgs, msg)
+		return nil
+	}
+	rl, err := NewLimiter("batch", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(5), WithPub(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewLimiter("batch", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := rl.AddBlockListBatchCtx(ctx, []string{"a", "b", "c"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("published %q, want a single batch message", msgs)
+	}
+	if msg, _, _ := parseSyncMessage(msgs[0]); msg.Op != batchAddBlockList || len(msg.Ids) != 3 {
+		t.Fatalf("published %q, want a batch of 3 ids", msgs[0])
+	}
+	if err := other.Sub(msgs[0]); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		if _, err := other.Check(id); !IsBlocked(err) {
+			t.Fatalf("%s not blocked on the other instance: %v", id, err)
+		}
+	}
+
+	rl.Check("d")
+	if err := rl.RemoveBlockListBatchCtx(ctx, []string{"a", "b", "d"}, true); err != nil {
+		t.Fatal(err)
+	}
+	if members, _ := mr.Members("batch-block"); len(members) != 1 || members[0] != "c" {
+		t.Fatalf("block list in Redis %v, want [c]", members)
+	}
+	if mr.Exists("batch:d") {
+		t.Fatal("batch removal kept the counter")
+	}
+	if err := other.Sub(msgs[1]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Check("a"); err != nil {
+		t.Fatalf("a still blocked on the other instance: %v", err)
+	}
+
+	if err := rl.AddBlockListBatchCtx(ctx, []string{"e", ""}, false); !stderrors.Is(err, ErrorInvalidId) {
+		t.Fatalf("batch with an invalid id: %v", err)
+	}
+	if ok, _ := mr.SIsMember("batch-block", "e"); ok {
+		t.Fatal("batch with an invalid id partly applied")
+	}
+}
+
+func TestWhiteListBatch(t *testing.T) {
+	_, rl := testLimiter(t, "batch", WithDuration(time.Minute), WithBlockTimes(1))
+	ctx := context.Background()
+	if err := rl.AddWhiteListBatchCtx(ctx, []string{"a", "b"}, false); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := rl.Check("a"); err != nil {
+			t.Fatalf("white listed id: %v", err)
+		}
+	}
+	if err := rl.RemoveWhiteListBatchCtx(ctx, []string{"a", "b"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := rl.GetWhiteList(nil); len(ids) != 0 {
+		t.Fatalf("white list %v after removal", ids)
+	}
+}
+
+func TestRemoveBlockListBatchWriteBehind(t *testing.T) {
+	ctx := context.Background()
+	mr, r := testRedis(t)
+	opts := []Option{WithRedis(r), WithDuration(time.Minute), WithBlockTimes(100), WithWriteBehind(time.Hour)}
+	a, err := NewLimiter("batch", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.Close(ctx) })
+	b, err := NewLimiter("batch", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close(ctx) })
+	for i := 0; i < 4; i++ {
+		a.Check("x")
+		b.Check("x")
+	}
+
+	// the removal resets the counters, so neither instance may flush its
+	// buffered checks back afterwards
+	if err := a.RemoveBlockListBatchCtx(ctx, []string{"x"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.syncBatch(ctx, batchRemoveBlockList, []string{"x"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, rl := range []*RateLimiter{a, b} {
+		if err := rl.flushCounters(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if mr.Exists(a.counterKey("x")) {
+		v, _ := mr.Get(a.counterKey("x"))
+		t.Fatalf("counter %s flushed back after the removal", v)
+	}
+}
@@ -0,0 +1,116 @@
+package rateLimiter
+
+import (
+	"sync"
+	"time"
+)
+
+type cachedBlock struct {
+	until  time.Time
+	window Window
+	used   int
+}
+
+// blockCache remembers ids rejected by the counter script until their block ends.
+// Expired entries are dropped on lookup, by a sweep once the earliest one is due
+// and by the janitor.
+type blockCache struct {
+	mu      sync.RWMutex
+	entries map[string]cachedBlock
+	next    time.Time
+}
+
+func (b *blockCache) get(id string, now time.Time) (cachedBlock, bool) {
+	b.mu.RLock()
+	e, ok := b.entries[id]
+	b.mu.RUnlock()
+	if !ok || now.Before(e.until) {
+		return e, ok
+	}
+	b.mu.Lock()
+	if e, ok := b.entries[id]; ok && !now.Before(e.until) {
+		delete(b.entries, id)
+	}
+	b.mu.Unlock()
+	return cachedBlock{}, false
+}
+
+// set caches e unless the cache already holds max other entries, 0=unlimited.
+func (b *blockCache) set(id string, e cachedBlock, now time.Time, max int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.entries == nil {
+		b.entries = make(map[string]cachedBlock)
+	}
+	if !b.next.IsZero() && !now.Before(b.next) {
+		b.sweepLocked(now)
+	}
+	if _, ok := b.entries[id]; !ok && max > 0 && len(b.entries) >= max {
+		return
+	}
+	b.entries[id] = e
+	if b.next.IsZero() || e.until.Before(b.next) {
+		b.next = e.until
+	}
+}
+
+// sweep drops the expired entries and returns how many are left.
+func (b *blockCache) sweep(now time.Time) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sweepLocked(now)
+	return len(b.entries)
+}
+
+func (b *blockCache) sweepLocked(now time.Time) {
+	b.next = time.Time{}
+	for id, e := range b.entries {
+		if !now.Before(e.until) {
+			delete(b.entries, id)
+		} else if b.next.IsZero() || e.until.Before(b.next) {
+			b.next = e.until
+		}
+	}
+}
+
+func (b *blockCache) del(id string) {
+	b.mu.Lock()
+	delete(b.entries, id)
+	b.mu.Unlock()
+}
+
+func (b *blockCache) reset() {
+	b.mu.Lock()
+	b.entries = nil
+	b.next = time.Time{}
+	b.mu.Unlock()
+}
+
+// cacheBlock remembers that id is rejected for ttl, so that BlockCache can answer
+// its next checks from memory. Permanent blocks are left to the block list.
+func (rl *RateLimiter) cacheBlock(id string, res *CheckResult, ttl time.Duration) {
+	if !rl.BlockCache || rl.DryRun || ttl <= 0 {
+		return
+	}
+	now := rl.Clock.Now()
+	rl.blocked.set(id, cachedBlock{
+		until:  now.Add(ttl),
+		window: Window{Duration: res.Window, Limit: res.Limit},
+		used:   res.Used,
+	}, now, rl.maxCacheEntries())
+}
+
+// cachedBlockResult returns the rejection of a cached block of id, if it hasn't ended.
+func (rl *RateLimiter) cachedBlockResult(id string) (*CheckResult, bool) {
+	if !rl.BlockCache {
+		return nil, false
+	}
+	now := rl.Clock.Now()
+	e, ok := rl.blocked.get(id, now)
+	if !ok {
+		return nil, false
+	}
+	res := rl.newResult(e.window, e.used, 0)
+	rl.block(res, e.until.Sub(now))
+	return res, true
+}
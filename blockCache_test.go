@@ -0,0 +1,62 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlockCache(t *testing.T) {
+	clock := NewFakeClock(time.UnixMilli(time.Now().UnixMilli()))
+	mr, rl := testLimiter(t, "blockCache", WithDuration(time.Minute), WithBlockTimes(2), WithBlockDuration(time.Minute),
+		WithClock(clock), WithBlockCache())
+	rl.Allow("a")
+	if res, _ := rl.Allow("a"); res.Allowed {
+		t.Fatal("second request allowed")
+	}
+
+	// the block is answered from memory while Redis is unreachable
+	mr.Close()
+	res, err := rl.Allow("a")
+	if !IsBlocked(err) || res.Allowed || res.Used != 2 || res.Limit != 2 {
+		t.Fatalf("cached block %+v, %v", res, err)
+	}
+	if res.RetryAfter <= 0 || res.RetryAfter > time.Minute {
+		t.Fatalf("RetryAfter %v", res.RetryAfter)
+	}
+	if _, err := rl.Allow("b"); err == nil || IsBlocked(err) {
+		t.Fatalf("uncached id: %v", err)
+	}
+	clock.Advance(time.Minute)
+	if _, err := rl.Allow("a"); err == nil || IsBlocked(err) {
+		t.Fatalf("ended block still cached: %v", err)
+	}
+}
+
+func TestBlockCacheInvalidation(t *testing.T) {
+	_, rl := testLimiter(t, "blockCache", WithDuration(time.Minute), WithBlockTimes(2), WithBlockDuration(time.Minute), WithBlockCache())
+	block := func(id string) {
+		rl.Allow(id)
+		rl.Allow(id)
+	}
+	block("a")
+	if err := rl.CheckReset("a"); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := rl.Allow("a"); !res.Allowed {
+		t.Fatal("block cached after CheckReset")
+	}
+	block("b")
+	if err := rl.SetOverride("b", Override{BlockTimes: 10}, false); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := rl.Allow("b"); res.Limit != 10 {
+		t.Fatalf("block cached after SetOverride: %+v", res)
+	}
+	block("c")
+	if err := rl.SetLimit(10, time.Minute, false); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := rl.Allow("c"); res.Limit != 10 {
+		t.Fatalf("block cached after SetLimit: %+v", res)
+	}
+}
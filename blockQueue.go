@@ -0,0 +1,115 @@
+package rateLimiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	blockQueueRetryInterval = time.Second
+	blockQueueMaxAttempts   = 5
+)
+
+type queuedBlock struct {
+	entry    ListEntry
+	ttl      time.Duration
+	attempts int
+}
+
+// blockQueue holds the ids waiting to be added to the block list by AsyncBlockList
+// and anomaly detection. An id is queued once however many checks reach the limit
+// before it is added.
+type blockQueue struct {
+	mu      sync.Mutex
+	pending map[string]*queuedBlock
+	wake    chan struct{}
+}
+
+func (q *blockQueue) init() {
+	q.pending = make(map[string]*queuedBlock)
+	q.wake = make(chan struct{}, 1)
+}
+
+func (q *blockQueue) push(entry ListEntry, ttl time.Duration) {
+	q.mu.Lock()
+	if _, ok := q.pending[entry.Id]; !ok {
+		q.pending[entry.Id] = &queuedBlock{entry: entry, ttl: ttl}
+	}
+	q.mu.Unlock()
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *blockQueue) snapshot() []*queuedBlock {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	blocks := make([]*queuedBlock, 0, len(q.pending))
+	for _, b := range q.pending {
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
+
+func (q *blockQueue) done(id string) {
+	q.mu.Lock()
+	delete(q.pending, id)
+	q.mu.Unlock()
+}
+
+// autoBlock adds id to the block list after it reached BlockTimes, in the
+// background with AsyncBlockList.
+func (rl *RateLimiter) autoBlock(ctx context.Context, id string, times int) {
+	entry := ListEntry{Id: id, Reason: "BlockTimes reached", Operator: AutoBlockOperator, CreatedAt: rl.Clock.Now()}
+	if rl.AsyncBlockList {
+		rl.blockQueue.push(entry, 0)
+		return
+	}
+	if err := rl.addBlockListEntry(ctx, entry, 0, true); err != nil && err != ErrorBlockListExists {
+		rl.Logger.Error("auto block list failed", rl.fields("id", id, "err", err)...)
+	} else {
+		rl.Logger.Warn("id added to block list", rl.fields("id", id, "times", times)...)
+	}
+}
+
+// queuesBlocks reports whether the limiter runs the block queue.
+func (rl *RateLimiter) queuesBlocks() bool {
+	return rl.AsyncBlockList || rl.Anomaly != nil && rl.Anomaly.BlockDuration > 0
+}
+
+func (rl *RateLimiter) blockQueueLoop(ctx context.Context) {
+	ticker := time.NewTicker(blockQueueRetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-rl.blockQueue.wake:
+		case <-ticker.C:
+		}
+		rl.drainBlockQueue(ctx, false)
+	}
+}
+
+// drainBlockQueue adds the queued ids to the block list. Failed ids stay queued
+// for the next round until blockQueueMaxAttempts, or are dropped when final is set.
+func (rl *RateLimiter) drainBlockQueue(ctx context.Context, final bool) error {
+	var errs []error
+	for _, b := range rl.blockQueue.snapshot() {
+		err := rl.addBlockListEntry(ctx, b.entry, b.ttl, true)
+		if err == nil || err == ErrorBlockListExists {
+			rl.blockQueue.done(b.entry.Id)
+			rl.Logger.Warn("id added to block list", rl.fields("id", b.entry.Id)...)
+			continue
+		}
+		b.attempts++
+		if final || b.attempts >= blockQueueMaxAttempts {
+			rl.blockQueue.done(b.entry.Id)
+			rl.Logger.Error("auto block list failed", rl.fields("id", b.entry.Id, "attempts", b.attempts, "err", err)...)
+			errs = append(errs, err)
+		}
+	}
+	return joinErrors(errs)
+}
@@ -0,0 +1,75 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAsyncBlockList(t *testing.T) {
+	mr, rl := testLimiter(t, "asyncBlock", WithDuration(time.Minute), WithBlockTimes(2),
+		WithBlockDuration(0), WithAsyncBlockList())
+	rl.Check("a")
+	if _, err := rl.Check("a"); !IsBlocked(err) {
+		t.Fatalf("second check: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for ok, _ := mr.SIsMember(rl.blockListKey, "a"); !ok; ok, _ = mr.SIsMember(rl.blockListKey, "a") {
+		if time.Now().After(deadline) {
+			t.Fatal("auto-block not persisted by the queue")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(rl.blockQueue.snapshot()) != 0 {
+		t.Fatal("persisted id left in the queue")
+	}
+}
+
+func TestBlockQueueDedup(t *testing.T) {
+	var q blockQueue
+	q.init()
+	q.push(ListEntry{Id: "a", Reason: "first"}, 0)
+	q.push(ListEntry{Id: "a", Reason: "second"}, 0)
+	q.push(ListEntry{Id: "b"}, 0)
+	blocks := q.snapshot()
+	if len(blocks) != 2 {
+		t.Fatalf("%d queued blocks, want 2", len(blocks))
+	}
+	for _, b := range blocks {
+		if b.entry.Id == "a" && b.entry.Reason != "first" {
+			t.Fatalf("queued entry replaced: %+v", b.entry)
+		}
+	}
+}
+
+func TestBlockQueueDrain(t *testing.T) {
+	l := newTestLogger()
+	mr, rl := testLimiter(t, "asyncBlock", WithDuration(time.Minute), WithBlockTimes(2),
+		WithBlockDuration(0), WithAsyncBlockList(), WithLogger(l))
+	rl.blockQueue.push(ListEntry{Id: "a", Operator: AutoBlockOperator}, 0)
+	if err := rl.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := mr.SIsMember(rl.blockListKey, "a"); !ok {
+		t.Fatal("queued id not persisted on Close")
+	}
+
+	// without AsyncBlockList no loop drains the queue behind the test's back
+	mr, rl = testLimiter(t, "asyncBlock", WithDuration(time.Minute), WithBlockTimes(2),
+		WithBlockDuration(0), WithLogger(l))
+	rl.blockQueue.init()
+	mr.Close()
+	rl.blockQueue.push(ListEntry{Id: "b", Operator: AutoBlockOperator}, 0)
+	if err := rl.drainBlockQueue(context.Background(), false); err != nil {
+		t.Fatalf("first failure reported: %v", err)
+	}
+	if blocks := rl.blockQueue.snapshot(); len(blocks) != 1 || blocks[0].attempts != 1 {
+		t.Fatalf("failed id not kept for a retry: %v", blocks)
+	}
+	if err := rl.drainBlockQueue(context.Background(), true); err == nil {
+		t.Fatal("final drain with Redis down succeeded")
+	}
+	if len(rl.blockQueue.snapshot()) != 0 || !l.logged("error", "auto block list failed") {
+		t.Fatal("final drain kept or didn't log the failed id")
+	}
+}
@@ -0,0 +1,204 @@
+package rateLimiter
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	DefaultBloomFalsePositive = 0.01
+	DefaultBloomInterval      = 10 * time.Minute
+	bloomScanCount            = 10000
+)
+
+// bloomFilter is an immutable Bloom filter; it is rebuilt rather than updated.
+type bloomFilter struct {
+	bits []uint64
+	m    uint64
+	k    uint64
+}
+
+func newBloomFilter(n int, p float64) *bloomFilter {
+	if n < 1 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &bloomFilter{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+// bloomHashes derives the two hashes of the Kirsch-Mitzenmacher scheme from FNV-1a.
+func bloomHashes(s string) (uint64, uint64) {
+	h := uint64(14695981039346656037)
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= 1099511628211
+	}
+	h2 := h ^ (h >> 33)
+	h2 *= 0xff51afd7ed558ccd
+	h2 ^= h2 >> 33
+	return h, h2 | 1
+}
+
+func (f *bloomFilter) add(s string) {
+	h1, h2 := bloomHashes(s)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+func (f *bloomFilter) test(s string) bool {
+	h1, h2 := bloomHashes(s)
+	for i := uint64(0); i < f.k; i++ {
+		bit := (h1 + i*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// loadBloomBlockList replaces the local block list with Config.BlockList and the
+// temporary, CIDR, wildcard and country entries of the Redis block list, and builds a Bloom
+// filter of its other, permanent entries. It doesn't report list changes: the
+// entries only move between the list and the filter. Block list changes wait
+// for the scan, which runs inside rl.blockIds.apply.
+func (rl *RateLimiter) loadBloomBlockList(ctx context.Context) error {
+	var purged []string
+	err := rl.blockIds.apply(func() error {
+		var err error
+		if purged, err = rl.purgeExpiredIds(ctx, rl.blockListKey, rl.blockTTLKey); err != nil {
+			return err
+		}
+		return rl.buildBloomBlockList(ctx)
+	})
+	rl.reportPurged(rl.blockListKey, purged)
+	return err
+}
+
+// addBloomBlockListBatch adds ids permanently to the Redis block list and then
+// rebuilds the local block list and filter like loadBloomBlockList, so that a large
+// batch ends up in the filter rather than in memory. It returns the ids that were
+// not permanent entries before.
+func (rl *RateLimiter) addBloomBlockListBatch(ctx context.Context, ids []string) ([]string, error) {
+	pipe := rl.Redis.TxPipeline()
+	sadds := make([]*goredis.IntCmd, len(ids))
+	zrems := make([]*goredis.IntCmd, len(ids))
+	for i, id := range ids {
+		sadds[i] = pipe.SAdd(ctx, rl.blockListKey, id)
+		zrems[i] = pipe.ZRem(ctx, rl.blockTTLKey, id)
+	}
+	err := rl.blockIds.apply(func() error {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+		return rl.buildBloomBlockList(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var changed []string
+	for i, id := range ids {
+		if sadds[i].Val() == 1 || zrems[i].Val() == 1 {
+			changed = append(changed, id)
+		}
+	}
+	return changed, nil
+}
+
+func (rl *RateLimiter) buildBloomBlockList(ctx context.Context) error {
+	n, err := rl.Redis.SCard(ctx, rl.blockListKey).Result()
+	if err != nil {
+		return err
+	}
+	vals, err := rl.Redis.ZRangeWithScores(ctx, rl.blockTTLKey, 0, -1).Result()
+	if err != nil {
+		return err
+	}
+	expiry := make(map[string]time.Time, len(vals))
+	for _, z := range vals {
+		expiry[z.Member.(string)] = time.UnixMilli(int64(z.Score))
+	}
+	p := rl.BloomFalsePositive
+	if p == 0 {
+		p = DefaultBloomFalsePositive
+	}
+	filter := newBloomFilter(int(n), p)
+	local := rl.seedIds(rl.Config.BlockList)
+	for id := range expiry {
+		local = append(local, id)
+	}
+	var cursor uint64
+	for {
+		ids, next, err := rl.Redis.SScan(ctx, rl.blockListKey, cursor, "", bloomScanCount).Result()
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if _, ok := expiry[id]; ok {
+				continue
+			}
+			if _, ok := parsePrefix(id); ok || isGlob(id) || strings.HasPrefix(id, CountryPrefix) {
+				local = append(local, id)
+			} else {
+				filter.add(id)
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	rl.blockIds.replace(local, expiry)
+	rl.bloom.Store(filter)
+	rl.synced()
+	return nil
+}
+
+// inBloomBlockList reports whether id is a permanent entry of the Redis block list
+// left out of the local one. Ids passing the filter are verified with SISMEMBER;
+// if that fails the id is treated as not blocked, the limiter still counts it.
+func (rl *RateLimiter) inBloomBlockList(id string) bool {
+	filter := rl.bloom.Load()
+	if filter == nil || !filter.test(id) {
+		return false
+	}
+	ctx, cancel := rl.checkContext(context.Background())
+	defer cancel()
+	ok, err := rl.Redis.SIsMember(ctx, rl.blockListKey, id).Result()
+	if err != nil {
+		rl.Logger.Error("block list lookup failed", rl.fields("id", id, "err", err)...)
+		return false
+	}
+	return ok
+}
+
+func (rl *RateLimiter) bloomLoop(ctx context.Context) {
+	interval := rl.BloomInterval
+	if interval == 0 {
+		interval = DefaultBloomInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := rl.loadBloomBlockList(ctx); err != nil && ctx.Err() == nil {
+				rl.Logger.Error("rebuild block list filter failed", rl.fields("err", err)...)
+			}
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package rateLimiter
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBloomBlockList(t *testing.T) {
+	mr, r := testRedis(t)
+	seed, err := NewLimiter("bloom", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { seed.Close(context.Background()) })
+	for _, id := range []string{"a", "10.0.0.0/8", "bot-*"} {
+		if err := seed.AddBlockList(id, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := seed.AddBlockListTTL("tmp", time.Hour, false); err != nil {
+		t.Fatal(err)
+	}
+
+	rl, err := NewLimiter("bloom", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(10),
+		WithBloomBlockList(0.001, time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rl.Close(context.Background()) })
+	if rl.blockIds.has("a") {
+		t.Fatal("permanent entry kept in the local block list")
+	}
+	for _, id := range []string{"10.0.0.0/8", "bot-*", "tmp"} {
+		if !rl.blockIds.has(id) {
+			t.Fatalf("%s left out of the local block list", id)
+		}
+	}
+	for id, blocked := range map[string]bool{"a": true, "10.1.2.3": true, "bot-1": true, "tmp": true, "b": false} {
+		if _, err := rl.Check(id); IsBlocked(err) != blocked {
+			t.Errorf("check of %s: %v", id, err)
+		}
+	}
+
+	// a filter hit that Redis doesn't confirm isn't blocked
+	mr.SRem(rl.blockListKey, "a")
+	if _, err := rl.Check("a"); err != nil {
+		t.Fatalf("check of an id removed behind the filter: %v", err)
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	const n = 10000
+	f := newBloomFilter(n, 0.01)
+	for i := 0; i < n; i++ {
+		f.add("in-" + strconv.Itoa(i))
+	}
+	for i := 0; i < n; i++ {
+		if !f.test("in-" + strconv.Itoa(i)) {
+			t.Fatalf("in-%d missing", i)
+		}
+	}
+	fp := 0
+	for i := 0; i < n; i++ {
+		if f.test("out-" + strconv.Itoa(i)) {
+			fp++
+		}
+	}
+	if fp > n/50 {
+		t.Fatalf("%d false positives in %d, want about 1%%", fp, n)
+	}
+}
@@ -0,0 +1,156 @@
+package rateLimiter
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	stderrors "errors"
+	"strings"
+	"time"
+)
+
+const DefaultBypassMaxTTL = time.Hour
+
+var ErrorBypassToken = stderrors.New("invalid bypass token")
+
+// BypassToken is what a signed bypass token grants. It is verified with
+// Config.BypassKeys only, without Redis, so it can't be revoked before it
+// expires: keep it short-lived.
+type BypassToken struct {
+	Limiter    string    //Name of the limiter it is valid for, ""=every limiter sharing the keys
+	Id         string    //id it is valid for, ""=any id, e.g. a job whose IP changes
+	Multiplier int       //0=bypass the limits like the white list, otherwise raise them this many times like the trusted list
+	ExpiresAt  time.Time //set by IssueBypassToken
+}
+
+type bypassClaims struct {
+	Limiter    string `json:"l,omitempty"`
+	Id         string `json:"i,omitempty"`
+	Multiplier int    `json:"m,omitempty"`
+	ExpiresAt  int64  `json:"e"`
+}
+
+type bypassTokenKey struct{}
+
+type bypassMultiplierKey struct{}
+
+// WithBypassToken attaches a token presented by the caller to ctx; checks with
+// ctx verify it and, if it is valid for the id, apply it.
+func WithBypassToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, bypassTokenKey{}, token)
+}
+
+func bypassTokenFrom(ctx context.Context) string {
+	token, _ := ctx.Value(bypassTokenKey{}).(string)
+	return token
+}
+
+func bypassMultiplier(ctx context.Context) int {
+	mul, _ := ctx.Value(bypassMultiplierKey{}).(int)
+	return mul
+}
+
+func signBypass(key []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// IssueBypassToken signs t with the first of BypassKeys, valid for ttl. The Id
+// of t is sanitized like the ids it will be compared with.
+func (rl *RateLimiter) IssueBypassToken(t BypassToken, ttl time.Duration) (string, error) {
+	if len(rl.BypassKeys) == 0 {
+		return "", stderrors.New("BypassKeys未设置")
+	}
+	if ttl <= 0 || ttl > rl.bypassMaxTTL() {
+		return "", stderrors.New("ttl必须大于0且不超过BypassMaxTTL")
+	}
+	if t.Multiplier < 0 {
+		return "", stderrors.New("Multiplier不能小于0")
+	}
+	if t.Id != "" {
+		id, err := rl.sanitizeId(t.Id)
+		if err != nil {
+			return "", err
+		}
+		t.Id = id
+	}
+	payload, err := json.Marshal(bypassClaims{
+		Limiter:    t.Limiter,
+		Id:         t.Id,
+		Multiplier: t.Multiplier,
+		ExpiresAt:  rl.Clock.Now().Add(ttl).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(signBypass(rl.BypassKeys[0], encoded)), nil
+}
+
+// VerifyBypassToken checks the signature of token against every key of
+// BypassKeys, so keys can be rotated, and that it is meant for this limiter and
+// hasn't expired. Tokens expiring more than BypassMaxTTL ahead are rejected
+// too, in case a leaked key was used to mint long-lived ones.
+func (rl *RateLimiter) VerifyBypassToken(token string) (BypassToken, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok || len(rl.BypassKeys) == 0 {
+		return BypassToken{}, ErrorBypassToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return BypassToken{}, ErrorBypassToken
+	}
+	signed := false
+	for _, key := range rl.BypassKeys {
+		if hmac.Equal(mac, signBypass(key, encoded)) {
+			signed = true
+			break
+		}
+	}
+	if !signed {
+		return BypassToken{}, ErrorBypassToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return BypassToken{}, ErrorBypassToken
+	}
+	var c bypassClaims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return BypassToken{}, ErrorBypassToken
+	}
+	now := rl.Clock.Now()
+	expiresAt := time.Unix(c.ExpiresAt, 0)
+	if !now.Before(expiresAt) || expiresAt.Sub(now) > rl.bypassMaxTTL() ||
+		(c.Limiter != "" && c.Limiter != rl.Name) || c.Multiplier < 0 {
+		return BypassToken{}, ErrorBypassToken
+	}
+	return BypassToken{Limiter: c.Limiter, Id: c.Id, Multiplier: c.Multiplier, ExpiresAt: expiresAt}, nil
+}
+
+func (rl *RateLimiter) bypassMaxTTL() time.Duration {
+	if rl.BypassMaxTTL == 0 {
+		return DefaultBypassMaxTTL
+	}
+	return rl.BypassMaxTTL
+}
+
+// bypassToken returns the token on ctx if it is valid for id. Invalid tokens are
+// ignored, so the request is limited as if it had none.
+func (rl *RateLimiter) bypassToken(ctx context.Context, id string) (BypassToken, bool) {
+	token := bypassTokenFrom(ctx)
+	if token == "" || len(rl.BypassKeys) == 0 || id == globalId {
+		return BypassToken{}, false
+	}
+	t, err := rl.VerifyBypassToken(token)
+	if err == nil && t.Id != "" && t.Id != id {
+		err = ErrorBypassToken
+	}
+	if err != nil {
+		rl.Logger.Debug("bypass token rejected", rl.fields("id", id)...)
+		return BypassToken{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,69 @@
+package rateLimiter
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBypassToken(t *testing.T) {
+	oldKey, key := bytes.Repeat([]byte("o"), 32), bytes.Repeat([]byte("k"), 32)
+	clock := NewFakeClock(time.Now())
+	_, old := testLimiter(t, "bypass", WithDuration(time.Minute), WithBlockTimes(2), WithClock(clock),
+		WithBypassKeys(0, oldKey))
+	_, rl := testLimiter(t, "bypass", WithDuration(time.Minute), WithBlockTimes(2), WithClock(clock),
+		WithBypassKeys(0, key, oldKey))
+	issue := func(l *RateLimiter, bt BypassToken, ttl time.Duration) string {
+		token, err := l.IssueBypassToken(bt, ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	token := issue(rl, BypassToken{}, time.Minute)
+	bt, err := rl.VerifyBypassToken(token)
+	if err != nil || !bt.ExpiresAt.Equal(clock.Now().Add(time.Minute).Truncate(time.Second)) {
+		t.Fatalf("verify: %+v, %v", bt, err)
+	}
+	// tokens signed with a rotated key stay valid, the other way round they aren't
+	if _, err := rl.VerifyBypassToken(issue(old, BypassToken{}, time.Minute)); err != nil {
+		t.Fatalf("token of the old key: %v", err)
+	}
+	if _, err := old.VerifyBypassToken(token); err != ErrorBypassToken {
+		t.Fatalf("token of an unknown key: %v", err)
+	}
+	for name, token := range map[string]string{
+		"tampered":      token[:len(token)-2] + "xx",
+		"other limiter": issue(rl, BypassToken{Limiter: "other"}, time.Minute),
+		"garbage":       "not a token",
+	} {
+		if _, err := rl.VerifyBypassToken(token); err != ErrorBypassToken {
+			t.Errorf("%s token: %v", name, err)
+		}
+	}
+	if _, err := rl.IssueBypassToken(BypassToken{}, 2*time.Hour); err == nil {
+		t.Fatal("token past BypassMaxTTL issued")
+	}
+
+	// the token bypasses the limits of the ids it is valid for
+	ctx := WithBypassToken(context.Background(), issue(rl, BypassToken{Id: "a"}, time.Minute))
+	for i := 0; i < 5; i++ {
+		if _, err := rl.CheckCtx(ctx, "a"); err != nil {
+			t.Fatalf("check %d with a bypass token: %v", i+1, err)
+		}
+	}
+	rl.CheckCtx(ctx, "b")
+	if _, err := rl.CheckCtx(ctx, "b"); !IsBlocked(err) {
+		t.Fatalf("token of another id applied: %v", err)
+	}
+	ctx = WithBypassToken(context.Background(), issue(rl, BypassToken{Multiplier: 3}, time.Minute))
+	if res, err := rl.AllowCtx(ctx, "c"); err != nil || res.Limit != 6 {
+		t.Fatalf("check with a raising token: %+v, %v", res, err)
+	}
+
+	clock.Advance(time.Minute)
+	if _, err := rl.VerifyBypassToken(token); err != ErrorBypassToken {
+		t.Fatalf("expired token: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package rateLimiter
+
+import (
+	"context"
+	stderrors "errors"
+)
+
+func (rl *RateLimiter) CheckN(id string, n int) (int, error) {
+	return rl.CheckNCtx(context.Background(), id, n)
+}
+
+func (rl *RateLimiter) CheckNCtx(ctx context.Context, id string, n int) (int, error) {
+	res, err := rl.AllowNCtx(ctx, id, n)
+	if res == nil {
+		return 0, err
+	}
+	return res.Used, err
+}
+
+func (rl *RateLimiter) AllowN(id string, n int) (*CheckResult, error) {
+	return rl.AllowNCtx(context.Background(), id, n)
+}
+
+// AllowNCtx counts n units against id at once. A request that doesn't fit in what
+// is left of a window is rejected without being counted.
+func (rl *RateLimiter) AllowNCtx(ctx context.Context, id string, n int) (*CheckResult, error) {
+	if n <= 0 {
+		return nil, stderrors.New("n必须大于0")
+	}
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return nil, err
+	}
+	return rl.checked(ctx, id, func(ctx context.Context) (*CheckResult, error) {
+		return rl.allow(ctx, id, n)
+	})
+}
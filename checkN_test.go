@@ -0,0 +1,29 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllowN(t *testing.T) {
+	_, rl := testLimiter(t, "checkN", WithDuration(time.Minute), WithBlockTimes(10), WithBlockDuration(time.Hour))
+	if _, err := rl.AllowN("a", 0); err == nil {
+		t.Fatal("n=0 accepted")
+	}
+	res, err := rl.AllowN("a", 4)
+	if err != nil || res.Used != 4 || res.Remaining != 6 {
+		t.Fatalf("AllowN(4) = %+v, %v", res, err)
+	}
+	if used, err := rl.CheckN("a", 5); err != nil || used != 9 {
+		t.Fatalf("CheckN(5) = %d, %v", used, err)
+	}
+	// a request that doesn't fit is rejected without being counted
+	res, err = rl.AllowN("a", 2)
+	if !IsBlocked(err) || res.Used != 9 || res.RetryAfter > time.Minute {
+		t.Fatalf("AllowN(2) over the limit = %+v, %v", res, err)
+	}
+	res, err = rl.AllowN("a", 1)
+	if !IsBlocked(err) || res.Used != 10 || res.RetryAfter != time.Hour {
+		t.Fatalf("AllowN(1) reaching the limit = %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,91 @@
+package chiLimiter
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/rate-limiter/httpLimiter"
+)
+
+type checkedKey struct {
+	rl *rateLimiter.RateLimiter
+}
+
+// Middleware is httpLimiter.Middleware for chi routers. A request reaching the
+// same limiter more than once, e.g. when it is bound to a router and again to one
+// of its subrouters, is counted only the first time.
+func Middleware(rl *rateLimiter.RateLimiter, opts ...httpLimiter.Option) func(http.Handler) http.Handler {
+	limit := httpLimiter.Middleware(rl, opts...)
+	return func(next http.Handler) http.Handler {
+		limited := limit(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Context().Value(checkedKey{rl}) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			limited.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), checkedKey{rl}, true)))
+		})
+	}
+}
+
+// With returns an inline router of r whose routes are limited by rl, e.g.
+//
+//	chiLimiter.With(r, loginLimiter).Post("/login", login)
+//
+// Its middleware runs after routing, so URLParam keys work with it.
+func With(r chi.Router, rl *rateLimiter.RateLimiter, opts ...httpLimiter.Option) chi.Router {
+	return r.With(Middleware(rl, opts...))
+}
+
+// Group adds a group of routes to r, limited by rl on top of the limiters of r.
+func Group(r chi.Router, rl *rateLimiter.RateLimiter, fn func(r chi.Router), opts ...httpLimiter.Option) chi.Router {
+	return r.Group(func(r chi.Router) {
+		r.Use(Middleware(rl, opts...))
+		fn(r)
+	})
+}
+
+// Route mounts a subrouter at pattern whose routes are limited by rl on top of
+// the limiters of r, e.g.
+//
+//	chiLimiter.Route(r, "/api", apiLimiter, func(r chi.Router) {
+//		chiLimiter.Route(r, "/search", searchLimiter, func(r chi.Router) { ... })
+//	})
+func Route(r chi.Router, pattern string, rl *rateLimiter.RateLimiter, fn func(r chi.Router), opts ...httpLimiter.Option) chi.Router {
+	return r.Route(pattern, func(r chi.Router) {
+		r.Use(Middleware(rl, opts...))
+		fn(r)
+	})
+}
+
+// URLParam uses the URL parameter name of the route, e.g. the {tenant} of
+// "/{tenant}/orders". Parameters are only known once chi matched the part of the
+// pattern declaring them: use it with With, or in a subrouter mounted below the
+// parameter.
+func URLParam(name string) httpLimiter.KeyFunc {
+	return func(r *http.Request) (string, error) {
+		if v := chi.URLParam(r, name); v != "" {
+			return v, nil
+		}
+		return "", httpLimiter.ErrorNoKey
+	}
+}
+
+// RoutePattern prefixes the id extracted by key with the route pattern chi
+// matched so far, so that one limiter counts each route separately.
+func RoutePattern(key httpLimiter.KeyExtractor) httpLimiter.KeyFunc {
+	return func(r *http.Request) (string, error) {
+		id, err := key.Extract(r)
+		if err != nil || id == "" {
+			return id, err
+		}
+		if rctx := chi.RouteContext(r.Context()); rctx != nil {
+			if pattern := rctx.RoutePattern(); pattern != "" {
+				return pattern + " " + id, nil
+			}
+		}
+		return id, nil
+	}
+}
@@ -0,0 +1,66 @@
+package chiLimiter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-estar/rate-limiter/httpLimiter"
+)
+
+var noContent = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+})
+
+func serve(h http.Handler, path string) int {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w.Code
+}
+
+func TestMiddlewareCountsOnce(t *testing.T) {
+	rl := testLimiter(t, "chi")
+	r := chi.NewRouter()
+	r.Use(Middleware(rl))
+	Route(r, "/api", rl, func(r chi.Router) {
+		r.Get("/items", noContent)
+	})
+	// the limiter of the router and of the subrouter count the request once
+	if code := serve(r, "/api/items"); code != http.StatusNoContent {
+		t.Fatalf("first request: status %d", code)
+	}
+	if code := serve(r, "/api/items"); code != http.StatusTooManyRequests {
+		t.Fatalf("second request: status %d", code)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	api, login := testLimiter(t, "api"), testLimiter(t, "login")
+	r := chi.NewRouter()
+	With(r, login, httpLimiter.WithKeyFunc(URLParam("tenant"))).Get("/{tenant}/login", noContent)
+	Group(r, api, func(r chi.Router) {
+		r.Get("/a", noContent)
+		r.Get("/b", noContent)
+	}, httpLimiter.WithKeyFunc(RoutePattern(httpLimiter.KeyFunc(httpLimiter.RemoteIP))))
+
+	if code := serve(r, "/t1/login"); code != http.StatusNoContent {
+		t.Fatalf("login of t1: status %d", code)
+	}
+	if code := serve(r, "/t1/login"); code != http.StatusTooManyRequests {
+		t.Fatalf("second login of t1: status %d", code)
+	}
+	if code := serve(r, "/t2/login"); code != http.StatusNoContent {
+		t.Fatalf("login of t2 counted with t1: status %d", code)
+	}
+
+	// each route of the group has its own count
+	for _, path := range []string{"/a", "/b"} {
+		if code := serve(r, path); code != http.StatusNoContent {
+			t.Fatalf("%s: status %d", path, code)
+		}
+	}
+	if code := serve(r, "/a"); code != http.StatusTooManyRequests {
+		t.Fatalf("second request of /a: status %d", code)
+	}
+}
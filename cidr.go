@@ -0,0 +1,137 @@
+package rateLimiter
+
+import (
+	"net/netip"
+	"strings"
+	"sync"
+)
+
+// prefixTrie is a binary radix tree over IP address bits holding the CIDR entries of a list.
+type prefixTrie struct {
+	mu   sync.RWMutex
+	v4   *trieNode
+	v6   *trieNode
+	size int
+}
+
+type trieNode struct {
+	child [2]*trieNode
+	count int //number of list entries ending at this node
+}
+
+func parsePrefix(s string) (netip.Prefix, bool) {
+	if !strings.Contains(s, "/") {
+		return netip.Prefix{}, false
+	}
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	if p.Addr().Is4In6() {
+		p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+		if p.Bits() < 0 {
+			return netip.Prefix{}, false
+		}
+	}
+	return p.Masked(), true
+}
+
+func addrBit(b []byte, i int) int {
+	return int(b[i/8]>>(7-i%8)) & 1
+}
+
+func addrBytes(addr netip.Addr) []byte {
+	if addr.Is4() {
+		b := addr.As4()
+		return b[:]
+	}
+	b := addr.As16()
+	return b[:]
+}
+
+func (t *prefixTrie) root(addr netip.Addr, create bool) *trieNode {
+	if addr.Is4() {
+		if t.v4 == nil && create {
+			t.v4 = &trieNode{}
+		}
+		return t.v4
+	}
+	if t.v6 == nil && create {
+		t.v6 = &trieNode{}
+	}
+	return t.v6
+}
+
+// insert adds s if it is a CIDR entry; other ids are ignored.
+func (t *prefixTrie) insert(s string) {
+	p, ok := parsePrefix(s)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	node := t.root(p.Addr(), true)
+	b := addrBytes(p.Addr())
+	for i := 0; i < p.Bits(); i++ {
+		bit := addrBit(b, i)
+		if node.child[bit] == nil {
+			node.child[bit] = &trieNode{}
+		}
+		node = node.child[bit]
+	}
+	node.count++
+	t.size++
+}
+
+func (t *prefixTrie) remove(s string) {
+	p, ok := parsePrefix(s)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	node := t.root(p.Addr(), false)
+	b := addrBytes(p.Addr())
+	for i := 0; i < p.Bits() && node != nil; i++ {
+		node = node.child[addrBit(b, i)]
+	}
+	if node != nil && node.count > 0 {
+		node.count--
+		t.size--
+	}
+}
+
+func (t *prefixTrie) reset(entries []string) {
+	t.mu.Lock()
+	t.v4, t.v6, t.size = nil, nil, 0
+	t.mu.Unlock()
+	for _, s := range entries {
+		t.insert(s)
+	}
+}
+
+// match reports whether id is an IP address covered by one of the entries.
+func (t *prefixTrie) match(id string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if t.size == 0 {
+		return false
+	}
+	addr, err := netip.ParseAddr(id)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	node := t.root(addr, false)
+	b := addrBytes(addr)
+	for i := 0; node != nil; i++ {
+		if node.count > 0 {
+			return true
+		}
+		if i == addr.BitLen() {
+			break
+		}
+		node = node.child[addrBit(b, i)]
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPrefixTrie(t *testing.T) {
+	var trie prefixTrie
+	trie.reset([]string{"10.0.0.0/8", "192.168.1.0/24", "2001:db8::/32", "::ffff:172.16.0.0/108", "not-a-cidr"})
+	for id, want := range map[string]bool{
+		"10.1.2.3":      true,
+		"11.0.0.1":      false,
+		"192.168.1.200": true,
+		"192.168.2.1":   false,
+		"2001:db8::1":   true,
+		"2001:db9::1":   false,
+		"172.16.5.5":    true,
+		"not-a-cidr":    false,
+		"alice":         false,
+	} {
+		if got := trie.match(id); got != want {
+			t.Errorf("match(%q) = %v, want %v", id, got, want)
+		}
+	}
+	trie.insert("10.0.0.0/8")
+	trie.remove("10.0.0.0/8")
+	if !trie.match("10.1.2.3") {
+		t.Fatal("removing one of two equal entries dropped both")
+	}
+	trie.remove("10.0.0.0/8")
+	if trie.match("10.1.2.3") {
+		t.Fatal("removed entry still matches")
+	}
+}
+
+func TestCIDRLists(t *testing.T) {
+	_, rl := testLimiter(t, "cidr", WithDuration(time.Minute), WithBlockTimes(10),
+		WithWhiteList("10.0.0.0/24"), WithBlockList("10.0.0.0/8"))
+	if _, err := rl.Check("10.0.0.5"); err != nil {
+		t.Fatalf("white listed subnet: %v", err)
+	}
+	if _, err := rl.Check("10.2.0.5"); !IsBlocked(err) {
+		t.Fatalf("block listed subnet: %v", err)
+	}
+	if ids, _ := rl.GetBlockList("10.2.0.5"); len(ids) != 1 {
+		t.Fatalf("GetBlockList of a covered address = %v", ids)
+	}
+	if err := rl.RemoveBlockList("10.0.0.0/8", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rl.Check("10.2.0.5"); err != nil {
+		t.Fatalf("removed subnet still blocks: %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	_, rl := testLimiter(t, "clock", WithDuration(time.Minute), WithBlockTimes(1),
+		WithBlockDuration(time.Hour), WithClock(clock))
+	res, err := rl.Allow("a")
+	if !IsBlocked(err) || !res.ResetAt.Equal(start.Add(time.Hour)) {
+		t.Fatalf("ResetAt %v, %v, want %v", res.ResetAt, err, start.Add(time.Hour))
+	}
+	clock.Advance(time.Minute)
+	if got := clock.Since(start); got != time.Minute {
+		t.Fatalf("Since = %v", got)
+	}
+	res, _ = rl.Allow("b")
+	if !res.ResetAt.Equal(start.Add(time.Minute + time.Hour)) {
+		t.Fatalf("ResetAt %v ignores the advanced clock", res.ResetAt)
+	}
+}
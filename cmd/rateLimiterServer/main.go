@@ -0,0 +1,113 @@
+// Command rateLimiterServer serves the limiters declared in a LimitsDocument over
+// gRPC: the RateLimiter check service, the RateLimiterAdmin service and the Envoy
+// rate limit service, so that services in any language share the same limits.
+// The admin service changes the lists and limits of every limiter, so the server
+// refuses to start without -token or -admin-token unless -insecure is given.
+// Run several servers against the same Redis with -sync-channel, so that the
+// changes made through one server's admin service reach the others at once.
+//
+//	rateLimiterServer -limits limits.yaml -redis 127.0.0.1:6379 -token secret
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	rlsv3 "github.com/envoyproxy/go-control-plane/envoy/service/ratelimit/v3"
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/rate-limiter/envoyLimiter"
+	"github.com/go-estar/rate-limiter/grpcLimiter"
+	"github.com/go-estar/rate-limiter/grpcLimiter/adminpb"
+	"github.com/go-estar/rate-limiter/grpcLimiter/limiterpb"
+	"github.com/go-estar/redis"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func main() {
+	addr := flag.String("addr", ":8081", "gRPC listen address")
+	limits := flag.String("limits", "", "LimitsDocument declaring the limiters, .json, .yaml or .yml")
+	redisAddr := flag.String("redis", "127.0.0.1:6379", "Redis address")
+	redisPassword := flag.String("redis-password", "", "Redis password")
+	redisDB := flag.Int("redis-db", 0, "Redis database")
+	token := flag.String("token", "", "bearer token required by every call, empty=no authentication")
+	adminToken := flag.String("admin-token", "", "bearer token required by admin calls, empty=-token")
+	insecure := flag.Bool("insecure", false, "serve the admin service without a token, for trusted networks only")
+	resync := flag.Duration("resync", time.Minute, "reload the lists, limits, overrides and mode from Redis this often, for changes made by other servers")
+	syncChannel := flag.String("sync-channel", "", "Redis pub/sub channel prefix used to replicate admin changes to the other servers, empty=rely on -resync")
+	flag.Parse()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	if *limits == "" {
+		logger.Error("-limits is required")
+		os.Exit(2)
+	}
+	if *token == "" && *adminToken == "" && !*insecure {
+		logger.Error("-token or -admin-token is required, or -insecure to serve the admin service without authentication")
+		os.Exit(2)
+	}
+
+	r := redis.New(&redis.Config{Addr: *redisAddr, Password: *redisPassword, Database: *redisDB})
+	m, err := rateLimiter.LoadManagerFile(&rateLimiter.Config{
+		Redis:          r,
+		Logger:         logger,
+		ResyncInterval: *resync,
+		SyncChannel:    *syncChannel,
+	}, *limits)
+	if err != nil {
+		logger.Error("load limits failed", "err", err)
+		os.Exit(1)
+	}
+	if *syncChannel != "" {
+		for _, rl := range m.List() {
+			if err := rl.StartSync(context.Background()); err != nil {
+				logger.Error("start sync failed", "limiter", rl.Name, "err", err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	checkAuth, adminAuth := grpcLimiter.Authenticator(grpcLimiter.NoAuth), grpcLimiter.Authenticator(grpcLimiter.NoAuth)
+	if *token != "" {
+		checkAuth = grpcLimiter.BearerToken(*token, "")
+		adminAuth = grpcLimiter.BearerToken(*token, "admin")
+	}
+	if *adminToken != "" {
+		adminAuth = grpcLimiter.BearerToken(*adminToken, "admin")
+	}
+	if *token == "" && *adminToken == "" {
+		logger.Warn("admin service served without authentication, -insecure is set")
+	}
+	srv := grpc.NewServer()
+	limiterpb.RegisterRateLimiterServer(srv, grpcLimiter.NewManagerLimiterServer(checkAuth, m))
+	adminpb.RegisterRateLimiterAdminServer(srv, grpcLimiter.NewManagerAdminServer(adminAuth, *syncChannel != "", m))
+	rlsv3.RegisterRateLimitServiceServer(srv, envoyLimiter.NewManagerServer(nil, m))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	lis, err := net.Listen("tcp", *addr)
+	if err != nil {
+		logger.Error("listen failed", "err", err)
+		os.Exit(1)
+	}
+	go func() {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		<-sig
+		srv.GracefulStop()
+	}()
+	logger.Info("serving", "addr", lis.Addr().String(), "limiters", len(m.List()))
+	if err := srv.Serve(lis); err != nil {
+		logger.Error("serve failed", "err", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := m.CloseAll(ctx); err != nil {
+		logger.Error("close limiters failed", "err", err)
+	}
+}
@@ -0,0 +1,160 @@
+package connectLimiter
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"connectrpc.com/connect"
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/rate-limiter/httpLimiter"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var ErrorNoKey = errors.New("no rate limit key")
+
+// KeyFunc extracts the id to rate limit from an incoming call.
+type KeyFunc func(ctx context.Context, spec connect.Spec, peer connect.Peer, header http.Header) (string, error)
+
+// PeerAddr uses the host of the peer address.
+func PeerAddr(ctx context.Context, spec connect.Spec, peer connect.Peer, header http.Header) (string, error) {
+	if peer.Addr == "" {
+		return "", ErrorNoKey
+	}
+	host, _, err := net.SplitHostPort(peer.Addr)
+	if err != nil {
+		return peer.Addr, nil
+	}
+	return host, nil
+}
+
+// Header uses the value of the request header name, e.g. an API key or the
+// client IP set by a trusted proxy.
+func Header(name string) KeyFunc {
+	return func(ctx context.Context, spec connect.Spec, peer connect.Peer, header http.Header) (string, error) {
+		if v := header.Get(name); v != "" {
+			return v, nil
+		}
+		return "", ErrorNoKey
+	}
+}
+
+// Procedure uses the procedure name, limiting each procedure as a whole.
+func Procedure(ctx context.Context, spec connect.Spec, peer connect.Peer, header http.Header) (string, error) {
+	return spec.Procedure, nil
+}
+
+type config struct {
+	key          KeyFunc
+	perProcedure bool
+	skipper      func(ctx context.Context, spec connect.Spec) bool
+}
+
+type Option func(*config)
+
+// WithKeyFunc sets how the id is extracted; the default is PeerAddr.
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(c *config) {
+		c.key = fn
+	}
+}
+
+// WithPerProcedure counts every procedure separately by prefixing the id with it.
+func WithPerProcedure() Option {
+	return func(c *config) {
+		c.perProcedure = true
+	}
+}
+
+// WithSkipper lets calls for which fn returns true through unchecked.
+func WithSkipper(fn func(ctx context.Context, spec connect.Spec) bool) Option {
+	return func(c *config) {
+		c.skipper = fn
+	}
+}
+
+type interceptor struct {
+	rl *rateLimiter.RateLimiter
+	c  *config
+}
+
+// NewInterceptor rate limits the unary calls and the opening of the streams of
+// the handlers it is passed to with connect.WithInterceptors. Clients are left
+// alone.
+func NewInterceptor(rl *rateLimiter.RateLimiter, opts ...Option) connect.Interceptor {
+	c := &config{key: PeerAddr}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return &interceptor{rl: rl, c: c}
+}
+
+func (i *interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		if err := i.check(ctx, req.Spec(), req.Peer(), req.Header()); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+func (i *interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (i *interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		if err := i.check(ctx, conn.Spec(), conn.Peer(), conn.RequestHeader()); err != nil {
+			return err
+		}
+		return next(ctx, conn)
+	}
+}
+
+func (i *interceptor) check(ctx context.Context, spec connect.Spec, peer connect.Peer, header http.Header) error {
+	if i.c.skipper != nil && i.c.skipper(ctx, spec) {
+		return nil
+	}
+	id, err := i.c.key(ctx, spec, peer, header)
+	if err == nil && id == "" {
+		err = ErrorNoKey
+	}
+	if err != nil {
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	if i.c.perProcedure {
+		id = spec.Procedure + " " + id
+	}
+	res, err := i.rl.AllowCtx(ctx, id)
+	if res != nil && !res.Allowed {
+		return blockedError(res, err)
+	}
+	if errors.Is(err, rateLimiter.ErrorInvalidId) {
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	if err != nil {
+		return connect.NewError(connect.CodeUnavailable, err)
+	}
+	return nil
+}
+
+// blockedError is ResourceExhausted carrying the rate limit headers of res, a
+// Retry-After among them, and a RetryInfo detail for limited blocks.
+func blockedError(res *rateLimiter.CheckResult, err error) error {
+	if err == nil {
+		err = errors.New("rate limit exceeded")
+	}
+	cerr := connect.NewError(connect.CodeResourceExhausted, err)
+	httpLimiter.SetHeaders(cerr.Meta(), res)
+	if res.RetryAfter > 0 {
+		if d, err := connect.NewErrorDetail(&errdetails.RetryInfo{RetryDelay: durationpb.New(res.RetryAfter)}); err == nil {
+			cerr.AddDetail(d)
+		}
+	}
+	return cerr
+}
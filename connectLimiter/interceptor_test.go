@@ -0,0 +1,87 @@
+package connectLimiter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const procedure = "/test.v1.TestService/Ping"
+
+func ping(ctx context.Context, req *connect.Request[emptypb.Empty]) (*connect.Response[emptypb.Empty], error) {
+	return connect.NewResponse(&emptypb.Empty{}), nil
+}
+
+func testClient(t *testing.T, opts ...Option) *connect.Client[emptypb.Empty, emptypb.Empty] {
+	rl := testLimiter(t, "connect")
+	mux := http.NewServeMux()
+	mux.Handle(procedure, connect.NewUnaryHandler(procedure, ping,
+		connect.WithInterceptors(NewInterceptor(rl, opts...))))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return connect.NewClient[emptypb.Empty, emptypb.Empty](srv.Client(), srv.URL+procedure)
+}
+
+func TestInterceptor(t *testing.T) {
+	client := testClient(t)
+	ctx := context.Background()
+	if _, err := client.CallUnary(ctx, connect.NewRequest(&emptypb.Empty{})); err != nil {
+		t.Fatal(err)
+	}
+	_, err := client.CallUnary(ctx, connect.NewRequest(&emptypb.Empty{}))
+	var cerr *connect.Error
+	if !errors.As(err, &cerr) || cerr.Code() != connect.CodeResourceExhausted {
+		t.Fatalf("call over the limit: %v", err)
+	}
+	if got := cerr.Meta().Get("Retry-After"); got != "60" {
+		t.Fatalf("Retry-After %q, want 60", got)
+	}
+	var retry time.Duration
+	for _, d := range cerr.Details() {
+		if msg, err := d.Value(); err == nil {
+			if info, ok := msg.(*errdetails.RetryInfo); ok {
+				retry = info.RetryDelay.AsDuration()
+			}
+		}
+	}
+	if retry != time.Minute {
+		t.Fatalf("RetryInfo delay %v, want 1m", retry)
+	}
+}
+
+func TestInterceptorOptions(t *testing.T) {
+	client := testClient(t, WithKeyFunc(Header("X-Api-Key")), WithPerProcedure(),
+		WithSkipper(func(ctx context.Context, spec connect.Spec) bool { return false }))
+	ctx := context.Background()
+	_, err := client.CallUnary(ctx, connect.NewRequest(&emptypb.Empty{}))
+	if connect.CodeOf(err) != connect.CodeInvalidArgument {
+		t.Fatalf("call without a key: %v", err)
+	}
+	call := func(key string) error {
+		req := connect.NewRequest(&emptypb.Empty{})
+		req.Header().Set("X-Api-Key", key)
+		_, err := client.CallUnary(ctx, req)
+		return err
+	}
+	call("a")
+	if err := call("a"); connect.CodeOf(err) != connect.CodeResourceExhausted {
+		t.Fatalf("second call of a: %v", err)
+	}
+	if err := call("b"); err != nil {
+		t.Fatalf("call of another key: %v", err)
+	}
+
+	client = testClient(t, WithSkipper(func(ctx context.Context, spec connect.Spec) bool { return spec.Procedure == procedure }))
+	for i := 0; i < 3; i++ {
+		if _, err := client.CallUnary(ctx, connect.NewRequest(&emptypb.Empty{})); err != nil {
+			t.Fatalf("skipped call %d: %v", i+1, err)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package rateLimiter
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type Window struct {
+	Duration time.Duration
+	Limit    int //same meaning as BlockTimes
+}
+
+// alignedTTL is the Lua prelude of the scripts starting window counters. Given the
+// zone offset of aligned windows in ARGV[offsetArg], lifetime(d) is the time left
+// until the next multiple of d by Redis TIME; without it lifetime(d) is d.
+const alignedTTL = `
+local now
+local offset = tonumber(ARGV[offsetArg])
+if offset then
+	if redis.replicate_commands then
+		redis.replicate_commands()
+	end
+	local t = redis.call('time')
+	now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000) + offset
+end
+local function lifetime(d)
+	d = tonumber(d)
+	if now and d > 0 then
+		return d - now % d
+	end
+	return d
+end
+`
+
+// counterScript evaluates every window of an id atomically, adding ARGV[#KEYS*2+1]
+// units to each. If any window can't take them nothing is incremented. It returns
+// {window, reached, count, pttl}, where window is the 1-based index of the violated
+// window (0 if none) and count/pttl belong to that window (the first one if none).
+var counterScript = goredis.NewScript(`
+local n = #KEYS
+local inc = tonumber(ARGV[n * 2 + 1])
+local offsetArg = n * 2 + 2
+` + alignedTTL + `
+for i = 1, n do
+	local limit = tonumber(ARGV[i * 2 - 1])
+	local count = tonumber(redis.call('get', KEYS[i]) or '0')
+	if limit > 0 and count + inc > limit then
+		return {i, 1, count, redis.call('pttl', KEYS[i])}
+	end
+end
+local hit, counts, ttls = 0, {}, {}
+for i = 1, n do
+	local limit = tonumber(ARGV[i * 2 - 1])
+	local count = redis.call('incrby', KEYS[i], inc)
+	local ttl = redis.call('pttl', KEYS[i])
+	if count == inc or ttl < 0 then
+		ttl = lifetime(ARGV[i * 2])
+		redis.call('pexpire', KEYS[i], ttl)
+	end
+	counts[i], ttls[i] = count, ttl
+	if hit == 0 and limit > 0 and count >= limit then
+		hit = i
+	end
+end
+local r = math.max(hit, 1)
+return {hit, 0, counts[r], ttls[r]}
+`)
+
+// refundScript decrements each window counter by up to ARGV[1] without going below zero,
+// and returns the new count of the first one.
+var refundScript = goredis.NewScript(`
+local result = 0
+for i = 1, #KEYS do
+	local count = tonumber(redis.call('get', KEYS[i]) or '0')
+	local n = math.min(tonumber(ARGV[1]), count)
+	if n > 0 then
+		count = redis.call('decrby', KEYS[i], n)
+	end
+	if i == 1 then
+		result = count
+	end
+end
+return result
+`)
+
+type counter struct {
+	window  int //index into rl.windows(id), -1 if no window was violated
+	times   int
+	ttl     time.Duration
+	reached bool //the window was already full, the request was not counted
+	sharded bool //times is estimated from one of Config.Shards counters
+}
+
+// incr runs counterScript for the windows of id, as returned by rl.windows(id).
+// A sharded id counts against one of its shards at random, each taking its share
+// of the limits.
+func (rl *RateLimiter) incr(ctx context.Context, id string, windows []Window, n int) (counter, error) {
+	keys, shards := rl.counterKeys(id), 1
+	if rl.isSharded(id) {
+		keys, shards = rl.shardKeys(id, rand.Intn(rl.Shards)), rl.Shards
+	}
+	args := make([]interface{}, 0, len(windows)*2+2)
+	for _, w := range windows {
+		args = append(args, shareOf(w.Limit, shards), rl.windowTTL(w))
+	}
+	args = append(args, n)
+	args = rl.appendAlignOffset(args)
+	vals, err := counterScript.Run(ctx, rl.Redis, keys, args...).Int64Slice()
+	if err != nil {
+		return counter{}, err
+	}
+	return counter{
+		window:  int(vals[0]) - 1,
+		reached: vals[1] == 1,
+		times:   int(vals[2]) * shards,
+		ttl:     time.Duration(vals[3]) * time.Millisecond,
+		sharded: shards > 1,
+	}, nil
+}
+
+// windowTTL returns the lifetime in milliseconds of a new counter of w: its
+// Duration, or with AlignWindows the time left until the next multiple of it,
+// counted from the Unix epoch on the wall clock of AlignLocation. Hours thus
+// start on the hour and days at midnight. Unless ClientTimestamps is set the
+// scripts compute aligned lifetimes themselves from Redis TIME, so that pods
+// with skewed clocks agree on the boundaries.
+func (rl *RateLimiter) windowTTL(w Window) int64 {
+	d := w.Duration.Milliseconds()
+	if !rl.AlignWindows || !rl.ClientTimestamps || d <= 0 {
+		return d
+	}
+	now := rl.Clock.Now()
+	return d - (now.UnixMilli()+rl.alignOffset(now))%d
+}
+
+// alignOffset returns the offset of AlignLocation from UTC at t in milliseconds.
+func (rl *RateLimiter) alignOffset(t time.Time) int64 {
+	if rl.AlignLocation == nil {
+		return 0
+	}
+	_, offset := t.In(rl.AlignLocation).Zone()
+	return int64(offset) * 1000
+}
+
+// appendAlignOffset appends the argument that makes a script align windows by
+// Redis TIME, if they are aligned server side.
+func (rl *RateLimiter) appendAlignOffset(args []interface{}) []interface{} {
+	if !rl.AlignWindows || rl.ClientTimestamps {
+		return args
+	}
+	return append(args, rl.alignOffset(rl.Clock.Now()))
+}
+
+// windows returns the primary window of id followed by Config.Windows, whose
+// limits are multiplied by TrustedMultiplier for trusted ids.
+func (rl *RateLimiter) windows(id string) []Window {
+	return rl.multipliedWindows(id, rl.multiplier(id))
+}
+
+// checkWindows is windows with the Multiplier of a bypass token presented for the
+// check instead, if it is higher.
+func (rl *RateLimiter) checkWindows(ctx context.Context, id string) []Window {
+	mul := rl.multiplier(id)
+	if m := bypassMultiplier(ctx); m > mul {
+		mul = m
+	}
+	return rl.multipliedWindows(id, mul)
+}
+
+func (rl *RateLimiter) multipliedWindows(id string, mul int) []Window {
+	windows := make([]Window, 0, len(rl.Windows)+1)
+	windows = append(windows, rl.primary(id, mul))
+	for _, w := range rl.Windows {
+		windows = append(windows, Window{Duration: w.Duration, Limit: w.Limit * mul})
+	}
+	return windows
+}
+
+// primaryWindow returns rl.windows(id)[0] without building the other windows.
+func (rl *RateLimiter) primaryWindow(id string) Window {
+	return rl.primary(id, rl.multiplier(id))
+}
+
+func (rl *RateLimiter) multiplier(id string) int {
+	if id != globalId && rl.inTrustedList(id) {
+		return rl.TrustedMultiplier
+	}
+	return 1
+}
+
+// primary returns the Duration/BlockTimes window of id, with the current schedule,
+// its tier and then its override applied. The limit is multiplied by mul unless
+// the override sets it.
+func (rl *RateLimiter) primary(id string, mul int) Window {
+	limits := rl.Limits()
+	w := Window{Duration: limits.Duration, Limit: limits.BlockTimes}
+	if s, ok := rl.schedule(); ok {
+		if s.Duration > 0 {
+			w.Duration = s.Duration
+		}
+		if s.BlockTimes > 0 {
+			w.Limit = s.BlockTimes
+		}
+	}
+	if tier, ok := rl.tier(id); ok {
+		if tier.Duration > 0 {
+			w.Duration = tier.Duration
+		}
+		if tier.BlockTimes > 0 {
+			w.Limit = tier.BlockTimes
+		}
+	}
+	w.Limit *= mul
+	if o, ok := rl.override(id); ok {
+		if o.Duration > 0 {
+			w.Duration = o.Duration
+		}
+		if o.BlockTimes > 0 {
+			w.Limit = o.BlockTimes
+		}
+	}
+	return w
+}
+
+func (rl *RateLimiter) counterKey(id string) string {
+	if id == globalId {
+		return rl.globalKey
+	}
+	return rl.counterPrefix + id
+}
+
+// counterKeys returns the keys of every window of id.
+func (rl *RateLimiter) counterKeys(id string) []string {
+	if id == globalId {
+		return rl.windowKeys(rl.globalKey, id)
+	}
+	return rl.windowKeys(rl.counterPrefix, id)
+}
+
+// windowKeys returns prefix+id followed by it with the suffix of every extra
+// window. They share a single allocation: each key is a slice of one string
+// built for all of them.
+func (rl *RateLimiter) windowKeys(prefix, id string) []string {
+	n := len(prefix) + len(id)
+	size := n * (len(rl.keySuffixes) + 1)
+	for _, suffix := range rl.keySuffixes {
+		size += len(suffix)
+	}
+	var b strings.Builder
+	b.Grow(size)
+	b.WriteString(prefix)
+	b.WriteString(id)
+	for _, suffix := range rl.keySuffixes {
+		b.WriteString(prefix)
+		b.WriteString(id)
+		b.WriteString(suffix)
+	}
+	all := b.String()
+	keys := make([]string, 0, len(rl.keySuffixes)+1)
+	keys = append(keys, all[:n])
+	start := n
+	for _, suffix := range rl.keySuffixes {
+		end := start + n + len(suffix)
+		keys = append(keys, all[start:end])
+		start = end
+	}
+	return keys
+}
+
+// keySuffixes returns the counter key suffix of every Config.Windows entry.
+func keySuffixes(windows []Window) []string {
+	suffixes := make([]string, len(windows))
+	for i, w := range windows {
+		suffixes[i] = ":" + strconv.FormatInt(w.Duration.Milliseconds(), 10)
+	}
+	return suffixes
+}
@@ -0,0 +1,128 @@
+package rateLimiter
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWindows(t *testing.T) {
+	mr, rl := testLimiter(t, "windows", WithDuration(time.Minute), WithBlockTimes(10),
+		WithBlockDuration(time.Hour), WithWindows(Window{Duration: time.Hour, Limit: 3}))
+	for i := 0; i < 2; i++ {
+		if _, err := rl.Check("a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := rl.Allow("a")
+	if !IsBlocked(err) || res.Window != time.Hour || res.Limit != 3 {
+		t.Fatalf("third check: %+v, %v", res, err)
+	}
+	res, err = rl.Allow("a")
+	if !IsBlocked(err) || res.Window != time.Hour {
+		t.Fatalf("fourth check: %+v, %v", res, err)
+	}
+	// a full window blocks the request without counting it in any window
+	if got, _ := mr.Get("windows:a"); got != "3" {
+		t.Fatalf("minute window counted %s requests, want 3", got)
+	}
+	mr.FastForward(time.Minute)
+	if _, err := rl.Check("a"); !IsBlocked(err) {
+		t.Fatalf("hour window forgotten after a minute: %v", err)
+	}
+}
+
+func TestWindowsValidation(t *testing.T) {
+	_, r := testRedis(t)
+	if _, err := NewLimiter("windows", WithRedis(r), WithDuration(time.Minute), WithWindows(Window{Duration: time.Hour})); err == nil {
+		t.Fatal("window without a limit accepted")
+	}
+}
+
+func TestCounterKeys(t *testing.T) {
+	_, rl := testLimiter(t, "keys", WithDuration(time.Minute),
+		WithWindows(Window{Duration: time.Hour, Limit: 10}, Window{Duration: 24 * time.Hour, Limit: 100}))
+	for id, want := range map[string][]string{
+		"a":      {"keys:a", "keys:a:3600000", "keys:a:86400000"},
+		globalId: {"keys-global", "keys-global:3600000", "keys-global:86400000"},
+	} {
+		if got := rl.counterKeys(id); !reflect.DeepEqual(got, want) {
+			t.Errorf("counterKeys(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func BenchmarkCounterKeys(b *testing.B) {
+	_, rl := testLimiter(b, "bench", WithDuration(time.Minute), WithBlockTimes(100),
+		WithWindows(Window{Duration: time.Hour, Limit: 1000}, Window{Duration: 24 * time.Hour, Limit: 10000}))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rl.counterKeys("user-1234")
+	}
+}
+
+func TestCounterWindowsAllOrNothing(t *testing.T) {
+	mr, rl := testLimiter(t, "windows", WithDuration(time.Minute), WithBlockTimes(10),
+		WithWindows(Window{Duration: time.Hour, Limit: 3}))
+	for i := 0; i < 2; i++ {
+		if _, err := rl.Check("a"); err != nil {
+			t.Fatalf("check %d: %v", i+1, err)
+		}
+	}
+	// the check reaching the hour limit is counted, the next one isn't
+	for i := 0; i < 2; i++ {
+		res, err := rl.Allow("a")
+		if !IsBlocked(err) || res.Window != time.Hour || res.Limit != 3 {
+			t.Fatalf("check %d: %+v, %v; want rejected by the hour window", i+3, res, err)
+		}
+	}
+	keys := rl.counterKeys("a")
+	for i, key := range keys {
+		if v, _ := mr.Get(key); v != "3" {
+			t.Fatalf("window %d counted %s, want 3", i, v)
+		}
+	}
+	if ttl := mr.TTL(keys[0]); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("primary ttl %v", ttl)
+	}
+	if ttl := mr.TTL(keys[1]); ttl <= time.Minute || ttl > time.Hour {
+		t.Fatalf("hour window ttl %v", ttl)
+	}
+}
+
+func TestRefundNeverGoesNegative(t *testing.T) {
+	_, rl := testLimiter(t, "refund", WithDuration(time.Minute), WithBlockTimes(10))
+	if _, err := rl.CheckN("a", 3); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := rl.RefundN("a", 5); err != nil || n != 0 {
+		t.Fatalf("refund = %d, %v; want 0", n, err)
+	}
+	if n, err := rl.Check("a"); err != nil || n != 1 {
+		t.Fatalf("check after refund = %d, %v; want 1", n, err)
+	}
+}
+
+func TestWriteBehindFlush(t *testing.T) {
+	mr, rl := testLimiter(t, "flush", WithDuration(time.Minute), WithBlockTimes(100), WithWriteBehind(time.Hour))
+	for i := 0; i < 10; i++ {
+		if _, err := rl.Check("a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	key := rl.counterKey("a")
+	if v, _ := mr.Get(key); v != "1" {
+		t.Fatalf("redis count %s before flush, want only the first check", v)
+	}
+	if err := rl.flushCounters(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := mr.Get(key); v != "10" {
+		t.Fatalf("redis count %s after flush, want 10", v)
+	}
+	if ttl := mr.TTL(key); ttl <= 0 {
+		t.Fatalf("ttl %v after flush", ttl)
+	}
+}
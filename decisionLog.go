@@ -0,0 +1,84 @@
+package rateLimiter
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type DecisionRecord struct {
+	StreamId string
+	Id       string
+	Limiter  string
+	Times    int
+	Limit    int
+	DryRun   bool //the request was let through by Config.DryRun
+	Instance string
+	Time     time.Time
+}
+
+// logDecision appends a block decision to the capped decision stream; see
+// rejectionLog.
+func (rl *RateLimiter) logDecision(id string, res *CheckResult) {
+	rl.rejections.addDecision(&goredis.XAddArgs{
+		Stream: rl.decisionKey,
+		MaxLen: rl.DecisionLogSize,
+		Approx: true,
+		Values: map[string]interface{}{
+			"id":       id,
+			"limiter":  rl.Name,
+			"times":    res.Used,
+			"limit":    res.Limit,
+			"dryRun":   res.DryRunBlocked,
+			"instance": rl.InstanceId,
+			"time":     rl.Clock.Now().UnixMilli(),
+		},
+	})
+}
+
+func (rl *RateLimiter) GetDecisionLog(since time.Time, count int64) ([]DecisionRecord, error) {
+	return rl.GetDecisionLogCtx(context.Background(), since, count)
+}
+
+// GetDecisionLogCtx returns up to count block decisions (0=all) made at or after
+// since, oldest first.
+func (rl *RateLimiter) GetDecisionLogCtx(ctx context.Context, since time.Time, count int64) ([]DecisionRecord, error) {
+	start := "-"
+	if !since.IsZero() {
+		start = strconv.FormatInt(since.UnixMilli(), 10)
+	}
+	var msgs []goredis.XMessage
+	var err error
+	if count > 0 {
+		msgs, err = rl.Redis.XRangeN(ctx, rl.decisionKey, start, "+", count).Result()
+	} else {
+		msgs, err = rl.Redis.XRange(ctx, rl.decisionKey, start, "+").Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+	records := make([]DecisionRecord, 0, len(msgs))
+	for _, msg := range msgs {
+		r := DecisionRecord{StreamId: msg.ID}
+		r.Id, _ = msg.Values["id"].(string)
+		r.Limiter, _ = msg.Values["limiter"].(string)
+		r.Instance, _ = msg.Values["instance"].(string)
+		if s, ok := msg.Values["times"].(string); ok {
+			r.Times, _ = strconv.Atoi(s)
+		}
+		if s, ok := msg.Values["limit"].(string); ok {
+			r.Limit, _ = strconv.Atoi(s)
+		}
+		if s, ok := msg.Values["dryRun"].(string); ok {
+			r.DryRun, _ = strconv.ParseBool(s)
+		}
+		if s, ok := msg.Values["time"].(string); ok {
+			ms, _ := strconv.ParseInt(s, 10, 64)
+			r.Time = time.UnixMilli(ms)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
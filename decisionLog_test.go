@@ -0,0 +1,42 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDecisionLog(t *testing.T) {
+	clock := NewFakeClock(time.UnixMilli(time.Now().UnixMilli()))
+	_, rl := testLimiter(t, "decisions", WithDuration(time.Minute), WithBlockTimes(2), WithClock(clock),
+		WithDecisionLog(100), func(c *Config) { c.InstanceId = "i1" })
+	rl.Allow("a")
+	rl.Allow("a")
+	rl.Allow("b")
+	rl.Allow("b")
+	if err := rl.flushRejections(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := rl.GetDecisionLog(time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("%d records, want 2: %+v", len(records), records)
+	}
+	r := records[0]
+	if r.StreamId == "" || r.Id != "a" || r.Limiter != "decisions" || r.Times != 2 || r.Limit != 2 ||
+		r.DryRun || r.Instance != "i1" || !r.Time.Equal(clock.Now()) {
+		t.Fatalf("record %+v", r)
+	}
+	if records[1].Id != "b" {
+		t.Fatalf("second record %+v", records[1])
+	}
+	if records, _ := rl.GetDecisionLog(time.Time{}, 1); len(records) != 1 || records[0].Id != "a" {
+		t.Fatalf("count 1: %+v", records)
+	}
+	if records, _ := rl.GetDecisionLog(time.Now().Add(time.Hour), 0); len(records) != 0 {
+		t.Fatalf("future since: %+v", records)
+	}
+}
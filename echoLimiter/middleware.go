@@ -0,0 +1,139 @@
+package echoLimiter
+
+import (
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/rate-limiter/httpLimiter"
+	"github.com/labstack/echo/v4"
+)
+
+// KeyFunc extracts the id to rate limit from a request.
+type KeyFunc func(c echo.Context) (string, error)
+
+// RealIP uses c.RealIP(), which honours the echo IPExtractor.
+func RealIP(c echo.Context) (string, error) {
+	return c.RealIP(), nil
+}
+
+// Header uses the value of the request header name.
+func Header(name string) KeyFunc {
+	return func(c echo.Context) (string, error) {
+		v := c.Request().Header.Get(name)
+		if v == "" {
+			return "", httpLimiter.ErrorNoKey
+		}
+		return v, nil
+	}
+}
+
+type config struct {
+	key          KeyFunc
+	perRoute     bool
+	skipper      func(c echo.Context) bool
+	deny         func(c echo.Context, res *rateLimiter.CheckResult, err error) error
+	errorHandler func(c echo.Context, err error) error
+}
+
+type Option func(*config)
+
+// WithKeyFunc sets how the id is extracted; the default is RealIP.
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(c *config) {
+		c.key = fn
+	}
+}
+
+// WithKeyExtractor extracts the id from c.Request() with a shared extractor.
+func WithKeyExtractor(e httpLimiter.KeyExtractor) Option {
+	return WithKeyFunc(func(c echo.Context) (string, error) {
+		return e.Extract(c.Request())
+	})
+}
+
+// WithPerRoute counts every route separately by prefixing the id with the route
+// path, so one limiter can be shared by all routes.
+func WithPerRoute() Option {
+	return func(c *config) {
+		c.perRoute = true
+	}
+}
+
+// WithSkipper lets requests for which fn returns true through unchecked.
+func WithSkipper(fn func(c echo.Context) bool) Option {
+	return func(c *config) {
+		c.skipper = fn
+	}
+}
+
+// WithDenyHandler replaces the default echo.ErrTooManyRequests response.
+func WithDenyHandler(fn func(c echo.Context, res *rateLimiter.CheckResult, err error) error) Option {
+	return func(c *config) {
+		c.deny = fn
+	}
+}
+
+// WithRejectionHandler renders rejections with a net/http RejectionHandler.
+func WithRejectionHandler(h httpLimiter.RejectionHandler) Option {
+	return WithDenyHandler(func(c echo.Context, res *rateLimiter.CheckResult, err error) error {
+		h(c.Response(), c.Request(), res)
+		return nil
+	})
+}
+
+// WithErrorHandler handles key extraction and Redis errors; returning nil lets the
+// request through.
+func WithErrorHandler(fn func(c echo.Context, err error) error) Option {
+	return func(c *config) {
+		c.errorHandler = fn
+	}
+}
+
+func defaultDeny(c echo.Context, res *rateLimiter.CheckResult, err error) error {
+	return echo.ErrTooManyRequests
+}
+
+func defaultError(c echo.Context, err error) error {
+	return echo.NewHTTPError(httpLimiter.ErrorStatus(err)).SetInternal(err)
+}
+
+// Middleware rate limits every request by the id returned by the key function.
+func Middleware(rl *rateLimiter.RateLimiter, opts ...Option) echo.MiddlewareFunc {
+	conf := &config{
+		key:          RealIP,
+		deny:         defaultDeny,
+		errorHandler: defaultError,
+	}
+	for _, opt := range opts {
+		opt(conf)
+	}
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if conf.skipper != nil && conf.skipper(c) {
+				return next(c)
+			}
+			id, err := conf.key(c)
+			if err == nil && id == "" {
+				err = httpLimiter.ErrorNoKey
+			}
+			if err != nil {
+				if err := conf.errorHandler(c, err); err != nil {
+					return err
+				}
+				return next(c)
+			}
+			if conf.perRoute {
+				id = c.Path() + " " + id
+			}
+			res, err := rl.AllowCtx(c.Request().Context(), id)
+			httpLimiter.SetHeaders(c.Response().Header(), res)
+			if res != nil && !res.Allowed {
+				return conf.deny(c, res, err)
+			}
+			if err != nil {
+				if err := conf.errorHandler(c, err); err != nil {
+					return err
+				}
+			}
+			return next(c)
+		}
+	}
+}
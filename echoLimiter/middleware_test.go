@@ -0,0 +1,69 @@
+package echoLimiter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/redis"
+	"github.com/labstack/echo/v4"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func testLimiter(t *testing.T) *rateLimiter.RateLimiter {
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	rl, err := rateLimiter.NewLimiter("echo", rateLimiter.WithRedis(&redis.Redis{Client: client}),
+		rateLimiter.WithDuration(time.Minute), rateLimiter.WithBlockTimes(2), rateLimiter.WithBlockDuration(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rl.Close(context.Background()) })
+	return rl
+}
+
+func TestMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware(testLimiter(t), WithKeyFunc(Header("X-Id")), WithPerRoute()))
+	e.GET("/a", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+	e.GET("/b", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+	serve := func(path, id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", path, nil)
+		if id != "" {
+			r.Header.Set("X-Id", id)
+		}
+		e.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := serve("/a", "u"); w.Code != http.StatusNoContent || w.Header().Get("X-RateLimit-Remaining") != "1" {
+		t.Fatalf("first request: %d %v", w.Code, w.Header())
+	}
+	if w := serve("/a", "u"); w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Fatalf("second request: %d %v", w.Code, w.Header())
+	}
+	if w := serve("/b", "u"); w.Code != http.StatusNoContent {
+		t.Fatalf("routes share a counter: status %d", w.Code)
+	}
+	if w := serve("/b", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing key: status %d, want 400", w.Code)
+	}
+}
+
+func TestMiddlewareFailOpen(t *testing.T) {
+	e := echo.New()
+	e.Use(Middleware(testLimiter(t), WithKeyFunc(Header("X-Id")),
+		WithErrorHandler(func(c echo.Context, err error) error { return nil })))
+	e.GET("/", func(c echo.Context) error { return c.NoContent(http.StatusNoContent) })
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("error handler returning nil didn't let the request through: status %d", w.Code)
+	}
+}
@@ -0,0 +1,26 @@
+package envoyLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func testLimiter(t *testing.T, name string, opts ...rateLimiter.Option) *rateLimiter.RateLimiter {
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	opts = append([]rateLimiter.Option{rateLimiter.WithRedis(&redis.Redis{Client: client}),
+		rateLimiter.WithDuration(time.Minute), rateLimiter.WithBlockTimes(2), rateLimiter.WithBlockDuration(time.Minute)}, opts...)
+	rl, err := rateLimiter.NewLimiter(name, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rl.Close(context.Background()) })
+	return rl
+}
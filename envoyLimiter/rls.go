@@ -0,0 +1,151 @@
+package envoyLimiter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	ratelimitv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/common/ratelimit/v3"
+	rlsv3 "github.com/envoyproxy/go-control-plane/envoy/service/ratelimit/v3"
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Resolver maps a descriptor of a request for domain to the Name of the limiter
+// it is checked by and the id it is counted as; ok=false leaves the descriptor
+// unlimited.
+type Resolver func(domain string, entries []*ratelimitv3.RateLimitDescriptor_Entry) (name, id string, ok bool)
+
+// DefaultResolver checks a descriptor with the limiter named after the domain and
+// the keys of its entries joined by ".", counting the values joined by "|": the
+// descriptor [{remote_address, 1.2.3.4}] of the domain "edge" is checked by the
+// limiter "edge.remote_address" as "1.2.3.4".
+func DefaultResolver(domain string, entries []*ratelimitv3.RateLimitDescriptor_Entry) (string, string, bool) {
+	if len(entries) == 0 {
+		return "", "", false
+	}
+	keys := make([]string, 0, len(entries)+1)
+	values := make([]string, 0, len(entries))
+	keys = append(keys, domain)
+	for _, e := range entries {
+		keys = append(keys, e.GetKey())
+		values = append(values, e.GetValue())
+	}
+	return strings.Join(keys, "."), strings.Join(values, "|"), true
+}
+
+// Server implements the Envoy rate limit service, v3, so that Envoy and Istio
+// sidecars can share the limits of this package through their global rate limit
+// filters.
+type Server struct {
+	rlsv3.UnimplementedRateLimitServiceServer
+	limiters func() []*rateLimiter.RateLimiter
+	resolve  Resolver
+}
+
+// NewServer checks descriptors with limiters; a nil resolve is DefaultResolver.
+func NewServer(resolve Resolver, limiters ...*rateLimiter.RateLimiter) *Server {
+	return newServer(resolve, func() []*rateLimiter.RateLimiter { return limiters })
+}
+
+// NewManagerServer checks descriptors with the limiters of m at call time. They
+// are matched by Name, which includes the name prefix of m.
+func NewManagerServer(resolve Resolver, m *rateLimiter.Manager) *Server {
+	return newServer(resolve, m.List)
+}
+
+func newServer(resolve Resolver, limiters func() []*rateLimiter.RateLimiter) *Server {
+	if resolve == nil {
+		resolve = DefaultResolver
+	}
+	return &Server{limiters: limiters, resolve: resolve}
+}
+
+func (s *Server) limiter(name string) *rateLimiter.RateLimiter {
+	for _, rl := range s.limiters() {
+		if rl.Name == name {
+			return rl
+		}
+	}
+	return nil
+}
+
+// ShouldRateLimit counts every descriptor of req with its limiter, even after one
+// is over the limit, and answers OVER_LIMIT if any is. Limiter errors fail the
+// call with Unavailable, leaving the decision to the failure_mode_deny setting
+// of the filter.
+func (s *Server) ShouldRateLimit(ctx context.Context, req *rlsv3.RateLimitRequest) (*rlsv3.RateLimitResponse, error) {
+	if req.GetDomain() == "" {
+		return nil, status.Error(codes.InvalidArgument, "domain is required")
+	}
+	resp := &rlsv3.RateLimitResponse{
+		OverallCode: rlsv3.RateLimitResponse_OK,
+		Statuses:    make([]*rlsv3.RateLimitResponse_DescriptorStatus, 0, len(req.GetDescriptors())),
+	}
+	for _, d := range req.GetDescriptors() {
+		st := &rlsv3.RateLimitResponse_DescriptorStatus{Code: rlsv3.RateLimitResponse_OK}
+		resp.Statuses = append(resp.Statuses, st)
+		name, id, ok := s.resolve(req.GetDomain(), d.GetEntries())
+		if !ok {
+			continue
+		}
+		rl := s.limiter(name)
+		if rl == nil {
+			continue
+		}
+		res, err := rl.AllowNCtx(ctx, id, hits(req, d))
+		if res != nil && !res.Allowed {
+			st.Code = rlsv3.RateLimitResponse_OVER_LIMIT
+			resp.OverallCode = rlsv3.RateLimitResponse_OVER_LIMIT
+		} else if errors.Is(err, rateLimiter.ErrorInvalidId) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		} else if err != nil && !rateLimiter.IsBlocked(err) {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		describe(st, name, res)
+	}
+	return resp, nil
+}
+
+// hits is the per-descriptor hits_addend, else the one of the request, at least 1.
+func hits(req *rlsv3.RateLimitRequest, d *ratelimitv3.RateLimitDescriptor) int {
+	n := uint64(req.GetHitsAddend())
+	if d.GetHitsAddend() != nil {
+		n = d.GetHitsAddend().GetValue()
+	}
+	if n == 0 {
+		return 1
+	}
+	return int(n)
+}
+
+// describe fills the limit, remaining and reset of st from res, for the
+// x-ratelimit headers of Envoy. Windows that aren't a whole unit have no limit.
+func describe(st *rlsv3.RateLimitResponse_DescriptorStatus, name string, res *rateLimiter.CheckResult) {
+	if res == nil || res.Limit <= 0 {
+		return
+	}
+	if unit, ok := units[res.Window]; ok {
+		st.CurrentLimit = &rlsv3.RateLimitResponse_RateLimit{Name: name, RequestsPerUnit: uint32(res.Limit), Unit: unit}
+	}
+	if res.Remaining > 0 {
+		st.LimitRemaining = uint32(res.Remaining)
+	}
+	switch {
+	case res.RetryAfter > 0:
+		st.DurationUntilReset = durationpb.New(res.RetryAfter)
+	case !res.ResetAt.IsZero():
+		st.DurationUntilReset = durationpb.New(time.Until(res.ResetAt))
+	}
+}
+
+var units = map[time.Duration]rlsv3.RateLimitResponse_RateLimit_Unit{
+	time.Second:        rlsv3.RateLimitResponse_RateLimit_SECOND,
+	time.Minute:        rlsv3.RateLimitResponse_RateLimit_MINUTE,
+	time.Hour:          rlsv3.RateLimitResponse_RateLimit_HOUR,
+	24 * time.Hour:     rlsv3.RateLimitResponse_RateLimit_DAY,
+	7 * 24 * time.Hour: rlsv3.RateLimitResponse_RateLimit_WEEK,
+}
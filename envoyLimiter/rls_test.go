@@ -0,0 +1,80 @@
+package envoyLimiter
+
+import (
+	"context"
+	"testing"
+
+	ratelimitv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/common/ratelimit/v3"
+	rlsv3 "github.com/envoyproxy/go-control-plane/envoy/service/ratelimit/v3"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func descriptor(kv ...string) *ratelimitv3.RateLimitDescriptor {
+	d := &ratelimitv3.RateLimitDescriptor{}
+	for i := 0; i < len(kv); i += 2 {
+		d.Entries = append(d.Entries, &ratelimitv3.RateLimitDescriptor_Entry{Key: kv[i], Value: kv[i+1]})
+	}
+	return d
+}
+
+func TestDefaultResolver(t *testing.T) {
+	name, id, ok := DefaultResolver("edge", descriptor("remote_address", "1.2.3.4", "path", "/login").Entries)
+	if !ok || name != "edge.remote_address.path" || id != "1.2.3.4|/login" {
+		t.Fatalf("DefaultResolver = %q, %q, %v", name, id, ok)
+	}
+	if _, _, ok := DefaultResolver("edge", nil); ok {
+		t.Fatal("descriptor without entries resolved")
+	}
+}
+
+func TestShouldRateLimit(t *testing.T) {
+	rl := testLimiter(t, "edge.remote_address")
+	s := NewServer(nil, rl)
+	req := &rlsv3.RateLimitRequest{Domain: "edge", Descriptors: []*ratelimitv3.RateLimitDescriptor{
+		descriptor("remote_address", "1.2.3.4"),
+		descriptor("unknown", "x"),
+	}}
+	resp, err := s.ShouldRateLimit(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.OverallCode != rlsv3.RateLimitResponse_OK || len(resp.Statuses) != 2 {
+		t.Fatalf("first request: %v", resp)
+	}
+	st := resp.Statuses[0]
+	if st.CurrentLimit.GetRequestsPerUnit() != 2 || st.CurrentLimit.GetUnit() != rlsv3.RateLimitResponse_RateLimit_MINUTE ||
+		st.LimitRemaining != 1 || st.DurationUntilReset == nil {
+		t.Fatalf("status %v", st)
+	}
+	if resp.Statuses[1].CurrentLimit != nil {
+		t.Fatalf("descriptor without a limiter limited: %v", resp.Statuses[1])
+	}
+
+	resp, err = s.ShouldRateLimit(context.Background(), req)
+	if err != nil || resp.OverallCode != rlsv3.RateLimitResponse_OVER_LIMIT ||
+		resp.Statuses[0].Code != rlsv3.RateLimitResponse_OVER_LIMIT || resp.Statuses[1].Code != rlsv3.RateLimitResponse_OK {
+		t.Fatalf("second request: %v, %v", resp, err)
+	}
+
+	// hits_addend of a descriptor overrides the one of the request
+	req = &rlsv3.RateLimitRequest{Domain: "edge", HitsAddend: 5, Descriptors: []*ratelimitv3.RateLimitDescriptor{
+		{Entries: descriptor("remote_address", "5.6.7.8").Entries, HitsAddend: wrapperspb.UInt64(1)},
+	}}
+	if resp, err = s.ShouldRateLimit(context.Background(), req); err != nil || resp.OverallCode != rlsv3.RateLimitResponse_OK {
+		t.Fatalf("request with hits_addend: %v, %v", resp, err)
+	}
+}
+
+func TestShouldRateLimitErrors(t *testing.T) {
+	s := NewServer(nil, testLimiter(t, "edge.remote_address"))
+	req := &rlsv3.RateLimitRequest{Descriptors: []*ratelimitv3.RateLimitDescriptor{descriptor("remote_address", "1.2.3.4")}}
+	if _, err := s.ShouldRateLimit(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("request without a domain: %v", err)
+	}
+	req = &rlsv3.RateLimitRequest{Domain: "edge", Descriptors: []*ratelimitv3.RateLimitDescriptor{descriptor("remote_address", "")}}
+	if _, err := s.ShouldRateLimit(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("empty id: %v", err)
+	}
+}
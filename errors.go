@@ -0,0 +1,67 @@
+package rateLimiter
+
+import (
+	stderrors "errors"
+	"strings"
+	"time"
+)
+
+// BlockedError is returned instead of the bare BlockError; it matches both
+// ErrorBlock and the configured BlockError with errors.Is.
+type BlockedError struct {
+	Err       error
+	Id        string
+	Times     int
+	Limit     int
+	Permanent bool
+	ExpiresAt time.Time //zero when Permanent
+}
+
+func (e *BlockedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BlockedError) Unwrap() error {
+	return e.Err
+}
+
+func (e *BlockedError) Is(target error) bool {
+	return target == ErrorBlock
+}
+
+func (rl *RateLimiter) blockedError(id string, res *CheckResult) error {
+	return &BlockedError{
+		Err:       rl.BlockError,
+		Id:        id,
+		Times:     res.Used,
+		Limit:     res.Limit,
+		Permanent: res.RetryAfter < 0,
+		ExpiresAt: res.ResetAt,
+	}
+}
+
+// MultiError collects several independent failures.
+type MultiError []error
+
+func (e MultiError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e MultiError) Unwrap() []error {
+	return e
+}
+
+func joinErrors(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return MultiError(errs)
+}
+
+func IsBlocked(err error) bool {
+	return stderrors.Is(err, ErrorBlock)
+}
@@ -0,0 +1,30 @@
+package rateLimiter
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+)
+
+func TestBlockedError(t *testing.T) {
+	custom := stderrors.New("slow down")
+	_, rl := testLimiter(t, "errors", WithDuration(time.Minute), WithBlockTimes(2),
+		WithBlockDuration(time.Hour), WithBlockError(custom), WithBlockList("b"))
+	rl.Check("a")
+	_, err := rl.Check("a")
+	var blocked *BlockedError
+	if !stderrors.As(err, &blocked) {
+		t.Fatalf("got %T %v, want *BlockedError", err, err)
+	}
+	if !IsBlocked(err) || !stderrors.Is(err, custom) || err.Error() != "slow down" {
+		t.Fatalf("error %v doesn't match ErrorBlock and the BlockError", err)
+	}
+	if blocked.Id != "a" || blocked.Times != 2 || blocked.Limit != 2 || blocked.Permanent || blocked.ExpiresAt.IsZero() {
+		t.Fatalf("blocked %+v", blocked)
+	}
+
+	_, err = rl.Check("b")
+	if !stderrors.As(err, &blocked) || !blocked.Permanent || !blocked.ExpiresAt.IsZero() {
+		t.Fatalf("block listed id: %+v", blocked)
+	}
+}
@@ -0,0 +1,42 @@
+package rateLimiter
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const DefaultEscalationWindow = 24 * time.Hour
+
+func (rl *RateLimiter) violationKey(id string) string {
+	return rl.Name + "-violations:" + id
+}
+
+// escalate records a violation of id and returns the block duration for it from
+// Config.Escalation. The last duration is reused once the list is exhausted. In
+// DryRun mode nothing is recorded.
+func (rl *RateLimiter) escalate(ctx context.Context, id string) (time.Duration, error) {
+	key := rl.violationKey(id)
+	var n int64
+	if rl.DryRun {
+		v, err := rl.Redis.Get(ctx, key).Int64()
+		if err != nil && err != goredis.Nil {
+			return 0, err
+		}
+		n = v + 1
+	} else {
+		pipe := rl.Redis.TxPipeline()
+		incr := pipe.Incr(ctx, key)
+		pipe.PExpire(ctx, key, rl.EscalationWindow)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return 0, err
+		}
+		n = incr.Val()
+	}
+	i := int(n) - 1
+	if i >= len(rl.Escalation) {
+		i = len(rl.Escalation) - 1
+	}
+	return rl.Escalation[i], nil
+}
@@ -0,0 +1,44 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEscalation(t *testing.T) {
+	mr, rl := testLimiter(t, "esc", WithDuration(time.Minute), WithBlockTimes(1), WithEscalation(time.Hour, time.Minute, 10*time.Minute, 0))
+	for i, want := range []time.Duration{time.Minute, 10 * time.Minute} {
+		res, err := rl.Allow("a")
+		if !IsBlocked(err) {
+			t.Fatalf("violation %d: expected block, got %v", i+1, err)
+		}
+		if res.RetryAfter != want {
+			t.Fatalf("violation %d: RetryAfter = %v, want %v", i+1, res.RetryAfter, want)
+		}
+		mr.FastForward(want)
+	}
+	if _, err := rl.Check("a"); !IsBlocked(err) {
+		t.Fatalf("third violation: expected block, got %v", err)
+	}
+	if !rl.blockIds.has("a") {
+		t.Fatal("a zero escalation step doesn't block permanently")
+	}
+
+	// violations are forgotten after EscalationWindow
+	if res, _ := rl.Allow("b"); res.RetryAfter != time.Minute {
+		t.Fatalf("RetryAfter = %v, want 1m", res.RetryAfter)
+	}
+	mr.FastForward(time.Hour)
+	if res, _ := rl.Allow("b"); res.RetryAfter != time.Minute {
+		t.Fatalf("RetryAfter = %v after the window, want 1m", res.RetryAfter)
+	}
+}
+
+func TestEscalationValidation(t *testing.T) {
+	_, r := testRedis(t)
+	for _, opt := range []Option{WithEscalation(-time.Minute, time.Minute), WithEscalation(time.Hour, -time.Minute)} {
+		if _, err := NewLimiter("esc", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(1), opt); err == nil {
+			t.Fatal("invalid escalation accepted")
+		}
+	}
+}
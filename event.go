@@ -0,0 +1,60 @@
+package rateLimiter
+
+import "time"
+
+type EventType string
+
+const (
+	EventAllow      EventType = "allow"
+	EventBlock      EventType = "block"
+	EventUnblock    EventType = "unblock"
+	EventListChange EventType = "listChange"
+	EventAnomaly    EventType = "anomaly"
+	EventSubnetBan  EventType = "subnetBan" //Id is the banned subnet
+	EventReplay     EventType = "replay"    //a nonce was presented to CheckNonce again
+)
+
+// Event is a decision or state change of the limiter, for fraud scoring, audit
+// pipelines and the like.
+type Event struct {
+	Type    EventType
+	Limiter string
+	Time    time.Time
+	Id      string       //empty for list changes and global checks
+	Result  *CheckResult //allow and block events
+	List    string       //"white" or "block", list change events
+	Change  *ListChange  //list change events
+	Anomaly *Anomaly     //anomaly events
+}
+
+func (rl *RateLimiter) emit(e Event) {
+	if rl.OnEvent == nil {
+		return
+	}
+	e.Limiter = rl.Name
+	e.Time = rl.Clock.Now()
+	rl.OnEvent(e)
+}
+
+func (rl *RateLimiter) emitDecision(id string, res *CheckResult) {
+	if rl.OnEvent == nil || res == nil {
+		return
+	}
+	t := EventAllow
+	if !res.Allowed {
+		t = EventBlock
+	}
+	rl.emit(Event{Type: t, Id: id, Result: res})
+}
+
+func (rl *RateLimiter) emitListChange(list string, change ListChange) {
+	if rl.OnEvent == nil {
+		return
+	}
+	rl.emit(Event{Type: EventListChange, List: list, Change: &change})
+}
+
+// tracksUnblocks reports whether ended blocks have to be detected.
+func (rl *RateLimiter) tracksUnblocks() bool {
+	return rl.OnUnblock != nil || rl.OnEvent != nil
+}
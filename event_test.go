@@ -0,0 +1,42 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOnEvent(t *testing.T) {
+	clock := NewFakeClock(time.UnixMilli(time.Now().UnixMilli()))
+	events := make(chan Event, 10)
+	_, rl := testLimiter(t, "event", WithDuration(time.Minute), WithBlockTimes(2), WithBlockDuration(time.Minute),
+		WithClock(clock), WithOnEvent(func(e Event) { events <- e }))
+	next := func() Event {
+		t.Helper()
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(3 * unblockSweepInterval):
+			t.Fatal("no event")
+			return Event{}
+		}
+	}
+
+	rl.Allow("a")
+	if e := next(); e.Type != EventAllow || e.Id != "a" || e.Result == nil || !e.Result.Allowed || !e.Time.Equal(clock.Now()) {
+		t.Fatalf("allow event %+v", e)
+	}
+	rl.Allow("a")
+	if e := next(); e.Type != EventBlock || e.Id != "a" || e.Result == nil || e.Result.Allowed {
+		t.Fatalf("block event %+v", e)
+	}
+	if err := rl.AddWhiteList("w", false); err != nil {
+		t.Fatal(err)
+	}
+	if e := next(); e.Type != EventListChange || e.List != listWhite || e.Change == nil || e.Change.Op != ListAdd || e.Change.Ids[0] != "w" {
+		t.Fatalf("list change event %+v", e)
+	}
+	clock.Advance(time.Minute)
+	if e := next(); e.Type != EventUnblock || e.Id != "a" {
+		t.Fatalf("unblock event %+v", e)
+	}
+}
@@ -0,0 +1,120 @@
+// Command gateway is a reverse proxy rate limiting the requests it forwards to
+// -upstream. It wires the pieces a service usually needs: a Manager creating the
+// limiters, the httpLimiter middleware, the admin API and Prometheus metrics on a
+// separate listener, and pub/sub sync so that list changes made through the admin
+// API of one replica reach the others.
+//
+//	docker compose -f examples/gateway/docker-compose.yml up
+//	curl -i localhost:8080/
+//	curl -H "Authorization: Bearer secret" localhost:9090/admin/
+//	curl localhost:9090/metrics
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/rate-limiter/httpLimiter"
+	"github.com/go-estar/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "proxy listen address")
+	adminAddr := flag.String("admin-addr", ":9090", "admin API and /metrics listen address, keep it off the public network")
+	upstream := flag.String("upstream", "http://127.0.0.1:8000", "URL requests are forwarded to")
+	redisAddr := flag.String("redis", "127.0.0.1:6379", "Redis address")
+	adminToken := flag.String("admin-token", "", "bearer token required by the admin API")
+	flag.Parse()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	if *adminToken == "" {
+		logger.Error("-admin-token is required")
+		os.Exit(2)
+	}
+	target, err := url.Parse(*upstream)
+	if err != nil {
+		logger.Error("invalid -upstream", "err", err)
+		os.Exit(2)
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	m := rateLimiter.NewManager(&rateLimiter.Config{
+		Name:           "gateway",
+		Redis:          redis.New(&redis.Config{Addr: *redisAddr}),
+		Logger:         logger,
+		Registerer:     reg,
+		ResyncInterval: time.Minute,
+		Duration:       time.Minute,
+		BlockTimes:     120,
+		BlockDuration:  5 * time.Minute,
+	})
+	// sync messages don't name their limiter, so every limiter gets a channel
+	api := mustLimiter(m, "api")
+	login := mustLimiter(m, "login", rateLimiter.WithBlockTimes(5), rateLimiter.WithBlockDuration(time.Hour))
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	for _, rl := range m.List() {
+		if err := rl.StartSync(ctx); err != nil {
+			logger.Error("start sync failed", "limiter", rl.Name, "err", err)
+			os.Exit(1)
+		}
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	limitAPI := httpLimiter.Middleware(api)
+	mux := http.NewServeMux()
+	mux.Handle("POST /login", limitAPI(httpLimiter.Middleware(login)(proxy)))
+	mux.Handle("/", limitAPI(proxy))
+
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/admin/", http.StripPrefix("/admin", httpLimiter.ManagerAdminHandler(m,
+		httpLimiter.WithAuthenticator(httpLimiter.BearerToken(*adminToken, "admin")))))
+	adminMux.Handle("/stats", httpLimiter.ManagerStatsHandler(m))
+	adminMux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+
+	servers := []*http.Server{
+		{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+		{Addr: *adminAddr, Handler: adminMux, ReadHeaderTimeout: 10 * time.Second},
+	}
+	for _, srv := range servers {
+		go func(srv *http.Server) {
+			logger.Info("serving", "addr", srv.Addr)
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("serve failed", "addr", srv.Addr, "err", err)
+				stop()
+			}
+		}(srv)
+	}
+	<-ctx.Done()
+
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	for _, srv := range servers {
+		srv.Shutdown(shutdown)
+	}
+	if err := m.CloseAll(shutdown); err != nil {
+		logger.Error("close limiters failed", "err", err)
+	}
+}
+
+func mustLimiter(m *rateLimiter.Manager, name string, opts ...rateLimiter.Option) *rateLimiter.RateLimiter {
+	opts = append(opts, rateLimiter.WithSyncChannel("gateway-sync:"+name))
+	rl, err := m.Get(name, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return rl
+}
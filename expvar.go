@@ -0,0 +1,75 @@
+package rateLimiter
+
+import (
+	stderrors "errors"
+	"expvar"
+	"sync"
+)
+
+var expvarMu sync.Mutex
+
+// expvarMetrics mirrors metrics as expvar.Int counters under the map
+// Config.ExpvarPrefix, in a sub-map per limiter name.
+type expvarMetrics struct {
+	checks      *expvar.Int
+	allowed     *expvar.Int
+	blocked     *expvar.Int
+	bypassed    *expvar.Int
+	redisErrors *expvar.Int
+}
+
+func newExpvarMetrics(prefix, name string) (*expvarMetrics, error) {
+	expvarMu.Lock()
+	defer expvarMu.Unlock()
+	var root *expvar.Map
+	switch v := expvar.Get(prefix).(type) {
+	case nil:
+		root = expvar.NewMap(prefix)
+	case *expvar.Map:
+		root = v
+	default:
+		return nil, stderrors.New("ExpvarPrefix已被其它变量使用")
+	}
+	m, ok := root.Get(name).(*expvar.Map)
+	if !ok {
+		m = new(expvar.Map).Init()
+		root.Set(name, m)
+	}
+	counter := func(key string) *expvar.Int {
+		if v, ok := m.Get(key).(*expvar.Int); ok {
+			return v
+		}
+		v := new(expvar.Int)
+		m.Set(key, v)
+		return v
+	}
+	return &expvarMetrics{
+		checks:      counter("checks"),
+		allowed:     counter("allowed"),
+		blocked:     counter("blocked"),
+		bypassed:    counter("whitelistBypass"),
+		redisErrors: counter("redisErrors"),
+	}, nil
+}
+
+// observe records a finished check; m may be nil when expvar is disabled.
+func (m *expvarMetrics) observe(res *CheckResult, err error) {
+	if m == nil {
+		return
+	}
+	m.checks.Add(1)
+	switch {
+	case res != nil && res.Allowed:
+		m.allowed.Add(1)
+	case res != nil:
+		m.blocked.Add(1)
+	case err != nil:
+		m.redisErrors.Add(1)
+	}
+}
+
+func (m *expvarMetrics) bypass() {
+	if m != nil {
+		m.bypassed.Add(1)
+	}
+}
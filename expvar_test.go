@@ -0,0 +1,32 @@
+package rateLimiter
+
+import (
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestExpvar(t *testing.T) {
+	_, rl := testLimiter(t, "expvar", WithDuration(time.Minute), WithBlockTimes(2), WithExpvar("testRateLimiter"))
+	rl.Allow("a")
+	rl.Allow("a")
+	if err := rl.AddWhiteList("w", false); err != nil {
+		t.Fatal(err)
+	}
+	rl.Allow("w")
+
+	m, ok := expvar.Get("testRateLimiter").(*expvar.Map).Get("expvar").(*expvar.Map)
+	if !ok {
+		t.Fatal("limiter map not published")
+	}
+	for key, want := range map[string]int64{"checks": 3, "allowed": 2, "blocked": 1, "whitelistBypass": 1, "redisErrors": 0} {
+		if got := m.Get(key).(*expvar.Int).Value(); got != want {
+			t.Errorf("%s = %d, want %d", key, got, want)
+		}
+	}
+
+	expvar.NewString("testRateLimiterTaken")
+	if _, err := NewLimiter("taken", WithRedis(rl.Redis), WithDuration(time.Minute), WithBlockTimes(2), WithExpvar("testRateLimiterTaken")); err == nil {
+		t.Fatal("ExpvarPrefix of a non-map variable accepted")
+	}
+}
@@ -0,0 +1,146 @@
+package fiberLimiter
+
+import (
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/rate-limiter/httpLimiter"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// KeyFunc extracts the id to rate limit from a request. The limiter keeps ids after
+// the request, so the id must not point into fasthttp's request buffer, which is
+// reused: copy values taken from c with utils.CopyString.
+type KeyFunc func(c *fiber.Ctx) (string, error)
+
+// IP uses c.IP(), which honours the app's ProxyHeader setting.
+func IP(c *fiber.Ctx) (string, error) {
+	return utils.CopyString(c.IP()), nil
+}
+
+// Header uses the value of the request header name.
+func Header(name string) KeyFunc {
+	return func(c *fiber.Ctx) (string, error) {
+		v := c.Get(name)
+		if v == "" {
+			return "", httpLimiter.ErrorNoKey
+		}
+		return utils.CopyString(v), nil
+	}
+}
+
+type config struct {
+	key          KeyFunc
+	perRoute     bool
+	skipper      func(c *fiber.Ctx) bool
+	deny         func(c *fiber.Ctx, res *rateLimiter.CheckResult, err error) error
+	errorHandler func(c *fiber.Ctx, err error) error
+}
+
+type Option func(*config)
+
+// WithKeyFunc sets how the id is extracted; the default is IP.
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(c *config) {
+		c.key = fn
+	}
+}
+
+// WithKeyExtractor runs a shared extractor on a net/http copy of the request. The
+// conversion costs an allocation per request; prefer a KeyFunc on hot paths. The
+// copy shares fasthttp's buffers, so the extracted id is copied too.
+func WithKeyExtractor(e httpLimiter.KeyExtractor) Option {
+	return WithKeyFunc(func(c *fiber.Ctx) (string, error) {
+		r, err := adaptor.ConvertRequest(c, false)
+		if err != nil {
+			return "", err
+		}
+		id, err := e.Extract(r)
+		return utils.CopyString(id), err
+	})
+}
+
+// WithPerRoute counts every route separately by prefixing the id with the route
+// path, so one limiter can be shared by all routes.
+func WithPerRoute() Option {
+	return func(c *config) {
+		c.perRoute = true
+	}
+}
+
+// WithSkipper lets requests for which fn returns true through unchecked.
+func WithSkipper(fn func(c *fiber.Ctx) bool) Option {
+	return func(c *config) {
+		c.skipper = fn
+	}
+}
+
+// WithDenyHandler replaces the default 429 response.
+func WithDenyHandler(fn func(c *fiber.Ctx, res *rateLimiter.CheckResult, err error) error) Option {
+	return func(c *config) {
+		c.deny = fn
+	}
+}
+
+// WithErrorHandler handles key extraction and Redis errors; returning nil lets the
+// request through.
+func WithErrorHandler(fn func(c *fiber.Ctx, err error) error) Option {
+	return func(c *config) {
+		c.errorHandler = fn
+	}
+}
+
+func defaultDeny(c *fiber.Ctx, res *rateLimiter.CheckResult, err error) error {
+	return c.SendStatus(fiber.StatusTooManyRequests)
+}
+
+func defaultError(c *fiber.Ctx, err error) error {
+	return fiber.NewError(httpLimiter.ErrorStatus(err))
+}
+
+func setHeaders(c *fiber.Ctx, res *rateLimiter.CheckResult) {
+	for k, v := range httpLimiter.Headers(res) {
+		c.Set(k, v)
+	}
+}
+
+// Middleware rate limits every request by the id returned by the key function.
+func Middleware(rl *rateLimiter.RateLimiter, opts ...Option) fiber.Handler {
+	conf := &config{
+		key:          IP,
+		deny:         defaultDeny,
+		errorHandler: defaultError,
+	}
+	for _, opt := range opts {
+		opt(conf)
+	}
+	return func(c *fiber.Ctx) error {
+		if conf.skipper != nil && conf.skipper(c) {
+			return c.Next()
+		}
+		id, err := conf.key(c)
+		if err == nil && id == "" {
+			err = httpLimiter.ErrorNoKey
+		}
+		if err != nil {
+			if err := conf.errorHandler(c, err); err != nil {
+				return err
+			}
+			return c.Next()
+		}
+		if conf.perRoute {
+			id = c.Route().Path + " " + id
+		}
+		res, err := rl.AllowCtx(c.UserContext(), id)
+		setHeaders(c, res)
+		if res != nil && !res.Allowed {
+			return conf.deny(c, res, err)
+		}
+		if err != nil {
+			if err := conf.errorHandler(c, err); err != nil {
+				return err
+			}
+		}
+		return c.Next()
+	}
+}
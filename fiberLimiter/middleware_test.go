@@ -0,0 +1,102 @@
+package fiberLimiter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/redis"
+	"github.com/gofiber/fiber/v2"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func testLimiter(t *testing.T) *rateLimiter.RateLimiter {
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	rl, err := rateLimiter.NewLimiter("fiber", rateLimiter.WithRedis(&redis.Redis{Client: client}),
+		rateLimiter.WithDuration(time.Minute), rateLimiter.WithBlockTimes(10), rateLimiter.WithBlockDuration(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rl.Close(context.Background()) })
+	return rl
+}
+
+// TestKeyFuncCopiesId checks that ids stay intact after fasthttp reuses the
+// request buffer they were read from.
+func TestKeyFuncCopiesId(t *testing.T) {
+	for name, tc := range map[string]struct {
+		conf   fiber.Config
+		header string
+		key    KeyFunc
+	}{
+		"Header": {header: "X-Id", key: Header("X-Id")},
+		"IP":     {conf: fiber.Config{ProxyHeader: "X-Real-Ip"}, header: "X-Real-Ip", key: IP},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rl := testLimiter(t)
+			var ids []string
+			app := fiber.New(tc.conf)
+			app.Use(Middleware(rl, WithKeyFunc(func(c *fiber.Ctx) (string, error) {
+				id, err := tc.key(c)
+				ids = append(ids, id)
+				return id, err
+			})))
+			app.Get("/", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+			for _, id := range []string{"10.0.0.1", "10.0.0.2"} {
+				req := httptest.NewRequest("GET", "/", nil)
+				req.Header.Set(tc.header, id)
+				resp, err := app.Test(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+			}
+			if len(ids) != 2 || ids[0] != "10.0.0.1" || ids[1] != "10.0.0.2" {
+				t.Fatalf("ids %q", ids)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	// c.Route() is the matched route only in handlers registered on it
+	limit := Middleware(testLimiter(t), WithKeyFunc(Header("X-Id")), WithPerRoute())
+	app := fiber.New()
+	app.Get("/a", limit, func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+	app.Get("/b", limit, func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+	serve := func(path, id string) *http.Response {
+		req := httptest.NewRequest("GET", path, nil)
+		if id != "" {
+			req.Header.Set("X-Id", id)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	for i := 1; i < 10; i++ {
+		if resp := serve("/a", "u"); resp.StatusCode != fiber.StatusNoContent {
+			t.Fatalf("request %d: status %d", i, resp.StatusCode)
+		}
+	}
+	resp := serve("/a", "u")
+	if resp.StatusCode != fiber.StatusTooManyRequests || resp.Header.Get("Retry-After") != "60" || resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		t.Fatalf("10th request: %d %v", resp.StatusCode, resp.Header)
+	}
+	if resp := serve("/b", "u"); resp.StatusCode != fiber.StatusNoContent {
+		t.Fatalf("routes share a counter: status %d", resp.StatusCode)
+	}
+	if resp := serve("/b", ""); resp.StatusCode != fiber.StatusBadRequest {
+		t.Fatalf("missing key: status %d, want 400", resp.StatusCode)
+	}
+}
@@ -0,0 +1,52 @@
+package rateLimiter
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// CountryPrefix starts the white and block list entries that match every IP of a
+// country, e.g. "country:CN". Codes are ISO 3166-1 alpha-2, in upper case.
+const CountryPrefix = "country:"
+
+// CountryDefault is the CountryTiers key of the countries it doesn't list.
+const CountryDefault = "*"
+
+// GeoResolver returns the country code of ip, "" if it is unknown. It is called
+// on every check of an IP id, so it must be fast, e.g. a local GeoIP database.
+type GeoResolver func(ip netip.Addr) string
+
+// country resolves the country of id, ok is false when id is not an IP or
+// GeoResolver is not set.
+func (rl *RateLimiter) country(id string) (country string, ok bool) {
+	if rl.GeoResolver == nil || id == globalId {
+		return "", false
+	}
+	addr, err := netip.ParseAddr(id)
+	if err != nil {
+		return "", false
+	}
+	return strings.ToUpper(rl.GeoResolver(addr.Unmap())), true
+}
+
+// countryMatch reports whether l has an entry for the country of id.
+func (rl *RateLimiter) countryMatch(l *idList, id string) bool {
+	country, ok := rl.country(id)
+	return ok && country != "" && l.has(CountryPrefix+country)
+}
+
+// countryTier returns the CountryTiers tier of id. IPs of unknown countries get
+// the CountryDefault tier; other ids get none.
+func (rl *RateLimiter) countryTier(id string) string {
+	if len(rl.CountryTiers) == 0 {
+		return ""
+	}
+	country, ok := rl.country(id)
+	if !ok {
+		return ""
+	}
+	if name, ok := rl.CountryTiers[country]; ok {
+		return name
+	}
+	return rl.CountryTiers[CountryDefault]
+}
@@ -0,0 +1,71 @@
+package rateLimiter
+
+import (
+	"context"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testGeo places 10.0.0.0/8 in CN, 20.0.0.0/8 in US and every other IP nowhere.
+func testGeo(ip netip.Addr) string {
+	switch {
+	case netip.MustParsePrefix("10.0.0.0/8").Contains(ip):
+		return "cn"
+	case netip.MustParsePrefix("20.0.0.0/8").Contains(ip):
+		return "US"
+	}
+	return ""
+}
+
+func TestGeoResolver(t *testing.T) {
+	_, rl := testLimiter(t, "geo", WithDuration(time.Minute), WithBlockTimes(2),
+		WithTiers(nil, map[string]Tier{"high": {BlockTimes: 10}, "low": {BlockTimes: 1}}),
+		WithGeoResolver(testGeo, map[string]string{"US": "high", CountryDefault: "low"}))
+	for id, want := range map[string]int{
+		"20.1.2.3":        10,
+		"::ffff:20.1.2.4": 10,
+		"30.1.2.3":        1,
+		"user":            2,
+	} {
+		if res, _ := rl.Allow(id); res.Limit != want {
+			t.Errorf("limit of %s = %d, want %d", id, res.Limit, want)
+		}
+	}
+
+	if err := rl.AddBlockList(CountryPrefix+"CN", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rl.Check("10.9.9.9"); !IsBlocked(err) {
+		t.Fatalf("check of an IP of a blocked country: %v", err)
+	}
+	if err := rl.AddWhiteList(CountryPrefix+"US", false); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if _, err := rl.Check("20.9.9.9"); err != nil {
+			t.Fatalf("check %d of an IP of a white listed country: %v", i+1, err)
+		}
+	}
+}
+
+func TestCountryTiersValidation(t *testing.T) {
+	_, r := testRedis(t)
+	l := newTestLogger()
+	if _, err := NewLimiter("geo", WithRedis(r), WithDuration(time.Minute),
+		WithTiers(nil, map[string]Tier{"high": {BlockTimes: 10}}),
+		WithGeoResolver(testGeo, map[string]string{"US": "missing"})); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("country tier missing from Tiers: %v", err)
+	}
+	rl, err := NewLimiter("geo", WithRedis(r), WithDuration(time.Minute), WithLogger(l),
+		WithTiers(nil, map[string]Tier{"high": {BlockTimes: 10}}),
+		WithGeoResolver(nil, map[string]string{"US": "high"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rl.Close(context.Background())
+	if !l.logged("warn", "config warning") {
+		t.Fatal("CountryTiers without GeoResolver not reported")
+	}
+}
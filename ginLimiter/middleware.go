@@ -0,0 +1,138 @@
+package ginLimiter
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/rate-limiter/httpLimiter"
+)
+
+// KeyFunc extracts the id to rate limit from a request.
+type KeyFunc func(c *gin.Context) (string, error)
+
+// ClientIP uses c.ClientIP(), which honours the engine's trusted proxies.
+func ClientIP(c *gin.Context) (string, error) {
+	return c.ClientIP(), nil
+}
+
+// Header uses the value of the request header name.
+func Header(name string) KeyFunc {
+	return func(c *gin.Context) (string, error) {
+		v := c.GetHeader(name)
+		if v == "" {
+			return "", httpLimiter.ErrorNoKey
+		}
+		return v, nil
+	}
+}
+
+type config struct {
+	key          KeyFunc
+	perRoute     bool
+	skipper      func(c *gin.Context) bool
+	abort        func(c *gin.Context, res *rateLimiter.CheckResult, err error)
+	errorHandler func(c *gin.Context, err error)
+}
+
+type Option func(*config)
+
+// WithKeyFunc sets how the id is extracted; the default is ClientIP.
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(c *config) {
+		c.key = fn
+	}
+}
+
+// WithKeyExtractor extracts the id from c.Request with a shared extractor.
+func WithKeyExtractor(e httpLimiter.KeyExtractor) Option {
+	return WithKeyFunc(func(c *gin.Context) (string, error) {
+		return e.Extract(c.Request)
+	})
+}
+
+// WithPerRoute counts every route separately by prefixing the id with the route
+// pattern, so one limiter can be shared by all routes.
+func WithPerRoute() Option {
+	return func(c *config) {
+		c.perRoute = true
+	}
+}
+
+// WithSkipper lets requests for which fn returns true through unchecked.
+func WithSkipper(fn func(c *gin.Context) bool) Option {
+	return func(c *config) {
+		c.skipper = fn
+	}
+}
+
+// WithAbortHandler replaces the default 429 JSON response; fn must abort c.
+func WithAbortHandler(fn func(c *gin.Context, res *rateLimiter.CheckResult, err error)) Option {
+	return func(c *config) {
+		c.abort = fn
+	}
+}
+
+// WithRejectionHandler renders rejections with a net/http RejectionHandler.
+func WithRejectionHandler(h httpLimiter.RejectionHandler) Option {
+	return WithAbortHandler(func(c *gin.Context, res *rateLimiter.CheckResult, err error) {
+		h(c.Writer, c.Request, res)
+		c.Abort()
+	})
+}
+
+// WithErrorHandler handles key extraction and Redis errors; fn must abort c or call
+// c.Next to fail open.
+func WithErrorHandler(fn func(c *gin.Context, err error)) Option {
+	return func(c *config) {
+		c.errorHandler = fn
+	}
+}
+
+func defaultAbort(c *gin.Context, res *rateLimiter.CheckResult, err error) {
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"message": http.StatusText(http.StatusTooManyRequests)})
+}
+
+func defaultError(c *gin.Context, err error) {
+	c.AbortWithStatus(httpLimiter.ErrorStatus(err))
+}
+
+// Middleware rate limits every request by the id returned by the key function.
+func Middleware(rl *rateLimiter.RateLimiter, opts ...Option) gin.HandlerFunc {
+	conf := &config{
+		key:          ClientIP,
+		abort:        defaultAbort,
+		errorHandler: defaultError,
+	}
+	for _, opt := range opts {
+		opt(conf)
+	}
+	return func(c *gin.Context) {
+		if conf.skipper != nil && conf.skipper(c) {
+			c.Next()
+			return
+		}
+		id, err := conf.key(c)
+		if err == nil && id == "" {
+			err = httpLimiter.ErrorNoKey
+		}
+		if err != nil {
+			conf.errorHandler(c, err)
+			return
+		}
+		if conf.perRoute {
+			id = c.FullPath() + " " + id
+		}
+		res, err := rl.AllowCtx(c.Request.Context(), id)
+		httpLimiter.SetHeaders(c.Writer.Header(), res)
+		if res != nil && !res.Allowed {
+			conf.abort(c, res, err)
+			return
+		}
+		if err != nil {
+			conf.errorHandler(c, err)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,62 @@
+package ginLimiter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func testLimiter(t *testing.T) *rateLimiter.RateLimiter {
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	rl, err := rateLimiter.NewLimiter("gin", rateLimiter.WithRedis(&redis.Redis{Client: client}),
+		rateLimiter.WithDuration(time.Minute), rateLimiter.WithBlockTimes(2), rateLimiter.WithBlockDuration(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rl.Close(context.Background()) })
+	return rl
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	e := gin.New()
+	e.Use(Middleware(testLimiter(t), WithKeyFunc(Header("X-Id")), WithPerRoute()))
+	e.GET("/a", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	e.GET("/b", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	serve := func(path, id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", path, nil)
+		if id != "" {
+			r.Header.Set("X-Id", id)
+		}
+		e.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := serve("/a", "u"); w.Code != http.StatusNoContent || w.Header().Get("X-RateLimit-Remaining") != "1" {
+		t.Fatalf("first request: %d %v", w.Code, w.Header())
+	}
+	w := serve("/a", "u")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Fatalf("second request: %d %v", w.Code, w.Header())
+	}
+	if w.Body.String() != `{"message":"Too Many Requests"}` {
+		t.Fatalf("body %s", w.Body)
+	}
+	if w := serve("/b", "u"); w.Code != http.StatusNoContent {
+		t.Fatalf("routes share a counter: status %d", w.Code)
+	}
+	if w := serve("/b", ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("missing key: status %d, want 400", w.Code)
+	}
+}
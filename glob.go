@@ -0,0 +1,88 @@
+package rateLimiter
+
+import (
+	"strings"
+	"sync"
+)
+
+// globSet holds the wildcard entries of a list; '*' matches any run of characters
+// and '?' matches exactly one.
+type globSet struct {
+	mu       sync.RWMutex
+	patterns map[string]int //pattern -> number of list entries
+}
+
+func isGlob(s string) bool {
+	return strings.ContainsAny(s, "*?")
+}
+
+func (g *globSet) insert(s string) {
+	if !isGlob(s) {
+		return
+	}
+	g.mu.Lock()
+	if g.patterns == nil {
+		g.patterns = make(map[string]int)
+	}
+	g.patterns[s]++
+	g.mu.Unlock()
+}
+
+func (g *globSet) remove(s string) {
+	if !isGlob(s) {
+		return
+	}
+	g.mu.Lock()
+	if g.patterns[s] > 1 {
+		g.patterns[s]--
+	} else {
+		delete(g.patterns, s)
+	}
+	g.mu.Unlock()
+}
+
+func (g *globSet) reset(entries []string) {
+	g.mu.Lock()
+	g.patterns = nil
+	g.mu.Unlock()
+	for _, s := range entries {
+		g.insert(s)
+	}
+}
+
+func (g *globSet) match(id string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	for pattern := range g.patterns {
+		if globMatch(pattern, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func globMatch(pattern, s string) bool {
+	p, str := []rune(pattern), []rune(s)
+	pi, si := 0, 0
+	star, mark := -1, 0
+	for si < len(str) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == str[si]):
+			pi++
+			si++
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, si
+			pi++
+		case star != -1:
+			pi = star + 1
+			mark++
+			si = mark
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
@@ -0,0 +1,49 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGlobMatch(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"bot-*", "bot-crawler", true},
+		{"bot-*", "bot-", true},
+		{"bot-*", "robot-1", false},
+		{"*.example.com", "api.example.com", true},
+		{"*.example.com", "example.com", false},
+		{"user-??", "user-42", true},
+		{"user-??", "user-421", false},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+		{"*", "", true},
+		{"é?", "éé", true},
+	} {
+		if got := globMatch(tc.pattern, tc.s); got != tc.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tc.pattern, tc.s, got, tc.want)
+		}
+	}
+}
+
+func TestGlobLists(t *testing.T) {
+	_, rl := testLimiter(t, "glob", WithDuration(time.Minute), WithBlockTimes(10),
+		WithWhiteList("internal-*"), WithBlockList("bot-*"))
+	if res, err := rl.Allow("internal-job"); err != nil || res.Used != 0 {
+		t.Fatalf("white listed pattern: %+v, %v", res, err)
+	}
+	if _, err := rl.Check("bot-1"); !IsBlocked(err) {
+		t.Fatalf("block listed pattern: %v", err)
+	}
+	if _, err := rl.Check("robot"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.RemoveBlockList("bot-*", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rl.Check("bot-1"); err != nil {
+		t.Fatalf("removed pattern still blocks: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package rateLimiter
+
+import "context"
+
+// globalId is never produced by sanitizeId, so the global budget can't collide with a real id.
+const globalId = ""
+
+func (rl *RateLimiter) CheckGlobal() (int, error) {
+	return rl.CheckGlobalCtx(context.Background())
+}
+
+func (rl *RateLimiter) CheckGlobalCtx(ctx context.Context) (int, error) {
+	res, err := rl.AllowGlobalCtx(ctx)
+	if res == nil {
+		return 0, err
+	}
+	return res.Used, err
+}
+
+func (rl *RateLimiter) AllowGlobal() (*CheckResult, error) {
+	return rl.AllowGlobalCtx(context.Background())
+}
+
+// AllowGlobalCtx counts against a single budget shared by every caller of the limiter.
+// The mode applies, white/block lists and BlockDuration don't; a full window rejects
+// until it resets.
+func (rl *RateLimiter) AllowGlobalCtx(ctx context.Context) (*CheckResult, error) {
+	return rl.checked(ctx, globalId, func(ctx context.Context) (*CheckResult, error) {
+		if res, ok := rl.modeResult(globalId); ok {
+			if res.Allowed {
+				return res, nil
+			}
+			return res, rl.blockedError(globalId, res)
+		}
+		return rl.count(ctx, globalId, 1)
+	})
+}
@@ -0,0 +1,54 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllowGlobal(t *testing.T) {
+	mr, rl := testLimiter(t, "global", WithDuration(time.Minute), WithBlockTimes(3))
+	for i := 1; i < 3; i++ {
+		if n, err := rl.CheckGlobal(); err != nil || n != i {
+			t.Fatalf("global check %d = %d, %v", i, n, err)
+		}
+	}
+	if n, err := rl.Check("a"); err != nil || n != 1 {
+		t.Fatalf("per-id check shares the global budget: %d, %v", n, err)
+	}
+	res, err := rl.AllowGlobal()
+	if !IsBlocked(err) || res.RetryAfter <= 0 || res.RetryAfter > time.Minute {
+		t.Fatalf("full global window: %+v, %v", res, err)
+	}
+	// the global budget is never added to the block list, it frees up with the window
+	if ids, _ := rl.GetBlockList(nil); len(ids) != 0 {
+		t.Fatalf("block list %v", ids)
+	}
+	mr.FastForward(time.Minute)
+	if _, err := rl.AllowGlobal(); err != nil {
+		t.Fatalf("global window didn't reset: %v", err)
+	}
+	if !mr.Exists("global-global") {
+		t.Fatal("global counter not stored under its own key")
+	}
+}
+
+func TestAllowGlobalMode(t *testing.T) {
+	mr, rl := testLimiter(t, "global", WithDuration(time.Minute), WithBlockTimes(3))
+	if err := rl.SetMode(ModeBlockAll, false); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := rl.AllowGlobal(); res.Allowed || !IsBlocked(err) {
+		t.Fatalf("AllowGlobal in blockAll: %+v, %v", res, err)
+	}
+	if err := rl.SetMode(ModeAllowAll, false); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := rl.AllowGlobal(); err != nil {
+			t.Fatalf("AllowGlobal in allowAll: %v", err)
+		}
+	}
+	if mr.Exists("global-global") {
+		t.Fatal("global budget counted outside normal mode")
+	}
+}
@@ -0,0 +1,148 @@
+package graphqlLimiter
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// DefaultListArgs are the arguments read as the page size of a list field.
+var DefaultListArgs = []string{"first", "last", "limit"}
+
+type config struct {
+	listArgs        []string
+	defaultListSize int
+	maxCost         int
+}
+
+type Option func(*config)
+
+// WithListArgs replaces DefaultListArgs.
+func WithListArgs(args ...string) Option {
+	return func(c *config) {
+		c.listArgs = args
+	}
+}
+
+// WithDefaultListSize sets the page size assumed for list fields without a size
+// argument. It needs a validated operation; the default is 1.
+func WithDefaultListSize(n int) Option {
+	return func(c *config) {
+		c.defaultListSize = n
+	}
+}
+
+// WithMaxCost rejects operations costing more than n with ErrorTooComplex, without
+// counting them.
+func WithMaxCost(n int) Option {
+	return func(c *config) {
+		c.maxCost = n
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{listArgs: DefaultListArgs, defaultListSize: 1}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Cost returns the complexity of op: every field costs 1, and the selection of a
+// list field counts once per item of its page size. op is usually the one gqlgen
+// keeps in graphql.GetOperationContext(ctx).Operation.
+func Cost(op *ast.OperationDefinition, vars map[string]interface{}, opts ...Option) int {
+	if op == nil {
+		return 0
+	}
+	return newConfig(opts).selectionCost(op.SelectionSet, vars, map[string]bool{})
+}
+
+// QueryCost parses and validates query against schema, then returns the Cost of
+// operationName, which may be empty if the query has a single operation.
+func QueryCost(schema *ast.Schema, query, operationName string, vars map[string]interface{}, opts ...Option) (int, error) {
+	op, err := operation(schema, query, operationName)
+	if err != nil {
+		return 0, err
+	}
+	return Cost(op, vars, opts...), nil
+}
+
+func operation(schema *ast.Schema, query, operationName string) (*ast.OperationDefinition, error) {
+	doc, errs := gqlparser.LoadQuery(schema, query)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if operationName == "" && len(doc.Operations) == 1 {
+		return doc.Operations[0], nil
+	}
+	op := doc.Operations.ForName(operationName)
+	if op == nil {
+		return nil, ErrorNoOperation
+	}
+	return op, nil
+}
+
+// selectionCost walks set; spreads holds the fragments on the current path so a
+// cyclic fragment in an unvalidated document can't recurse forever.
+func (c *config) selectionCost(set ast.SelectionSet, vars map[string]interface{}, spreads map[string]bool) int {
+	cost := 0
+	for _, sel := range set {
+		switch s := sel.(type) {
+		case *ast.Field:
+			cost += 1 + c.listSize(s, vars)*c.selectionCost(s.SelectionSet, vars, spreads)
+		case *ast.InlineFragment:
+			cost += c.selectionCost(s.SelectionSet, vars, spreads)
+		case *ast.FragmentSpread:
+			if s.Definition == nil || spreads[s.Name] {
+				continue
+			}
+			spreads[s.Name] = true
+			cost += c.selectionCost(s.Definition.SelectionSet, vars, spreads)
+			delete(spreads, s.Name)
+		}
+	}
+	return cost
+}
+
+func (c *config) listSize(f *ast.Field, vars map[string]interface{}) int {
+	for _, name := range c.listArgs {
+		arg := f.Arguments.ForName(name)
+		if arg == nil || arg.Value == nil {
+			continue
+		}
+		val, err := arg.Value.Value(vars)
+		if err != nil {
+			continue
+		}
+		if n, ok := toInt(val); ok && n > 0 {
+			return n
+		}
+	}
+	if f.Definition != nil && f.Definition.Type != nil && f.Definition.Type.Elem != nil {
+		return c.defaultListSize
+	}
+	return 1
+}
+
+func toInt(v interface{}) (int, bool) {
+	switch n := v.(type) {
+	case int:
+		return n, true
+	case int32:
+		return int(n), true
+	case int64:
+		return int(n), true
+	case float64:
+		return int(n), true
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err == nil
+	case string:
+		i, err := strconv.Atoi(n)
+		return i, err == nil
+	}
+	return 0, false
+}
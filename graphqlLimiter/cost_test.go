@@ -0,0 +1,89 @@
+package graphqlLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/redis"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const testSchema = `
+type Query {
+	user(id: ID!): User
+	users(first: Int): [User!]!
+}
+type User {
+	id: ID!
+	name: String!
+	friends(first: Int): [User!]!
+}
+`
+
+func loadSchema(t *testing.T) *ast.Schema {
+	schema, err := gqlparser.LoadSchema(&ast.Source{Input: testSchema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func TestQueryCost(t *testing.T) {
+	schema := loadSchema(t)
+	for query, want := range map[string]int{
+		`{ user(id: 1) { id name } }`:                                       3,
+		`{ users(first: 10) { id } }`:                                       11,
+		`{ users(first: 10) { id friends(first: 5) { id } } }`:              1 + 10*(1+1+5*1),
+		`query($n: Int) { users(first: $n) { id } }`:                        1 + 3,
+		`{ users { ...f } } fragment f on User { id name }`:                 3,
+		`{ user(id: 1) { ... on User { id } } }`:                            2,
+		`query A { user(id: 1) { id } } query B { users(first: 2) { id } }`: -1,
+	} {
+		cost, err := QueryCost(schema, query, "", map[string]interface{}{"n": 3})
+		if want < 0 {
+			if err != ErrorNoOperation {
+				t.Errorf("%s: got %d, %v, want ErrorNoOperation", query, cost, err)
+			}
+			continue
+		}
+		if err != nil || cost != want {
+			t.Errorf("%s: cost %d, %v, want %d", query, cost, err, want)
+		}
+	}
+	if cost, _ := QueryCost(schema, `{ users { id } }`, "", nil, WithDefaultListSize(20)); cost != 21 {
+		t.Errorf("cost with a default list size %d, want 21", cost)
+	}
+	if _, err := QueryCost(schema, `{ missing }`, "", nil); err == nil {
+		t.Error("invalid query accepted")
+	}
+}
+
+func TestCheckQuery(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	rl, err := rateLimiter.NewLimiter("graphql", rateLimiter.WithRedis(&redis.Redis{Client: client}),
+		rateLimiter.WithDuration(time.Minute), rateLimiter.WithBlockTimes(30))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rl.Close(context.Background()) })
+	schema := loadSchema(t)
+	ctx := context.Background()
+
+	res, err := CheckQuery(ctx, rl, "u", schema, `{ users(first: 10) { id } }`, "", nil)
+	if err != nil || res.Used != 11 {
+		t.Fatalf("CheckQuery = %+v, %v", res, err)
+	}
+	if _, err := CheckQuery(ctx, rl, "u", schema, `{ users(first: 100) { id } }`, "", nil, WithMaxCost(50)); err != ErrorTooComplex {
+		t.Fatalf("got %v, want ErrorTooComplex", err)
+	}
+	if _, err := CheckQuery(ctx, rl, "u", schema, `{ users(first: 20) { id } }`, "", nil); !rateLimiter.IsBlocked(err) {
+		t.Fatalf("expected block over the budget, got %v", err)
+	}
+}
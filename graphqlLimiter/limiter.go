@@ -0,0 +1,45 @@
+package graphqlLimiter
+
+import (
+	"context"
+	"errors"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+var (
+	ErrorTooComplex  = errors.New("query is too complex")
+	ErrorNoOperation = errors.New("operation not found")
+)
+
+// Check counts the Cost of op against id with CheckN, so an expensive query uses
+// more of the budget than a trivial one. With gqlgen, call it from an
+// AroundOperations handler with the operation context's Operation and Variables.
+func Check(ctx context.Context, rl *rateLimiter.RateLimiter, id string, op *ast.OperationDefinition,
+	vars map[string]interface{}, opts ...Option) (*rateLimiter.CheckResult, error) {
+	if op == nil {
+		return nil, ErrorNoOperation
+	}
+	c := newConfig(opts)
+	cost := c.selectionCost(op.SelectionSet, vars, map[string]bool{})
+	if c.maxCost > 0 && cost > c.maxCost {
+		return nil, ErrorTooComplex
+	}
+	if cost < 1 {
+		cost = 1
+	}
+	return rl.AllowNCtx(ctx, id, cost)
+}
+
+// CheckQuery is Check for a raw query, for servers that don't use gqlparser
+// themselves, such as graph-gophers/graphql-go; schema can be loaded from the same
+// SDL with gqlparser.LoadSchema.
+func CheckQuery(ctx context.Context, rl *rateLimiter.RateLimiter, id string, schema *ast.Schema,
+	query, operationName string, vars map[string]interface{}, opts ...Option) (*rateLimiter.CheckResult, error) {
+	op, err := operation(schema, query, operationName)
+	if err != nil {
+		return nil, err
+	}
+	return Check(ctx, rl, id, op, vars, opts...)
+}
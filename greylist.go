@@ -0,0 +1,144 @@
+package rateLimiter
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+)
+
+var ErrorChallenge = stderrors.New("challenge required")
+
+type ChallengeOutcome int
+
+const (
+	ChallengePending ChallengeOutcome = iota //reject the request until PassChallenge or FailChallenge is called
+	ChallengePassed                          //exempt the id from the greylist for ChallengeTTL
+	ChallengeFailed                          //block the id for ChallengeBlockTTL
+)
+
+// ChallengeHandler decides a request of a greylisted id, e.g. by verifying a
+// CAPTCHA token carried by ctx. It must not block for long: challenges that need
+// the user, like an email link, return ChallengePending and are settled later
+// with PassChallenge or FailChallenge.
+type ChallengeHandler func(ctx context.Context, id string, res *CheckResult) ChallengeOutcome
+
+// ChallengeError is returned for greylisted ids that haven't passed a challenge;
+// it matches ErrorChallenge with errors.Is.
+type ChallengeError struct {
+	Id    string
+	Times int
+	Limit int
+}
+
+func (e *ChallengeError) Error() string {
+	return ErrorChallenge.Error()
+}
+
+func (e *ChallengeError) Is(target error) bool {
+	return target == ErrorChallenge
+}
+
+func IsChallenge(err error) bool {
+	return stderrors.Is(err, ErrorChallenge)
+}
+
+func (rl *RateLimiter) passKey(id string) string {
+	return rl.Name + "-pass:" + id
+}
+
+// greylist applies GreyListRatio to an allowed result: past it, ids that haven't
+// passed a challenge go to ChallengeHandler and are rejected until they pass.
+// The requests stay counted, so the hard limit still applies to them.
+func (rl *RateLimiter) greylist(ctx context.Context, id string, res *CheckResult) (*CheckResult, error) {
+	if rl.GreyListRatio <= 0 || id == globalId || !res.Allowed || res.Limit <= 0 ||
+		float64(res.Used) <= rl.GreyListRatio*float64(res.Limit) {
+		return res, nil
+	}
+	passed, err := rl.Redis.Exists(ctx, rl.passKey(id)).Result()
+	if err != nil {
+		rl.Logger.Error("challenge lookup failed", rl.fields("id", id, "err", err)...)
+		return res, nil
+	}
+	if passed > 0 {
+		return res, nil
+	}
+	res.Greylisted = true
+	if rl.DryRun {
+		return res, nil
+	}
+	outcome := ChallengePending
+	if rl.ChallengeHandler != nil {
+		outcome = rl.ChallengeHandler(ctx, id, res)
+	}
+	switch outcome {
+	case ChallengePassed:
+		if err := rl.PassChallengeCtx(ctx, id); err != nil {
+			rl.Logger.Error("pass challenge failed", rl.fields("id", id, "err", err)...)
+		}
+		res.Greylisted = false
+		return res, nil
+	case ChallengeFailed:
+		if err := rl.FailChallengeCtx(ctx, id); err != nil {
+			rl.Logger.Error("fail challenge failed", rl.fields("id", id, "err", err)...)
+		}
+		res.Greylisted = false
+		rl.block(res, rl.challengeBlockTTL())
+		return res, rl.blockedError(id, res)
+	}
+	res.Allowed = false
+	res.Remaining = 0
+	return res, &ChallengeError{Id: id, Times: res.Used, Limit: res.Limit}
+}
+
+// challengeBlockTTL returns the block of ids failing a challenge, -1 if it is permanent.
+func (rl *RateLimiter) challengeBlockTTL() time.Duration {
+	d := rl.ChallengeBlockTTL
+	if d == 0 {
+		d = rl.Limits().BlockDuration
+	}
+	if d == 0 {
+		return -1
+	}
+	return d
+}
+
+func (rl *RateLimiter) PassChallenge(id string) error {
+	return rl.PassChallengeCtx(context.Background(), id)
+}
+
+// PassChallengeCtx lets id through the greylist for ChallengeTTL, on every instance.
+func (rl *RateLimiter) PassChallengeCtx(ctx context.Context, id string) error {
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return err
+	}
+	ttl := rl.ChallengeTTL
+	if ttl == 0 {
+		ttl = rl.primaryWindow(id).Duration
+	}
+	return rl.Redis.Set(ctx, rl.passKey(id), 1, ttl).Err()
+}
+
+func (rl *RateLimiter) FailChallenge(id string) error {
+	return rl.FailChallengeCtx(context.Background(), id)
+}
+
+// FailChallengeCtx adds id to the block list for ChallengeBlockTTL.
+func (rl *RateLimiter) FailChallengeCtx(ctx context.Context, id string) error {
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return err
+	}
+	if err := rl.Redis.Del(ctx, rl.passKey(id)).Err(); err != nil {
+		return err
+	}
+	ttl := rl.challengeBlockTTL()
+	if ttl < 0 {
+		ttl = 0
+	}
+	entry := ListEntry{Id: id, Reason: "challenge failed", Operator: AutoBlockOperator, CreatedAt: rl.Clock.Now()}
+	if err := rl.addBlockListEntry(ctx, entry, ttl, true); err != nil && err != ErrorBlockListExists {
+		return err
+	}
+	return nil
+}
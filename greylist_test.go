@@ -0,0 +1,70 @@
+package rateLimiter
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+)
+
+func TestGreyList(t *testing.T) {
+	mr, rl := testLimiter(t, "grey", WithDuration(time.Minute), WithBlockTimes(10), WithBlockDuration(time.Minute),
+		WithGreyList(0.5, nil), WithChallengeTTL(time.Hour, 0))
+	for i := 0; i < 5; i++ {
+		if _, err := rl.Check("a"); err != nil {
+			t.Fatalf("check %d: %v", i+1, err)
+		}
+	}
+	res, err := rl.Allow("a")
+	if !IsChallenge(err) || res.Allowed || !res.Greylisted {
+		t.Fatalf("check past the ratio: %+v, %v", res, err)
+	}
+	var ce *ChallengeError
+	if !stderrors.As(err, &ce) || ce.Id != "a" || ce.Times != 6 || ce.Limit != 10 {
+		t.Fatalf("challenge error %#v", err)
+	}
+
+	if err := rl.PassChallenge("a"); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("grey-pass:a"); ttl != time.Hour {
+		t.Fatalf("pass kept %v, want ChallengeTTL", ttl)
+	}
+	if res, err := rl.Allow("a"); err != nil || res.Greylisted {
+		t.Fatalf("check after a passed challenge: %+v, %v", res, err)
+	}
+
+	if err := rl.FailChallenge("a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rl.Check("a"); !IsBlocked(err) {
+		t.Fatalf("check after a failed challenge: %v", err)
+	}
+	if at, ok := rl.blockIds.expiry.get("a"); !ok || time.Until(at) > time.Minute {
+		t.Fatalf("failed challenge blocked until %v, want BlockDuration", at)
+	}
+}
+
+func TestChallengeHandler(t *testing.T) {
+	outcomes := map[string]ChallengeOutcome{"pass": ChallengePassed, "fail": ChallengeFailed}
+	_, rl := testLimiter(t, "grey", WithDuration(time.Minute), WithBlockTimes(10), WithBlockDuration(time.Minute),
+		WithGreyList(0.2, func(ctx context.Context, id string, res *CheckResult) ChallengeOutcome {
+			return outcomes[id]
+		}))
+	for _, id := range []string{"pass", "fail"} {
+		rl.Check(id)
+		rl.Check(id)
+	}
+	if res, err := rl.Allow("pass"); err != nil || res.Greylisted {
+		t.Fatalf("passed challenge: %+v, %v", res, err)
+	}
+	if res, err := rl.Allow("pass"); err != nil || res.Greylisted {
+		t.Fatalf("check after a passed challenge: %+v, %v", res, err)
+	}
+	if res, err := rl.Allow("fail"); !IsBlocked(err) || res.Allowed {
+		t.Fatalf("failed challenge: %+v, %v", res, err)
+	}
+	if !rl.blockIds.has("fail") {
+		t.Fatal("id failing the challenge not on the block list")
+	}
+}
@@ -0,0 +1,326 @@
+package grpcLimiter
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/rate-limiter/grpcLimiter/adminpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Authenticator authorizes an admin call and returns the operator recorded in
+// the audit log; a non-nil error fails the call with Unauthenticated.
+type Authenticator func(ctx context.Context) (operator string, err error)
+
+// NoAuth lets every call through without an operator, for servers that
+// authenticate in an interceptor.
+func NoAuth(ctx context.Context) (string, error) {
+	return "", nil
+}
+
+// BearerToken accepts calls carrying the metadata "authorization: Bearer <token>"
+// and records operator for them. The token is compared in constant time.
+func BearerToken(token, operator string) Authenticator {
+	want := []byte("Bearer " + token)
+	return func(ctx context.Context) (string, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if vals := md.Get("authorization"); token == "" || len(vals) == 0 || subtle.ConstantTimeCompare([]byte(vals[0]), want) != 1 {
+			return "", errors.New("invalid token")
+		}
+		return operator, nil
+	}
+}
+
+// AdminServer implements adminpb.RateLimiterAdminServer over a set of limiters.
+type AdminServer struct {
+	adminpb.UnimplementedRateLimiterAdminServer
+	limiters func() []*rateLimiter.RateLimiter
+	auth     Authenticator
+	pub      bool
+}
+
+// NewAdminServer serves limiters; changes are published to the other instances
+// when pub is true.
+func NewAdminServer(auth Authenticator, pub bool, limiters ...*rateLimiter.RateLimiter) *AdminServer {
+	return newAdminServer(auth, pub, func() []*rateLimiter.RateLimiter { return limiters })
+}
+
+// NewManagerAdminServer serves every limiter of m at call time.
+func NewManagerAdminServer(auth Authenticator, pub bool, m *rateLimiter.Manager) *AdminServer {
+	return newAdminServer(auth, pub, m.List)
+}
+
+func newAdminServer(auth Authenticator, pub bool, limiters func() []*rateLimiter.RateLimiter) *AdminServer {
+	if auth == nil {
+		panic("Authenticator必须设置")
+	}
+	return &AdminServer{limiters: limiters, auth: auth, pub: pub}
+}
+
+// limiter authenticates the call and finds the limiter named name.
+func (s *AdminServer) limiter(ctx context.Context, name string) (context.Context, string, *rateLimiter.RateLimiter, error) {
+	operator, err := s.auth(ctx)
+	if err != nil {
+		return nil, "", nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if operator != "" {
+		ctx = rateLimiter.WithOperator(ctx, operator)
+	}
+	for _, rl := range s.limiters() {
+		if rl.Name == name {
+			return ctx, operator, rl, nil
+		}
+	}
+	return nil, "", nil, status.Error(codes.NotFound, "limiter not found: "+name)
+}
+
+func adminError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, rateLimiter.ErrorInvalidId):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, rateLimiter.ErrorWhiteListExists), errors.Is(err, rateLimiter.ErrorBlockListExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func (s *AdminServer) ListLimiters(ctx context.Context, req *adminpb.ListLimitersRequest) (*adminpb.ListLimitersResponse, error) {
+	if _, err := s.auth(ctx); err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	res := &adminpb.ListLimitersResponse{}
+	for _, rl := range s.limiters() {
+		res.Names = append(res.Names, rl.Name)
+	}
+	return res, nil
+}
+
+func (s *AdminServer) GetConfig(ctx context.Context, req *adminpb.GetConfigRequest) (*adminpb.LimiterConfig, error) {
+	_, _, rl, err := s.limiter(ctx, req.Limiter)
+	if err != nil {
+		return nil, err
+	}
+	return limiterConfig(rl), nil
+}
+
+func limiterConfig(rl *rateLimiter.RateLimiter) *adminpb.LimiterConfig {
+	limits := rl.Limits()
+	res := &adminpb.LimiterConfig{
+		Name:          rl.Name,
+		Duration:      durationpb.New(limits.Duration),
+		BlockTimes:    int32(limits.BlockTimes),
+		BlockDuration: durationpb.New(limits.BlockDuration),
+		DryRun:        rl.DryRun,
+	}
+	for _, win := range rl.Windows {
+		res.Windows = append(res.Windows, &adminpb.Window{Duration: durationpb.New(win.Duration), Limit: int32(win.Limit)})
+	}
+	return res
+}
+
+func (s *AdminServer) UpdateConfig(ctx context.Context, req *adminpb.UpdateConfigRequest) (*adminpb.LimiterConfig, error) {
+	ctx, _, rl, err := s.limiter(ctx, req.Limiter)
+	if err != nil {
+		return nil, err
+	}
+	l := rateLimiter.Limits{Duration: req.Duration.AsDuration(), BlockTimes: int(req.BlockTimes), BlockDuration: req.BlockDuration.AsDuration()}
+	if l.Duration <= 0 || l.BlockTimes < 0 || l.BlockDuration < 0 {
+		return nil, status.Error(codes.InvalidArgument, "duration must be positive and the others not negative")
+	}
+	if err := rl.UpdateConfigCtx(ctx, l, s.pub); err != nil {
+		return nil, adminError(err)
+	}
+	return limiterConfig(rl), nil
+}
+
+func (s *AdminServer) ResetConfig(ctx context.Context, req *adminpb.ResetConfigRequest) (*adminpb.LimiterConfig, error) {
+	ctx, _, rl, err := s.limiter(ctx, req.Limiter)
+	if err != nil {
+		return nil, err
+	}
+	if err := rl.ResetConfigCtx(ctx, s.pub); err != nil {
+		return nil, adminError(err)
+	}
+	return limiterConfig(rl), nil
+}
+
+func (s *AdminServer) GetMode(ctx context.Context, req *adminpb.GetModeRequest) (*adminpb.ModeResponse, error) {
+	_, _, rl, err := s.limiter(ctx, req.Limiter)
+	if err != nil {
+		return nil, err
+	}
+	return &adminpb.ModeResponse{Mode: adminpb.Mode(rl.Mode())}, nil
+}
+
+func (s *AdminServer) SetMode(ctx context.Context, req *adminpb.SetModeRequest) (*adminpb.ModeResponse, error) {
+	ctx, _, rl, err := s.limiter(ctx, req.Limiter)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := adminpb.Mode_name[int32(req.Mode)]; !ok {
+		return nil, status.Error(codes.InvalidArgument, "unknown mode")
+	}
+	if err := rl.SetModeCtx(ctx, rateLimiter.Mode(req.Mode), s.pub); err != nil {
+		return nil, adminError(err)
+	}
+	return &adminpb.ModeResponse{Mode: adminpb.Mode(rl.Mode())}, nil
+}
+
+func (s *AdminServer) ListEntries(ctx context.Context, req *adminpb.ListEntriesRequest) (*adminpb.ListEntriesResponse, error) {
+	ctx, _, rl, err := s.limiter(ctx, req.Limiter)
+	if err != nil {
+		return nil, err
+	}
+	var entries []rateLimiter.ListEntry
+	switch req.List {
+	case adminpb.List_LIST_WHITE:
+		entries, err = rl.GetWhiteListEntries(ctx, nil)
+	case adminpb.List_LIST_BLOCK:
+		entries, err = rl.GetBlockListEntries(ctx, nil)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "list must be set")
+	}
+	if err != nil {
+		return nil, adminError(err)
+	}
+	res := &adminpb.ListEntriesResponse{}
+	for _, e := range entries {
+		entry := &adminpb.ListEntry{Id: e.Id, Reason: e.Reason, Operator: e.Operator}
+		if !e.CreatedAt.IsZero() {
+			entry.CreatedAt = timestamppb.New(e.CreatedAt)
+		}
+		if !e.ExpiresAt.IsZero() {
+			entry.ExpiresAt = timestamppb.New(e.ExpiresAt)
+		}
+		res.Entries = append(res.Entries, entry)
+	}
+	return res, nil
+}
+
+func (s *AdminServer) AddEntry(ctx context.Context, req *adminpb.AddEntryRequest) (*adminpb.AddEntryResponse, error) {
+	ctx, operator, rl, err := s.limiter(ctx, req.Limiter)
+	if err != nil {
+		return nil, err
+	}
+	ttl := req.Ttl.AsDuration()
+	if ttl < 0 {
+		return nil, status.Error(codes.InvalidArgument, "ttl must not be negative")
+	}
+	entry := rateLimiter.ListEntry{Id: req.Id, Reason: req.Reason, Operator: operator}
+	switch req.List {
+	case adminpb.List_LIST_WHITE:
+		err = rl.AddWhiteListEntryCtx(ctx, entry, ttl, s.pub)
+	case adminpb.List_LIST_BLOCK:
+		err = rl.AddBlockListEntryCtx(ctx, entry, ttl, s.pub)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "list must be set")
+	}
+	if err != nil {
+		return nil, adminError(err)
+	}
+	return &adminpb.AddEntryResponse{}, nil
+}
+
+func (s *AdminServer) RemoveEntry(ctx context.Context, req *adminpb.RemoveEntryRequest) (*adminpb.RemoveEntryResponse, error) {
+	ctx, _, rl, err := s.limiter(ctx, req.Limiter)
+	if err != nil {
+		return nil, err
+	}
+	switch req.List {
+	case adminpb.List_LIST_WHITE:
+		err = rl.RemoveWhiteListCtx(ctx, req.Id, s.pub)
+	case adminpb.List_LIST_BLOCK:
+		err = rl.RemoveBlockListCtx(ctx, req.Id, s.pub)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "list must be set")
+	}
+	if err != nil {
+		return nil, adminError(err)
+	}
+	return &adminpb.RemoveEntryResponse{}, nil
+}
+
+func (s *AdminServer) Inspect(ctx context.Context, req *adminpb.InspectRequest) (*adminpb.Inspection, error) {
+	ctx, _, rl, err := s.limiter(ctx, req.Limiter)
+	if err != nil {
+		return nil, err
+	}
+	ins, err := rl.InspectCtx(ctx, req.Id)
+	if err != nil {
+		return nil, adminError(err)
+	}
+	return &adminpb.Inspection{
+		Id:          ins.Id,
+		Times:       int32(ins.Times),
+		Limit:       int32(ins.Limit),
+		Ttl:         durationpb.New(ins.TTL),
+		Blocked:     ins.Blocked,
+		WhiteListed: ins.WhiteListed,
+		BlockListed: ins.BlockListed,
+		Trusted:     ins.Trusted,
+		AllowRule:   ins.AllowRule,
+		DenyRule:    ins.DenyRule,
+		Tier:        ins.Tier,
+	}, nil
+}
+
+func (s *AdminServer) ResetCounter(ctx context.Context, req *adminpb.ResetCounterRequest) (*adminpb.ResetCounterResponse, error) {
+	ctx, _, rl, err := s.limiter(ctx, req.Limiter)
+	if err != nil {
+		return nil, err
+	}
+	if err := rl.CheckResetCtx(ctx, req.Id); err != nil {
+		return nil, adminError(err)
+	}
+	return &adminpb.ResetCounterResponse{}, nil
+}
+
+func (s *AdminServer) GetOverride(ctx context.Context, req *adminpb.GetOverrideRequest) (*adminpb.Override, error) {
+	_, _, rl, err := s.limiter(ctx, req.Limiter)
+	if err != nil {
+		return nil, err
+	}
+	o, err := rl.GetOverride(req.Id)
+	if err != nil {
+		return nil, adminError(err)
+	}
+	if o == nil {
+		return nil, status.Error(codes.NotFound, "override not found: "+req.Id)
+	}
+	return &adminpb.Override{BlockTimes: int32(o.BlockTimes), Duration: durationpb.New(o.Duration)}, nil
+}
+
+func (s *AdminServer) SetOverride(ctx context.Context, req *adminpb.SetOverrideRequest) (*adminpb.SetOverrideResponse, error) {
+	ctx, _, rl, err := s.limiter(ctx, req.Limiter)
+	if err != nil {
+		return nil, err
+	}
+	o := rateLimiter.Override{BlockTimes: int(req.Override.GetBlockTimes()), Duration: req.Override.GetDuration().AsDuration()}
+	if o.BlockTimes < 0 || o.Duration < 0 {
+		return nil, status.Error(codes.InvalidArgument, "override must not be negative")
+	}
+	if err := rl.SetOverrideCtx(ctx, req.Id, o, s.pub); err != nil {
+		return nil, adminError(err)
+	}
+	return &adminpb.SetOverrideResponse{}, nil
+}
+
+func (s *AdminServer) RemoveOverride(ctx context.Context, req *adminpb.RemoveOverrideRequest) (*adminpb.RemoveOverrideResponse, error) {
+	ctx, _, rl, err := s.limiter(ctx, req.Limiter)
+	if err != nil {
+		return nil, err
+	}
+	if err := rl.RemoveOverrideCtx(ctx, req.Id, s.pub); err != nil {
+		return nil, adminError(err)
+	}
+	return &adminpb.RemoveOverrideResponse{}, nil
+}
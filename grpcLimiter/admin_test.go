@@ -0,0 +1,158 @@
+package grpcLimiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/rate-limiter/grpcLimiter/adminpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestAdminServer(t *testing.T) {
+	rl := testLimiter(t, "api")
+	s := NewAdminServer(func(ctx context.Context) (string, error) { return "ops", nil }, true, rl)
+	ctx := context.Background()
+
+	names, err := s.ListLimiters(ctx, &adminpb.ListLimitersRequest{})
+	if err != nil || len(names.Names) != 1 || names.Names[0] != "api" {
+		t.Fatalf("ListLimiters %v, %v", names, err)
+	}
+	conf, err := s.GetConfig(ctx, &adminpb.GetConfigRequest{Limiter: "api"})
+	if err != nil || conf.BlockTimes != 2 || conf.Duration.AsDuration() != time.Minute {
+		t.Fatalf("GetConfig %v, %v", conf, err)
+	}
+	if _, err := s.GetConfig(ctx, &adminpb.GetConfigRequest{Limiter: "other"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("unknown limiter: %v", err)
+	}
+
+	if _, err := s.AddEntry(ctx, &adminpb.AddEntryRequest{Limiter: "api", List: adminpb.List_LIST_BLOCK, Id: "a",
+		Reason: "abuse", Ttl: durationpb.New(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddEntry(ctx, &adminpb.AddEntryRequest{Limiter: "api", Id: "a"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("unset list: %v", err)
+	}
+	if _, err := s.AddEntry(ctx, &adminpb.AddEntryRequest{Limiter: "api", List: adminpb.List_LIST_WHITE, Id: "w"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.AddEntry(ctx, &adminpb.AddEntryRequest{Limiter: "api", List: adminpb.List_LIST_WHITE, Id: "w"}); status.Code(err) != codes.AlreadyExists {
+		t.Fatalf("listed id: %v", err)
+	}
+	entries, err := s.ListEntries(ctx, &adminpb.ListEntriesRequest{Limiter: "api", List: adminpb.List_LIST_BLOCK})
+	if err != nil || len(entries.Entries) != 1 {
+		t.Fatalf("ListEntries %v, %v", entries, err)
+	}
+	if e := entries.Entries[0]; e.Id != "a" || e.Reason != "abuse" || e.Operator != "ops" || e.ExpiresAt == nil {
+		t.Fatalf("entry %v", e)
+	}
+	ins, err := s.Inspect(ctx, &adminpb.InspectRequest{Limiter: "api", Id: "a"})
+	if err != nil || !ins.BlockListed {
+		t.Fatalf("Inspect %v, %v", ins, err)
+	}
+	if _, err := s.RemoveEntry(ctx, &adminpb.RemoveEntryRequest{Limiter: "api", List: adminpb.List_LIST_BLOCK, Id: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := rl.GetBlockList(nil); len(ids) != 0 {
+		t.Fatalf("block list %v after RemoveEntry", ids)
+	}
+
+	rl.Allow("c")
+	if _, err := s.ResetCounter(ctx, &adminpb.ResetCounterRequest{Limiter: "api", Id: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if ins, _ := rl.Inspect("c"); ins.Times != 0 {
+		t.Fatalf("counter %d after ResetCounter", ins.Times)
+	}
+
+	if _, err := s.GetOverride(ctx, &adminpb.GetOverrideRequest{Limiter: "api", Id: "c"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("missing override: %v", err)
+	}
+	if _, err := s.SetOverride(ctx, &adminpb.SetOverrideRequest{Limiter: "api", Id: "c",
+		Override: &adminpb.Override{BlockTimes: 10, Duration: durationpb.New(time.Hour)}}); err != nil {
+		t.Fatal(err)
+	}
+	o, err := s.GetOverride(ctx, &adminpb.GetOverrideRequest{Limiter: "api", Id: "c"})
+	if err != nil || o.BlockTimes != 10 || o.Duration.AsDuration() != time.Hour {
+		t.Fatalf("GetOverride %v, %v", o, err)
+	}
+	if _, err := s.RemoveOverride(ctx, &adminpb.RemoveOverrideRequest{Limiter: "api", Id: "c"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdminServerInspectTier(t *testing.T) {
+	rl := testLimiter(t, "api", rateLimiter.WithTiers(func(id string) string { return "pro" },
+		map[string]rateLimiter.Tier{"pro": {BlockTimes: 10, Duration: time.Minute}}))
+	s := NewAdminServer(func(ctx context.Context) (string, error) { return "ops", nil }, true, rl)
+	ins, err := s.Inspect(context.Background(), &adminpb.InspectRequest{Limiter: "api", Id: "a"})
+	if err != nil || ins.Tier != "pro" || ins.Limit != 10 {
+		t.Fatalf("Inspect %v, %v", ins, err)
+	}
+}
+
+func TestAdminServerAuth(t *testing.T) {
+	rl := testLimiter(t, "api")
+	s := NewAdminServer(func(ctx context.Context) (string, error) { return "", errors.New("denied") }, true, rl)
+	if _, err := s.ListLimiters(context.Background(), &adminpb.ListLimitersRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("ListLimiters: %v", err)
+	}
+	if _, err := s.Inspect(context.Background(), &adminpb.InspectRequest{Limiter: "api", Id: "a"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Inspect: %v", err)
+	}
+}
+
+func TestAdminServerConfig(t *testing.T) {
+	rl := testLimiter(t, "api")
+	s := NewAdminServer(NoAuth, true, rl)
+	ctx := context.Background()
+	conf, err := s.UpdateConfig(ctx, &adminpb.UpdateConfigRequest{Limiter: "api", Duration: durationpb.New(time.Hour), BlockTimes: 10})
+	if err != nil || conf.BlockTimes != 10 || conf.Duration.AsDuration() != time.Hour {
+		t.Fatalf("UpdateConfig %v, %v", conf, err)
+	}
+	if _, err := s.UpdateConfig(ctx, &adminpb.UpdateConfigRequest{Limiter: "api", BlockTimes: 10}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("unset duration: %v", err)
+	}
+	if conf, err := s.ResetConfig(ctx, &adminpb.ResetConfigRequest{Limiter: "api"}); err != nil || conf.BlockTimes != 2 {
+		t.Fatalf("ResetConfig %v, %v", conf, err)
+	}
+}
+
+func TestAdminServerMode(t *testing.T) {
+	rl := testLimiter(t, "api")
+	s := NewAdminServer(NoAuth, true, rl)
+	ctx := context.Background()
+	if res, err := s.SetMode(ctx, &adminpb.SetModeRequest{Limiter: "api", Mode: adminpb.Mode_MODE_ALLOW_ALL}); err != nil || res.Mode != adminpb.Mode_MODE_ALLOW_ALL {
+		t.Fatalf("SetMode %v, %v", res, err)
+	}
+	if res, err := s.GetMode(ctx, &adminpb.GetModeRequest{Limiter: "api"}); err != nil || res.Mode != adminpb.Mode_MODE_ALLOW_ALL {
+		t.Fatalf("GetMode %v, %v", res, err)
+	}
+	if _, err := s.SetMode(ctx, &adminpb.SetModeRequest{Limiter: "api", Mode: 7}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("unknown mode: %v", err)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	auth := BearerToken("secret", "ops")
+	for header, ok := range map[string]bool{
+		"Bearer secret":  true,
+		"Bearer secret2": false,
+		"Bearer secre":   false,
+		"secret":         false,
+	} {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", header))
+		op, err := auth(ctx)
+		if (err == nil) != ok || ok && op != "ops" {
+			t.Errorf("%q: operator %q, err %v", header, op, err)
+		}
+	}
+	if _, err := auth(context.Background()); err == nil {
+		t.Error("call without metadata accepted")
+	}
+}
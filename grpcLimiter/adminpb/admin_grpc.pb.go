@@ -0,0 +1,621 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v3.21.12
+// source: admin.proto
+
+package adminpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RateLimiterAdmin_ListLimiters_FullMethodName   = "/ratelimiter.admin.v1.RateLimiterAdmin/ListLimiters"
+	RateLimiterAdmin_GetConfig_FullMethodName      = "/ratelimiter.admin.v1.RateLimiterAdmin/GetConfig"
+	RateLimiterAdmin_UpdateConfig_FullMethodName   = "/ratelimiter.admin.v1.RateLimiterAdmin/UpdateConfig"
+	RateLimiterAdmin_ResetConfig_FullMethodName    = "/ratelimiter.admin.v1.RateLimiterAdmin/ResetConfig"
+	RateLimiterAdmin_GetMode_FullMethodName        = "/ratelimiter.admin.v1.RateLimiterAdmin/GetMode"
+	RateLimiterAdmin_SetMode_FullMethodName        = "/ratelimiter.admin.v1.RateLimiterAdmin/SetMode"
+	RateLimiterAdmin_ListEntries_FullMethodName    = "/ratelimiter.admin.v1.RateLimiterAdmin/ListEntries"
+	RateLimiterAdmin_AddEntry_FullMethodName       = "/ratelimiter.admin.v1.RateLimiterAdmin/AddEntry"
+	RateLimiterAdmin_RemoveEntry_FullMethodName    = "/ratelimiter.admin.v1.RateLimiterAdmin/RemoveEntry"
+	RateLimiterAdmin_Inspect_FullMethodName        = "/ratelimiter.admin.v1.RateLimiterAdmin/Inspect"
+	RateLimiterAdmin_ResetCounter_FullMethodName   = "/ratelimiter.admin.v1.RateLimiterAdmin/ResetCounter"
+	RateLimiterAdmin_GetOverride_FullMethodName    = "/ratelimiter.admin.v1.RateLimiterAdmin/GetOverride"
+	RateLimiterAdmin_SetOverride_FullMethodName    = "/ratelimiter.admin.v1.RateLimiterAdmin/SetOverride"
+	RateLimiterAdmin_RemoveOverride_FullMethodName = "/ratelimiter.admin.v1.RateLimiterAdmin/RemoveOverride"
+)
+
+// RateLimiterAdminClient is the client API for RateLimiterAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// RateLimiterAdmin manages the limiters of a service. Changes are published to
+// the other instances like the Go API does with pub=true.
+type RateLimiterAdminClient interface {
+	ListLimiters(ctx context.Context, in *ListLimitersRequest, opts ...grpc.CallOption) (*ListLimitersResponse, error)
+	GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*LimiterConfig, error)
+	UpdateConfig(ctx context.Context, in *UpdateConfigRequest, opts ...grpc.CallOption) (*LimiterConfig, error)
+	ResetConfig(ctx context.Context, in *ResetConfigRequest, opts ...grpc.CallOption) (*LimiterConfig, error)
+	GetMode(ctx context.Context, in *GetModeRequest, opts ...grpc.CallOption) (*ModeResponse, error)
+	SetMode(ctx context.Context, in *SetModeRequest, opts ...grpc.CallOption) (*ModeResponse, error)
+	ListEntries(ctx context.Context, in *ListEntriesRequest, opts ...grpc.CallOption) (*ListEntriesResponse, error)
+	AddEntry(ctx context.Context, in *AddEntryRequest, opts ...grpc.CallOption) (*AddEntryResponse, error)
+	RemoveEntry(ctx context.Context, in *RemoveEntryRequest, opts ...grpc.CallOption) (*RemoveEntryResponse, error)
+	Inspect(ctx context.Context, in *InspectRequest, opts ...grpc.CallOption) (*Inspection, error)
+	ResetCounter(ctx context.Context, in *ResetCounterRequest, opts ...grpc.CallOption) (*ResetCounterResponse, error)
+	GetOverride(ctx context.Context, in *GetOverrideRequest, opts ...grpc.CallOption) (*Override, error)
+	SetOverride(ctx context.Context, in *SetOverrideRequest, opts ...grpc.CallOption) (*SetOverrideResponse, error)
+	RemoveOverride(ctx context.Context, in *RemoveOverrideRequest, opts ...grpc.CallOption) (*RemoveOverrideResponse, error)
+}
+
+type rateLimiterAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRateLimiterAdminClient(cc grpc.ClientConnInterface) RateLimiterAdminClient {
+	return &rateLimiterAdminClient{cc}
+}
+
+func (c *rateLimiterAdminClient) ListLimiters(ctx context.Context, in *ListLimitersRequest, opts ...grpc.CallOption) (*ListLimitersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLimitersResponse)
+	err := c.cc.Invoke(ctx, RateLimiterAdmin_ListLimiters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterAdminClient) GetConfig(ctx context.Context, in *GetConfigRequest, opts ...grpc.CallOption) (*LimiterConfig, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LimiterConfig)
+	err := c.cc.Invoke(ctx, RateLimiterAdmin_GetConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterAdminClient) UpdateConfig(ctx context.Context, in *UpdateConfigRequest, opts ...grpc.CallOption) (*LimiterConfig, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LimiterConfig)
+	err := c.cc.Invoke(ctx, RateLimiterAdmin_UpdateConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterAdminClient) ResetConfig(ctx context.Context, in *ResetConfigRequest, opts ...grpc.CallOption) (*LimiterConfig, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LimiterConfig)
+	err := c.cc.Invoke(ctx, RateLimiterAdmin_ResetConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterAdminClient) GetMode(ctx context.Context, in *GetModeRequest, opts ...grpc.CallOption) (*ModeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ModeResponse)
+	err := c.cc.Invoke(ctx, RateLimiterAdmin_GetMode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterAdminClient) SetMode(ctx context.Context, in *SetModeRequest, opts ...grpc.CallOption) (*ModeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ModeResponse)
+	err := c.cc.Invoke(ctx, RateLimiterAdmin_SetMode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterAdminClient) ListEntries(ctx context.Context, in *ListEntriesRequest, opts ...grpc.CallOption) (*ListEntriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListEntriesResponse)
+	err := c.cc.Invoke(ctx, RateLimiterAdmin_ListEntries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterAdminClient) AddEntry(ctx context.Context, in *AddEntryRequest, opts ...grpc.CallOption) (*AddEntryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddEntryResponse)
+	err := c.cc.Invoke(ctx, RateLimiterAdmin_AddEntry_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterAdminClient) RemoveEntry(ctx context.Context, in *RemoveEntryRequest, opts ...grpc.CallOption) (*RemoveEntryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveEntryResponse)
+	err := c.cc.Invoke(ctx, RateLimiterAdmin_RemoveEntry_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterAdminClient) Inspect(ctx context.Context, in *InspectRequest, opts ...grpc.CallOption) (*Inspection, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Inspection)
+	err := c.cc.Invoke(ctx, RateLimiterAdmin_Inspect_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterAdminClient) ResetCounter(ctx context.Context, in *ResetCounterRequest, opts ...grpc.CallOption) (*ResetCounterResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResetCounterResponse)
+	err := c.cc.Invoke(ctx, RateLimiterAdmin_ResetCounter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterAdminClient) GetOverride(ctx context.Context, in *GetOverrideRequest, opts ...grpc.CallOption) (*Override, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Override)
+	err := c.cc.Invoke(ctx, RateLimiterAdmin_GetOverride_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterAdminClient) SetOverride(ctx context.Context, in *SetOverrideRequest, opts ...grpc.CallOption) (*SetOverrideResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetOverrideResponse)
+	err := c.cc.Invoke(ctx, RateLimiterAdmin_SetOverride_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterAdminClient) RemoveOverride(ctx context.Context, in *RemoveOverrideRequest, opts ...grpc.CallOption) (*RemoveOverrideResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveOverrideResponse)
+	err := c.cc.Invoke(ctx, RateLimiterAdmin_RemoveOverride_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RateLimiterAdminServer is the server API for RateLimiterAdmin service.
+// All implementations must embed UnimplementedRateLimiterAdminServer
+// for forward compatibility.
+//
+// RateLimiterAdmin manages the limiters of a service. Changes are published to
+// the other instances like the Go API does with pub=true.
+type RateLimiterAdminServer interface {
+	ListLimiters(context.Context, *ListLimitersRequest) (*ListLimitersResponse, error)
+	GetConfig(context.Context, *GetConfigRequest) (*LimiterConfig, error)
+	UpdateConfig(context.Context, *UpdateConfigRequest) (*LimiterConfig, error)
+	ResetConfig(context.Context, *ResetConfigRequest) (*LimiterConfig, error)
+	GetMode(context.Context, *GetModeRequest) (*ModeResponse, error)
+	SetMode(context.Context, *SetModeRequest) (*ModeResponse, error)
+	ListEntries(context.Context, *ListEntriesRequest) (*ListEntriesResponse, error)
+	AddEntry(context.Context, *AddEntryRequest) (*AddEntryResponse, error)
+	RemoveEntry(context.Context, *RemoveEntryRequest) (*RemoveEntryResponse, error)
+	Inspect(context.Context, *InspectRequest) (*Inspection, error)
+	ResetCounter(context.Context, *ResetCounterRequest) (*ResetCounterResponse, error)
+	GetOverride(context.Context, *GetOverrideRequest) (*Override, error)
+	SetOverride(context.Context, *SetOverrideRequest) (*SetOverrideResponse, error)
+	RemoveOverride(context.Context, *RemoveOverrideRequest) (*RemoveOverrideResponse, error)
+	mustEmbedUnimplementedRateLimiterAdminServer()
+}
+
+// UnimplementedRateLimiterAdminServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRateLimiterAdminServer struct{}
+
+func (UnimplementedRateLimiterAdminServer) ListLimiters(context.Context, *ListLimitersRequest) (*ListLimitersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListLimiters not implemented")
+}
+func (UnimplementedRateLimiterAdminServer) GetConfig(context.Context, *GetConfigRequest) (*LimiterConfig, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConfig not implemented")
+}
+func (UnimplementedRateLimiterAdminServer) UpdateConfig(context.Context, *UpdateConfigRequest) (*LimiterConfig, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateConfig not implemented")
+}
+func (UnimplementedRateLimiterAdminServer) ResetConfig(context.Context, *ResetConfigRequest) (*LimiterConfig, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetConfig not implemented")
+}
+func (UnimplementedRateLimiterAdminServer) GetMode(context.Context, *GetModeRequest) (*ModeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMode not implemented")
+}
+func (UnimplementedRateLimiterAdminServer) SetMode(context.Context, *SetModeRequest) (*ModeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMode not implemented")
+}
+func (UnimplementedRateLimiterAdminServer) ListEntries(context.Context, *ListEntriesRequest) (*ListEntriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEntries not implemented")
+}
+func (UnimplementedRateLimiterAdminServer) AddEntry(context.Context, *AddEntryRequest) (*AddEntryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddEntry not implemented")
+}
+func (UnimplementedRateLimiterAdminServer) RemoveEntry(context.Context, *RemoveEntryRequest) (*RemoveEntryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveEntry not implemented")
+}
+func (UnimplementedRateLimiterAdminServer) Inspect(context.Context, *InspectRequest) (*Inspection, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Inspect not implemented")
+}
+func (UnimplementedRateLimiterAdminServer) ResetCounter(context.Context, *ResetCounterRequest) (*ResetCounterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetCounter not implemented")
+}
+func (UnimplementedRateLimiterAdminServer) GetOverride(context.Context, *GetOverrideRequest) (*Override, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOverride not implemented")
+}
+func (UnimplementedRateLimiterAdminServer) SetOverride(context.Context, *SetOverrideRequest) (*SetOverrideResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetOverride not implemented")
+}
+func (UnimplementedRateLimiterAdminServer) RemoveOverride(context.Context, *RemoveOverrideRequest) (*RemoveOverrideResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveOverride not implemented")
+}
+func (UnimplementedRateLimiterAdminServer) mustEmbedUnimplementedRateLimiterAdminServer() {}
+func (UnimplementedRateLimiterAdminServer) testEmbeddedByValue()                          {}
+
+// UnsafeRateLimiterAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RateLimiterAdminServer will
+// result in compilation errors.
+type UnsafeRateLimiterAdminServer interface {
+	mustEmbedUnimplementedRateLimiterAdminServer()
+}
+
+func RegisterRateLimiterAdminServer(s grpc.ServiceRegistrar, srv RateLimiterAdminServer) {
+	// If the following call pancis, it indicates UnimplementedRateLimiterAdminServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RateLimiterAdmin_ServiceDesc, srv)
+}
+
+func _RateLimiterAdmin_ListLimiters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLimitersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterAdminServer).ListLimiters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiterAdmin_ListLimiters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterAdminServer).ListLimiters(ctx, req.(*ListLimitersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiterAdmin_GetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterAdminServer).GetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiterAdmin_GetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterAdminServer).GetConfig(ctx, req.(*GetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiterAdmin_UpdateConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterAdminServer).UpdateConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiterAdmin_UpdateConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterAdminServer).UpdateConfig(ctx, req.(*UpdateConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiterAdmin_ResetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterAdminServer).ResetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiterAdmin_ResetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterAdminServer).ResetConfig(ctx, req.(*ResetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiterAdmin_GetMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetModeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterAdminServer).GetMode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiterAdmin_GetMode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterAdminServer).GetMode(ctx, req.(*GetModeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiterAdmin_SetMode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetModeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterAdminServer).SetMode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiterAdmin_SetMode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterAdminServer).SetMode(ctx, req.(*SetModeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiterAdmin_ListEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterAdminServer).ListEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiterAdmin_ListEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterAdminServer).ListEntries(ctx, req.(*ListEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiterAdmin_AddEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterAdminServer).AddEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiterAdmin_AddEntry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterAdminServer).AddEntry(ctx, req.(*AddEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiterAdmin_RemoveEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterAdminServer).RemoveEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiterAdmin_RemoveEntry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterAdminServer).RemoveEntry(ctx, req.(*RemoveEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiterAdmin_Inspect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterAdminServer).Inspect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiterAdmin_Inspect_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterAdminServer).Inspect(ctx, req.(*InspectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiterAdmin_ResetCounter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetCounterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterAdminServer).ResetCounter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiterAdmin_ResetCounter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterAdminServer).ResetCounter(ctx, req.(*ResetCounterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiterAdmin_GetOverride_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOverrideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterAdminServer).GetOverride(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiterAdmin_GetOverride_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterAdminServer).GetOverride(ctx, req.(*GetOverrideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiterAdmin_SetOverride_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetOverrideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterAdminServer).SetOverride(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiterAdmin_SetOverride_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterAdminServer).SetOverride(ctx, req.(*SetOverrideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiterAdmin_RemoveOverride_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveOverrideRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterAdminServer).RemoveOverride(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiterAdmin_RemoveOverride_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterAdminServer).RemoveOverride(ctx, req.(*RemoveOverrideRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RateLimiterAdmin_ServiceDesc is the grpc.ServiceDesc for RateLimiterAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RateLimiterAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ratelimiter.admin.v1.RateLimiterAdmin",
+	HandlerType: (*RateLimiterAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListLimiters",
+			Handler:    _RateLimiterAdmin_ListLimiters_Handler,
+		},
+		{
+			MethodName: "GetConfig",
+			Handler:    _RateLimiterAdmin_GetConfig_Handler,
+		},
+		{
+			MethodName: "UpdateConfig",
+			Handler:    _RateLimiterAdmin_UpdateConfig_Handler,
+		},
+		{
+			MethodName: "ResetConfig",
+			Handler:    _RateLimiterAdmin_ResetConfig_Handler,
+		},
+		{
+			MethodName: "GetMode",
+			Handler:    _RateLimiterAdmin_GetMode_Handler,
+		},
+		{
+			MethodName: "SetMode",
+			Handler:    _RateLimiterAdmin_SetMode_Handler,
+		},
+		{
+			MethodName: "ListEntries",
+			Handler:    _RateLimiterAdmin_ListEntries_Handler,
+		},
+		{
+			MethodName: "AddEntry",
+			Handler:    _RateLimiterAdmin_AddEntry_Handler,
+		},
+		{
+			MethodName: "RemoveEntry",
+			Handler:    _RateLimiterAdmin_RemoveEntry_Handler,
+		},
+		{
+			MethodName: "Inspect",
+			Handler:    _RateLimiterAdmin_Inspect_Handler,
+		},
+		{
+			MethodName: "ResetCounter",
+			Handler:    _RateLimiterAdmin_ResetCounter_Handler,
+		},
+		{
+			MethodName: "GetOverride",
+			Handler:    _RateLimiterAdmin_GetOverride_Handler,
+		},
+		{
+			MethodName: "SetOverride",
+			Handler:    _RateLimiterAdmin_SetOverride_Handler,
+		},
+		{
+			MethodName: "RemoveOverride",
+			Handler:    _RateLimiterAdmin_RemoveOverride_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "admin.proto",
+}
@@ -0,0 +1,161 @@
+package grpcLimiter
+
+import (
+	"context"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/rate-limiter/grpcLimiter/adminpb"
+	"github.com/go-estar/rate-limiter/grpcLimiter/limiterpb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Client calls the limiters of a server running LimiterServer and AdminServer
+// with the types of the rateLimiter package. Credentials expected by their
+// Authenticators go on conn, e.g. as per-RPC credentials.
+type Client struct {
+	check limiterpb.RateLimiterClient
+	admin adminpb.RateLimiterAdminClient
+}
+
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{check: limiterpb.NewRateLimiterClient(conn), admin: adminpb.NewRateLimiterAdminClient(conn)}
+}
+
+// Admin returns the raw admin client, for the calls Client doesn't wrap.
+func (c *Client) Admin() adminpb.RateLimiterAdminClient {
+	return c.admin
+}
+
+func (c *Client) Check(ctx context.Context, limiter, id string) (int, error) {
+	res, err := c.AllowN(ctx, limiter, id, 1)
+	if res == nil {
+		return 0, err
+	}
+	return res.Used, err
+}
+
+func (c *Client) Allow(ctx context.Context, limiter, id string) (*rateLimiter.CheckResult, error) {
+	return c.AllowN(ctx, limiter, id, 1)
+}
+
+// AllowN is RateLimiter.AllowNCtx on the server: rejections return the result
+// with a *rateLimiter.BlockedError, or a *rateLimiter.ChallengeError for
+// greylisted ids, so IsBlocked and IsChallenge work as they do locally. Other
+// failures are gRPC status errors.
+func (c *Client) AllowN(ctx context.Context, limiter, id string, n int) (*rateLimiter.CheckResult, error) {
+	resp, err := c.check.Check(ctx, &limiterpb.CheckRequest{Limiter: limiter, Id: id, N: int32(n)})
+	if err != nil {
+		return nil, err
+	}
+	res := &rateLimiter.CheckResult{
+		Allowed:       resp.Allowed,
+		Window:        resp.Window.AsDuration(),
+		Limit:         int(resp.Limit),
+		Used:          int(resp.Used),
+		Remaining:     int(resp.Remaining),
+		RetryAfter:    resp.RetryAfter.AsDuration(),
+		DryRunBlocked: resp.DryRunBlocked,
+		FailedOpen:    resp.FailedOpen,
+		Greylisted:    resp.Greylisted,
+		Delay:         resp.Delay.AsDuration(),
+	}
+	if resp.ResetAt != nil {
+		res.ResetAt = resp.ResetAt.AsTime()
+	}
+	if resp.Permanent {
+		res.RetryAfter = -1
+	}
+	switch {
+	case res.Allowed:
+		return res, nil
+	case res.Greylisted:
+		return res, &rateLimiter.ChallengeError{Id: id, Times: res.Used, Limit: res.Limit}
+	default:
+		return res, &rateLimiter.BlockedError{
+			Err:       rateLimiter.ErrorBlock,
+			Id:        id,
+			Times:     res.Used,
+			Limit:     res.Limit,
+			Permanent: resp.Permanent,
+			ExpiresAt: res.ResetAt,
+		}
+	}
+}
+
+func (c *Client) Inspect(ctx context.Context, limiter, id string) (*rateLimiter.Inspection, error) {
+	ins, err := c.admin.Inspect(ctx, &adminpb.InspectRequest{Limiter: limiter, Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return &rateLimiter.Inspection{
+		Id:          ins.Id,
+		Times:       int(ins.Times),
+		Limit:       int(ins.Limit),
+		TTL:         ins.Ttl.AsDuration(),
+		Blocked:     ins.Blocked,
+		WhiteListed: ins.WhiteListed,
+		BlockListed: ins.BlockListed,
+		Trusted:     ins.Trusted,
+		AllowRule:   ins.AllowRule,
+		DenyRule:    ins.DenyRule,
+	}, nil
+}
+
+func (c *Client) GetWhiteListEntries(ctx context.Context, limiter string) ([]rateLimiter.ListEntry, error) {
+	return c.listEntries(ctx, limiter, adminpb.List_LIST_WHITE)
+}
+
+func (c *Client) GetBlockListEntries(ctx context.Context, limiter string) ([]rateLimiter.ListEntry, error) {
+	return c.listEntries(ctx, limiter, adminpb.List_LIST_BLOCK)
+}
+
+func (c *Client) listEntries(ctx context.Context, limiter string, list adminpb.List) ([]rateLimiter.ListEntry, error) {
+	resp, err := c.admin.ListEntries(ctx, &adminpb.ListEntriesRequest{Limiter: limiter, List: list})
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]rateLimiter.ListEntry, 0, len(resp.Entries))
+	for _, e := range resp.Entries {
+		entry := rateLimiter.ListEntry{Id: e.Id, Reason: e.Reason, Operator: e.Operator}
+		if e.CreatedAt != nil {
+			entry.CreatedAt = e.CreatedAt.AsTime()
+		}
+		if e.ExpiresAt != nil {
+			entry.ExpiresAt = e.ExpiresAt.AsTime()
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// AddWhiteList adds id to the white list of limiter for ttl, 0=permanently. The
+// server records the operator returned by its Authenticator.
+func (c *Client) AddWhiteList(ctx context.Context, limiter, id, reason string, ttl time.Duration) error {
+	return c.addEntry(ctx, limiter, adminpb.List_LIST_WHITE, id, reason, ttl)
+}
+
+func (c *Client) AddBlockList(ctx context.Context, limiter, id, reason string, ttl time.Duration) error {
+	return c.addEntry(ctx, limiter, adminpb.List_LIST_BLOCK, id, reason, ttl)
+}
+
+func (c *Client) addEntry(ctx context.Context, limiter string, list adminpb.List, id, reason string, ttl time.Duration) error {
+	_, err := c.admin.AddEntry(ctx, &adminpb.AddEntryRequest{Limiter: limiter, List: list, Id: id, Reason: reason, Ttl: durationpb.New(ttl)})
+	return err
+}
+
+func (c *Client) RemoveWhiteList(ctx context.Context, limiter, id string) error {
+	_, err := c.admin.RemoveEntry(ctx, &adminpb.RemoveEntryRequest{Limiter: limiter, List: adminpb.List_LIST_WHITE, Id: id})
+	return err
+}
+
+func (c *Client) RemoveBlockList(ctx context.Context, limiter, id string) error {
+	_, err := c.admin.RemoveEntry(ctx, &adminpb.RemoveEntryRequest{Limiter: limiter, List: adminpb.List_LIST_BLOCK, Id: id})
+	return err
+}
+
+func (c *Client) CheckReset(ctx context.Context, limiter, id string) error {
+	_, err := c.admin.ResetCounter(ctx, &adminpb.ResetCounterRequest{Limiter: limiter, Id: id})
+	return err
+}
@@ -0,0 +1,151 @@
+package grpcLimiter
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+var ErrorNoKey = errors.New("no rate limit key")
+
+// KeyFunc extracts the id to rate limit from an incoming call.
+type KeyFunc func(ctx context.Context, fullMethod string) (string, error)
+
+// PeerAddr uses the host of the peer address.
+func PeerAddr(ctx context.Context, fullMethod string) (string, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "", ErrorNoKey
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String(), nil
+	}
+	return host, nil
+}
+
+// Metadata uses the first value of the incoming metadata key, e.g. an API key.
+func Metadata(key string) KeyFunc {
+	return func(ctx context.Context, fullMethod string) (string, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if vals := md.Get(key); len(vals) > 0 && vals[0] != "" {
+			return vals[0], nil
+		}
+		return "", ErrorNoKey
+	}
+}
+
+// Method uses the full method name, limiting each method as a whole.
+func Method(ctx context.Context, fullMethod string) (string, error) {
+	return fullMethod, nil
+}
+
+type config struct {
+	key       KeyFunc
+	perMethod bool
+	skipper   func(ctx context.Context, fullMethod string) bool
+}
+
+type Option func(*config)
+
+// WithKeyFunc sets how the id is extracted; the default is PeerAddr.
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(c *config) {
+		c.key = fn
+	}
+}
+
+// WithPerMethod counts every method separately by prefixing the id with it.
+func WithPerMethod() Option {
+	return func(c *config) {
+		c.perMethod = true
+	}
+}
+
+// WithSkipper lets calls for which fn returns true through unchecked.
+func WithSkipper(fn func(ctx context.Context, fullMethod string) bool) Option {
+	return func(c *config) {
+		c.skipper = fn
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{key: PeerAddr}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *config) check(ctx context.Context, rl *rateLimiter.RateLimiter, fullMethod string) error {
+	if c.skipper != nil && c.skipper(ctx, fullMethod) {
+		return nil
+	}
+	id, err := c.key(ctx, fullMethod)
+	if err == nil && id == "" {
+		err = ErrorNoKey
+	}
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if c.perMethod {
+		id = fullMethod + " " + id
+	}
+	res, err := rl.AllowCtx(ctx, id)
+	if res != nil && !res.Allowed {
+		return blockedStatus(res, err)
+	}
+	if errors.Is(err, rateLimiter.ErrorInvalidId) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return nil
+}
+
+// blockedStatus is ResourceExhausted with a RetryInfo detail for limited blocks.
+func blockedStatus(res *rateLimiter.CheckResult, err error) error {
+	msg := "rate limit exceeded"
+	if err != nil {
+		msg = err.Error()
+	}
+	st := status.New(codes.ResourceExhausted, msg)
+	if res.RetryAfter > 0 {
+		if d, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(res.RetryAfter)}); err == nil {
+			st = d
+		}
+	}
+	return st.Err()
+}
+
+// UnaryServerInterceptor rate limits unary calls.
+func UnaryServerInterceptor(rl *rateLimiter.RateLimiter, opts ...Option) grpc.UnaryServerInterceptor {
+	c := newConfig(opts)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := c.check(ctx, rl, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rate limits the opening of streams.
+func StreamServerInterceptor(rl *rateLimiter.RateLimiter, opts ...Option) grpc.StreamServerInterceptor {
+	c := newConfig(opts)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := c.check(ss.Context(), rl, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
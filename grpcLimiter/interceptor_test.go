@@ -0,0 +1,70 @@
+package grpcLimiter
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func peerContext(addr string) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 1234}})
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(testLimiter(t, "grpc"), WithPerMethod())
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	call := func(ctx context.Context, method string) error {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	ctx := peerContext("10.0.0.1")
+	if err := call(ctx, "/svc/A"); err != nil {
+		t.Fatal(err)
+	}
+	err := call(ctx, "/svc/A")
+	st := status.Convert(err)
+	if st.Code() != codes.ResourceExhausted {
+		t.Fatalf("got %v, want ResourceExhausted", err)
+	}
+	if len(st.Details()) != 1 {
+		t.Fatalf("details %v, want RetryInfo", st.Details())
+	}
+	if info, ok := st.Details()[0].(*errdetails.RetryInfo); !ok || info.RetryDelay.AsDuration() != time.Minute {
+		t.Fatalf("details %v, want a 1m RetryInfo", st.Details())
+	}
+	if err := call(ctx, "/svc/B"); err != nil {
+		t.Fatalf("methods share a counter: %v", err)
+	}
+	if err := call(context.Background(), "/svc/B"); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("call without peer: %v, want InvalidArgument", err)
+	}
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testStream) Context() context.Context { return s.ctx }
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := StreamServerInterceptor(testLimiter(t, "grpc"), WithKeyFunc(Metadata("x-api-key")))
+	handler := func(srv interface{}, ss grpc.ServerStream) error { return nil }
+	ss := &testStream{ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "k"))}
+	info := &grpc.StreamServerInfo{FullMethod: "/svc/Watch"}
+	if err := interceptor(nil, ss, info, handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := interceptor(nil, ss, info, handler); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("got %v, want ResourceExhausted", err)
+	}
+}
@@ -0,0 +1,4 @@
+// Package limiterpb holds the generated code of the limiter check service.
+package limiterpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative limiter.proto
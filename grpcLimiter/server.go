@@ -0,0 +1,94 @@
+package grpcLimiter
+
+import (
+	"context"
+	"errors"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/rate-limiter/grpcLimiter/limiterpb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// LimiterServer implements limiterpb.RateLimiterServer over a set of limiters,
+// so that other services can check requests against them.
+type LimiterServer struct {
+	limiterpb.UnimplementedRateLimiterServer
+	limiters func() []*rateLimiter.RateLimiter
+	auth     Authenticator
+}
+
+// NewLimiterServer serves Check for limiters; pass NoAuth if every caller may
+// consume their limits.
+func NewLimiterServer(auth Authenticator, limiters ...*rateLimiter.RateLimiter) *LimiterServer {
+	return newLimiterServer(auth, func() []*rateLimiter.RateLimiter { return limiters })
+}
+
+// NewManagerLimiterServer serves Check for every limiter of m at call time.
+func NewManagerLimiterServer(auth Authenticator, m *rateLimiter.Manager) *LimiterServer {
+	return newLimiterServer(auth, m.List)
+}
+
+func newLimiterServer(auth Authenticator, limiters func() []*rateLimiter.RateLimiter) *LimiterServer {
+	if auth == nil {
+		panic("Authenticator必须设置")
+	}
+	return &LimiterServer{limiters: limiters, auth: auth}
+}
+
+func (s *LimiterServer) Check(ctx context.Context, req *limiterpb.CheckRequest) (*limiterpb.CheckResponse, error) {
+	if _, err := s.auth(ctx); err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	var rl *rateLimiter.RateLimiter
+	for _, l := range s.limiters() {
+		if l.Name == req.Limiter {
+			rl = l
+			break
+		}
+	}
+	if rl == nil {
+		return nil, status.Error(codes.NotFound, "limiter not found: "+req.Limiter)
+	}
+	n := int(req.N)
+	if n == 0 {
+		n = 1
+	}
+	if n < 0 {
+		return nil, status.Error(codes.InvalidArgument, "n must not be negative")
+	}
+	res, err := rl.AllowNCtx(ctx, req.Id, n)
+	if res == nil {
+		if errors.Is(err, rateLimiter.ErrorInvalidId) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return checkResponse(res), nil
+}
+
+func checkResponse(res *rateLimiter.CheckResult) *limiterpb.CheckResponse {
+	resp := &limiterpb.CheckResponse{
+		Allowed:       res.Allowed,
+		Window:        durationpb.New(res.Window),
+		Limit:         int32(res.Limit),
+		Used:          int32(res.Used),
+		Remaining:     int32(res.Remaining),
+		Permanent:     res.RetryAfter < 0,
+		DryRunBlocked: res.DryRunBlocked,
+		FailedOpen:    res.FailedOpen,
+		Greylisted:    res.Greylisted,
+	}
+	if !res.ResetAt.IsZero() {
+		resp.ResetAt = timestamppb.New(res.ResetAt)
+	}
+	if res.RetryAfter > 0 {
+		resp.RetryAfter = durationpb.New(res.RetryAfter)
+	}
+	if res.Delay > 0 {
+		resp.Delay = durationpb.New(res.Delay)
+	}
+	return resp
+}
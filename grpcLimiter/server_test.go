@@ -0,0 +1,103 @@
+package grpcLimiter
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/rate-limiter/grpcLimiter/adminpb"
+	"github.com/go-estar/rate-limiter/grpcLimiter/limiterpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestLimiterServer(t *testing.T) {
+	s := NewLimiterServer(NoAuth, testLimiter(t, "api"))
+	ctx := context.Background()
+	resp, err := s.Check(ctx, &limiterpb.CheckRequest{Limiter: "api", Id: "a"})
+	if err != nil || !resp.Allowed || resp.Used != 1 || resp.Limit != 2 || resp.Window.AsDuration() != time.Minute {
+		t.Fatalf("first check %v, %v", resp, err)
+	}
+	resp, err = s.Check(ctx, &limiterpb.CheckRequest{Limiter: "api", Id: "a"})
+	if err != nil || resp.Allowed || resp.RetryAfter.AsDuration() != time.Minute {
+		t.Fatalf("second check %v, %v", resp, err)
+	}
+	for code, req := range map[codes.Code]*limiterpb.CheckRequest{
+		codes.NotFound:        {Limiter: "other", Id: "a"},
+		codes.InvalidArgument: {Limiter: "api", Id: "b", N: -1},
+	} {
+		if _, err := s.Check(ctx, req); status.Code(err) != code {
+			t.Errorf("%v: %v, want %v", req, err, code)
+		}
+	}
+	if _, err := s.Check(ctx, &limiterpb.CheckRequest{Limiter: "api", Id: ""}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("empty id: %v", err)
+	}
+}
+
+func TestLimiterServerAuth(t *testing.T) {
+	s := NewLimiterServer(BearerToken("secret", "ops"), testLimiter(t, "api"))
+	req := &limiterpb.CheckRequest{Limiter: "api", Id: "a"}
+	if _, err := s.Check(context.Background(), req); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("call without a token: %v", err)
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer secret"))
+	if _, err := s.Check(ctx, req); err != nil {
+		t.Fatalf("call with the token: %v", err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	rl := testLimiter(t, "api")
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	limiterpb.RegisterRateLimiterServer(srv, NewLimiterServer(NoAuth, rl))
+	adminpb.RegisterRateLimiterAdminServer(srv, NewAdminServer(NoAuth, false, rl))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet", grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	c := NewClient(conn)
+	ctx := context.Background()
+
+	if used, err := c.Check(ctx, "api", "a"); err != nil || used != 1 {
+		t.Fatalf("Check = %d, %v", used, err)
+	}
+	res, err := c.Allow(ctx, "api", "a")
+	if !rateLimiter.IsBlocked(err) || res.Allowed || res.RetryAfter != time.Minute {
+		t.Fatalf("Allow over the limit: %+v, %v", res, err)
+	}
+	if _, err := c.Check(ctx, "other", "a"); status.Code(err) != codes.NotFound {
+		t.Fatalf("unknown limiter: %v", err)
+	}
+
+	if err := c.AddBlockList(ctx, "api", "b", "abuse", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := c.GetBlockListEntries(ctx, "api")
+	if err != nil || len(entries) != 1 || entries[0].Id != "b" || entries[0].Reason != "abuse" || entries[0].ExpiresAt.IsZero() {
+		t.Fatalf("block list entries %+v, %v", entries, err)
+	}
+	if ins, err := c.Inspect(ctx, "api", "b"); err != nil || !ins.BlockListed {
+		t.Fatalf("Inspect %+v, %v", ins, err)
+	}
+	if err := c.RemoveBlockList(ctx, "api", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.CheckReset(ctx, "api", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := c.Allow(ctx, "api", "a"); err != nil || res.Used != 1 {
+		t.Fatalf("Allow after CheckReset: %+v, %v", res, err)
+	}
+}
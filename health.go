@@ -0,0 +1,36 @@
+package rateLimiter
+
+import (
+	"context"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// scripts are the Lua scripts the limiter runs; Healthy makes sure they are cached.
+var scripts = []*goredis.Script{counterScript, refundScript, purgeExpiredScript, subnetScript}
+
+// Healthy verifies that Redis answers and that the Lua scripts are loaded, loading
+// those that are missing, e.g. after a SCRIPT FLUSH or a failover. Services can use
+// it in readiness probes to know when checks would fail.
+func (rl *RateLimiter) Healthy(ctx context.Context) error {
+	if err := rl.Redis.Ping(ctx).Err(); err != nil {
+		return err
+	}
+	hashes := make([]string, len(scripts))
+	for i, s := range scripts {
+		hashes[i] = s.Hash()
+	}
+	exists, err := rl.Redis.ScriptExists(ctx, hashes...).Result()
+	if err != nil {
+		return err
+	}
+	for i, ok := range exists {
+		if ok {
+			continue
+		}
+		if err := scripts[i].Load(ctx, rl.Redis).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHealthy(t *testing.T) {
+	mr, rl := testLimiter(t, "health", WithDuration(time.Minute), WithBlockTimes(2))
+	ctx := context.Background()
+	if err := rl.Redis.ScriptFlush(ctx).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.Healthy(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range scripts {
+		if ok, _ := rl.Redis.ScriptExists(ctx, s.Hash()).Result(); len(ok) != 1 || !ok[0] {
+			t.Fatalf("script %s not loaded", s.Hash())
+		}
+	}
+
+	mr.Close()
+	if err := rl.Healthy(ctx); err == nil {
+		t.Fatal("Healthy with Redis down")
+	}
+}
@@ -0,0 +1,27 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-estar/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func testRedis(t testing.TB) (*miniredis.Miniredis, *redis.Redis) {
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return mr, &redis.Redis{Client: client}
+}
+
+func testLimiter(t testing.TB, name string, opts ...Option) (*miniredis.Miniredis, *RateLimiter) {
+	mr, r := testRedis(t)
+	rl, err := NewLimiter(name, append([]Option{WithRedis(r)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rl.Close(context.Background()) })
+	return mr, rl
+}
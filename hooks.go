@@ -0,0 +1,40 @@
+package rateLimiter
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ListAdd    = "add"
+	ListRemove = "remove"
+	ListClear  = "clear"  //the list was reset to Config.WhiteList/BlockList
+	ListExpire = "expire" //temporary entries expired
+)
+
+type ListChange struct {
+	Op         string
+	Ids        []string
+	TTL        time.Duration //0 for permanent entries
+	Replicated bool          //the change was made by another instance
+	Operator   string        //see WithOperator, AutoBlockOperator for automatic blocks
+}
+
+func (rl *RateLimiter) whiteListChanged(ctx context.Context, op string, ids []string, ttl time.Duration) {
+	rl.listChanged(ctx, listWhite, rl.OnWhiteListChange, op, ids, ttl)
+}
+
+func (rl *RateLimiter) blockListChanged(ctx context.Context, op string, ids []string, ttl time.Duration) {
+	rl.listChanged(ctx, listBlock, rl.OnBlockListChange, op, ids, ttl)
+}
+
+func (rl *RateLimiter) listChanged(ctx context.Context, list string, fn func(ListChange), op string, ids []string, ttl time.Duration) {
+	if (fn == nil && rl.OnEvent == nil) || (len(ids) == 0 && op != ListClear) {
+		return
+	}
+	change := ListChange{Op: op, Ids: ids, TTL: ttl, Replicated: isReplicated(ctx), Operator: operatorFrom(ctx)}
+	if fn != nil {
+		fn(change)
+	}
+	rl.emitListChange(list, change)
+}
@@ -0,0 +1,74 @@
+package rateLimiter
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+type changeRecorder struct {
+	mu      sync.Mutex
+	changes []ListChange
+}
+
+func (r *changeRecorder) record(c ListChange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, c)
+}
+
+func (r *changeRecorder) take() []ListChange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changes := r.changes
+	r.changes = nil
+	return changes
+}
+
+func TestListChangeHooks(t *testing.T) {
+	var white, block changeRecorder
+	mr, rl := testLimiter(t, "hooks", WithDuration(time.Minute), WithBlockTimes(5), WithListChangeHooks(white.record, block.record))
+	ctx := context.Background()
+
+	rl.AddBlockList("a", false)
+	rl.AddBlockList("a", false)
+	rl.AddBlockListBatchCtx(ctx, []string{"a", "b"}, false)
+	rl.RemoveBlockList("a", false)
+	rl.AddWhiteListTTL("w", time.Minute, false)
+	want := []ListChange{
+		{Op: ListAdd, Ids: []string{"a"}},
+		{Op: ListAdd, Ids: []string{"b"}},
+		{Op: ListRemove, Ids: []string{"a"}},
+	}
+	if got := block.take(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("block list changes %+v, want %+v", got, want)
+	}
+	if got, want := white.take(), []ListChange{{Op: ListAdd, Ids: []string{"w"}, TTL: time.Minute}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("white list changes %+v, want %+v", got, want)
+	}
+
+	if err := rl.Sub(`abs-["c"]`); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := block.take(), []ListChange{{Op: ListAdd, Ids: []string{"c"}, Replicated: true}}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("replicated changes %+v, want %+v", got, want)
+	}
+
+	mr.SRem("hooks-block", "b")
+	mr.SAdd("hooks-block", "d")
+	if err := rl.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	// c was only applied locally, so resync drops it as well
+	got := block.take()
+	if len(got) == 2 {
+		sort.Strings(got[1].Ids)
+	}
+	if len(got) != 2 || got[0].Op != ListAdd || !reflect.DeepEqual(got[0].Ids, []string{"d"}) ||
+		got[1].Op != ListRemove || !reflect.DeepEqual(got[1].Ids, []string{"b", "c"}) || !got[1].Replicated {
+		t.Fatalf("resync changes %+v", got)
+	}
+}
@@ -0,0 +1,395 @@
+package httpLimiter
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+// Authenticator authorizes an admin request and returns the operator recorded
+// in the audit log; a non-nil error responds 401.
+type Authenticator func(r *http.Request) (operator string, err error)
+
+// BearerToken accepts requests carrying "Authorization: Bearer <token>" and
+// records operator for them. The token is compared in constant time.
+func BearerToken(token, operator string) Authenticator {
+	want := []byte("Bearer " + token)
+	return func(r *http.Request) (string, error) {
+		if token == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			return "", errors.New("invalid token")
+		}
+		return operator, nil
+	}
+}
+
+type adminConfig struct {
+	auth Authenticator
+	pub  bool
+}
+
+type AdminOption func(*adminConfig)
+
+// WithAuthenticator is required unless the handler is mounted behind another
+// authentication layer, in which case pass NoAuth.
+func WithAuthenticator(auth Authenticator) AdminOption {
+	return func(c *adminConfig) {
+		c.auth = auth
+	}
+}
+
+// NoAuth lets every request through without an operator.
+func NoAuth(r *http.Request) (string, error) {
+	return "", nil
+}
+
+// WithAdminPub sets whether changes are published to the other instances; the
+// default is true.
+func WithAdminPub(pub bool) AdminOption {
+	return func(c *adminConfig) {
+		c.pub = pub
+	}
+}
+
+// maxAdminBody caps the size of admin request bodies.
+const maxAdminBody = 1 << 20
+
+type entryRequest struct {
+	Id     string `json:"id"`
+	Reason string `json:"reason"`
+	TTL    string `json:"ttl"` //time.ParseDuration format, empty for permanent entries
+}
+
+type configRequest struct {
+	Duration      string `json:"duration"`
+	BlockTimes    int    `json:"blockTimes"`
+	BlockDuration string `json:"blockDuration"` //empty blocks permanently
+}
+
+type overrideRequest struct {
+	BlockTimes int    `json:"blockTimes"`
+	Duration   string `json:"duration"`
+}
+
+// AdminHandler serves a REST API over limiters, to be mounted with
+// http.StripPrefix:
+//
+//	GET    /                              limiter names
+//	GET    /{limiter}/config
+//	PUT    /{limiter}/config              update {"duration","blockTimes","blockDuration"}
+//	DELETE /{limiter}/config              back to the Config the limiter was created with
+//	GET    /{limiter}/{white|block}list   entries
+//	POST   /{limiter}/{white|block}list   add {"id","reason","ttl"}
+//	DELETE /{limiter}/{white|block}list/{id}
+//	GET    /{limiter}/mode
+//	PUT    /{limiter}/mode                switch {"mode":"normal|allowAll|blockAll"}
+//	GET    /{limiter}/ids/{id}            inspection
+//	DELETE /{limiter}/ids/{id}/counter    reset the counter
+//	GET    /{limiter}/ids/{id}/override
+//	PUT    /{limiter}/ids/{id}/override   set {"blockTimes","duration"}
+//	DELETE /{limiter}/ids/{id}/override
+//
+// Escape a slash in {id} as %2F, as in /{limiter}/blocklist/10.0.0.0%2F8.
+// Adding an id that is already listed responds 409, and bodies over 1MB 413.
+func AdminHandler(limiters []*rateLimiter.RateLimiter, opts ...AdminOption) http.Handler {
+	return adminHandler(func() []*rateLimiter.RateLimiter { return limiters }, opts)
+}
+
+// ManagerAdminHandler is AdminHandler for every limiter of m at request time.
+func ManagerAdminHandler(m *rateLimiter.Manager, opts ...AdminOption) http.Handler {
+	return adminHandler(m.List, opts)
+}
+
+func adminHandler(limiters func() []*rateLimiter.RateLimiter, opts []AdminOption) http.Handler {
+	c := &adminConfig{pub: true}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.auth == nil {
+		panic("Authenticator必须设置")
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operator, err := c.auth(r)
+		if err != nil {
+			writeAdminError(w, http.StatusUnauthorized, err)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, maxAdminBody)
+		ctx := r.Context()
+		if operator != "" {
+			ctx = rateLimiter.WithOperator(ctx, operator)
+		}
+		r = r.WithContext(ctx)
+
+		parts, err := pathSegments(r.URL)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+		if parts[0] == "" {
+			if r.Method != http.MethodGet {
+				writeAdminError(w, http.StatusMethodNotAllowed, nil)
+				return
+			}
+			names := []string{}
+			for _, rl := range limiters() {
+				names = append(names, rl.Name)
+			}
+			writeAdminJSON(w, http.StatusOK, map[string]interface{}{"limiters": names})
+			return
+		}
+		var rl *rateLimiter.RateLimiter
+		for _, l := range limiters() {
+			if l.Name == parts[0] {
+				rl = l
+				break
+			}
+		}
+		if rl == nil || len(parts) < 2 {
+			writeAdminError(w, http.StatusNotFound, nil)
+			return
+		}
+		switch {
+		case parts[1] == "whitelist" || parts[1] == "blocklist":
+			c.serveList(w, r, rl, operator, parts[1] == "whitelist", parts[2:])
+		case parts[1] == "config" && len(parts) == 2:
+			c.serveConfig(w, r, rl)
+		case parts[1] == "mode" && len(parts) == 2:
+			c.serveMode(w, r, rl)
+		case parts[1] == "ids" && len(parts) >= 3:
+			c.serveId(w, r, rl, parts[2], parts[3:])
+		default:
+			writeAdminError(w, http.StatusNotFound, nil)
+		}
+	})
+}
+
+// pathSegments splits the escaped path, so that ids containing a slash, such as
+// CIDR entries sent as 10.0.0.0%2F8, stay one segment.
+func pathSegments(u *url.URL) ([]string, error) {
+	parts := strings.Split(strings.Trim(u.EscapedPath(), "/"), "/")
+	for i, part := range parts {
+		var err error
+		if parts[i], err = url.PathUnescape(part); err != nil {
+			return nil, err
+		}
+	}
+	return parts, nil
+}
+
+func (c *adminConfig) serveList(w http.ResponseWriter, r *http.Request, rl *rateLimiter.RateLimiter, operator string, white bool, rest []string) {
+	ctx := r.Context()
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		var entries []rateLimiter.ListEntry
+		var err error
+		if white {
+			entries, err = rl.GetWhiteListEntries(ctx, nil)
+		} else {
+			entries, err = rl.GetBlockListEntries(ctx, nil)
+		}
+		if err != nil {
+			writeAdminError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if entries == nil {
+			entries = []rateLimiter.ListEntry{}
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]interface{}{"entries": entries})
+	case len(rest) == 0 && r.Method == http.MethodPost:
+		var req entryRequest
+		if !decodeAdminBody(w, r, &req) {
+			return
+		}
+		var ttl time.Duration
+		if req.TTL != "" {
+			var err error
+			if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl < 0 {
+				writeAdminError(w, http.StatusBadRequest, errors.New("invalid ttl"))
+				return
+			}
+		}
+		entry := rateLimiter.ListEntry{Id: req.Id, Reason: req.Reason, Operator: operator}
+		var err error
+		if white {
+			err = rl.AddWhiteListEntryCtx(ctx, entry, ttl, c.pub)
+		} else {
+			err = rl.AddBlockListEntryCtx(ctx, entry, ttl, c.pub)
+		}
+		writeAdminResult(w, http.StatusCreated, err)
+	case len(rest) == 1 && r.Method == http.MethodDelete:
+		var err error
+		if white {
+			err = rl.RemoveWhiteListCtx(ctx, rest[0], c.pub)
+		} else {
+			err = rl.RemoveBlockListCtx(ctx, rest[0], c.pub)
+		}
+		writeAdminResult(w, http.StatusNoContent, err)
+	case len(rest) <= 1:
+		writeAdminError(w, http.StatusMethodNotAllowed, nil)
+	default:
+		writeAdminError(w, http.StatusNotFound, nil)
+	}
+}
+
+func (c *adminConfig) serveConfig(w http.ResponseWriter, r *http.Request, rl *rateLimiter.RateLimiter) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req configRequest
+		if !decodeAdminBody(w, r, &req) {
+			return
+		}
+		l := rateLimiter.Limits{BlockTimes: req.BlockTimes}
+		var err error
+		if l.Duration, err = time.ParseDuration(req.Duration); err != nil {
+			writeAdminError(w, http.StatusBadRequest, errors.New("invalid duration"))
+			return
+		}
+		if req.BlockDuration != "" {
+			if l.BlockDuration, err = time.ParseDuration(req.BlockDuration); err != nil {
+				writeAdminError(w, http.StatusBadRequest, errors.New("invalid blockDuration"))
+				return
+			}
+		}
+		if l.Duration <= 0 || l.BlockTimes < 0 || l.BlockDuration < 0 {
+			writeAdminError(w, http.StatusBadRequest, errors.New("duration must be positive and the others not negative"))
+			return
+		}
+		if err := rl.UpdateConfigCtx(ctx, l, c.pub); err != nil {
+			writeAdminResult(w, 0, err)
+			return
+		}
+	case http.MethodDelete:
+		if err := rl.ResetConfigCtx(ctx, c.pub); err != nil {
+			writeAdminResult(w, 0, err)
+			return
+		}
+	default:
+		writeAdminError(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, limiterConfig(rl))
+}
+
+func (c *adminConfig) serveMode(w http.ResponseWriter, r *http.Request, rl *rateLimiter.RateLimiter) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req struct {
+			Mode string `json:"mode"`
+		}
+		if !decodeAdminBody(w, r, &req) {
+			return
+		}
+		mode, err := rateLimiter.ParseMode(req.Mode)
+		if err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := rl.SetModeCtx(r.Context(), mode, c.pub); err != nil {
+			writeAdminResult(w, 0, err)
+			return
+		}
+	default:
+		writeAdminError(w, http.StatusMethodNotAllowed, nil)
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, map[string]interface{}{"mode": rl.Mode().String()})
+}
+
+func (c *adminConfig) serveId(w http.ResponseWriter, r *http.Request, rl *rateLimiter.RateLimiter, id string, rest []string) {
+	ctx := r.Context()
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		ins, err := rl.InspectCtx(ctx, id)
+		if err != nil {
+			writeAdminResult(w, 0, err)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, ins)
+	case len(rest) == 1 && rest[0] == "counter" && r.Method == http.MethodDelete:
+		writeAdminResult(w, http.StatusNoContent, rl.CheckResetCtx(ctx, id))
+	case len(rest) == 1 && rest[0] == "override" && r.Method == http.MethodGet:
+		o, err := rl.GetOverride(id)
+		if err != nil {
+			writeAdminResult(w, 0, err)
+			return
+		}
+		if o == nil {
+			writeAdminError(w, http.StatusNotFound, nil)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, overrideRequest{BlockTimes: o.BlockTimes, Duration: o.Duration.String()})
+	case len(rest) == 1 && rest[0] == "override" && r.Method == http.MethodPut:
+		var req overrideRequest
+		if !decodeAdminBody(w, r, &req) {
+			return
+		}
+		o := rateLimiter.Override{BlockTimes: req.BlockTimes}
+		if req.Duration != "" {
+			d, err := time.ParseDuration(req.Duration)
+			if err != nil {
+				writeAdminError(w, http.StatusBadRequest, errors.New("invalid duration"))
+				return
+			}
+			o.Duration = d
+		}
+		writeAdminResult(w, http.StatusNoContent, rl.SetOverrideCtx(ctx, id, o, c.pub))
+	case len(rest) == 1 && rest[0] == "override" && r.Method == http.MethodDelete:
+		writeAdminResult(w, http.StatusNoContent, rl.RemoveOverrideCtx(ctx, id, c.pub))
+	case len(rest) == 0 || len(rest) == 1 && (rest[0] == "counter" || rest[0] == "override"):
+		writeAdminError(w, http.StatusMethodNotAllowed, nil)
+	default:
+		writeAdminError(w, http.StatusNotFound, nil)
+	}
+}
+
+// decodeAdminBody decodes the JSON body of r into v. It responds 413 to a body
+// over maxAdminBody and 400 to one that doesn't decode, and returns false then.
+func decodeAdminBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	var tooLarge *http.MaxBytesError
+	switch {
+	case err == nil:
+		return true
+	case errors.As(err, &tooLarge):
+		writeAdminError(w, http.StatusRequestEntityTooLarge, err)
+	default:
+		writeAdminError(w, http.StatusBadRequest, err)
+	}
+	return false
+}
+
+// writeAdminResult responds status, 409 to an entry that is already listed, or
+// the ErrorStatus of err.
+func writeAdminResult(w http.ResponseWriter, status int, err error) {
+	switch {
+	case err == nil:
+		w.WriteHeader(status)
+	case errors.Is(err, rateLimiter.ErrorWhiteListExists), errors.Is(err, rateLimiter.ErrorBlockListExists):
+		writeAdminError(w, http.StatusConflict, err)
+	default:
+		writeAdminError(w, ErrorStatus(err), err)
+	}
+}
+
+func writeAdminError(w http.ResponseWriter, status int, err error) {
+	message := http.StatusText(status)
+	if err != nil {
+		message = err.Error()
+	}
+	writeAdminJSON(w, status, map[string]interface{}{"code": status, "message": message})
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,200 @@
+package httpLimiter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+// serveAdmin sends a request to h and decodes the JSON response into out, if any.
+func serveAdmin(t *testing.T, h http.Handler, method, path, body string, out interface{}) int {
+	t.Helper()
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if out != nil && w.Code < 300 {
+		if err := json.NewDecoder(w.Body).Decode(out); err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+	}
+	return w.Code
+}
+
+func TestAdminHandler(t *testing.T) {
+	rl := testLimiter(t, "api")
+	h := AdminHandler([]*rateLimiter.RateLimiter{rl}, WithAuthenticator(BearerToken("secret", "ops")))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("unauthenticated status %d", w.Code)
+	}
+	var names struct{ Limiters []string }
+	if code := serveAdmin(t, h, "GET", "/", "", &names); code != http.StatusOK || len(names.Limiters) != 1 || names.Limiters[0] != "api" {
+		t.Fatalf("GET / %d %v", code, names)
+	}
+
+	if code := serveAdmin(t, h, "POST", "/api/blocklist", `{"id":"a","reason":"abuse","ttl":"1h"}`, nil); code != http.StatusCreated {
+		t.Fatalf("POST blocklist status %d", code)
+	}
+	if code := serveAdmin(t, h, "POST", "/api/blocklist", `{"id":"b","ttl":"soon"}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("invalid ttl status %d", code)
+	}
+	var list struct{ Entries []rateLimiter.ListEntry }
+	if code := serveAdmin(t, h, "GET", "/api/blocklist", "", &list); code != http.StatusOK || len(list.Entries) != 1 {
+		t.Fatalf("GET blocklist %d %+v", code, list)
+	}
+	if e := list.Entries[0]; e.Id != "a" || e.Reason != "abuse" || e.Operator != "ops" || e.ExpiresAt.IsZero() {
+		t.Fatalf("entry %+v", e)
+	}
+	var ins rateLimiter.Inspection
+	if code := serveAdmin(t, h, "GET", "/api/ids/a", "", &ins); code != http.StatusOK || !ins.BlockListed {
+		t.Fatalf("inspect %d %+v", code, ins)
+	}
+	if code := serveAdmin(t, h, "DELETE", "/api/blocklist/a", "", nil); code != http.StatusNoContent {
+		t.Fatalf("DELETE blocklist status %d", code)
+	}
+	if ids, _ := rl.GetBlockList(nil); len(ids) != 0 {
+		t.Fatalf("block list %v after DELETE", ids)
+	}
+
+	rl.Allow("c")
+	if code := serveAdmin(t, h, "DELETE", "/api/ids/c/counter", "", nil); code != http.StatusNoContent {
+		t.Fatalf("reset status %d", code)
+	}
+	if ins, _ := rl.Inspect("c"); ins.Times != 0 {
+		t.Fatalf("counter %d after reset", ins.Times)
+	}
+
+	if code := serveAdmin(t, h, "GET", "/api/ids/c/override", "", nil); code != http.StatusNotFound {
+		t.Fatalf("missing override status %d", code)
+	}
+	if code := serveAdmin(t, h, "PUT", "/api/ids/c/override", `{"blockTimes":10,"duration":"1h"}`, nil); code != http.StatusNoContent {
+		t.Fatalf("PUT override status %d", code)
+	}
+	var o overrideRequest
+	if code := serveAdmin(t, h, "GET", "/api/ids/c/override", "", &o); code != http.StatusOK || o.BlockTimes != 10 || o.Duration != "1h0m0s" {
+		t.Fatalf("GET override %d %+v", code, o)
+	}
+	if code := serveAdmin(t, h, "DELETE", "/api/ids/c/override", "", nil); code != http.StatusNoContent {
+		t.Fatalf("DELETE override status %d", code)
+	}
+
+	for path, want := range map[string]int{"/other/blocklist": http.StatusNotFound, "/api/unknown": http.StatusNotFound} {
+		if code := serveAdmin(t, h, "GET", path, "", nil); code != want {
+			t.Errorf("GET %s status %d, want %d", path, code, want)
+		}
+	}
+	if code := serveAdmin(t, h, "PATCH", "/api/whitelist", "", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("PATCH status %d", code)
+	}
+}
+
+func TestAdminConfig(t *testing.T) {
+	rl := testLimiter(t, "api")
+	h := AdminHandler([]*rateLimiter.RateLimiter{rl}, WithAuthenticator(NoAuth))
+
+	var conf configJSON
+	if code := serveAdmin(t, h, "PUT", "/api/config", `{"duration":"1h","blockTimes":10}`, &conf); code != http.StatusOK {
+		t.Fatalf("PUT config status %d", code)
+	}
+	if conf.Duration != "1h0m0s" || conf.BlockTimes != 10 || conf.BlockDuration != "0s" {
+		t.Fatalf("config %+v", conf)
+	}
+	if l := rl.Limits(); l.Duration != time.Hour || l.BlockTimes != 10 {
+		t.Fatalf("limits %+v", l)
+	}
+	if code := serveAdmin(t, h, "PUT", "/api/config", `{"duration":"0s","blockTimes":10}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("zero duration status %d", code)
+	}
+	if code := serveAdmin(t, h, "DELETE", "/api/config", "", &conf); code != http.StatusOK || conf.BlockTimes != 3 {
+		t.Fatalf("DELETE config %d %+v", code, conf)
+	}
+}
+
+func TestAdminMode(t *testing.T) {
+	rl := testLimiter(t, "api")
+	h := AdminHandler([]*rateLimiter.RateLimiter{rl}, WithAuthenticator(NoAuth))
+	var res struct{ Mode string }
+	if code := serveAdmin(t, h, "PUT", "/api/mode", `{"mode":"blockAll"}`, &res); code != http.StatusOK || res.Mode != "blockAll" {
+		t.Fatalf("PUT mode %d %+v", code, res)
+	}
+	if rl.Mode() != rateLimiter.ModeBlockAll {
+		t.Fatalf("mode %v", rl.Mode())
+	}
+	if code := serveAdmin(t, h, "PUT", "/api/mode", `{"mode":"panic"}`, nil); code != http.StatusBadRequest {
+		t.Fatalf("unknown mode status %d", code)
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	auth := BearerToken("secret", "ops")
+	for header, ok := range map[string]bool{
+		"Bearer secret":  true,
+		"Bearer secret2": false,
+		"Bearer secre":   false,
+		"secret":         false,
+		"":               false,
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+		op, err := auth(r)
+		if (err == nil) != ok || ok && op != "ops" {
+			t.Errorf("%q: operator %q, err %v", header, op, err)
+		}
+	}
+	if _, err := BearerToken("", "ops")(httptest.NewRequest("GET", "/", nil)); err == nil {
+		t.Error("empty token accepted")
+	}
+}
+
+func TestAdminCIDREntry(t *testing.T) {
+	rl := testLimiter(t, "admin", rateLimiter.WithBlockTimes(10))
+	if err := rl.AddBlockList("10.0.0.0/8", false); err != nil {
+		t.Fatal(err)
+	}
+	h := http.StripPrefix("/admin", AdminHandler([]*rateLimiter.RateLimiter{rl}, WithAuthenticator(NoAuth)))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("DELETE", "/admin/admin/blocklist/10.0.0.0%2F8", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("DELETE status %d: %s", w.Code, w.Body)
+	}
+	if ids, _ := rl.GetBlockList(nil); len(ids) != 0 {
+		t.Fatalf("block list %v after DELETE", ids)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/admin/admin/ids/10.0.0.0%2F8", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("inspect status %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestAdminConflictAndBodyLimit(t *testing.T) {
+	rl := testLimiter(t, "api")
+	h := AdminHandler([]*rateLimiter.RateLimiter{rl}, WithAuthenticator(BearerToken("secret", "ops")))
+	for _, list := range []string{"whitelist", "blocklist"} {
+		if code := serveAdmin(t, h, "POST", "/api/"+list, `{"id":"a"}`, nil); code != http.StatusCreated {
+			t.Fatalf("POST %s status %d", list, code)
+		}
+		if code := serveAdmin(t, h, "POST", "/api/"+list, `{"id":"a"}`, nil); code != http.StatusConflict {
+			t.Fatalf("POST %s again status %d, want 409", list, code)
+		}
+	}
+	body := `{"id":"b","reason":"` + strings.Repeat("x", maxAdminBody) + `"}`
+	if code := serveAdmin(t, h, "POST", "/api/blocklist", body, nil); code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized body status %d, want 413", code)
+	}
+	if ids, _ := rl.GetBlockList(nil); len(ids) != 1 {
+		t.Fatalf("block list %v", ids)
+	}
+}
@@ -0,0 +1,54 @@
+package httpLimiter
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+// Headers returns the rate limit response headers of res: the IETF draft
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset (seconds until reset) and
+// RateLimit-Policy, the legacy X-RateLimit-* ones (reset as a Unix time), and
+// Retry-After when the request was blocked for a limited time.
+func Headers(res *rateLimiter.CheckResult) map[string]string {
+	h := make(map[string]string, 8)
+	if res == nil {
+		return h
+	}
+	if res.Limit > 0 {
+		limit, remaining := strconv.Itoa(res.Limit), strconv.Itoa(res.Remaining)
+		h["RateLimit-Limit"] = limit
+		h["RateLimit-Remaining"] = remaining
+		h["X-RateLimit-Limit"] = limit
+		h["X-RateLimit-Remaining"] = remaining
+		if res.Window > 0 {
+			h["RateLimit-Policy"] = limit + ";w=" + strconv.FormatInt(int64(res.Window/time.Second), 10)
+		}
+	}
+	if !res.ResetAt.IsZero() {
+		h["RateLimit-Reset"] = RetryAfterSeconds(time.Until(res.ResetAt))
+		h["X-RateLimit-Reset"] = strconv.FormatInt(res.ResetAt.Unix(), 10)
+	}
+	if !res.Allowed && res.RetryAfter > 0 {
+		h["Retry-After"] = RetryAfterSeconds(res.RetryAfter)
+	}
+	return h
+}
+
+// SetHeaders writes Headers(res) to h.
+func SetHeaders(h http.Header, res *rateLimiter.CheckResult) {
+	for k, v := range Headers(res) {
+		h.Set(k, v)
+	}
+}
+
+// RetryAfterSeconds formats d as whole seconds, rounding up.
+func RetryAfterSeconds(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	s := int64((d + time.Second - 1) / time.Second)
+	return strconv.FormatInt(s, 10)
+}
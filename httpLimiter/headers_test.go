@@ -0,0 +1,37 @@
+package httpLimiter
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+func TestHeaders(t *testing.T) {
+	resetAt := time.Now().Add(30 * time.Second)
+	got := Headers(&rateLimiter.CheckResult{Window: time.Minute, Limit: 10, Remaining: 0, ResetAt: resetAt, RetryAfter: 30 * time.Second})
+	want := map[string]string{
+		"RateLimit-Limit":       "10",
+		"RateLimit-Remaining":   "0",
+		"RateLimit-Reset":       "30",
+		"RateLimit-Policy":      "10;w=60",
+		"X-RateLimit-Limit":     "10",
+		"X-RateLimit-Remaining": "0",
+		"X-RateLimit-Reset":     strconv.FormatInt(resetAt.Unix(), 10),
+		"Retry-After":           "30",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Headers() = %v, want %v", got, want)
+	}
+	if got := Headers(&rateLimiter.CheckResult{Allowed: true, Remaining: -1}); len(got) != 0 {
+		t.Fatalf("headers for an unlimited result: %v", got)
+	}
+	if got := Headers(nil); len(got) != 0 {
+		t.Fatalf("headers for nil: %v", got)
+	}
+	if got := RetryAfterSeconds(-time.Second); got != "0" {
+		t.Fatalf("RetryAfterSeconds(-1s) = %q, want 0", got)
+	}
+}
@@ -0,0 +1,158 @@
+package httpLimiter
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrorNoKey = errors.New("no rate limit key")
+	// ErrorInvalidToken wraps the reason a JWT key function rejected the bearer
+	// token, e.g. jwt.ErrTokenExpired.
+	ErrorInvalidToken = errors.New("invalid token")
+)
+
+// KeyExtractor extracts the id to rate limit from a request. It is shared by the
+// net/http, Gin, Echo and Fiber middlewares.
+type KeyExtractor interface {
+	Extract(r *http.Request) (string, error)
+}
+
+// KeyFunc adapts a function to KeyExtractor.
+type KeyFunc func(r *http.Request) (string, error)
+
+func (fn KeyFunc) Extract(r *http.Request) (string, error) {
+	return fn(r)
+}
+
+// RemoteIP uses the IP of the direct peer. Behind a proxy use RealIP instead.
+func RemoteIP(r *http.Request) (string, error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr, nil
+	}
+	return host, nil
+}
+
+// ForwardedIP uses the first address of X-Forwarded-For, falling back to RemoteIP.
+// Only use it behind a proxy that overwrites the header.
+func ForwardedIP(r *http.Request) (string, error) {
+	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+		ip, _, _ := strings.Cut(xff, ",")
+		if ip = strings.TrimSpace(ip); ip != "" {
+			return ip, nil
+		}
+	}
+	return RemoteIP(r)
+}
+
+// RealIP returns the client IP, honouring X-Forwarded-For and X-Real-IP only when
+// they were set by one of trustedProxies (IPs or CIDRs). X-Forwarded-For is walked
+// from the right, skipping trusted hops, so clients cannot spoof it. It panics on
+// an invalid proxy address.
+func RealIP(trustedProxies ...string) KeyFunc {
+	nets := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, p := range trustedProxies {
+		if !strings.Contains(p, "/") {
+			if strings.Contains(p, ":") {
+				p += "/128"
+			} else {
+				p += "/32"
+			}
+		}
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			panic("trustedProxies包含非法地址: " + err.Error())
+		}
+		nets = append(nets, n)
+	}
+	trusted := func(s string) bool {
+		ip := net.ParseIP(strings.TrimSpace(s))
+		if ip == nil {
+			return false
+		}
+		for _, n := range nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(r *http.Request) (string, error) {
+		remote, _ := RemoteIP(r)
+		if !trusted(remote) {
+			return remote, nil
+		}
+		if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+			hops := strings.Split(strings.Join(xff, ","), ",")
+			for i := len(hops) - 1; i >= 0; i-- {
+				hop := strings.TrimSpace(hops[i])
+				if hop != "" && !trusted(hop) {
+					return hop, nil
+				}
+			}
+		}
+		if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+			return ip, nil
+		}
+		return remote, nil
+	}
+}
+
+// Header uses the value of the request header name.
+func Header(name string) KeyFunc {
+	return func(r *http.Request) (string, error) {
+		v := r.Header.Get(name)
+		if v == "" {
+			return "", ErrorNoKey
+		}
+		return v, nil
+	}
+}
+
+// JWTClaim uses a string claim of the bearer token in the Authorization header. The
+// token is verified with keyFunc, so clients cannot pick someone else's key.
+func JWTClaim(claim string, keyFunc jwt.Keyfunc, opts ...jwt.ParserOption) KeyFunc {
+	parser := jwt.NewParser(opts...)
+	return func(r *http.Request) (string, error) {
+		auth := r.Header.Get("Authorization")
+		if len(auth) < 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+			return "", ErrorNoKey
+		}
+		claims := jwt.MapClaims{}
+		if _, err := parser.ParseWithClaims(auth[7:], claims, keyFunc); err != nil {
+			return "", fmt.Errorf("%w: %w", ErrorInvalidToken, err)
+		}
+		v, ok := claims[claim].(string)
+		if !ok || v == "" {
+			return "", ErrorNoKey
+		}
+		return v, nil
+	}
+}
+
+// JWTSubject uses the sub claim of the bearer token.
+func JWTSubject(keyFunc jwt.Keyfunc, opts ...jwt.ParserOption) KeyFunc {
+	return JWTClaim("sub", keyFunc, opts...)
+}
+
+// Composite joins the ids of all extractors with "|", e.g. user and IP. It fails if
+// any of them fails.
+func Composite(extractors ...KeyExtractor) KeyFunc {
+	return func(r *http.Request) (string, error) {
+		parts := make([]string, 0, len(extractors))
+		for _, e := range extractors {
+			v, err := e.Extract(r)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, v)
+		}
+		return strings.Join(parts, "|"), nil
+	}
+}
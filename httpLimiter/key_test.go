@@ -0,0 +1,102 @@
+package httpLimiter
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestRealIP(t *testing.T) {
+	key := RealIP("10.0.0.0/8", "192.168.1.1")
+	for _, tc := range []struct {
+		remote, xff, realIP, want string
+	}{
+		{remote: "1.2.3.4", xff: "5.6.7.8", want: "1.2.3.4"},
+		{remote: "10.0.0.1", xff: "5.6.7.8", want: "5.6.7.8"},
+		{remote: "10.0.0.1", xff: "9.9.9.9, 5.6.7.8, 10.0.0.2", want: "5.6.7.8"},
+		{remote: "192.168.1.1", realIP: "5.6.7.8", want: "5.6.7.8"},
+		{remote: "10.0.0.1", want: "10.0.0.1"},
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tc.remote + ":1234"
+		if tc.xff != "" {
+			r.Header.Set("X-Forwarded-For", tc.xff)
+		}
+		if tc.realIP != "" {
+			r.Header.Set("X-Real-IP", tc.realIP)
+		}
+		if got, _ := key(r); got != tc.want {
+			t.Errorf("%+v: got %q", tc, got)
+		}
+	}
+}
+
+func TestJWTClaim(t *testing.T) {
+	secret := []byte("secret")
+	keyFunc := func(*jwt.Token) (interface{}, error) { return secret, nil }
+	sign := func(key []byte, claims jwt.MapClaims) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	key := JWTSubject(keyFunc)
+	for name, tc := range map[string]struct {
+		auth    string
+		want    string
+		wantErr bool
+	}{
+		"valid":      {auth: "Bearer " + sign(secret, jwt.MapClaims{"sub": "u1"}), want: "u1"},
+		"lower case": {auth: "bearer " + sign(secret, jwt.MapClaims{"sub": "u1"}), want: "u1"},
+		"forged":     {auth: "Bearer " + sign([]byte("other"), jwt.MapClaims{"sub": "u1"}), wantErr: true},
+		"no claim":   {auth: "Bearer " + sign(secret, jwt.MapClaims{"name": "u1"}), wantErr: true},
+		"no token":   {wantErr: true},
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		if tc.auth != "" {
+			r.Header.Set("Authorization", tc.auth)
+		}
+		got, err := key(r)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("%s: got %q, %v", name, got, err)
+		}
+	}
+}
+
+func TestJWTClaimExpired(t *testing.T) {
+	secret := []byte("secret")
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "u1",
+		"exp": time.Now().Add(-time.Minute).Unix(),
+	}).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	_, err = JWTSubject(func(*jwt.Token) (interface{}, error) { return secret, nil })(r)
+	if !errors.Is(err, ErrorInvalidToken) || !errors.Is(err, jwt.ErrTokenExpired) {
+		t.Fatalf("expired token: %v", err)
+	}
+	if status := ErrorStatus(err); status != http.StatusUnauthorized {
+		t.Fatalf("status %d, want 401", status)
+	}
+}
+
+func TestComposite(t *testing.T) {
+	key := Composite(Header("X-User"), KeyFunc(RemoteIP))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "1.2.3.4:1234"
+	if _, err := key(r); err != ErrorNoKey {
+		t.Fatalf("got %v, want ErrorNoKey", err)
+	}
+	r.Header.Set("X-User", "u1")
+	if got, err := key(r); err != nil || got != "u1|1.2.3.4" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+}
@@ -0,0 +1,131 @@
+package httpLimiter
+
+import (
+	"errors"
+	"net/http"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+type config struct {
+	key          KeyExtractor
+	skipper      func(r *http.Request) bool
+	reject       RejectionHandler
+	errorHandler func(w http.ResponseWriter, r *http.Request, err error)
+	bypassHeader string
+}
+
+type Option func(*config)
+
+// WithKeyFunc sets how the id is extracted; the default is RemoteIP.
+func WithKeyFunc(fn KeyFunc) Option {
+	return WithKeyExtractor(fn)
+}
+
+func WithKeyExtractor(e KeyExtractor) Option {
+	return func(c *config) {
+		c.key = e
+	}
+}
+
+// WithSkipper lets requests for which fn returns true through unchecked.
+func WithSkipper(fn func(r *http.Request) bool) Option {
+	return func(c *config) {
+		c.skipper = fn
+	}
+}
+
+// WithRejectionHandler replaces the default plain 429 response.
+func WithRejectionHandler(h RejectionHandler) Option {
+	return func(c *config) {
+		c.reject = h
+	}
+}
+
+// WithErrorHandler handles key extraction and Redis errors; the default responds
+// 400 for invalid keys and 500 otherwise.
+func WithErrorHandler(fn func(w http.ResponseWriter, r *http.Request, err error)) Option {
+	return func(c *config) {
+		c.errorHandler = fn
+	}
+}
+
+// WithBypassHeader passes the token in header to the limiter, see
+// rateLimiter.WithBypassToken.
+func WithBypassHeader(header string) Option {
+	return func(c *config) {
+		c.bypassHeader = header
+	}
+}
+
+func newConfig(opts []Option) *config {
+	c := &config{
+		key:          KeyFunc(RemoteIP),
+		reject:       PlainRejection,
+		errorHandler: defaultError,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func defaultError(w http.ResponseWriter, r *http.Request, err error) {
+	http.Error(w, http.StatusText(ErrorStatus(err)), ErrorStatus(err))
+}
+
+// ErrorStatus maps a key or limiter error to an HTTP status code.
+func ErrorStatus(err error) int {
+	switch {
+	case rateLimiter.IsBlocked(err):
+		return http.StatusTooManyRequests
+	case errors.Is(err, rateLimiter.ErrorInvalidId), errors.Is(err, ErrorNoKey):
+		return http.StatusBadRequest
+	case errors.Is(err, ErrorInvalidToken):
+		return http.StatusUnauthorized
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Middleware rate limits every request by the id returned by the key function.
+func Middleware(rl *rateLimiter.RateLimiter, opts ...Option) func(http.Handler) http.Handler {
+	c := newConfig(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if c.skipper != nil && c.skipper(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			c.serve(w, r, next, rl, c.key)
+		})
+	}
+}
+
+func (c *config) serve(w http.ResponseWriter, r *http.Request, next http.Handler, rl *rateLimiter.RateLimiter, key KeyExtractor) {
+	id, err := key.Extract(r)
+	if err == nil && id == "" {
+		err = ErrorNoKey
+	}
+	if err != nil {
+		c.errorHandler(w, r, err)
+		return
+	}
+	ctx := r.Context()
+	if c.bypassHeader != "" {
+		if token := r.Header.Get(c.bypassHeader); token != "" {
+			ctx = rateLimiter.WithBypassToken(ctx, token)
+		}
+	}
+	res, err := rl.AllowCtx(ctx, id)
+	SetHeaders(w.Header(), res)
+	if res != nil && !res.Allowed {
+		c.reject(w, r, res)
+		return
+	}
+	if err != nil {
+		c.errorHandler(w, r, err)
+		return
+	}
+	next.ServeHTTP(w, r)
+}
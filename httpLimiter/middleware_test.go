@@ -0,0 +1,119 @@
+package httpLimiter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+var noContent = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+})
+
+func TestMiddleware(t *testing.T) {
+	rl := testLimiter(t, "http")
+	h := Middleware(rl)(noContent)
+	for i, want := range []int{http.StatusNoContent, http.StatusNoContent, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		h.ServeHTTP(w, r)
+		if w.Code != want {
+			t.Fatalf("request %d: status %d, want %d", i+1, w.Code, want)
+		}
+		if w.Header().Get("X-RateLimit-Limit") != "3" {
+			t.Fatalf("request %d: headers %v", i+1, w.Header())
+		}
+		if want == http.StatusTooManyRequests && w.Header().Get("Retry-After") != "60" {
+			t.Fatalf("Retry-After = %q, want 60", w.Header().Get("Retry-After"))
+		}
+	}
+
+	// other peers have their own counter
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status %d for another peer", w.Code)
+	}
+}
+
+func TestMiddlewareOptions(t *testing.T) {
+	rl := testLimiter(t, "http")
+	h := Middleware(rl,
+		WithKeyFunc(Header("X-Api-Key")),
+		WithSkipper(func(r *http.Request) bool { return r.URL.Path == "/health" }),
+		WithRejectionHandler(func(w http.ResponseWriter, r *http.Request, res *rateLimiter.CheckResult) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}),
+	)(noContent)
+	serve := func(path, key string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", path, nil)
+		if key != "" {
+			r.Header.Set("X-Api-Key", key)
+		}
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+	if code := serve("/", ""); code != http.StatusBadRequest {
+		t.Fatalf("missing key: status %d, want 400", code)
+	}
+	for i := 0; i < 5; i++ {
+		if code := serve("/health", ""); code != http.StatusNoContent {
+			t.Fatalf("skipped request: status %d", code)
+		}
+	}
+	serve("/", "k")
+	serve("/", "k")
+	if code := serve("/", "k"); code != http.StatusServiceUnavailable {
+		t.Fatalf("rejection handler not used: status %d", code)
+	}
+}
+
+func TestForwardedIP(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	if ip, _ := ForwardedIP(r); ip != "10.0.0.1" {
+		t.Fatalf("ForwardedIP without header = %q", ip)
+	}
+	r.Header.Set("X-Forwarded-For", " 1.2.3.4 , 10.0.0.2")
+	if ip, _ := ForwardedIP(r); ip != "1.2.3.4" {
+		t.Fatalf("ForwardedIP = %q, want 1.2.3.4", ip)
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	for d, want := range map[time.Duration]string{time.Second: "1", 1500 * time.Millisecond: "2", time.Minute: "60"} {
+		if got := RetryAfterSeconds(d); got != want {
+			t.Errorf("RetryAfterSeconds(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestBypassHeader(t *testing.T) {
+	rl := testLimiter(t, "http", rateLimiter.WithBypassKeys(0, []byte("0123456789abcdef0123456789abcdef")))
+	token, err := rl.IssueBypassToken(rateLimiter.BypassToken{}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := Middleware(rl, WithBypassHeader("X-Bypass"))(noContent)
+	for i := 0; i < 5; i++ {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("X-Bypass", token)
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("request %d with a bypass token: status %d", i+1, w.Code)
+		}
+	}
+	for i, want := range []int{http.StatusNoContent, http.StatusNoContent, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if w.Code != want {
+			t.Fatalf("request %d without a token: status %d, want %d", i+1, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package httpLimiter
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+// RejectionHandler writes the response for a rate limited request. The rate limit
+// headers are already set when it is called.
+type RejectionHandler func(w http.ResponseWriter, r *http.Request, res *rateLimiter.CheckResult)
+
+// PlainRejection responds 429 with a plain text body.
+func PlainRejection(w http.ResponseWriter, r *http.Request, res *rateLimiter.CheckResult) {
+	http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+}
+
+// JSONRejection responds 429 with {"code":429,"message":...,"retryAfter":seconds},
+// retryAfter being -1 for permanent blocks.
+func JSONRejection(message string) RejectionHandler {
+	return func(w http.ResponseWriter, r *http.Request, res *rateLimiter.CheckResult) {
+		retryAfter := int64(-1)
+		if res.RetryAfter >= 0 {
+			retryAfter = int64((res.RetryAfter + time.Second - 1) / time.Second)
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code":       http.StatusTooManyRequests,
+			"message":    message,
+			"retryAfter": retryAfter,
+		})
+	}
+}
+
+// RedirectRejection redirects rejected requests, e.g. to a challenge page.
+func RedirectRejection(url string) RejectionHandler {
+	return func(w http.ResponseWriter, r *http.Request, res *rateLimiter.CheckResult) {
+		http.Redirect(w, r, url, http.StatusSeeOther)
+	}
+}
@@ -0,0 +1,38 @@
+package httpLimiter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+func TestJSONRejection(t *testing.T) {
+	for retryAfter, want := range map[time.Duration]float64{1500 * time.Millisecond: 2, -1: -1} {
+		w := httptest.NewRecorder()
+		JSONRejection("slow down")(w, httptest.NewRequest("GET", "/", nil), &rateLimiter.CheckResult{RetryAfter: retryAfter})
+		if w.Code != http.StatusTooManyRequests || w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+			t.Fatalf("status %d, headers %v", w.Code, w.Header())
+		}
+		var body map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body["code"] != float64(429) || body["message"] != "slow down" || body["retryAfter"] != want {
+			t.Fatalf("RetryAfter %v: body %v", retryAfter, body)
+		}
+	}
+}
+
+func TestRedirectRejection(t *testing.T) {
+	rl := testLimiter(t, "http", rateLimiter.WithBlockTimes(1))
+	h := Middleware(rl, WithRejectionHandler(RedirectRejection("/challenge")))(noContent)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/challenge" {
+		t.Fatalf("status %d, headers %v", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,160 @@
+package httpLimiter
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+// Rule declares the limit of the requests matching Pattern, "[METHOD ]/path". A
+// path segment "*", ":name" or "{name}" matches any single segment, and a trailing
+// "/*" matches the rest of the path, e.g. "POST /api/v1/orders" or "GET /public/*".
+type Rule struct {
+	Pattern  string
+	Limit    int           //same meaning as BlockTimes, 0=the Manager default
+	Duration time.Duration //0=the Manager default
+	Key      KeyExtractor  //nil=the key of the middleware options
+}
+
+type route struct {
+	method   string
+	segments []string
+	rest     bool //the pattern ends in /*
+	literals int
+	rl       *rateLimiter.RateLimiter
+	key      KeyExtractor
+}
+
+// Router resolves the Rule of a request.
+type Router struct {
+	routes []*route
+}
+
+// NewRouter creates one limiter per rule from m, named after its pattern, so they
+// share the Manager defaults: Redis, lists, pub and so on.
+func NewRouter(m *rateLimiter.Manager, rules ...Rule) (*Router, error) {
+	routes := make([]*route, 0, len(rules))
+	for _, rule := range rules {
+		rt, err := parsePattern(rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		var opts []rateLimiter.Option
+		if rule.Limit > 0 {
+			opts = append(opts, rateLimiter.WithBlockTimes(rule.Limit))
+		}
+		if rule.Duration > 0 {
+			opts = append(opts, rateLimiter.WithDuration(rule.Duration))
+		}
+		if rt.rl, err = m.Get(rule.Pattern, opts...); err != nil {
+			return nil, err
+		}
+		rt.key = rule.Key
+		routes = append(routes, rt)
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].before(routes[j])
+	})
+	return &Router{routes: routes}, nil
+}
+
+func parsePattern(pattern string) (*route, error) {
+	rt := &route{}
+	path := pattern
+	if method, p, ok := strings.Cut(pattern, " "); ok {
+		rt.method, path = strings.ToUpper(method), strings.TrimSpace(p)
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, errors.New("invalid rule pattern: " + pattern)
+	}
+	if strings.HasSuffix(path, "/*") {
+		rt.rest = true
+		path = strings.TrimSuffix(path, "/*")
+	}
+	rt.segments = splitPath(path)
+	for _, s := range rt.segments {
+		if !isWildcard(s) {
+			rt.literals++
+		}
+	}
+	return rt, nil
+}
+
+// before orders more specific routes first: more literal segments, then longer
+// patterns, then a method over any method.
+func (rt *route) before(o *route) bool {
+	if rt.literals != o.literals {
+		return rt.literals > o.literals
+	}
+	if len(rt.segments) != len(o.segments) {
+		return len(rt.segments) > len(o.segments)
+	}
+	if rt.rest != o.rest {
+		return !rt.rest
+	}
+	return rt.method != "" && o.method == ""
+}
+
+func (rt *route) match(method string, segments []string) bool {
+	if rt.method != "" && rt.method != method {
+		return false
+	}
+	if len(segments) < len(rt.segments) || (!rt.rest && len(segments) != len(rt.segments)) {
+		return false
+	}
+	if rt.rest && len(segments) == len(rt.segments) {
+		return false
+	}
+	for i, s := range rt.segments {
+		if !isWildcard(s) && s != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func isWildcard(segment string) bool {
+	return segment == "*" || strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "{")
+}
+
+// Match returns the limiter of the most specific rule matching r, or nil.
+func (rt *Router) Match(r *http.Request) (*rateLimiter.RateLimiter, KeyExtractor) {
+	segments := splitPath(r.URL.Path)
+	for _, route := range rt.routes {
+		if route.match(r.Method, segments) {
+			return route.rl, route.key
+		}
+	}
+	return nil, nil
+}
+
+// Middleware rate limits every request by its most specific rule; requests that
+// match no rule pass through.
+func (rt *Router) Middleware(opts ...Option) func(http.Handler) http.Handler {
+	c := newConfig(opts)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rl, key := rt.Match(r)
+			if rl == nil || (c.skipper != nil && c.skipper(r)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if key == nil {
+				key = c.key
+			}
+			c.serve(w, r, next, rl, key)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package httpLimiter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func testManager(t *testing.T) *rateLimiter.Manager {
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	m := rateLimiter.NewManager(&rateLimiter.Config{
+		Redis:         &redis.Redis{Client: client},
+		Duration:      time.Minute,
+		BlockTimes:    100,
+		BlockDuration: time.Minute,
+	})
+	t.Cleanup(func() { m.CloseAll(context.Background()) })
+	return m
+}
+
+func TestRouterMatch(t *testing.T) {
+	rt, err := NewRouter(testManager(t),
+		Rule{Pattern: "/api/*"},
+		Rule{Pattern: "/api/users/:id"},
+		Rule{Pattern: "POST /api/users/{id}"},
+		Rule{Pattern: "/api/users/me"},
+		Rule{Pattern: "GET /public/*", Limit: 5},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for req, want := range map[string]string{
+		"GET /api/users/me":      "/api/users/me",
+		"GET /api/users/42":      "/api/users/:id",
+		"POST /api/users/42":     "POST /api/users/{id}",
+		"GET /api/orders":        "/api/*",
+		"GET /api/users/42/x":    "/api/*",
+		"GET /api":               "",
+		"GET /public/a.css":      "GET /public/*",
+		"POST /public/a.css":     "",
+		"GET /somewhere/else/42": "",
+	} {
+		method, path, _ := strings.Cut(req, " ")
+		rl, _ := rt.Match(httptest.NewRequest(method, path, nil))
+		got := ""
+		if rl != nil {
+			got = rl.Name
+		}
+		if got != want {
+			t.Errorf("%s matched %q, want %q", req, got, want)
+		}
+	}
+	if _, err := NewRouter(testManager(t), Rule{Pattern: "GET api"}); err == nil {
+		t.Error("pattern without a leading slash accepted")
+	}
+}
+
+func TestRouterMiddleware(t *testing.T) {
+	rt, err := NewRouter(testManager(t), Rule{Pattern: "POST /login", Limit: 2, Key: Header("X-User")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := rt.Middleware()(noContent)
+	serve := func(method, path string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, path, nil)
+		r.Header.Set("X-User", "u")
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+	if code := serve("POST", "/login"); code != http.StatusNoContent {
+		t.Fatalf("status %d", code)
+	}
+	if code := serve("POST", "/login"); code != http.StatusTooManyRequests {
+		t.Fatalf("status %d over the rule limit, want 429", code)
+	}
+	for i := 0; i < 5; i++ {
+		if code := serve("GET", "/login"); code != http.StatusNoContent {
+			t.Fatalf("request without a rule: status %d", code)
+		}
+	}
+}
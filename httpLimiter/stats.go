@@ -0,0 +1,123 @@
+package httpLimiter
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+const defaultStatsTop = 10
+
+type windowJSON struct {
+	Duration string `json:"duration"`
+	Limit    int    `json:"limit"`
+}
+
+type configJSON struct {
+	Duration      string       `json:"duration"`
+	BlockTimes    int          `json:"blockTimes"`
+	BlockDuration string       `json:"blockDuration"`
+	Windows       []windowJSON `json:"windows,omitempty"`
+	DryRun        bool         `json:"dryRun"`
+}
+
+type offenderJSON struct {
+	Id     string `json:"id"`
+	Blocks int    `json:"blocks"`
+	Times  int    `json:"times"`
+}
+
+type limiterStatsJSON struct {
+	Name             string         `json:"name"`
+	Config           configJSON     `json:"config"`
+	WhiteList        int            `json:"whiteList"`
+	StaticWhiteList  int            `json:"staticWhiteList"`
+	DynamicWhiteList int            `json:"dynamicWhiteList"`
+	BlockList        int            `json:"blockList"`
+	StaticBlockList  int            `json:"staticBlockList"`
+	DynamicBlockList int            `json:"dynamicBlockList"`
+	TrustedList      int            `json:"trustedList"`
+	BlockedCounters  int            `json:"blockedCounters"`
+	LastSync         *time.Time     `json:"lastSync,omitempty"`
+	TopOffenders     []offenderJSON `json:"topOffenders,omitempty"`
+	Error            string         `json:"error,omitempty"`
+}
+
+// StatsHandler serves the stats of limiters as JSON, for an internal debug port:
+// list sizes, blocked counters, config and, with TrackOffenders, the top ids. The
+// "top" query parameter sets how many ids are listed and "name" selects a limiter.
+func StatsHandler(limiters ...*rateLimiter.RateLimiter) http.Handler {
+	return statsHandler(func() []*rateLimiter.RateLimiter { return limiters })
+}
+
+// ManagerStatsHandler is StatsHandler for every limiter of m at request time.
+func ManagerStatsHandler(m *rateLimiter.Manager) http.Handler {
+	return statsHandler(m.List)
+}
+
+func statsHandler(limiters func() []*rateLimiter.RateLimiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		top := defaultStatsTop
+		if s := r.URL.Query().Get("top"); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				http.Error(w, "invalid top", http.StatusBadRequest)
+				return
+			}
+			top = n
+		}
+		name := r.URL.Query().Get("name")
+		out := []limiterStatsJSON{}
+		for _, rl := range limiters() {
+			if name != "" && rl.Name != name {
+				continue
+			}
+			out = append(out, limiterStats(r, rl, top))
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(map[string]interface{}{"limiters": out})
+	})
+}
+
+func limiterConfig(rl *rateLimiter.RateLimiter) configJSON {
+	limits := rl.Limits()
+	c := configJSON{
+		Duration:      limits.Duration.String(),
+		BlockTimes:    limits.BlockTimes,
+		BlockDuration: limits.BlockDuration.String(),
+		DryRun:        rl.DryRun,
+	}
+	for _, win := range rl.Windows {
+		c.Windows = append(c.Windows, windowJSON{Duration: win.Duration.String(), Limit: win.Limit})
+	}
+	return c
+}
+
+func limiterStats(r *http.Request, rl *rateLimiter.RateLimiter, top int) limiterStatsJSON {
+	s := limiterStatsJSON{Name: rl.Name, Config: limiterConfig(rl)}
+	stats, err := rl.StatsCtx(r.Context())
+	if err != nil {
+		s.Error = err.Error()
+		return s
+	}
+	s.WhiteList, s.StaticWhiteList, s.DynamicWhiteList = stats.WhiteList, stats.StaticWhiteList, stats.DynamicWhiteList
+	s.BlockList, s.StaticBlockList, s.DynamicBlockList = stats.BlockList, stats.StaticBlockList, stats.DynamicBlockList
+	s.TrustedList, s.BlockedCounters = stats.TrustedList, stats.BlockedCounters
+	if !stats.LastSync.IsZero() {
+		s.LastSync = &stats.LastSync
+	}
+	if rl.TrackOffenders && top > 0 {
+		offenders, err := rl.TopOffendersCtx(r.Context(), top)
+		if err != nil {
+			s.Error = err.Error()
+			return s
+		}
+		for _, o := range offenders {
+			s.TopOffenders = append(s.TopOffenders, offenderJSON{Id: o.Id, Blocks: o.Blocks, Times: o.Times})
+		}
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package httpLimiter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+func TestStatsHandler(t *testing.T) {
+	rl := testLimiter(t, "stats", rateLimiter.WithBlockTimes(1), rateLimiter.WithTopOffenders(time.Hour))
+	other := testLimiter(t, "other")
+	if err := rl.AddBlockList("b", false); err != nil {
+		t.Fatal(err)
+	}
+	rl.Allow("a")
+	rl.Allow("a")
+	// offenders are written in the background
+	deadline := time.Now().Add(2 * time.Second)
+	for top, _ := rl.TopOffenders(1); len(top) == 0; top, _ = rl.TopOffenders(1) {
+		if time.Now().After(deadline) {
+			t.Fatal("offenders not written")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	h := StatsHandler(rl, other)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/?name=stats&top=5", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	var out struct {
+		Limiters []limiterStatsJSON `json:"limiters"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Limiters) != 1 {
+		t.Fatalf("%d limiters, want 1", len(out.Limiters))
+	}
+	s := out.Limiters[0]
+	if s.Name != "stats" || s.Config.Duration != "1m0s" || s.Config.BlockTimes != 1 || s.Error != "" {
+		t.Fatalf("stats %+v", s)
+	}
+	if s.BlockList != 1 || s.DynamicBlockList != 1 || s.BlockedCounters != 1 {
+		t.Fatalf("list stats %+v", s)
+	}
+	if len(s.TopOffenders) != 1 || s.TopOffenders[0].Id != "a" || s.TopOffenders[0].Blocks != 2 {
+		t.Fatalf("top offenders %+v", s.TopOffenders)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/?top=-1", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("top=-1 status %d", w.Code)
+	}
+}
@@ -0,0 +1,84 @@
+package httpLimiter
+
+import (
+	"net/http"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+// Policy decides what an outgoing request over its budget does.
+type Policy int
+
+const (
+	PolicyError Policy = iota //fail with the limiter's BlockedError
+	PolicyWait                //wait until the budget allows it, or the request context ends
+)
+
+type transportConfig struct {
+	key    KeyExtractor
+	policy Policy
+}
+
+type TransportOption func(*transportConfig)
+
+// WithRequestKey sets the id outgoing requests are counted under; the default is Host.
+func WithRequestKey(e KeyExtractor) TransportOption {
+	return func(c *transportConfig) {
+		c.key = e
+	}
+}
+
+func WithPolicy(p Policy) TransportOption {
+	return func(c *transportConfig) {
+		c.policy = p
+	}
+}
+
+// Host uses the host, with its port, of the request URL.
+func Host(r *http.Request) (string, error) {
+	if r.URL == nil || r.URL.Host == "" {
+		return "", ErrorNoKey
+	}
+	return r.URL.Host, nil
+}
+
+type transport struct {
+	rl   *rateLimiter.RateLimiter
+	base http.RoundTripper
+	c    *transportConfig
+}
+
+// Transport wraps base, http.DefaultTransport if nil, so outgoing requests share
+// the Redis-coordinated budget of rl, e.g. to keep every pod within the quota of a
+// third-party API. Reaching the limit paces the key; it never blocks it.
+func Transport(rl *rateLimiter.RateLimiter, base http.RoundTripper, opts ...TransportOption) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c := &transportConfig{key: KeyFunc(Host)}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return &transport{rl: rl, base: base, c: c}
+}
+
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	id, err := t.c.key.Extract(r)
+	if err == nil && id == "" {
+		err = ErrorNoKey
+	}
+	if err == nil {
+		if t.c.policy == PolicyWait {
+			_, err = t.rl.WaitCtx(r.Context(), id)
+		} else {
+			_, err = t.rl.TakeCtx(r.Context(), id)
+		}
+	}
+	if err != nil {
+		if r.Body != nil {
+			r.Body.Close()
+		}
+		return nil, err
+	}
+	return t.base.RoundTrip(r)
+}
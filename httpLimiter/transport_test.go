@@ -0,0 +1,35 @@
+package httpLimiter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(noContent)
+	defer srv.Close()
+	rl := testLimiter(t, "transport", rateLimiter.WithBlockTimes(2))
+	client := &http.Client{Transport: Transport(rl, nil)}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if _, err := client.Get(srv.URL); !rateLimiter.IsBlocked(err) {
+		t.Fatalf("got %v, want a BlockedError", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client = &http.Client{Transport: Transport(rl, nil, WithPolicy(PolicyWait))}
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("request over the limit sent")
+	}
+}
@@ -0,0 +1,80 @@
+package rateLimiter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const DefaultMaxIdLength = 256
+
+// hashedIdPrefix marks the ids HashLongId stored in place of long ones. Ids
+// given by clients can't use it, so they never collide with a hashed id.
+const hashedIdPrefix = "sha256:"
+
+var ErrorInvalidId = stderrors.New("invalid id")
+
+type InvalidIdError struct {
+	Id     string
+	Reason string
+}
+
+func (e *InvalidIdError) Error() string {
+	return "invalid id: " + e.Reason
+}
+
+func (e *InvalidIdError) Is(target error) bool {
+	return target == ErrorInvalidId
+}
+
+func (rl *RateLimiter) sanitizeId(id string) (string, error) {
+	if rl.Normalizer != nil {
+		id = rl.Normalizer(id)
+	}
+	return sanitizeId(id, rl.MaxIdLength, rl.HashLongId)
+}
+
+// sanitizeStoredId is sanitizeId for ids that may be taken from a list or a
+// counter, e.g. by an admin: hashed ids are used as they are.
+func (rl *RateLimiter) sanitizeStoredId(id string) (string, error) {
+	if rl.HashLongId && isHashedId(id) {
+		return id, nil
+	}
+	return rl.sanitizeId(id)
+}
+
+func isHashedId(id string) bool {
+	if len(id) != len(hashedIdPrefix)+sha256.Size*2 || !strings.HasPrefix(id, hashedIdPrefix) {
+		return false
+	}
+	_, err := hex.DecodeString(id[len(hashedIdPrefix):])
+	return err == nil
+}
+
+func sanitizeId(id string, maxLength int, hash bool) (string, error) {
+	if id == "" {
+		return "", &InvalidIdError{Id: id, Reason: "empty"}
+	}
+	if !utf8.ValidString(id) {
+		return "", &InvalidIdError{Id: id, Reason: "invalid utf-8"}
+	}
+	for _, r := range id {
+		if unicode.IsControl(r) {
+			return "", &InvalidIdError{Id: id, Reason: "control character"}
+		}
+	}
+	if hash && strings.HasPrefix(id, hashedIdPrefix) {
+		return "", &InvalidIdError{Id: id, Reason: "reserved prefix " + hashedIdPrefix}
+	}
+	if len(id) > maxLength {
+		if !hash {
+			return "", &InvalidIdError{Id: id, Reason: "too long"}
+		}
+		sum := sha256.Sum256([]byte(id))
+		return hashedIdPrefix + hex.EncodeToString(sum[:]), nil
+	}
+	return id, nil
+}
@@ -0,0 +1,88 @@
+package rateLimiter
+
+import (
+	stderrors "errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInvalidIds(t *testing.T) {
+	_, r := testRedis(t)
+	rl := New(&Config{Name: "ids", Duration: time.Minute, BlockTimes: 10, Redis: r, MaxIdLength: 16})
+	for _, id := range []string{"", "a\x00b", "a\nb", "\xff\xfe", strings.Repeat("a", 17)} {
+		if _, err := rl.Check(id); !stderrors.Is(err, ErrorInvalidId) {
+			t.Errorf("Check(%q) = %v, want ErrorInvalidId", id, err)
+		}
+	}
+	if _, err := rl.Check(strings.Repeat("a", 16)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHashLongId(t *testing.T) {
+	mr, r := testRedis(t)
+	rl := New(&Config{Name: "ids", Duration: time.Minute, BlockTimes: 10, Redis: r, MaxIdLength: 16, HashLongId: true})
+	long := strings.Repeat("a", 100)
+	if _, err := rl.Check(long); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range mr.Keys() {
+		if strings.Contains(k, long) {
+			t.Fatalf("long id used as key %q", k)
+		}
+	}
+	if n, _ := rl.Check(long); n != 2 {
+		t.Fatalf("hashed id counted %d times, want 2", n)
+	}
+}
+
+func TestSanitizeIdReservedPrefix(t *testing.T) {
+	if _, err := sanitizeId("sha256:abc", 16, true); !stderrors.Is(err, ErrorInvalidId) {
+		t.Fatalf("hashed prefix accepted: %v", err)
+	}
+	if id, err := sanitizeId("sha256:abc", 16, false); err != nil || id != "sha256:abc" {
+		t.Fatalf("got %q, %v without HashLongId", id, err)
+	}
+	long := strings.Repeat("a", 100)
+	hashed, err := sanitizeId(long, 16, true)
+	if err != nil || !isHashedId(hashed) {
+		t.Fatalf("got %q, %v", hashed, err)
+	}
+}
+
+func TestAutoBlockLongId(t *testing.T) {
+	_, rl := testLimiter(t, "long", WithDuration(time.Minute), WithBlockTimes(2), WithMaxIdLength(16, true))
+	long := strings.Repeat("x", 100)
+	for i := 0; i < 2; i++ {
+		rl.Check(long)
+	}
+	hashed, _ := rl.sanitizeId(long)
+	ids, err := rl.GetBlockList(nil)
+	if err != nil || len(ids) != 1 || ids[0] != hashed {
+		t.Fatalf("block list %v, %v, want [%s]", ids, err, hashed)
+	}
+	ins, err := rl.Inspect(long)
+	if err != nil || !ins.BlockListed {
+		t.Fatalf("long id not block listed: %+v, %v", ins, err)
+	}
+	if err := rl.RemoveBlockList(hashed, false); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := rl.GetBlockList(nil); len(ids) != 0 {
+		t.Fatalf("block list %v after removing the hashed id", ids)
+	}
+}
+
+func TestAutoBlockNormalizerAppliedOnce(t *testing.T) {
+	_, rl := testLimiter(t, "norm", WithDuration(time.Minute), WithBlockTimes(1),
+		WithNormalizer(func(id string) string { return "n-" + id }))
+	rl.Check("a")
+	ids, _ := rl.GetBlockList(nil)
+	if len(ids) != 1 || ids[0] != "n-a" {
+		t.Fatalf("block list %v, want [n-a]", ids)
+	}
+	if _, err := rl.Check("a"); !IsBlocked(err) {
+		t.Fatalf("a not blocked: %v", err)
+	}
+}
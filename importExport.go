@@ -0,0 +1,299 @@
+package rateLimiter
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"sort"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type ListFormat string
+
+const (
+	FormatJSON ListFormat = "json"
+	FormatCSV  ListFormat = "csv"
+)
+
+const (
+	listWhite = "white"
+	listBlock = "block"
+)
+
+var csvHeader = []string{"list", "id", "reason", "operator", "createdAt", "expiresAt"}
+
+type listsDocument struct {
+	WhiteList []ListEntry `json:"whiteList"`
+	BlockList []ListEntry `json:"blockList"`
+}
+
+// ExportLists writes both Redis lists, with their metadata, in the given format.
+// Config seeds are left out, as they are in every instance anyway.
+func (rl *RateLimiter) ExportLists(ctx context.Context, w io.Writer, format ListFormat) error {
+	var doc listsDocument
+	var err error
+	if doc.WhiteList, err = rl.redisEntries(ctx, rl.whiteListKey, rl.whiteTTLKey, rl.whiteMetaKey); err != nil {
+		return err
+	}
+	if doc.BlockList, err = rl.redisEntries(ctx, rl.blockListKey, rl.blockTTLKey, rl.blockMetaKey); err != nil {
+		return err
+	}
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(csvHeader)
+		for _, e := range doc.WhiteList {
+			cw.Write(entryRecord(listWhite, e))
+		}
+		for _, e := range doc.BlockList {
+			cw.Write(entryRecord(listBlock, e))
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return stderrors.New("unsupported format: " + string(format))
+	}
+}
+
+// ImportLists loads lists written by ExportLists. Without merge the current Redis
+// lists are replaced; Config seeds always stay. Expired temporary entries are skipped.
+// Every entry is validated before any list changes, and each list is written in
+// one Redis transaction.
+func (rl *RateLimiter) ImportLists(ctx context.Context, r io.Reader, format ListFormat, merge bool, pub bool) error {
+	var doc listsDocument
+	switch format {
+	case FormatJSON:
+		if err := json.NewDecoder(r).Decode(&doc); err != nil {
+			return err
+		}
+	case FormatCSV:
+		records, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return err
+		}
+		for i, rec := range records {
+			if i == 0 && len(rec) > 0 && rec[0] == csvHeader[0] {
+				continue
+			}
+			list, e, err := recordEntry(rec)
+			if err != nil {
+				return err
+			}
+			if list == listWhite {
+				doc.WhiteList = append(doc.WhiteList, e)
+			} else {
+				doc.BlockList = append(doc.BlockList, e)
+			}
+		}
+	default:
+		return stderrors.New("unsupported format: " + string(format))
+	}
+
+	white, err := rl.importEntries(doc.WhiteList)
+	if err != nil {
+		return err
+	}
+	block, err := rl.importEntries(doc.BlockList)
+	if err != nil {
+		return err
+	}
+	if err := rl.importList(ctx, importWhite(rl), white, merge, pub); err != nil {
+		return err
+	}
+	return rl.importList(ctx, importBlock(rl), block, merge, pub)
+}
+
+// redisEntries reads the entries of a Redis list that haven't expired, sorted by id.
+func (rl *RateLimiter) redisEntries(ctx context.Context, setKey, ttlKey, metaKey string) ([]ListEntry, error) {
+	ids, err := rl.Redis.SMembers(ctx, setKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	vals, err := rl.Redis.ZRangeWithScores(ctx, ttlKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	expiry := make(map[string]time.Time, len(vals))
+	for _, z := range vals {
+		expiry[z.Member.(string)] = time.UnixMilli(int64(z.Score))
+	}
+	now := rl.Clock.Now()
+	live := ids[:0]
+	for _, id := range ids {
+		if at, ok := expiry[id]; !ok || now.Before(at) {
+			live = append(live, id)
+		}
+	}
+	if len(live) == 0 {
+		return nil, nil
+	}
+	sort.Strings(live)
+	metas, err := rl.Redis.HMGet(ctx, metaKey, live...).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]ListEntry, len(live))
+	for i, id := range live {
+		if s, ok := metas[i].(string); ok {
+			json.Unmarshal([]byte(s), &entries[i])
+		}
+		entries[i].Id = id
+		entries[i].ExpiresAt = expiry[id]
+	}
+	return entries, nil
+}
+
+// importEntries sanitizes every entry and drops the expired ones, before
+// anything is changed. Hashed ids, as exported, are kept as they are.
+func (rl *RateLimiter) importEntries(entries []ListEntry) ([]ListEntry, error) {
+	now := rl.Clock.Now()
+	out := make([]ListEntry, 0, len(entries))
+	for _, e := range entries {
+		id, err := rl.sanitizeStoredId(e.Id)
+		if err != nil {
+			return nil, err
+		}
+		e.Id = id
+		if !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+type importTarget struct {
+	l                       *idList
+	setKey, ttlKey, metaKey string
+	seeds                   []string
+	changed                 func(ctx context.Context, op string, ids []string, ttl time.Duration)
+	auditAdd, auditClear    string
+	clearOp, addOp, ttlOp   string
+}
+
+func importWhite(rl *RateLimiter) importTarget {
+	return importTarget{rl.whiteIds, rl.whiteListKey, rl.whiteTTLKey, rl.whiteMetaKey, rl.Config.WhiteList,
+		rl.whiteListChanged, AuditAddWhiteList, AuditClearWhiteList, "cw", batchAddWhiteList, "tw"}
+}
+
+func importBlock(rl *RateLimiter) importTarget {
+	return importTarget{rl.blockIds, rl.blockListKey, rl.blockTTLKey, rl.blockMetaKey, rl.Config.BlockList,
+		rl.blockListChanged, AuditAddBlockList, AuditClearBlockList, "cb", batchAddBlockList, "tb"}
+}
+
+// importList writes entries to the Redis list in one transaction, replacing it
+// unless merge is set, and then to the local list.
+func (rl *RateLimiter) importList(ctx context.Context, t importTarget, entries []ListEntry, merge bool, pub bool) error {
+	var permanent []string
+	var temporary []ListEntry
+	for _, e := range entries {
+		if e.ExpiresAt.IsZero() {
+			permanent = append(permanent, e.Id)
+		} else {
+			temporary = append(temporary, e)
+		}
+	}
+	var changed []string
+	err := t.l.apply(func() error {
+		_, err := rl.Redis.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			if !merge {
+				pipe.Del(ctx, t.setKey, t.ttlKey, t.metaKey)
+			}
+			for _, e := range entries {
+				pipe.SAdd(ctx, t.setKey, e.Id)
+				if e.ExpiresAt.IsZero() {
+					pipe.ZRem(ctx, t.ttlKey, e.Id)
+				} else {
+					pipe.ZAdd(ctx, t.ttlKey, goredis.Z{Score: float64(e.ExpiresAt.UnixMilli()), Member: e.Id})
+				}
+				if e.Reason != "" || e.Operator != "" || !e.CreatedAt.IsZero() {
+					data, err := json.Marshal(e)
+					if err != nil {
+						return err
+					}
+					pipe.HSet(ctx, t.metaKey, e.Id, data)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if !merge {
+			t.l.reset(rl.seedIds(t.seeds))
+		}
+		changed = t.l.addAll(permanent)
+		for _, e := range temporary {
+			t.l.addTemporary(e.Id, e.ExpiresAt)
+			changed = append(changed, e.Id)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if !merge {
+		t.changed(ctx, ListClear, nil, 0)
+		rl.audit(ctx, t.auditClear, "", 0)
+	}
+	t.changed(ctx, ListAdd, changed, 0)
+	rl.auditBatch(ctx, t.auditAdd, changed)
+	if !pub {
+		return nil
+	}
+	if !merge {
+		rl.publish(ctx, SyncMessage{Op: t.clearOp})
+	}
+	if len(permanent) > 0 {
+		rl.publish(ctx, SyncMessage{Op: t.addOp, Ids: permanent})
+	}
+	for _, e := range temporary {
+		rl.publish(ctx, SyncMessage{Op: t.ttlOp, Id: e.Id, TTL: e.ExpiresAt.Sub(rl.Clock.Now()).Milliseconds()})
+	}
+	return nil
+}
+
+func entryRecord(list string, e ListEntry) []string {
+	return []string{list, e.Id, e.Reason, e.Operator, formatTime(e.CreatedAt), formatTime(e.ExpiresAt)}
+}
+
+func recordEntry(rec []string) (string, ListEntry, error) {
+	var e ListEntry
+	if len(rec) != len(csvHeader) {
+		return "", e, stderrors.New("csv记录字段数错误")
+	}
+	if rec[0] != listWhite && rec[0] != listBlock {
+		return "", e, stderrors.New("csv记录list必须为white或block")
+	}
+	e.Id, e.Reason, e.Operator = rec[1], rec[2], rec[3]
+	var err error
+	if e.CreatedAt, err = parseTime(rec[4]); err != nil {
+		return "", e, err
+	}
+	if e.ExpiresAt, err = parseTime(rec[5]); err != nil {
+		return "", e, err
+	}
+	return rec[0], e, nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
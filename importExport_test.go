@@ -0,0 +1,120 @@
+package rateLimiter
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	clock := NewFakeClock(time.UnixMilli(time.Now().UnixMilli()))
+	_, src := testLimiter(t, "export", WithDuration(time.Minute), WithClock(clock))
+	src.AddWhiteListBatchCtx(ctx, []string{"w"}, false)
+	src.AddBlockListEntry(ListEntry{Id: "temp", Reason: "abuse", Operator: "ops"}, time.Hour, false)
+	src.AddBlockListBatchCtx(ctx, []string{"perm"}, false)
+	for _, format := range []ListFormat{FormatJSON, FormatCSV} {
+		var buf bytes.Buffer
+		if err := src.ExportLists(ctx, &buf, format); err != nil {
+			t.Fatal(err)
+		}
+		_, dst := testLimiter(t, "import", WithDuration(time.Minute), WithClock(clock))
+		dst.AddBlockListBatchCtx(ctx, []string{"old"}, false)
+		if err := dst.ImportLists(ctx, &buf, format, false, false); err != nil {
+			t.Fatal(err)
+		}
+		white, _ := dst.GetWhiteList(nil)
+		block, _ := dst.GetBlockList(nil)
+		block = append([]string(nil), block...)
+		sort.Strings(block)
+		if len(white) != 1 || white[0] != "w" || strings.Join(block, ",") != "perm,temp" {
+			t.Fatalf("%s: imported lists %v %v", format, white, block)
+		}
+		entries, _ := dst.GetBlockListEntries(ctx, "temp")
+		if len(entries) != 1 || entries[0].Reason != "abuse" || !entries[0].ExpiresAt.Equal(clock.Now().Add(time.Hour)) {
+			t.Fatalf("%s: imported entry %+v", format, entries)
+		}
+	}
+}
+
+func TestImportMerge(t *testing.T) {
+	ctx := context.Background()
+	_, rl := testLimiter(t, "merge", WithDuration(time.Minute))
+	rl.AddBlockListBatchCtx(ctx, []string{"old"}, false)
+	doc := "list,id,reason,operator,createdAt,expiresAt\nblock,new,,,,\n"
+	if err := rl.ImportLists(ctx, strings.NewReader(doc), FormatCSV, true, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"old", "new"} {
+		if _, err := rl.Check(id); !IsBlocked(err) {
+			t.Fatalf("%s not blocked after a merging import: %v", id, err)
+		}
+	}
+	if err := rl.ImportLists(ctx, strings.NewReader("block,x\n"), FormatCSV, true, false); err == nil {
+		t.Fatal("short csv record accepted")
+	}
+	if err := rl.ImportLists(ctx, strings.NewReader("{}"), "xml", true, false); err == nil {
+		t.Fatal("unknown format accepted")
+	}
+}
+
+func TestImportInvalidEntryKeepsLists(t *testing.T) {
+	_, rl := testLimiter(t, "import", WithDuration(time.Minute), WithBlockTimes(100))
+	rl.AddWhiteList("w", false)
+	rl.AddBlockList("b", false)
+	doc := `{"whiteList":[{"id":"w2"}],"blockList":[{"id":"b2"},{"id":""}]}`
+	if err := rl.ImportLists(context.Background(), strings.NewReader(doc), FormatJSON, false, false); err == nil {
+		t.Fatal("import with an empty id succeeded")
+	}
+	white, _ := rl.GetWhiteList(nil)
+	block, _ := rl.GetBlockList(nil)
+	if len(white) != 1 || white[0] != "w" || len(block) != 1 || block[0] != "b" {
+		t.Fatalf("lists changed by a failed import: %v %v", white, block)
+	}
+	if n, _ := rl.Redis.SCard(context.Background(), rl.whiteListKey).Result(); n != 1 {
+		t.Fatalf("Redis white list has %d entries", n)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	_, rl := testLimiter(t, "roundtrip", WithDuration(time.Minute), WithBlockTimes(100),
+		WithBloomBlockList(0.01, time.Hour), WithBlockList("seed"))
+	rl.AddBlockListEntry(ListEntry{Id: "perm", Reason: "abuse", Operator: "ops"}, 0, false)
+	rl.AddBlockListTTL("temp", time.Hour, false)
+	rl.AddWhiteList("w", false)
+	if err := rl.loadBloomBlockList(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []ListFormat{FormatJSON, FormatCSV} {
+		var buf bytes.Buffer
+		if err := rl.ExportLists(ctx, &buf, format); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "perm") || strings.Contains(buf.String(), "seed") {
+			t.Fatalf("%s export: %s", format, buf.String())
+		}
+		if err := rl.ImportLists(ctx, &buf, format, false, false); err != nil {
+			t.Fatal(err)
+		}
+		members, _ := rl.Redis.SMembers(ctx, rl.blockListKey).Result()
+		if len(members) != 2 {
+			t.Fatalf("%s: block list %v after round trip", format, members)
+		}
+		entries, _ := rl.redisEntries(ctx, rl.blockListKey, rl.blockTTLKey, rl.blockMetaKey)
+		if entries[0].Id != "perm" || entries[0].Reason != "abuse" || entries[1].Id != "temp" || entries[1].ExpiresAt.IsZero() {
+			t.Fatalf("%s: entries %+v", format, entries)
+		}
+		for _, id := range []string{"perm", "temp", "seed"} {
+			if _, err := rl.Check(id); !IsBlocked(err) {
+				t.Fatalf("%s: %s not blocked after import: %v", format, id, err)
+			}
+		}
+		if _, err := rl.Check("w"); err != nil {
+			t.Fatalf("%s: w: %v", format, err)
+		}
+	}
+}
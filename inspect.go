@@ -0,0 +1,62 @@
+package rateLimiter
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type Inspection struct {
+	Id          string
+	Times       int
+	Limit       int
+	TTL         time.Duration //remaining lifetime of the counter, 0 if there is none
+	Blocked     bool          //the counter has reached the limit
+	WhiteListed bool
+	BlockListed bool
+	Trusted     bool
+	Tier        string //see Config.TierResolver
+	AllowRule   string //first matching AllowRules expression
+	DenyRule    string //first matching DenyRules expression
+}
+
+func (rl *RateLimiter) Inspect(id string) (*Inspection, error) {
+	return rl.InspectCtx(context.Background(), id)
+}
+
+// InspectCtx reports the state of id without consuming a request.
+func (rl *RateLimiter) InspectCtx(ctx context.Context, id string) (*Inspection, error) {
+	id, err := rl.sanitizeStoredId(id)
+	if err != nil {
+		return nil, err
+	}
+	key := rl.counterKey(id)
+	pipe := rl.Redis.Pipeline()
+	get := pipe.Get(ctx, key)
+	pttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != goredis.Nil {
+		return nil, err
+	}
+	times, err := get.Int()
+	if err != nil && err != goredis.Nil {
+		return nil, err
+	}
+	limit := rl.primaryWindow(id).Limit
+	ins := &Inspection{
+		Id:          id,
+		Times:       times,
+		Limit:       limit,
+		WhiteListed: rl.inWhiteList(id),
+		BlockListed: rl.inBlockList(id),
+		Trusted:     rl.inTrustedList(id),
+		Tier:        rl.tierName(id),
+		AllowRule:   matchRule(rl.allowRules, id),
+		DenyRule:    matchRule(rl.denyRules, id),
+	}
+	if ttl := pttl.Val(); ttl > 0 {
+		ins.TTL = ttl
+	}
+	ins.Blocked = limit > 0 && times >= limit
+	return ins, nil
+}
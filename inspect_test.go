@@ -0,0 +1,29 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInspect(t *testing.T) {
+	_, rl := testLimiter(t, "inspect", WithDuration(time.Minute), WithBlockTimes(2),
+		WithBlockDuration(time.Hour), WithWhiteList("w"))
+	ins, err := rl.Inspect("a")
+	if err != nil || ins.Times != 0 || ins.TTL != 0 || ins.Blocked {
+		t.Fatalf("unknown id: %+v, %v", ins, err)
+	}
+	rl.Check("a")
+	for i := 0; i < 2; i++ {
+		ins, err = rl.Inspect("a")
+		if err != nil || ins.Times != 1 || ins.Limit != 2 || ins.TTL <= 0 || ins.Blocked {
+			t.Fatalf("inspect %d after one check: %+v, %v", i, ins, err)
+		}
+	}
+	rl.Check("a")
+	if ins, _ = rl.Inspect("a"); !ins.Blocked || ins.TTL <= time.Minute {
+		t.Fatalf("blocked id: %+v", ins)
+	}
+	if ins, _ = rl.Inspect("w"); !ins.WhiteListed || ins.BlockListed {
+		t.Fatalf("white listed id: %+v", ins)
+	}
+}
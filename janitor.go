@@ -0,0 +1,56 @@
+package rateLimiter
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DefaultJanitorInterval = time.Minute
+	DefaultMaxCacheEntries = 100000
+)
+
+// runsJanitor reports whether the local caches need sweeping: they are only
+// filled with BlockCache, WriteBehind or Anomaly, unless JanitorInterval asks for it.
+func (rl *RateLimiter) runsJanitor() bool {
+	return rl.JanitorInterval > 0 || rl.BlockCache || rl.WriteBehind > 0 || rl.Anomaly != nil
+}
+
+func (rl *RateLimiter) maxCacheEntries() int {
+	if rl.MaxCacheEntries == 0 {
+		return DefaultMaxCacheEntries
+	}
+	return rl.MaxCacheEntries
+}
+
+func (rl *RateLimiter) janitorLoop(ctx context.Context) {
+	interval := rl.JanitorInterval
+	if interval == 0 {
+		interval = DefaultJanitorInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rl.sweep()
+		}
+	}
+}
+
+// sweep evicts expired entries from the local caches, so ids that are never seen
+// again don't stay in memory: cached blocks, idle write-behind counts, idle
+// anomaly detector ids, and temporary list entries, which are otherwise only
+// expired on access.
+func (rl *RateLimiter) sweep() {
+	now := rl.Clock.Now()
+	if n := rl.blocked.sweep(now); rl.BlockCache && n >= rl.maxCacheEntries() {
+		rl.Logger.Warn("block cache full", rl.fields("entries", n)...)
+	}
+	rl.buffer.sweep(now)
+	rl.anomalies.sweep(now)
+	rl.expireWhiteList()
+	rl.expireBlockList()
+}
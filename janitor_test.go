@@ -0,0 +1,60 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestJanitorBlockCache(t *testing.T) {
+	l := newTestLogger()
+	clock := NewFakeClock(time.UnixMilli(time.Now().UnixMilli()))
+	_, rl := testLimiter(t, "janitor", WithDuration(time.Minute), WithBlockTimes(2), WithBlockDuration(time.Minute),
+		WithClock(clock), WithBlockCache(), WithJanitor(time.Hour, 1), WithLogger(l))
+	for _, id := range []string{"a", "a", "b", "b"} {
+		rl.Allow(id)
+	}
+	if n := rl.blocked.sweep(clock.Now()); n != 1 {
+		t.Fatalf("%d cached blocks, want MaxCacheEntries 1", n)
+	}
+	rl.sweep()
+	if !l.logged("warn", "block cache full") {
+		t.Fatal("full block cache not reported")
+	}
+	clock.Advance(time.Minute)
+	rl.sweep()
+	if n := rl.blocked.sweep(clock.Now()); n != 0 {
+		t.Fatalf("%d ended blocks left after the sweep", n)
+	}
+}
+
+func TestJanitorWriteBehind(t *testing.T) {
+	clock := NewFakeClock(time.UnixMilli(time.Now().UnixMilli()))
+	_, rl := testLimiter(t, "janitor", WithDuration(time.Minute), WithBlockTimes(10), WithClock(clock),
+		WithWriteBehind(time.Hour), WithJanitor(time.Hour, 0))
+	rl.Allow("a")
+	rl.Allow("a")
+	size := func() int {
+		rl.buffer.mu.Lock()
+		defer rl.buffer.mu.Unlock()
+		return len(rl.buffer.counts)
+	}
+	clock.Advance(time.Minute)
+	rl.sweep()
+	if size() != 1 {
+		t.Fatal("count with pending units swept")
+	}
+	if err := rl.flushCounters(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// the flush restarts the count from the window Redis reports
+	rl.sweep()
+	if size() != 1 {
+		t.Fatal("count of a running window swept")
+	}
+	clock.Advance(time.Minute)
+	rl.sweep()
+	if size() != 0 {
+		t.Fatal("idle count kept after its window ended")
+	}
+}
@@ -0,0 +1,82 @@
+package rateLimiter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Keyer lets custom id types choose their own encoding.
+type Keyer interface {
+	RateLimitKey() string
+}
+
+type Id interface {
+	~string | ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// FormatId encodes strings as-is and integers in base 10, ignoring any String method
+// so that named types share the encoding of their underlying type.
+func FormatId[T Id](id T) string {
+	s, _ := formatKind(reflect.ValueOf(id))
+	return s
+}
+
+// KeyOf encodes Keyer, Id kinds and fmt.Stringer values (e.g. UUIDs), in that order.
+func KeyOf(id interface{}) (string, error) {
+	switch v := id.(type) {
+	case string:
+		return v, nil
+	case Keyer:
+		return v.RateLimitKey(), nil
+	}
+	if s, ok := formatKind(reflect.ValueOf(id)); ok {
+		return s, nil
+	}
+	if v, ok := id.(fmt.Stringer); ok {
+		return v.String(), nil
+	}
+	return "", &InvalidIdError{Reason: fmt.Sprintf("unsupported type %T", id)}
+}
+
+func formatKind(v reflect.Value) (string, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), true
+	default:
+		return "", false
+	}
+}
+
+func (rl *RateLimiter) CheckAny(id interface{}) (int, error) {
+	key, err := KeyOf(id)
+	if err != nil {
+		return 0, err
+	}
+	return rl.CheckCtx(context.Background(), key)
+}
+
+func (rl *RateLimiter) AllowAny(id interface{}) (*CheckResult, error) {
+	return rl.AllowAnyCtx(context.Background(), id)
+}
+
+func (rl *RateLimiter) AllowAnyCtx(ctx context.Context, id interface{}) (*CheckResult, error) {
+	key, err := KeyOf(id)
+	if err != nil {
+		return nil, err
+	}
+	return rl.AllowCtx(ctx, key)
+}
+
+func CheckId[T Id](rl *RateLimiter, id T) (int, error) {
+	return rl.CheckCtx(context.Background(), FormatId(id))
+}
+
+func AllowId[T Id](ctx context.Context, rl *RateLimiter, id T) (*CheckResult, error) {
+	return rl.AllowCtx(ctx, FormatId(id))
+}
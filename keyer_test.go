@@ -0,0 +1,56 @@
+package rateLimiter
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+)
+
+type userId int64
+
+func (u userId) String() string { return "user" }
+
+type tenantKey struct{ tenant, user string }
+
+func (k tenantKey) RateLimitKey() string { return k.tenant + "/" + k.user }
+
+type stringer struct{}
+
+func (stringer) String() string { return "stringer" }
+
+func TestKeyOf(t *testing.T) {
+	for _, tc := range []struct {
+		id   interface{}
+		want string
+	}{
+		{"a", "a"},
+		{42, "42"},
+		{uint8(7), "7"},
+		{userId(-3), "-3"},
+		{tenantKey{"t", "u"}, "t/u"},
+		{stringer{}, "stringer"},
+	} {
+		if got, err := KeyOf(tc.id); err != nil || got != tc.want {
+			t.Errorf("KeyOf(%#v) = %q, %v, want %q", tc.id, got, err, tc.want)
+		}
+	}
+	if _, err := KeyOf(1.5); !stderrors.Is(err, ErrorInvalidId) {
+		t.Errorf("KeyOf(1.5) = %v, want ErrorInvalidId", err)
+	}
+	if got := FormatId(userId(9)); got != "9" {
+		t.Errorf("FormatId used the String method: %q", got)
+	}
+}
+
+func TestCheckId(t *testing.T) {
+	_, rl := testLimiter(t, "keyer", WithDuration(time.Minute), WithBlockTimes(10))
+	if n, err := CheckId(rl, userId(7)); err != nil || n != 1 {
+		t.Fatalf("CheckId = %d, %v", n, err)
+	}
+	if n, err := rl.CheckAny(7); err != nil || n != 2 {
+		t.Fatalf("CheckAny shares the key of CheckId: %d, %v", n, err)
+	}
+	if n, err := rl.Check("7"); err != nil || n != 3 {
+		t.Fatalf("Check shares the key of CheckId: %d, %v", n, err)
+	}
+}
@@ -0,0 +1,56 @@
+package rateLimiter
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// keyspaceChannel returns the keyspace notification channel of key.
+func (rl *RateLimiter) keyspaceChannel(key string) string {
+	return "__keyspace@" + strconv.Itoa(rl.Redis.Options().DB) + "__:" + key
+}
+
+// keyspaceLoop reloads a list whenever its set or expiry keys change in Redis, and
+// the mode, limits and overrides whenever their keys do, so local caches follow
+// every writer without application-level Pub/Sub. Redis must have keyspace
+// notifications enabled for generic, string, hash, set and sorted set commands.
+func (rl *RateLimiter) keyspaceLoop(ctx context.Context) {
+	white := []string{rl.keyspaceChannel(rl.whiteListKey), rl.keyspaceChannel(rl.whiteTTLKey)}
+	block := []string{rl.keyspaceChannel(rl.blockListKey), rl.keyspaceChannel(rl.blockTTLKey)}
+	shared := []string{rl.keyspaceChannel(rl.modeKey), rl.keyspaceChannel(rl.limitsKey), rl.keyspaceChannel(rl.overrideKey)}
+	channels := append(append(white, block...), shared...)
+	ps := rl.Redis.Subscribe(ctx, channels...)
+	defer ps.Close()
+	if _, err := ps.Receive(ctx); err != nil {
+		if ctx.Err() == nil {
+			rl.Logger.Error("keyspace subscription failed", rl.fields("err", err)...)
+		}
+		return
+	}
+	//changes made before the subscription was active
+	rl.ResyncCtx(ctx)
+	ch := ps.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			switch {
+			case strings.HasSuffix(msg.Channel, ":"+rl.whiteListKey), strings.HasSuffix(msg.Channel, ":"+rl.whiteTTLKey):
+				rl.resyncList(ctx, rl.whiteIds, rl.Config.WhiteList, rl.whiteListKey, rl.whiteTTLKey)
+			case strings.HasSuffix(msg.Channel, ":"+rl.blockListKey), strings.HasSuffix(msg.Channel, ":"+rl.blockTTLKey):
+				rl.resyncBlockList(ctx)
+			case strings.HasSuffix(msg.Channel, ":"+rl.modeKey):
+				rl.loadMode(ctx)
+			case strings.HasSuffix(msg.Channel, ":"+rl.limitsKey):
+				rl.loadLimits(ctx)
+			case strings.HasSuffix(msg.Channel, ":"+rl.overrideKey):
+				rl.loadOverrides(ctx)
+			}
+		}
+	}
+}
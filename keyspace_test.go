@@ -0,0 +1,38 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyspaceSync(t *testing.T) {
+	mr, rl := testLimiter(t, "keyspace", WithDuration(time.Minute), WithBlockTimes(10), WithKeyspaceSync())
+	if got, want := rl.keyspaceChannel("keyspace-block"), "__keyspace@0__:keyspace-block"; got != want {
+		t.Fatalf("keyspaceChannel = %q, want %q", got, want)
+	}
+	waitFor := func(cond func() bool, what string) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatal(what)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	// wait for the subscription before changing the lists
+	waitFor(func() bool { return len(mr.PubSubChannels("__keyspace@0__:*")) == 7 }, "keyspace channels not subscribed")
+
+	// miniredis doesn't emit keyspace notifications, publish them by hand
+	mr.SAdd("keyspace-block", "a")
+	mr.Publish("__keyspace@0__:keyspace-block", "sadd")
+	waitFor(func() bool { return rl.blockIds.has("a") }, "block list not reloaded on notification")
+
+	mr.SAdd("keyspace-white", "b")
+	mr.Publish("__keyspace@0__:keyspace-white-ttl", "zadd")
+	waitFor(func() bool { return rl.whiteIds.has("b") }, "white list not reloaded on notification")
+
+	mr.Set(rl.modeKey, ModeBlockAll.String())
+	mr.Publish(rl.keyspaceChannel(rl.modeKey), "set")
+	waitFor(func() bool { return rl.Mode() == ModeBlockAll }, "mode not reloaded on notification")
+}
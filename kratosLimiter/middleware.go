@@ -0,0 +1,171 @@
+// Package kratosLimiter adapts rateLimiter to go-kratos server middleware. It is
+// a module of its own so that the rate-limiter module doesn't depend on Kratos.
+package kratosLimiter
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/rate-limiter/httpLimiter"
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	khttp "github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/peer"
+)
+
+// Reason is the reason of the errors returned for blocked requests, the one of
+// the Kratos ratelimit middleware.
+const Reason = "RATELIMIT"
+
+var ErrorNoKey = errors.New("no rate limit key")
+
+// KeyFunc extracts the id to rate limit from an incoming request.
+type KeyFunc func(ctx context.Context, tr transport.Transporter) (string, error)
+
+// PeerAddr uses the host of the remote address, for both the HTTP and the gRPC
+// transports.
+func PeerAddr(ctx context.Context, tr transport.Transporter) (string, error) {
+	var addr string
+	if ht, ok := tr.(khttp.Transporter); ok {
+		addr = ht.Request().RemoteAddr
+	} else if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		addr = p.Addr.String()
+	}
+	if addr == "" {
+		return "", ErrorNoKey
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, nil
+	}
+	return host, nil
+}
+
+// Header uses the value of the request header name, HTTP header or gRPC
+// metadata, e.g. an API key or the client IP set by a trusted proxy.
+func Header(name string) KeyFunc {
+	return func(ctx context.Context, tr transport.Transporter) (string, error) {
+		if v := tr.RequestHeader().Get(name); v != "" {
+			return v, nil
+		}
+		return "", ErrorNoKey
+	}
+}
+
+// Operation uses the operation name, limiting each operation as a whole.
+func Operation(ctx context.Context, tr transport.Transporter) (string, error) {
+	return tr.Operation(), nil
+}
+
+type config struct {
+	key          KeyFunc
+	perOperation bool
+	skipper      func(ctx context.Context, operation string) bool
+	bypassHeader string
+}
+
+type Option func(*config)
+
+// WithKeyFunc sets how the id is extracted; the default is PeerAddr.
+func WithKeyFunc(fn KeyFunc) Option {
+	return func(c *config) {
+		c.key = fn
+	}
+}
+
+// WithPerOperation counts every operation separately by prefixing the id with it.
+func WithPerOperation() Option {
+	return func(c *config) {
+		c.perOperation = true
+	}
+}
+
+// WithSkipper lets requests for which fn returns true through unchecked.
+func WithSkipper(fn func(ctx context.Context, operation string) bool) Option {
+	return func(c *config) {
+		c.skipper = fn
+	}
+}
+
+// WithBypassHeader reads bypass tokens issued by the limiter from the request
+// header.
+func WithBypassHeader(header string) Option {
+	return func(c *config) {
+		c.bypassHeader = header
+	}
+}
+
+// Server rate limits the requests of the Kratos HTTP and gRPC servers it is
+// passed to, e.g. with http.Middleware or grpc.Middleware. Rejections are 429
+// errors, ResourceExhausted over gRPC, with reason Reason; the rate limit headers,
+// Retry-After among them, go to the reply header.
+func Server(rl *rateLimiter.RateLimiter, opts ...Option) middleware.Middleware {
+	c := &config{key: PeerAddr}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return func(next middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return next(ctx, req)
+			}
+			if err := c.check(ctx, rl, tr); err != nil {
+				return nil, err
+			}
+			return next(ctx, req)
+		}
+	}
+}
+
+func (c *config) check(ctx context.Context, rl *rateLimiter.RateLimiter, tr transport.Transporter) error {
+	if c.skipper != nil && c.skipper(ctx, tr.Operation()) {
+		return nil
+	}
+	id, err := c.key(ctx, tr)
+	if err == nil && id == "" {
+		err = ErrorNoKey
+	}
+	if err != nil {
+		return kerrors.BadRequest(Reason, err.Error()).WithCause(err)
+	}
+	if c.perOperation {
+		id = tr.Operation() + " " + id
+	}
+	if c.bypassHeader != "" {
+		if token := tr.RequestHeader().Get(c.bypassHeader); token != "" {
+			ctx = rateLimiter.WithBypassToken(ctx, token)
+		}
+	}
+	res, err := rl.AllowCtx(ctx, id)
+	for k, v := range httpLimiter.Headers(res) {
+		tr.ReplyHeader().Set(k, v)
+	}
+	if res != nil && !res.Allowed {
+		return blockedError(res, err)
+	}
+	if errors.Is(err, rateLimiter.ErrorInvalidId) {
+		return kerrors.BadRequest(Reason, err.Error()).WithCause(err)
+	}
+	if err != nil {
+		return kerrors.ServiceUnavailable(Reason, err.Error()).WithCause(err)
+	}
+	return nil
+}
+
+// blockedError is a 429 error whose metadata carries the rate limit headers of
+// res, for clients reading the error rather than the reply header.
+func blockedError(res *rateLimiter.CheckResult, err error) error {
+	msg := "rate limit exceeded"
+	if err != nil {
+		msg = err.Error()
+	}
+	kerr := kerrors.New(429, Reason, msg).WithMetadata(httpLimiter.Headers(res))
+	if err != nil {
+		kerr = kerr.WithCause(err)
+	}
+	return kerr
+}
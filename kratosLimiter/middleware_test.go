@@ -0,0 +1,85 @@
+package kratosLimiter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/redis"
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type headerCarrier http.Header
+
+func (h headerCarrier) Get(key string) string      { return http.Header(h).Get(key) }
+func (h headerCarrier) Set(key, value string)      { http.Header(h).Set(key, value) }
+func (h headerCarrier) Add(key, value string)      { http.Header(h).Add(key, value) }
+func (h headerCarrier) Values(key string) []string { return http.Header(h).Values(key) }
+func (h headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type testTransport struct {
+	operation string
+	req, rep  headerCarrier
+}
+
+func (t *testTransport) Kind() transport.Kind            { return transport.KindGRPC }
+func (t *testTransport) Endpoint() string                { return "" }
+func (t *testTransport) Operation() string               { return t.operation }
+func (t *testTransport) RequestHeader() transport.Header { return t.req }
+func (t *testTransport) ReplyHeader() transport.Header   { return t.rep }
+
+func TestServer(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	rl, err := rateLimiter.NewLimiter("kratos", rateLimiter.WithRedis(&redis.Redis{Client: client}),
+		rateLimiter.WithDuration(time.Minute), rateLimiter.WithBlockTimes(3), rateLimiter.WithBlockDuration(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rl.Close(context.Background()) })
+
+	calls := 0
+	handler := Server(rl, WithKeyFunc(Header("x-api-key")))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return "ok", nil
+	})
+	call := func(key string) (*testTransport, error) {
+		tr := &testTransport{operation: "/test.Service/Call", req: headerCarrier{}, rep: headerCarrier{}}
+		if key != "" {
+			tr.req.Set("x-api-key", key)
+		}
+		_, err := handler(transport.NewServerContext(context.Background(), tr), nil)
+		return tr, err
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := call("k"); err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+	}
+	tr, err := call("k")
+	if e := kerrors.FromError(err); e == nil || e.Code != 429 || e.Reason != Reason {
+		t.Fatalf("third call: %v, want a 429 %s error", err, Reason)
+	}
+	if tr.rep.Get("Retry-After") == "" {
+		t.Fatal("Retry-After not set on the reply header")
+	}
+	if _, err := call(""); !kerrors.IsBadRequest(err) {
+		t.Fatalf("call without key: %v, want bad request", err)
+	}
+	if calls != 2 {
+		t.Fatalf("handler called %d times, want 2", calls)
+	}
+}
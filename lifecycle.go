@@ -0,0 +1,83 @@
+package rateLimiter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// closeFlushTimeout bounds the flushes of Close when its ctx leaves less time.
+const closeFlushTimeout = 5 * time.Second
+
+type lifecycle struct {
+	once    sync.Once
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	flushes []func(context.Context) error
+}
+
+func (l *lifecycle) init() {
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+}
+
+// goBackground runs fn until Close is called; fn must return once ctx is done.
+func (rl *RateLimiter) goBackground(fn func(ctx context.Context)) {
+	rl.lc.wg.Add(1)
+	go func() {
+		defer rl.lc.wg.Done()
+		fn(rl.lc.ctx)
+	}()
+}
+
+// onClose registers fn to flush pending state when the limiter is closed.
+func (rl *RateLimiter) onClose(fn func(ctx context.Context) error) {
+	rl.lc.mu.Lock()
+	rl.lc.flushes = append(rl.lc.flushes, fn)
+	rl.lc.mu.Unlock()
+}
+
+// Close stops background goroutines and flushes pending state. The flushes run
+// even if ctx is done, for up to closeFlushTimeout, so that buffered counts and
+// queued blocks aren't lost. It is safe to call more than once; only the first
+// call does any work.
+func (rl *RateLimiter) Close(ctx context.Context) error {
+	var err error
+	rl.lc.once.Do(func() {
+		rl.lc.cancel()
+		done := make(chan struct{})
+		go func() {
+			rl.lc.wg.Wait()
+			close(done)
+		}()
+		var errs []error
+		select {
+		case <-done:
+		case <-ctx.Done():
+			errs = append(errs, ctx.Err())
+		}
+		rl.lc.mu.Lock()
+		flushes := rl.lc.flushes
+		rl.lc.mu.Unlock()
+		flushCtx, cancel := flushContext(ctx)
+		defer cancel()
+		for _, flush := range flushes {
+			if e := flush(flushCtx); e != nil {
+				errs = append(errs, e)
+			}
+		}
+		err = joinErrors(errs)
+	})
+	return err
+}
+
+// flushContext keeps the values of ctx but not its cancellation, with the
+// deadline of ctx or closeFlushTimeout, whichever is later.
+func flushContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := closeFlushTimeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) > timeout {
+		timeout = time.Until(deadline)
+	}
+	return context.WithTimeout(context.WithoutCancel(ctx), timeout)
+}
@@ -0,0 +1,72 @@
+package rateLimiter
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	_, rl := testLimiter(t, "close", WithDuration(time.Minute))
+	stopped := make(chan struct{})
+	rl.goBackground(func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+	flushes := 0
+	rl.onClose(func(ctx context.Context) error {
+		select {
+		case <-stopped:
+		default:
+			t.Error("flush ran before the background goroutines stopped")
+		}
+		flushes++
+		return stderrors.New("flush failed")
+	})
+	if err := rl.Close(context.Background()); err == nil || err.Error() != "flush failed" {
+		t.Fatalf("Close = %v, want the flush error", err)
+	}
+	if err := rl.Close(context.Background()); err != nil || flushes != 1 {
+		t.Fatalf("second Close = %v, %d flushes", err, flushes)
+	}
+}
+
+func TestManagerCloseAll(t *testing.T) {
+	_, r := testRedis(t)
+	m := NewManager(&Config{Redis: r, Duration: time.Minute})
+	a, err := m.Get("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := false
+	a.onClose(func(context.Context) error {
+		closed = true
+		return nil
+	})
+	if err := m.CloseAll(context.Background()); err != nil || !closed {
+		t.Fatalf("CloseAll = %v, closed %v", err, closed)
+	}
+	if b, _ := m.Get("a"); b == a {
+		t.Fatal("Get returned a closed limiter")
+	}
+}
+
+func TestCloseFlushesWithDoneContext(t *testing.T) {
+	_, rl := testLimiter(t, "close", WithDuration(time.Minute), WithBlockTimes(100), WithWriteBehind(time.Hour))
+	for i := 0; i < 3; i++ {
+		if _, err := rl.Check("a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rl.Close(ctx)
+	n, err := rl.Redis.Get(context.Background(), rl.counterKey("a")).Int()
+	if err != nil || n != 3 {
+		t.Fatalf("counter %d, %v after Close, want 3", n, err)
+	}
+	if err := rl.Close(context.Background()); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package rateLimiter
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Limits are the settings UpdateConfig changes on a live limiter. Config keeps
+// the values the limiter was created with.
+type Limits struct {
+	Duration      time.Duration `json:"duration"`
+	BlockTimes    int           `json:"blockTimes"`
+	BlockDuration time.Duration `json:"blockDuration"` //0=ever
+}
+
+func (l Limits) validate() error {
+	if l.Duration <= 0 {
+		return stderrors.New("Duration必须大于0")
+	}
+	if l.BlockTimes < 0 {
+		return stderrors.New("BlockTimes不能小于0")
+	}
+	if l.BlockDuration < 0 {
+		return stderrors.New("BlockDuration不能小于0")
+	}
+	return nil
+}
+
+// Limits returns the limits in effect.
+func (rl *RateLimiter) Limits() Limits {
+	return *rl.limits.Load()
+}
+
+// loadLimits applies the limits stored by UpdateConfig, or Config when there are none.
+func (rl *RateLimiter) loadLimits(ctx context.Context) error {
+	val, err := rl.Redis.Get(ctx, rl.limitsKey).Result()
+	if err == goredis.Nil {
+		rl.setLimits(Limits{Duration: rl.Duration, BlockTimes: rl.BlockTimes, BlockDuration: rl.BlockDuration})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var l Limits
+	if err := json.Unmarshal([]byte(val), &l); err != nil {
+		return err
+	}
+	if err := l.validate(); err != nil {
+		return err
+	}
+	rl.setLimits(l)
+	return nil
+}
+
+// setLimits stores l, dropping the cached blocks only when the limits changed, so
+// that periodic resyncs keep the cache.
+func (rl *RateLimiter) setLimits(l Limits) {
+	if cur := rl.limits.Load(); cur != nil && *cur == l {
+		return
+	}
+	rl.limits.Store(&l)
+	rl.blocked.reset()
+}
+
+func (rl *RateLimiter) UpdateConfig(l Limits, pub bool) error {
+	return rl.UpdateConfigCtx(context.Background(), l, pub)
+}
+
+// UpdateConfigCtx replaces the limits of a live limiter. They are stored in Redis,
+// so they also apply to instances started later, until ResetConfig. Counters
+// already running keep their TTL.
+func (rl *RateLimiter) UpdateConfigCtx(ctx context.Context, l Limits, pub bool) error {
+	if err := l.validate(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+	if err := rl.Redis.Set(ctx, rl.limitsKey, data, 0).Err(); err != nil {
+		return err
+	}
+	rl.limits.Store(&l)
+	rl.blocked.reset()
+	rl.Logger.Info("config updated", rl.fields("duration", l.Duration, "blockTimes", l.BlockTimes, "blockDuration", l.BlockDuration)...)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: "uc"})
+	}
+	return nil
+}
+
+func (rl *RateLimiter) SetLimit(blockTimes int, duration time.Duration, pub bool) error {
+	return rl.SetLimitCtx(context.Background(), blockTimes, duration, pub)
+}
+
+// SetLimitCtx changes BlockTimes and Duration on every instance, keeping the
+// current BlockDuration; see UpdateConfigCtx.
+func (rl *RateLimiter) SetLimitCtx(ctx context.Context, blockTimes int, duration time.Duration, pub bool) error {
+	l := rl.Limits()
+	l.BlockTimes = blockTimes
+	l.Duration = duration
+	return rl.UpdateConfigCtx(ctx, l, pub)
+}
+
+func (rl *RateLimiter) ResetConfig(pub bool) error {
+	return rl.ResetConfigCtx(context.Background(), pub)
+}
+
+// ResetConfigCtx drops the limits set by UpdateConfig and goes back to Config.
+func (rl *RateLimiter) ResetConfigCtx(ctx context.Context, pub bool) error {
+	if err := rl.Redis.Del(ctx, rl.limitsKey).Err(); err != nil {
+		return err
+	}
+	rl.limits.Store(&Limits{Duration: rl.Duration, BlockTimes: rl.BlockTimes, BlockDuration: rl.BlockDuration})
+	rl.blocked.reset()
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: "uc"})
+	}
+	return nil
+}
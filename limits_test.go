@@ -0,0 +1,77 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestUpdateConfig(t *testing.T) {
+	_, r := testRedis(t)
+	var b *RateLimiter
+	opts := []Option{WithRedis(r), WithDuration(time.Minute), WithBlockTimes(2), WithBlockDuration(time.Minute),
+		WithPub(func(name, message string) error { return b.Sub(message) })}
+	a, err := NewLimiter("limits", append(opts, func(c *Config) { c.InstanceId = "a" })...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.Close(context.Background()) })
+	b, err = NewLimiter("limits", append(opts, func(c *Config) { c.InstanceId = "b" })...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close(context.Background()) })
+
+	l := Limits{Duration: time.Hour, BlockTimes: 3, BlockDuration: 0}
+	if err := a.UpdateConfig(l, true); err != nil {
+		t.Fatal(err)
+	}
+	if a.Limits() != l || b.Limits() != l {
+		t.Fatalf("limits %+v and %+v, want %+v", a.Limits(), b.Limits(), l)
+	}
+	if a.BlockTimes != 2 {
+		t.Fatalf("Config changed to %d", a.BlockTimes)
+	}
+	for i := 0; i < 2; i++ {
+		if res, _ := a.Allow("x"); !res.Allowed {
+			t.Fatalf("request %d rejected", i+1)
+		}
+	}
+	if res, _ := a.Allow("x"); res.Allowed || res.Limit != 3 {
+		t.Fatalf("third request %+v", res)
+	}
+
+	// instances started later use the stored limits
+	c, err := NewLimiter("limits", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close(context.Background()) })
+	if c.Limits() != l {
+		t.Fatalf("new instance limits %+v", c.Limits())
+	}
+
+	if err := a.ResetConfig(true); err != nil {
+		t.Fatal(err)
+	}
+	want := Limits{Duration: time.Minute, BlockTimes: 2, BlockDuration: time.Minute}
+	if a.Limits() != want || b.Limits() != want {
+		t.Fatalf("limits after reset %+v and %+v", a.Limits(), b.Limits())
+	}
+	if err := a.UpdateConfig(Limits{BlockTimes: 3}, false); err == nil {
+		t.Fatal("zero Duration accepted")
+	}
+}
+
+func TestSetLimit(t *testing.T) {
+	_, rl := testLimiter(t, "setLimit", WithDuration(time.Minute), WithBlockTimes(2), WithBlockDuration(time.Hour))
+	if err := rl.SetLimit(5, time.Second, false); err != nil {
+		t.Fatal(err)
+	}
+	if l := rl.Limits(); l != (Limits{Duration: time.Second, BlockTimes: 5, BlockDuration: time.Hour}) {
+		t.Fatalf("limits %+v", l)
+	}
+	if err := rl.SetLimit(-1, time.Second, false); err == nil {
+		t.Fatal("negative BlockTimes accepted")
+	}
+}
@@ -0,0 +1,298 @@
+package rateLimiter
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// idList is the local cache of a white or block list. Exact ids live in a set;
+// CIDR and wildcard entries are additionally indexed for matching.
+type idList struct {
+	applyMu sync.Mutex //serializes mutations, see apply
+	mu      sync.RWMutex
+	ids     map[string]struct{}
+	nets    prefixTrie
+	globs   globSet
+	expiry  expirySet
+}
+
+func newIdList() *idList {
+	return &idList{ids: make(map[string]struct{})}
+}
+
+// apply runs fn while no other mutation of l runs. Every add, remove, sync,
+// resync, clear and expiry of the list goes through it, making its Redis change
+// or reading Redis inside fn before changing l, so that Redis and l see
+// concurrent mutations in the same order. Checks only wait for the local change.
+// fn must not call hooks: they may mutate the list again.
+func (l *idList) apply(fn func() error) error {
+	l.applyMu.Lock()
+	defer l.applyMu.Unlock()
+	return fn()
+}
+
+// expire removes the entries expired at now and returns them. If a mutation is
+// running it returns nil instead, leaving them to a later call, so that checks
+// never wait for its Redis round trip.
+func (l *idList) expire(now time.Time) []string {
+	if !l.applyMu.TryLock() {
+		return nil
+	}
+	defer l.applyMu.Unlock()
+	return l.removeAll(l.expiry.due(now))
+}
+
+// add reports whether id was not in the list yet.
+func (l *idList) add(id string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.addLocked(id)
+}
+
+func (l *idList) addLocked(id string) bool {
+	if _, ok := l.ids[id]; ok {
+		return false
+	}
+	l.ids[id] = struct{}{}
+	l.nets.insert(id)
+	l.globs.insert(id)
+	return true
+}
+
+// addPermanent adds id, or makes a temporary entry of it permanent, in one step.
+// It reports whether the list changed.
+func (l *idList) addPermanent(id string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, temporary := l.expiry.get(id)
+	l.expiry.del(id)
+	return l.addLocked(id) || temporary
+}
+
+// addTemporary adds id until at, or moves the expiry of an existing temporary
+// entry, in one step. It leaves a permanent entry alone and reports false then.
+func (l *idList) addTemporary(id string, at time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, temporary := l.expiry.get(id); !temporary && !l.addLocked(id) {
+		return false
+	}
+	l.expiry.set(id, at)
+	return true
+}
+
+// permanent reports whether id is in the list without an expiry.
+func (l *idList) permanent(id string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, temporary := l.expiry.get(id)
+	_, ok := l.ids[id]
+	return ok && !temporary
+}
+
+// remove reports whether id was in the list.
+func (l *idList) remove(id string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expiry.del(id)
+	if _, ok := l.ids[id]; !ok {
+		return false
+	}
+	delete(l.ids, id)
+	l.nets.remove(id)
+	l.globs.remove(id)
+	return true
+}
+
+// addAll adds ids as permanent entries and returns the ones that changed.
+func (l *idList) addAll(ids []string) []string {
+	var added []string
+	for _, id := range ids {
+		if l.addPermanent(id) {
+			added = append(added, id)
+		}
+	}
+	return added
+}
+
+// addToList adds id permanently to the Redis set setKey, dropping a TTL from ttlKey
+// in the same transaction, and then to l. It reports whether the entry changed in
+// Redis or locally; nothing is changed locally if Redis fails.
+func (rl *RateLimiter) addToList(ctx context.Context, l *idList, setKey, ttlKey, id string) (changed bool, err error) {
+	err = l.apply(func() error {
+		var sadd, zrem *goredis.IntCmd
+		_, err := rl.Redis.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+			sadd = pipe.SAdd(ctx, setKey, id)
+			zrem = pipe.ZRem(ctx, ttlKey, id)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		changed = l.addPermanent(id) || sadd.Val() == 1 || zrem.Val() == 1
+		return nil
+	})
+	return changed, err
+}
+
+// addToLocalList adds id permanently to l only, for a change another instance
+// already made in Redis. It reports whether l changed.
+func addToLocalList(l *idList, id string) (changed bool) {
+	l.apply(func() error {
+		changed = l.addPermanent(id)
+		return nil
+	})
+	return changed
+}
+
+// removeFromLocalList is addToLocalList for a removal.
+func removeFromLocalList(l *idList, id string) (removed bool) {
+	l.apply(func() error {
+		removed = l.remove(id)
+		return nil
+	})
+	return removed
+}
+
+// removeFromList removes id from the Redis set setKey, its TTL from ttlKey and its
+// metadata from metaKey, and then from l. It reports whether l listed id.
+func (rl *RateLimiter) removeFromList(ctx context.Context, l *idList, setKey, ttlKey, metaKey, id string) (removed bool, err error) {
+	err = l.apply(func() error {
+		if err := rl.Redis.SRem(ctx, setKey, id).Err(); err != nil {
+			return err
+		}
+		rl.Redis.ZRem(ctx, ttlKey, id)
+		rl.Redis.HDel(ctx, metaKey, id)
+		removed = l.remove(id)
+		return nil
+	})
+	return removed, err
+}
+
+// removeAll removes ids and returns the ones that were listed.
+func (l *idList) removeAll(ids []string) []string {
+	var removed []string
+	for _, id := range ids {
+		if l.remove(id) {
+			removed = append(removed, id)
+		}
+	}
+	return removed
+}
+
+func (l *idList) reset(ids []string) {
+	l.replace(ids, nil)
+}
+
+// has reports exact membership.
+func (l *idList) has(id string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.ids[id]
+	return ok
+}
+
+// match reports whether id is listed exactly or covered by a CIDR or wildcard entry.
+func (l *idList) match(id string) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if _, ok := l.ids[id]; ok {
+		return true
+	}
+	return l.nets.match(id) || l.globs.match(id)
+}
+
+func (l *idList) list() []string {
+	l.mu.RLock()
+	ids := make([]string, 0, len(l.ids))
+	for id := range l.ids {
+		ids = append(ids, id)
+	}
+	l.mu.RUnlock()
+	sort.Strings(ids)
+	return ids
+}
+
+// replace swaps the whole content of the list, expiries included, in one step,
+// and returns the ids it added and removed.
+func (l *idList) replace(ids []string, expiry map[string]time.Time) (added, removed []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	old := l.ids
+	l.ids = make(map[string]struct{}, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := l.ids[id]; !ok {
+			l.ids[id] = struct{}{}
+			unique = append(unique, id)
+			if _, ok := old[id]; !ok {
+				added = append(added, id)
+			}
+		}
+	}
+	for id := range old {
+		if _, ok := l.ids[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	l.nets.reset(unique)
+	l.globs.reset(unique)
+	l.expiry.reset()
+	for id, at := range expiry {
+		l.expiry.set(id, at)
+	}
+	return added, removed
+}
+
+func (l *idList) size() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.ids)
+}
+
+// loadList merges the members of setKey into l, dropping expired temporary
+// entries first so they are not loaded without their expiry.
+func (rl *RateLimiter) loadList(ctx context.Context, l *idList, setKey, ttlKey string) error {
+	var purged []string
+	err := l.apply(func() error {
+		var err error
+		if purged, err = rl.purgeExpiredIds(ctx, setKey, ttlKey); err != nil {
+			return err
+		}
+		ids, err := rl.Redis.SMembers(ctx, setKey).Result()
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			l.add(id)
+		}
+		rl.synced()
+		return rl.loadExpiry(ctx, ttlKey, &l.expiry)
+	})
+	rl.reportPurged(setKey, purged)
+	return err
+}
+
+func (rl *RateLimiter) loadWhiteList(ctx context.Context) error {
+	return rl.loadList(ctx, rl.whiteIds, rl.whiteListKey, rl.whiteTTLKey)
+}
+
+func (rl *RateLimiter) loadBlockList(ctx context.Context) error {
+	if rl.BloomBlockList {
+		return rl.loadBloomBlockList(ctx)
+	}
+	return rl.loadList(ctx, rl.blockIds, rl.blockListKey, rl.blockTTLKey)
+}
+
+// resyncBlockList is resyncList for the block list; with BloomBlockList it
+// rebuilds the filter instead.
+func (rl *RateLimiter) resyncBlockList(ctx context.Context) error {
+	if rl.BloomBlockList {
+		return rl.loadBloomBlockList(ctx)
+	}
+	return rl.resyncList(ctx, rl.blockIds, rl.Config.BlockList, rl.blockListKey, rl.blockTTLKey)
+}
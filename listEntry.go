@@ -0,0 +1,130 @@
+package rateLimiter
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// AutoBlockOperator is recorded as the operator of entries added by Check itself.
+const AutoBlockOperator = "rateLimiter"
+
+type ListEntry struct {
+	Id        string    `json:"id"`
+	Reason    string    `json:"reason,omitempty"`
+	Operator  string    `json:"operator,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"` //zero for permanent entries
+}
+
+func (rl *RateLimiter) setEntryMeta(ctx context.Context, metaKey string, entry *ListEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = rl.Clock.Now()
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return rl.Redis.HSet(ctx, metaKey, entry.Id, data).Err()
+}
+
+func (rl *RateLimiter) getEntries(ctx context.Context, metaKey string, ids []string, expiry *expirySet) ([]ListEntry, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	vals, err := rl.Redis.HMGet(ctx, metaKey, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]ListEntry, len(ids))
+	for i, id := range ids {
+		if s, ok := vals[i].(string); ok {
+			json.Unmarshal([]byte(s), &entries[i])
+		}
+		entries[i].Id = id
+		entries[i].ExpiresAt, _ = expiry.get(id)
+	}
+	return entries, nil
+}
+
+func (rl *RateLimiter) AddWhiteListEntry(entry ListEntry, ttl time.Duration, pub bool) error {
+	return rl.AddWhiteListEntryCtx(context.Background(), entry, ttl, pub)
+}
+
+// AddWhiteListEntryCtx whitelists entry.Id and records its reason and operator;
+// ttl 0 adds a permanent entry.
+func (rl *RateLimiter) AddWhiteListEntryCtx(ctx context.Context, entry ListEntry, ttl time.Duration, pub bool) error {
+	id, err := rl.sanitizeId(entry.Id)
+	if err != nil {
+		return err
+	}
+	entry.Id = id
+	return rl.addWhiteListEntry(ctx, entry, ttl, pub)
+}
+
+// addWhiteListEntry is AddWhiteListEntryCtx for an entry whose id is already sanitized.
+func (rl *RateLimiter) addWhiteListEntry(ctx context.Context, entry ListEntry, ttl time.Duration, pub bool) error {
+	ctx, cancel := rl.listContext(ctx)
+	defer cancel()
+	if entry.Operator != "" {
+		ctx = WithOperator(ctx, entry.Operator)
+	}
+	ctx = withReason(ctx, entry.Reason)
+	if err := rl.setEntryMeta(ctx, rl.whiteMetaKey, &entry); err != nil {
+		return err
+	}
+	if ttl > 0 {
+		return rl.addWhiteListTTL(ctx, entry.Id, ttl, pub)
+	}
+	return rl.addWhiteList(ctx, entry.Id, pub)
+}
+
+func (rl *RateLimiter) AddBlockListEntry(entry ListEntry, ttl time.Duration, pub bool) error {
+	return rl.AddBlockListEntryCtx(context.Background(), entry, ttl, pub)
+}
+
+// AddBlockListEntryCtx blocklists entry.Id and records its reason and operator;
+// ttl 0 adds a permanent entry.
+func (rl *RateLimiter) AddBlockListEntryCtx(ctx context.Context, entry ListEntry, ttl time.Duration, pub bool) error {
+	id, err := rl.sanitizeId(entry.Id)
+	if err != nil {
+		return err
+	}
+	entry.Id = id
+	return rl.addBlockListEntry(ctx, entry, ttl, pub)
+}
+
+// addBlockListEntry is AddBlockListEntryCtx for an entry whose id is already sanitized.
+func (rl *RateLimiter) addBlockListEntry(ctx context.Context, entry ListEntry, ttl time.Duration, pub bool) error {
+	ctx, cancel := rl.listContext(ctx)
+	defer cancel()
+	if entry.Operator != "" {
+		ctx = WithOperator(ctx, entry.Operator)
+	}
+	ctx = withReason(ctx, entry.Reason)
+	if err := rl.setEntryMeta(ctx, rl.blockMetaKey, &entry); err != nil {
+		return err
+	}
+	if ttl > 0 {
+		return rl.addBlockListTTL(ctx, entry.Id, ttl, pub)
+	}
+	return rl.addBlockList(ctx, entry.Id, pub)
+}
+
+// GetWhiteListEntries is GetWhiteList with the metadata of every entry.
+func (rl *RateLimiter) GetWhiteListEntries(ctx context.Context, id interface{}) ([]ListEntry, error) {
+	ids, err := rl.GetWhiteList(id)
+	if err != nil {
+		return nil, err
+	}
+	return rl.getEntries(ctx, rl.whiteMetaKey, ids, &rl.whiteIds.expiry)
+}
+
+// GetBlockListEntries is GetBlockList with the metadata of every entry.
+func (rl *RateLimiter) GetBlockListEntries(ctx context.Context, id interface{}) ([]ListEntry, error) {
+	ids, err := rl.GetBlockList(id)
+	if err != nil {
+		return nil, err
+	}
+	return rl.getEntries(ctx, rl.blockMetaKey, ids, &rl.blockIds.expiry)
+}
@@ -0,0 +1,40 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestListEntries(t *testing.T) {
+	clock := NewFakeClock(time.UnixMilli(time.Now().UnixMilli()))
+	_, rl := testLimiter(t, "entries", WithDuration(time.Minute), WithBlockTimes(1), WithClock(clock))
+	ctx := context.Background()
+	err := rl.AddBlockListEntry(ListEntry{Id: "a", Reason: "abuse", Operator: "alice"}, time.Hour, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := rl.GetBlockListEntries(ctx, "a")
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("entries %v, %v", entries, err)
+	}
+	e := entries[0]
+	if e.Id != "a" || e.Reason != "abuse" || e.Operator != "alice" ||
+		!e.CreatedAt.Equal(clock.Now()) || !e.ExpiresAt.Equal(clock.Now().Add(time.Hour)) {
+		t.Fatalf("entry %+v", e)
+	}
+
+	// reaching BlockTimes with BlockDuration 0 records the limiter as the operator
+	rl.Check("b")
+	entries, _ = rl.GetBlockListEntries(ctx, "b")
+	if len(entries) != 1 || entries[0].Operator != AutoBlockOperator || !entries[0].ExpiresAt.IsZero() {
+		t.Fatalf("auto-block entry %+v", entries)
+	}
+
+	if err := rl.RemoveBlockList("a", false); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := rl.Redis.HLen(ctx, rl.blockMetaKey).Result(); n != 1 {
+		t.Fatalf("%d metadata entries left, want 1", n)
+	}
+}
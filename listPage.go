@@ -0,0 +1,35 @@
+package rateLimiter
+
+import "context"
+
+const DefaultListPageSize = 100
+
+type ListPage struct {
+	Ids    []string
+	Cursor uint64 //pass back to get the next page, 0 when the scan is complete
+}
+
+// ScanWhiteList pages through the whitelist stored in Redis with SSCAN. Static
+// Config.WhiteList entries are not stored in Redis and are not returned.
+// pageSize is a hint, as with SSCAN COUNT.
+func (rl *RateLimiter) ScanWhiteList(ctx context.Context, cursor uint64, prefix string, pageSize int64) (*ListPage, error) {
+	return rl.scanList(ctx, rl.whiteListKey, cursor, prefix, pageSize)
+}
+
+// ScanBlockList pages through the block list stored in Redis with SSCAN. Static
+// Config.BlockList entries are not stored in Redis and are not returned.
+// pageSize is a hint, as with SSCAN COUNT.
+func (rl *RateLimiter) ScanBlockList(ctx context.Context, cursor uint64, prefix string, pageSize int64) (*ListPage, error) {
+	return rl.scanList(ctx, rl.blockListKey, cursor, prefix, pageSize)
+}
+
+func (rl *RateLimiter) scanList(ctx context.Context, key string, cursor uint64, prefix string, pageSize int64) (*ListPage, error) {
+	if pageSize <= 0 {
+		pageSize = DefaultListPageSize
+	}
+	ids, next, err := rl.Redis.SScan(ctx, key, cursor, escapeGlob(prefix)+"*", pageSize).Result()
+	if err != nil {
+		return nil, err
+	}
+	return &ListPage{Ids: ids, Cursor: next}, nil
+}
@@ -0,0 +1,39 @@
+package rateLimiter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestScanBlockList(t *testing.T) {
+	mr, rl := testLimiter(t, "page", WithDuration(time.Minute), WithBlockList("user-static"))
+	for i := 0; i < 30; i++ {
+		mr.SetAdd("page-block", fmt.Sprintf("user-%02d", i), fmt.Sprintf("bot-%02d", i))
+	}
+	mr.SetAdd("page-block", "user*x")
+	ctx := context.Background()
+	var ids []string
+	var cursor uint64
+	for {
+		page, err := rl.ScanBlockList(ctx, cursor, "user-", 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, page.Ids...)
+		if cursor = page.Cursor; cursor == 0 {
+			break
+		}
+	}
+	sort.Strings(ids)
+	if len(ids) != 30 || ids[0] != "user-00" || ids[29] != "user-29" {
+		t.Fatalf("scanned %d ids: %v", len(ids), ids)
+	}
+	// the prefix is matched literally, not as a pattern
+	page, err := rl.ScanBlockList(ctx, 0, "user*", 0)
+	if err != nil || len(page.Ids) != 1 || page.Ids[0] != "user*x" {
+		t.Fatalf("literal prefix: %+v, %v", page, err)
+	}
+}
@@ -0,0 +1,369 @@
+package rateLimiter
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// expirySet tracks when temporary list entries expire locally.
+type expirySet struct {
+	mu   sync.Mutex
+	at   map[string]time.Time
+	next time.Time
+}
+
+func (e *expirySet) set(id string, at time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.at == nil {
+		e.at = make(map[string]time.Time)
+	}
+	e.at[id] = at
+	if e.next.IsZero() || at.Before(e.next) {
+		e.next = at
+	}
+}
+
+func (e *expirySet) del(id string) {
+	e.mu.Lock()
+	delete(e.at, id)
+	e.mu.Unlock()
+}
+
+func (e *expirySet) reset() {
+	e.mu.Lock()
+	e.at = nil
+	e.next = time.Time{}
+	e.mu.Unlock()
+}
+
+func (e *expirySet) get(id string) (time.Time, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	at, ok := e.at[id]
+	return at, ok
+}
+
+// due removes and returns the entries expired at now. It is cheap until the
+// earliest expiry has passed.
+func (e *expirySet) due(now time.Time) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.next.IsZero() || now.Before(e.next) {
+		return nil
+	}
+	var ids []string
+	e.next = time.Time{}
+	for id, at := range e.at {
+		if !now.Before(at) {
+			ids = append(ids, id)
+			delete(e.at, id)
+		} else if e.next.IsZero() || at.Before(e.next) {
+			e.next = at
+		}
+	}
+	return ids
+}
+
+// purgeExpiredScript drops members whose expiry in the sorted set KEYS[2] has passed
+// from the set KEYS[1].
+var purgeExpiredScript = goredis.NewScript(`
+local ids = redis.call('zrangebyscore', KEYS[2], '-inf', ARGV[1])
+for _, id in ipairs(ids) do
+	redis.call('srem', KEYS[1], id)
+end
+redis.call('zremrangebyscore', KEYS[2], '-inf', ARGV[1])
+return ids
+`)
+
+// purgeExpired runs purgeExpiredScript. Since only one instance gets to remove an
+// entry, it is also where expired block list entries are reported to OnUnblock and OnEvent.
+func (rl *RateLimiter) purgeExpired(ctx context.Context, setKey, ttlKey string) error {
+	ids, err := rl.purgeExpiredIds(ctx, setKey, ttlKey)
+	rl.reportPurged(setKey, ids)
+	return err
+}
+
+// purgeExpiredIds runs purgeExpiredScript without reporting the ids it removed,
+// for callers inside idList.apply.
+func (rl *RateLimiter) purgeExpiredIds(ctx context.Context, setKey, ttlKey string) ([]string, error) {
+	now := rl.Clock.Now().UnixMilli()
+	return purgeExpiredScript.Run(ctx, rl.Redis, []string{setKey, ttlKey}, now).StringSlice()
+}
+
+func (rl *RateLimiter) reportPurged(setKey string, ids []string) {
+	if setKey == rl.blockListKey {
+		rl.unblocked(ids)
+	}
+}
+
+func (rl *RateLimiter) loadExpiry(ctx context.Context, ttlKey string, e *expirySet) error {
+	vals, err := rl.Redis.ZRangeWithScores(ctx, ttlKey, 0, -1).Result()
+	if err != nil {
+		return err
+	}
+	for _, z := range vals {
+		e.set(z.Member.(string), time.UnixMilli(int64(z.Score)))
+	}
+	return nil
+}
+
+// listPurge hands the Redis side of list expiry to a background goroutine, so
+// that the checks noticing an expired entry don't wait for it.
+type listPurge struct {
+	mu    sync.Mutex
+	white []string
+	block []string
+	wake  chan struct{}
+}
+
+func (p *listPurge) init() {
+	p.wake = make(chan struct{}, 1)
+}
+
+func (p *listPurge) push(white bool, ids []string) {
+	p.mu.Lock()
+	if white {
+		p.white = append(p.white, ids...)
+	} else {
+		p.block = append(p.block, ids...)
+	}
+	p.mu.Unlock()
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *listPurge) take() (white, block []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	white, block = p.white, p.block
+	p.white, p.block = nil, nil
+	return white, block
+}
+
+func (rl *RateLimiter) listPurgeLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-rl.listPurge.wake:
+		}
+		rl.purgeLists(ctx)
+	}
+}
+
+// purgeLists drops the metadata of the entries expired locally and purges the
+// expired entries from Redis, within ListTimeout.
+func (rl *RateLimiter) purgeLists(ctx context.Context) error {
+	white, block := rl.listPurge.take()
+	var errs []error
+	for _, l := range []struct {
+		ids                     []string
+		metaKey, setKey, ttlKey string
+	}{
+		{white, rl.whiteMetaKey, rl.whiteListKey, rl.whiteTTLKey},
+		{block, rl.blockMetaKey, rl.blockListKey, rl.blockTTLKey},
+	} {
+		if len(l.ids) == 0 {
+			continue
+		}
+		ctx, cancel := rl.listContext(ctx)
+		if err := rl.Redis.HDel(ctx, l.metaKey, l.ids...).Err(); err != nil {
+			errs = append(errs, err)
+		}
+		if err := rl.purgeExpired(ctx, l.setKey, l.ttlKey); err != nil {
+			errs = append(errs, err)
+		}
+		cancel()
+	}
+	if err := joinErrors(errs); err != nil {
+		rl.Logger.Error("purge expired list entries failed", rl.fields("err", err)...)
+		return err
+	}
+	return nil
+}
+
+// expireWhiteList drops expired temporary entries locally, and in Redis in the
+// background.
+func (rl *RateLimiter) expireWhiteList() {
+	expired := rl.whiteIds.expire(rl.Clock.Now())
+	if len(expired) == 0 {
+		return
+	}
+	rl.whiteListChanged(context.Background(), ListExpire, expired, 0)
+	rl.listPurge.push(true, expired)
+}
+
+// addTemporaryScript adds ARGV[1] to the set KEYS[1], expiring at ARGV[2] in the
+// sorted set KEYS[2], unless it is a permanent member already. It returns 0 then.
+var addTemporaryScript = goredis.NewScript(`
+if redis.call('sismember', KEYS[1], ARGV[1]) == 1 and not redis.call('zscore', KEYS[2], ARGV[1]) then
+	return 0
+end
+redis.call('sadd', KEYS[1], ARGV[1])
+redis.call('zadd', KEYS[2], ARGV[2], ARGV[1])
+return 1
+`)
+
+// addTemporaryToList adds id to the Redis set setKey until expiresAt, and then to
+// l. It leaves a permanent entry alone, in Redis and locally, and reports false for it.
+func (rl *RateLimiter) addTemporaryToList(ctx context.Context, l *idList, setKey, ttlKey, id string, expiresAt time.Time) (added bool, err error) {
+	err = l.apply(func() error {
+		if l.permanent(id) {
+			return nil
+		}
+		n, err := addTemporaryScript.Run(ctx, rl.Redis, []string{setKey, ttlKey}, id, expiresAt.UnixMilli()).Int()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			// the local cache missed the permanent entry; catch up instead
+			l.addPermanent(id)
+			return nil
+		}
+		added = l.addTemporary(id, expiresAt)
+		return nil
+	})
+	return added, err
+}
+
+func (rl *RateLimiter) AddWhiteListTTL(id string, ttl time.Duration, pub bool) error {
+	return rl.AddWhiteListTTLCtx(context.Background(), id, ttl, pub)
+}
+
+// AddWhiteListTTLCtx whitelists id until ttl elapses, in Redis and in every local cache.
+func (rl *RateLimiter) AddWhiteListTTLCtx(ctx context.Context, id string, ttl time.Duration, pub bool) error {
+	if ttl <= 0 {
+		return stderrors.New("ttl必须大于0")
+	}
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return err
+	}
+	return rl.addWhiteListTTL(ctx, id, ttl, pub)
+}
+
+// addWhiteListTTL is AddWhiteListTTLCtx for a sanitized id and a positive ttl.
+func (rl *RateLimiter) addWhiteListTTL(ctx context.Context, id string, ttl time.Duration, pub bool) error {
+	ctx, cancel := rl.listContext(ctx)
+	defer cancel()
+	expiresAt := rl.Clock.Now().Add(ttl)
+	added, err := rl.addTemporaryToList(ctx, rl.whiteIds, rl.whiteListKey, rl.whiteTTLKey, id, expiresAt)
+	if err != nil {
+		return err
+	}
+	if !added {
+		return ErrorWhiteListExists
+	}
+	rl.whiteListChanged(ctx, ListAdd, []string{id}, ttl)
+	rl.audit(ctx, AuditAddWhiteList, id, ttl)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: "tw", Id: id, TTL: ttl.Milliseconds()})
+	}
+	return nil
+}
+
+// syncWhiteListTTL applies a temporary entry added by another instance.
+func (rl *RateLimiter) syncWhiteListTTL(ctx context.Context, id string) error {
+	var expiresAt time.Time
+	added := false
+	err := rl.whiteIds.apply(func() error {
+		score, err := rl.Redis.ZScore(ctx, rl.whiteTTLKey, id).Result()
+		if err == goredis.Nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		expiresAt = time.UnixMilli(int64(score))
+		if !rl.Clock.Now().Before(expiresAt) {
+			return nil
+		}
+		added = rl.whiteIds.addTemporary(id, expiresAt)
+		return nil
+	})
+	if err != nil || !added {
+		return err
+	}
+	rl.whiteListChanged(ctx, ListAdd, []string{id}, expiresAt.Sub(rl.Clock.Now()))
+	return nil
+}
+
+// expireBlockList drops expired temporary entries locally, and in Redis in the
+// background.
+func (rl *RateLimiter) expireBlockList() {
+	expired := rl.blockIds.expire(rl.Clock.Now())
+	if len(expired) == 0 {
+		return
+	}
+	rl.blockListChanged(context.Background(), ListExpire, expired, 0)
+	rl.listPurge.push(false, expired)
+}
+
+func (rl *RateLimiter) AddBlockListTTL(id string, ttl time.Duration, pub bool) error {
+	return rl.AddBlockListTTLCtx(context.Background(), id, ttl, pub)
+}
+
+// AddBlockListTTLCtx blocklists id until ttl elapses, in Redis and in every local cache.
+func (rl *RateLimiter) AddBlockListTTLCtx(ctx context.Context, id string, ttl time.Duration, pub bool) error {
+	if ttl <= 0 {
+		return stderrors.New("ttl必须大于0")
+	}
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return err
+	}
+	return rl.addBlockListTTL(ctx, id, ttl, pub)
+}
+
+// addBlockListTTL is AddBlockListTTLCtx for a sanitized id and a positive ttl.
+func (rl *RateLimiter) addBlockListTTL(ctx context.Context, id string, ttl time.Duration, pub bool) error {
+	ctx, cancel := rl.listContext(ctx)
+	defer cancel()
+	expiresAt := rl.Clock.Now().Add(ttl)
+	added, err := rl.addTemporaryToList(ctx, rl.blockIds, rl.blockListKey, rl.blockTTLKey, id, expiresAt)
+	if err != nil {
+		return err
+	}
+	if !added {
+		return ErrorBlockListExists
+	}
+	rl.blockListChanged(ctx, ListAdd, []string{id}, ttl)
+	rl.audit(ctx, AuditAddBlockList, id, ttl)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: "tb", Id: id, TTL: ttl.Milliseconds()})
+	}
+	return nil
+}
+
+// syncBlockListTTL applies a temporary entry added by another instance.
+func (rl *RateLimiter) syncBlockListTTL(ctx context.Context, id string) error {
+	var expiresAt time.Time
+	added := false
+	err := rl.blockIds.apply(func() error {
+		score, err := rl.Redis.ZScore(ctx, rl.blockTTLKey, id).Result()
+		if err == goredis.Nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		expiresAt = time.UnixMilli(int64(score))
+		if !rl.Clock.Now().Before(expiresAt) {
+			return nil
+		}
+		added = rl.blockIds.addTemporary(id, expiresAt)
+		return nil
+	})
+	if err != nil || !added {
+		return err
+	}
+	rl.blockListChanged(ctx, ListAdd, []string{id}, expiresAt.Sub(rl.Clock.Now()))
+	return nil
+}
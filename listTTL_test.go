@@ -0,0 +1,162 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWhiteListTTL(t *testing.T) {
+	mr, r := testRedis(t)
+	clock := NewFakeClock(time.Now())
+	opts := []Option{WithRedis(r), WithDuration(time.Minute), WithBlockTimes(1), WithBlockDuration(time.Hour), WithClock(clock)}
+	rl, err := NewLimiter("ttl", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := NewLimiter("ttl", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.AddWhiteListTTL("a", time.Minute, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Sub("tw-a"); err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range []*RateLimiter{rl, other} {
+		if _, err := l.Check("a"); err != nil {
+			t.Fatalf("temporary white list entry not applied: %v", err)
+		}
+	}
+	if err := rl.AddWhiteListTTL("a", 0, false); err == nil {
+		t.Fatal("zero ttl accepted")
+	}
+
+	clock.Advance(time.Minute)
+	for _, l := range []*RateLimiter{rl, other} {
+		if _, err := l.Check("a"); !IsBlocked(err) {
+			t.Fatalf("expired white list entry still applies: %v", err)
+		}
+	}
+	// the purge runs in the background
+	deadline := time.Now().Add(2 * time.Second)
+	for ok, _ := mr.SIsMember("ttl-white", "a"); ok; ok, _ = mr.SIsMember("ttl-white", "a") {
+		if time.Now().After(deadline) {
+			t.Fatal("expired entry kept in Redis")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBlockListTTL(t *testing.T) {
+	mr, r := testRedis(t)
+	// Redis keeps expiries in milliseconds
+	clock := NewFakeClock(time.UnixMilli(time.Now().UnixMilli()))
+	opts := []Option{WithRedis(r), WithDuration(time.Minute), WithBlockTimes(10), WithClock(clock)}
+	rl, err := NewLimiter("ttl", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.AddBlockListTTL("a", time.Minute, false); err != nil {
+		t.Fatal(err)
+	}
+	// a limiter created later loads the temporary entry with its expiry
+	other, err := NewLimiter("ttl", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range []*RateLimiter{rl, other} {
+		res, err := l.Allow("a")
+		if !IsBlocked(err) || res.RetryAfter != time.Minute {
+			t.Fatalf("temporary block: %+v, %v", res, err)
+		}
+	}
+
+	clock.Advance(time.Minute)
+	for _, l := range []*RateLimiter{rl, other} {
+		if _, err := l.Check("a"); err != nil {
+			t.Fatalf("expired block still applies: %v", err)
+		}
+	}
+	// the purge runs in the background
+	deadline := time.Now().Add(2 * time.Second)
+	for ok, _ := mr.SIsMember("ttl-block", "a"); ok; ok, _ = mr.SIsMember("ttl-block", "a") {
+		if time.Now().After(deadline) {
+			t.Fatal("expired entry kept in Redis")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBlockListTTLExpires(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	_, rl := testLimiter(t, "ttl", WithDuration(time.Minute), WithBlockTimes(100), WithClock(clock))
+	entry := ListEntry{Id: "a", Reason: "test"}
+	if err := rl.AddBlockListEntry(entry, time.Minute, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rl.Check("a"); !IsBlocked(err) {
+		t.Fatalf("a not blocked: %v", err)
+	}
+	clock.Advance(2 * time.Minute)
+	if _, err := rl.Check("a"); err != nil {
+		t.Fatalf("a still blocked after its ttl: %v", err)
+	}
+	ctx := context.Background()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		member, _ := rl.Redis.SIsMember(ctx, rl.blockListKey, "a").Result()
+		meta, _ := rl.Redis.HExists(ctx, rl.blockMetaKey, "a").Result()
+		if !member && !meta {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expired entry left in Redis: member %v, meta %v", member, meta)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestListTTLKeepsPermanentEntries(t *testing.T) {
+	mr, rl := testLimiter(t, "ttl", WithDuration(time.Minute), WithBlockTimes(1))
+	if err := rl.AddWhiteList("w", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.AddBlockList("b", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.AddWhiteListTTL("w", time.Minute, false); err != ErrorWhiteListExists {
+		t.Fatalf("temporary add of a permanent white list entry: %v", err)
+	}
+	if err := rl.AddBlockListTTL("b", time.Minute, false); err != ErrorBlockListExists {
+		t.Fatalf("temporary add of a permanent block list entry: %v", err)
+	}
+	for _, c := range []struct {
+		l      *idList
+		ttlKey string
+		setKey string
+		id     string
+	}{{rl.whiteIds, rl.whiteTTLKey, rl.whiteListKey, "w"}, {rl.blockIds, rl.blockTTLKey, rl.blockListKey, "b"}} {
+		if !c.l.permanent(c.id) {
+			t.Fatalf("%s made temporary locally", c.id)
+		}
+		if _, err := mr.ZScore(c.ttlKey, c.id); err == nil {
+			t.Fatalf("%s given a TTL in Redis", c.id)
+		}
+		if ok, _ := mr.SIsMember(c.setKey, c.id); !ok {
+			t.Fatalf("%s dropped from Redis", c.id)
+		}
+	}
+
+	// a temporary entry still has its expiry moved
+	if err := rl.AddBlockListTTL("t", time.Minute, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.AddBlockListTTL("t", time.Hour, false); err != nil {
+		t.Fatalf("temporary entry not extended: %v", err)
+	}
+	if at, ok := rl.blockIds.expiry.get("t"); !ok || at.Before(rl.Clock.Now().Add(time.Minute)) {
+		t.Fatalf("expiry of t %v, %v", at, ok)
+	}
+}
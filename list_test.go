@@ -0,0 +1,357 @@
+package rateLimiter
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestIdList(t *testing.T) {
+	l := newIdList()
+	if !l.add("a") || l.add("a") {
+		t.Fatal("add must report whether the id was new")
+	}
+	l.add("10.0.0.0/8")
+	l.add("bot-*")
+	for id, want := range map[string]bool{"a": true, "10.1.1.1": true, "bot-1": true, "b": false} {
+		if got := l.match(id); got != want {
+			t.Errorf("match(%q) = %v, want %v", id, got, want)
+		}
+	}
+	if l.has("10.1.1.1") || !l.has("10.0.0.0/8") {
+		t.Fatal("has must only report exact entries")
+	}
+	if got := l.list(); len(got) != 3 || got[0] != "10.0.0.0/8" || l.size() != 3 {
+		t.Fatalf("list = %v", got)
+	}
+	if !l.remove("10.0.0.0/8") || l.remove("10.0.0.0/8") || l.match("10.1.1.1") {
+		t.Fatal("removed CIDR entry still matches")
+	}
+	l.reset([]string{"x"})
+	if l.match("a") || !l.match("x") || l.size() != 1 {
+		t.Fatalf("reset kept old entries: %v", l.list())
+	}
+}
+
+func TestIdListConcurrent(t *testing.T) {
+	l := newIdList()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				id := strconv.Itoa(i*100 + j)
+				l.add(id)
+				l.match(id)
+				if j%2 == 0 {
+					l.remove(id)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := l.size(); n != 400 {
+		t.Fatalf("size %d, want 400", n)
+	}
+}
+
+func TestAddToList(t *testing.T) {
+	mr, rl := testLimiter(t, "addList", WithDuration(time.Minute), WithBlockTimes(10))
+	if err := rl.AddBlockList("a", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.AddBlockList("a", false); err != ErrorBlockListExists {
+		t.Fatalf("second add: %v", err)
+	}
+
+	// an entry missing in Redis or locally is an update, not Exists
+	mr.SRem(rl.blockListKey, "a")
+	if err := rl.AddBlockList("a", false); err != nil {
+		t.Fatalf("add of an id missing in Redis: %v", err)
+	}
+	if ok, _ := mr.SIsMember(rl.blockListKey, "a"); !ok {
+		t.Fatal("id not restored in Redis")
+	}
+	rl.blockIds.remove("a")
+	if err := rl.AddBlockList("a", false); err != nil || !rl.blockIds.has("a") {
+		t.Fatalf("add of an id missing locally: %v", err)
+	}
+
+	// a temporary entry is made permanent
+	if err := rl.AddWhiteListTTL("b", time.Hour, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.AddWhiteList("b", false); err != nil {
+		t.Fatalf("add of a temporary entry: %v", err)
+	}
+	if _, temporary := rl.whiteIds.expiry.get("b"); temporary || mr.Exists(rl.whiteTTLKey) {
+		t.Fatal("entry still temporary")
+	}
+
+	// nothing changes locally when Redis fails
+	mr.Close()
+	if err := rl.AddBlockList("c", false); err == nil || rl.blockIds.has("c") {
+		t.Fatalf("add with Redis down: %v", err)
+	}
+}
+
+func TestListApply(t *testing.T) {
+	mr, rl := testLimiter(t, "apply", WithDuration(time.Minute), WithBlockTimes(10))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if (i+j)%2 == 0 {
+					rl.AddBlockList("a", false)
+				} else {
+					rl.RemoveBlockList("a", false)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	// Redis and the local list saw the mutations in the same order
+	if inRedis, _ := mr.SIsMember(rl.blockListKey, "a"); inRedis != rl.blockIds.has("a") {
+		t.Fatalf("Redis has a: %v, local list has a: %v", inRedis, rl.blockIds.has("a"))
+	}
+}
+
+func TestIdListExpire(t *testing.T) {
+	l := newIdList()
+	now := time.Now()
+	l.add("a")
+	l.expiry.set("a", now)
+	l.applyMu.Lock()
+	if got := l.expire(now); got != nil {
+		t.Fatalf("expire ran during a mutation: %v", got)
+	}
+	l.applyMu.Unlock()
+	if got := l.expire(now); len(got) != 1 || l.has("a") {
+		t.Fatalf("expire = %v", got)
+	}
+}
+
+func TestIdListReplace(t *testing.T) {
+	l := newIdList()
+	l.addAll([]string{"a", "10.0.0.0/24", "bot-*"})
+	for id, want := range map[string]bool{"a": true, "b": false, "10.0.0.7": true, "10.0.1.7": false, "bot-1": true} {
+		if l.match(id) != want {
+			t.Errorf("match(%q) = %v", id, !want)
+		}
+	}
+
+	now := time.Now()
+	l.addTemporary("t", now.Add(time.Minute))
+	if got := l.expire(now); len(got) != 0 {
+		t.Fatalf("expired %v before their time", got)
+	}
+	if got := l.expire(now.Add(time.Minute)); len(got) != 1 || got[0] != "t" {
+		t.Fatalf("expired %v, want [t]", got)
+	}
+	if l.addTemporary("a", now.Add(time.Minute)) {
+		t.Fatal("permanent entry made temporary")
+	}
+	l.addTemporary("d", now.Add(time.Minute))
+	if !l.addPermanent("d") {
+		t.Fatal("making a temporary entry permanent reported no change")
+	}
+	if got := l.expire(now.Add(time.Hour)); len(got) != 0 {
+		t.Fatalf("permanent entry expired: %v", got)
+	}
+
+	added, removed := l.replace([]string{"a", "c"}, nil)
+	if len(added) != 1 || added[0] != "c" || len(removed) != 3 {
+		t.Fatalf("replace added %v removed %v", added, removed)
+	}
+	if l.match("10.0.0.7") || l.match("bot-1") {
+		t.Fatal("replaced CIDR or wildcard entry still matches")
+	}
+}
+
+// TestConcurrentListMutations mutates the block list from admin calls, sync
+// messages, resyncs and auto blocks at once; run with -race. Afterwards the
+// local list must equal the Redis set. Sync messages only change the local
+// list, so each sync goroutine owns its ids, changing them in Redis through a
+// second instance before delivering the message, as pub/sub would.
+func TestConcurrentListMutations(t *testing.T) {
+	_, rl := testLimiter(t, "concurrent", WithDuration(time.Minute), WithBlockTimes(5), WithBlockDuration(0))
+	other, err := NewLimiter("concurrent", WithRedis(rl.Redis), WithDuration(time.Minute), WithBlockTimes(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { other.Close(context.Background()) })
+	ids := make([]string, 8)
+	for i := range ids {
+		ids[i] = "id" + strconv.Itoa(i)
+	}
+	var wg sync.WaitGroup
+	runOn := func(ids []string, fn func(r *rand.Rand, id string)) {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(seed))
+			for i := 0; i < 200; i++ {
+				fn(r, ids[r.Intn(len(ids))])
+			}
+		}(rand.Int63())
+	}
+	run := func(fn func(r *rand.Rand, id string)) { runOn(ids, fn) }
+	for i := 0; i < 4; i++ {
+		run(func(r *rand.Rand, id string) {
+			if r.Intn(2) == 0 {
+				rl.AddBlockList(id, false)
+			} else {
+				rl.RemoveBlockList(id, false)
+			}
+		})
+		synced := []string{"sync" + strconv.Itoa(i) + "a", "sync" + strconv.Itoa(i) + "b"}
+		runOn(synced, func(r *rand.Rand, id string) {
+			if r.Intn(2) == 0 {
+				other.AddBlockList(id, false)
+				rl.Sub("ab-" + id)
+			} else {
+				other.RemoveBlockList(id, false)
+				rl.Sub("rb-" + id)
+			}
+		})
+		run(func(r *rand.Rand, id string) {
+			rl.Check(id)
+			if r.Intn(20) == 0 {
+				rl.CheckReset(id)
+			}
+		})
+	}
+	run(func(r *rand.Rand, id string) {
+		if r.Intn(20) == 0 {
+			rl.Resync()
+		}
+	})
+	wg.Wait()
+
+	members, err := rl.Redis.SMembers(context.Background(), rl.blockListKey).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(members)
+	local := rl.blockIds.list()
+	if len(members) != len(local) {
+		t.Fatalf("redis %v, local %v", members, local)
+	}
+	for i := range members {
+		if members[i] != local[i] {
+			t.Fatalf("redis %v, local %v", members, local)
+		}
+	}
+}
+
+func TestSyncAcrossInstances(t *testing.T) {
+	_, r := testRedis(t)
+	newInstance := func() *RateLimiter {
+		rl, err := NewLimiter("sync", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(10), WithSyncChannel("sync-test"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { rl.Close(context.Background()) })
+		return rl
+	}
+	a, b := newInstance(), newInstance()
+	if err := b.StartSync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	eventually := func(what string, cond func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(2 * time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatal(what)
+			}
+		}
+	}
+
+	if err := a.AddBlockList("x", true); err != nil {
+		t.Fatal(err)
+	}
+	eventually("block list add not synced", func() bool { return b.blockIds.has("x") })
+	if err := a.AddBlockListTTL("y", time.Minute, true); err != nil {
+		t.Fatal(err)
+	}
+	eventually("block list TTL not synced", func() bool {
+		_, ok := b.blockIds.expiry.get("y")
+		return ok && b.blockIds.has("y")
+	})
+	if err := a.RemoveBlockList("x", true); err != nil {
+		t.Fatal(err)
+	}
+	eventually("block list removal not synced", func() bool { return !b.blockIds.has("x") })
+}
+
+func TestBloomBlockListBatch(t *testing.T) {
+	_, rl := testLimiter(t, "bloomBatch", WithDuration(time.Minute), WithBlockTimes(10),
+		WithBloomBlockList(0.01, time.Hour))
+	if err := rl.AddBlockListBatch([]string{"a", "b", "10.0.0.0/8"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if rl.blockIds.has("a") || rl.blockIds.has("b") {
+		t.Fatalf("permanent batch entries held locally: %v", rl.blockIds.list())
+	}
+	if !rl.blockIds.has("10.0.0.0/8") {
+		t.Fatal("CIDR entry missing from the local list")
+	}
+	for _, id := range []string{"a", "b", "10.1.2.3"} {
+		if !rl.inBlockList(id) {
+			t.Errorf("%s not blocked", id)
+		}
+	}
+	if rl.inBlockList("c") {
+		t.Error("c blocked")
+	}
+}
+
+// TestSyncAddKeepsRedis checks that a replicated permanent add arriving after a
+// later TTL add leaves the TTL in Redis.
+func TestSyncAddKeepsRedis(t *testing.T) {
+	mr, rl := testLimiter(t, "syncLocal", WithDuration(time.Minute), WithBlockTimes(10))
+	if err := rl.AddBlockListTTL("x", time.Minute, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.Sub(`{"v":1,"op":"ab","id":"x"}`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mr.ZScore(rl.blockTTLKey, "x"); err != nil {
+		t.Fatalf("TTL dropped from Redis: %v", err)
+	}
+	if err := rl.Sub(`{"v":1,"op":"rb","id":"x"}`); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := mr.SIsMember(rl.blockListKey, "x"); !ok {
+		t.Fatal("replicated removal removed the Redis entry")
+	}
+	if rl.blockIds.has("x") {
+		t.Fatal("replicated removal kept the local entry")
+	}
+}
+
+// TestSubSkipsOwnMessages delivers every published message back to its
+// publisher, as a shared pub/sub channel does.
+func TestSubSkipsOwnMessages(t *testing.T) {
+	var rl *RateLimiter
+	var changes int
+	_, rl = testLimiter(t, "own", WithDuration(time.Minute), WithBlockTimes(10),
+		WithPub(func(name, message string) error { return rl.Sub(message) }),
+		WithListChangeHooks(nil, func(ListChange) { changes++ }))
+	if err := rl.AddBlockListTTL("x", time.Minute, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.AddBlockList("y", true); err != nil {
+		t.Fatal(err)
+	}
+	if changes != 2 {
+		t.Fatalf("block list hook fired %d times, want 2", changes)
+	}
+}
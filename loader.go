@@ -0,0 +1,181 @@
+package rateLimiter
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type ConfigFormat string
+
+const (
+	ConfigJSON ConfigFormat = "json"
+	ConfigYAML ConfigFormat = "yaml"
+)
+
+// LimitsDocument declares a set of named limiters, e.g.
+//
+//	defaults:
+//	  duration: 1m
+//	  blockTimes: 100
+//	limiters:
+//	  login:
+//	    blockTimes: 5
+//	    blockDuration: 1h
+//	    whiteList: [10.0.0.1]
+type LimitsDocument struct {
+	Defaults LimiterDocument            `json:"defaults" yaml:"defaults"`
+	Limiters map[string]LimiterDocument `json:"limiters" yaml:"limiters"`
+}
+
+// LimiterDocument holds durations as time.ParseDuration strings; empty fields keep
+// the defaults, windows replace them and list seeds add to them.
+type LimiterDocument struct {
+	Duration      string           `json:"duration,omitempty" yaml:"duration,omitempty"`
+	BlockTimes    int              `json:"blockTimes,omitempty" yaml:"blockTimes,omitempty"`
+	BlockDuration string           `json:"blockDuration,omitempty" yaml:"blockDuration,omitempty"`
+	Windows       []WindowDocument `json:"windows,omitempty" yaml:"windows,omitempty"`
+	AlignWindows  bool             `json:"alignWindows,omitempty" yaml:"alignWindows,omitempty"`
+	WhiteList     []string         `json:"whiteList,omitempty" yaml:"whiteList,omitempty"`
+	BlockList     []string         `json:"blockList,omitempty" yaml:"blockList,omitempty"`
+}
+
+type WindowDocument struct {
+	Duration string `json:"duration" yaml:"duration"`
+	Limit    int    `json:"limit" yaml:"limit"`
+}
+
+func (d LimiterDocument) options(name string) ([]Option, error) {
+	var opts []Option
+	for _, f := range []struct {
+		field, value string
+		opt          func(time.Duration) Option
+	}{
+		{"duration", d.Duration, WithDuration},
+		{"blockDuration", d.BlockDuration, WithBlockDuration},
+	} {
+		if f.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(f.value)
+		if err != nil {
+			return nil, stderrors.New(name + "的" + f.field + "格式错误: " + err.Error())
+		}
+		opts = append(opts, f.opt(v))
+	}
+	if d.BlockTimes < 0 {
+		return nil, stderrors.New(name + "的blockTimes不能小于0")
+	}
+	if d.BlockTimes > 0 {
+		opts = append(opts, WithBlockTimes(d.BlockTimes))
+	}
+	if len(d.Windows) > 0 {
+		windows := make([]Window, len(d.Windows))
+		for i, w := range d.Windows {
+			v, err := time.ParseDuration(w.Duration)
+			if err != nil {
+				return nil, stderrors.New(name + "的windows格式错误: " + err.Error())
+			}
+			windows[i] = Window{Duration: v, Limit: w.Limit}
+		}
+		opts = append(opts, func(c *Config) { c.Windows = windows })
+	}
+	if d.AlignWindows {
+		opts = append(opts, func(c *Config) { c.AlignWindows = true })
+	}
+	if len(d.WhiteList) > 0 {
+		opts = append(opts, WithWhiteList(d.WhiteList...))
+	}
+	if len(d.BlockList) > 0 {
+		opts = append(opts, WithBlockList(d.BlockList...))
+	}
+	return opts, nil
+}
+
+// ParseLimitsDocument decodes a LimitsDocument, rejecting unknown fields so typos
+// in a config repo fail loudly.
+func ParseLimitsDocument(r io.Reader, format ConfigFormat) (*LimitsDocument, error) {
+	var doc LimitsDocument
+	switch format {
+	case ConfigJSON:
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&doc); err != nil {
+			return nil, err
+		}
+	case ConfigYAML:
+		dec := yaml.NewDecoder(r)
+		dec.KnownFields(true)
+		if err := dec.Decode(&doc); err != nil && err != io.EOF {
+			return nil, err
+		}
+	default:
+		return nil, stderrors.New("unsupported format: " + string(format))
+	}
+	return &doc, nil
+}
+
+// LoadManager creates the limiters declared in r. defaults supplies what a file
+// cannot, such as Redis and Pub, and is overridden by the document defaults.
+func LoadManager(defaults *Config, r io.Reader, format ConfigFormat) (*Manager, error) {
+	doc, err := ParseLimitsDocument(r, format)
+	if err != nil {
+		return nil, err
+	}
+	return NewManagerFromDocument(defaults, doc)
+}
+
+// LoadManagerFile is LoadManager for a .json, .yaml or .yml file.
+func LoadManagerFile(defaults *Config, path string) (*Manager, error) {
+	var format ConfigFormat
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		format = ConfigJSON
+	case ".yaml", ".yml":
+		format = ConfigYAML
+	default:
+		return nil, stderrors.New("unsupported format: " + filepath.Ext(path))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadManager(defaults, f, format)
+}
+
+func NewManagerFromDocument(defaults *Config, doc *LimitsDocument) (*Manager, error) {
+	if defaults == nil {
+		return nil, stderrors.New("config必须设置")
+	}
+	if len(doc.Limiters) == 0 {
+		return nil, stderrors.New("limiters必须设置")
+	}
+	c := defaults.clone()
+	opts, err := doc.Defaults.options("defaults")
+	if err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	m := NewManager(&c)
+	for name, d := range doc.Limiters {
+		opts, err := d.options(name)
+		if err == nil {
+			_, err = m.Get(name, opts...)
+		}
+		if err != nil {
+			m.CloseAll(context.Background())
+			return nil, err
+		}
+	}
+	return m, nil
+}
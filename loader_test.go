@@ -0,0 +1,85 @@
+package rateLimiter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLimitsYAML = `
+defaults:
+  duration: 1m
+  blockTimes: 100
+limiters:
+  api: {}
+  login:
+    blockTimes: 5
+    blockDuration: 1h
+    windows:
+      - duration: 1s
+        limit: 2
+    whiteList: [10.0.0.1]
+`
+
+func TestLoadManager(t *testing.T) {
+	_, r := testRedis(t)
+	m, err := LoadManager(&Config{Redis: r, WhiteList: []string{"w"}}, strings.NewReader(testLimitsYAML), ConfigYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.CloseAll(context.Background()) })
+	if n := len(m.List()); n != 2 {
+		t.Fatalf("%d limiters, want 2", n)
+	}
+	api, _ := m.Get("api")
+	if api.Duration != time.Minute || api.BlockTimes != 100 || api.BlockDuration != 0 {
+		t.Fatalf("api config %+v", api.Config)
+	}
+	login, _ := m.Get("login")
+	if login.Duration != time.Minute || login.BlockTimes != 5 || login.BlockDuration != time.Hour ||
+		!reflect.DeepEqual(login.Windows, []Window{{Duration: time.Second, Limit: 2}}) {
+		t.Fatalf("login config %+v", login.Config)
+	}
+	if !reflect.DeepEqual(login.WhiteList, []string{"w", "10.0.0.1"}) || !reflect.DeepEqual(api.WhiteList, []string{"w"}) {
+		t.Fatalf("white lists %v and %v", login.WhiteList, api.WhiteList)
+	}
+}
+
+func TestLoadManagerFile(t *testing.T) {
+	_, r := testRedis(t)
+	path := filepath.Join(t.TempDir(), "limits.json")
+	if err := os.WriteFile(path, []byte(`{"defaults":{"duration":"1m","blockTimes":10},"limiters":{"api":{}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := LoadManagerFile(&Config{Redis: r}, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.CloseAll(context.Background()) })
+	if api, _ := m.Get("api"); api.BlockTimes != 10 {
+		t.Fatalf("api config %+v", api.Config)
+	}
+	if _, err := LoadManagerFile(&Config{Redis: r}, "limits.toml"); err == nil {
+		t.Fatal("toml accepted")
+	}
+}
+
+func TestParseLimitsDocumentErrors(t *testing.T) {
+	for doc, format := range map[string]ConfigFormat{
+		"limiters:\n  api:\n    blockTime: 5\n":              ConfigYAML,
+		`{"limiters":{"api":{"duration":"soon"}}}`:           ConfigJSON,
+		`{"limiters":{"api":{}},"extra":1}`:                  ConfigJSON,
+		`{"defaults":{"blockTimes":-1},"limiters":{"a":{}}}`: ConfigJSON,
+		"{}": ConfigJSON,
+	} {
+		_, r := testRedis(t)
+		if m, err := LoadManager(&Config{Redis: r, Duration: time.Minute}, strings.NewReader(doc), format); err == nil {
+			m.CloseAll(context.Background())
+			t.Errorf("%q accepted", doc)
+		}
+	}
+}
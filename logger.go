@@ -0,0 +1,22 @@
+package rateLimiter
+
+// Logger receives the significant events of a limiter. keysAndValues alternate
+// between string keys and their values, as in logr or zap's SugaredLogger.
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
+
+// fields prefixes keysAndValues with the limiter name.
+func (rl *RateLimiter) fields(keysAndValues ...interface{}) []interface{} {
+	return append([]interface{}{"limiter", rl.Name}, keysAndValues...)
+}
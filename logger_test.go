@@ -0,0 +1,61 @@
+package rateLimiter
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// testLogger records the messages logged at each level.
+type testLogger struct {
+	mu   sync.Mutex
+	msgs map[string][]string
+}
+
+func newTestLogger() *testLogger {
+	return &testLogger{msgs: make(map[string][]string)}
+}
+
+func (l *testLogger) log(level, msg string) {
+	l.mu.Lock()
+	l.msgs[level] = append(l.msgs[level], msg)
+	l.mu.Unlock()
+}
+
+func (l *testLogger) logged(level, msg string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, m := range l.msgs[level] {
+		if m == msg {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *testLogger) Debug(msg string, _ ...interface{}) { l.log("debug", msg) }
+func (l *testLogger) Info(msg string, _ ...interface{})  { l.log("info", msg) }
+func (l *testLogger) Warn(msg string, _ ...interface{})  { l.log("warn", msg) }
+func (l *testLogger) Error(msg string, _ ...interface{}) { l.log("error", msg) }
+
+func TestLogger(t *testing.T) {
+	l := newTestLogger()
+	mr, rl := testLimiter(t, "log", WithDuration(time.Minute), WithBlockTimes(2), WithBlockDuration(time.Minute), WithLogger(l))
+	rl.Allow("a")
+	rl.Allow("a")
+	if !l.logged("info", "id blocked") {
+		t.Fatalf("block not logged: %v", l.msgs)
+	}
+	rl.Allow("a")
+	if !l.logged("debug", "request rejected") {
+		t.Fatalf("rejection not logged: %v", l.msgs)
+	}
+	if err := rl.Sub("{not json"); err == nil || !l.logged("warn", "invalid sync message") {
+		t.Fatalf("invalid message: err %v, logged %v", err, l.msgs)
+	}
+
+	mr.Close()
+	if _, err := rl.Check("b"); err == nil || !l.logged("error", "check failed") {
+		t.Fatalf("redis failure: err %v, logged %v", err, l.msgs)
+	}
+}
@@ -0,0 +1,146 @@
+package rateLimiter
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Manager creates limiters sharing the same defaults and caches them by name.
+type Manager struct {
+	defaults Config
+	mu       sync.Mutex
+	limiters map[string]*RateLimiter
+	pending  map[string]*pendingLimiter
+}
+
+// pendingLimiter is a limiter being created by Get; done is closed once rl and err
+// are set.
+type pendingLimiter struct {
+	done chan struct{}
+	rl   *RateLimiter
+	err  error
+}
+
+// NewManager copies defaults; a non-empty defaults.Name is used as a name prefix.
+// Sync messages don't name their limiter, so a non-empty defaults.SyncChannel is
+// used as a channel prefix too: each limiter syncs on SyncChannel + ":" + the name
+// of its lists, ListName or else Name, shared by the limiters sharing the lists.
+func NewManager(defaults *Config) *Manager {
+	if defaults == nil {
+		panic("config必须设置")
+	}
+	return &Manager{
+		defaults: defaults.clone(),
+		limiters: make(map[string]*RateLimiter),
+		pending:  make(map[string]*pendingLimiter),
+	}
+}
+
+// clone copies c with its slices and maps, so that options appending to or
+// writing into a copy can't change c.
+func (c *Config) clone() Config {
+	d := *c
+	d.WhiteList = append([]string(nil), c.WhiteList...)
+	d.BlockList = append([]string(nil), c.BlockList...)
+	d.Windows = append([]Window(nil), c.Windows...)
+	d.AllowRules = append([]string(nil), c.AllowRules...)
+	d.DenyRules = append([]string(nil), c.DenyRules...)
+	d.TrustedList = append([]string(nil), c.TrustedList...)
+	d.Escalation = append([]time.Duration(nil), c.Escalation...)
+	d.Schedules = append([]Schedule(nil), c.Schedules...)
+	d.ShardedIds = append([]string(nil), c.ShardedIds...)
+	d.BypassKeys = append([][]byte(nil), c.BypassKeys...)
+	if c.Tiers != nil {
+		d.Tiers = make(map[string]Tier, len(c.Tiers))
+		for k, v := range c.Tiers {
+			d.Tiers[k] = v
+		}
+	}
+	if c.CountryTiers != nil {
+		d.CountryTiers = make(map[string]string, len(c.CountryTiers))
+		for k, v := range c.CountryTiers {
+			d.CountryTiers[k] = v
+		}
+	}
+	return d
+}
+
+// Get returns the limiter registered under name, creating it on first use.
+// opts are only applied when the limiter is created. The limiter is created
+// outside the lock, so a slow Redis only delays the callers of the same name.
+func (m *Manager) Get(name string, opts ...Option) (*RateLimiter, error) {
+	m.mu.Lock()
+	if rl, ok := m.limiters[name]; ok {
+		m.mu.Unlock()
+		return rl, nil
+	}
+	if p, ok := m.pending[name]; ok {
+		m.mu.Unlock()
+		<-p.done
+		return p.rl, p.err
+	}
+	p := &pendingLimiter{done: make(chan struct{})}
+	m.pending[name] = p
+	c := m.defaults.clone()
+	m.mu.Unlock()
+
+	p.rl, p.err = m.newLimiter(name, &c, opts)
+	m.mu.Lock()
+	delete(m.pending, name)
+	if p.err == nil {
+		m.limiters[name] = p.rl
+	}
+	m.mu.Unlock()
+	close(p.done)
+	return p.rl, p.err
+}
+
+func (m *Manager) newLimiter(name string, c *Config, opts []Option) (*RateLimiter, error) {
+	c.Name = name
+	if m.defaults.Name != "" {
+		c.Name = m.defaults.Name + "-" + name
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.SyncChannel != "" && c.SyncChannel == m.defaults.SyncChannel {
+		list := c.ListName
+		if list == "" {
+			list = c.Name
+		}
+		c.SyncChannel += ":" + list
+	}
+	return newRateLimiter(c)
+}
+
+func (m *Manager) List() []*RateLimiter {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.limiters))
+	for name := range m.limiters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	list := make([]*RateLimiter, 0, len(names))
+	for _, name := range names {
+		list = append(list, m.limiters[name])
+	}
+	return list
+}
+
+// CloseAll closes and forgets every limiter; later Get calls create new ones.
+func (m *Manager) CloseAll(ctx context.Context) error {
+	m.mu.Lock()
+	limiters := m.limiters
+	m.limiters = make(map[string]*RateLimiter)
+	m.mu.Unlock()
+	var errs []error
+	for _, rl := range limiters {
+		if err := rl.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return joinErrors(errs)
+}
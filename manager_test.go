@@ -0,0 +1,92 @@
+package rateLimiter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestManagerGet(t *testing.T) {
+	_, r := testRedis(t)
+	m := NewManager(&Config{Name: "svc", Redis: r, Duration: time.Minute, BlockTimes: 10, WhiteList: []string{"w"}})
+	a, err := m.Get("a", WithBlockTimes(5), WithWhiteList("extra"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Name != "svc-a" || a.BlockTimes != 5 || a.Duration != time.Minute {
+		t.Fatalf("limiter a: %+v", a.Config)
+	}
+	again, err := m.Get("a", WithBlockTimes(1))
+	if err != nil || again != a || a.BlockTimes != 5 {
+		t.Fatalf("second Get created or changed the limiter: %v, %+v", err, again.Config)
+	}
+	b, err := m.Get("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.WhiteList) != 1 || len(m.defaults.WhiteList) != 1 {
+		t.Fatalf("options of a leaked into the defaults: %v, %v", b.WhiteList, m.defaults.WhiteList)
+	}
+	if list := m.List(); len(list) != 2 || list[0] != a || list[1] != b {
+		t.Fatalf("List = %v", list)
+	}
+	if _, err := m.Get("c", WithDuration(0)); err == nil {
+		t.Fatal("invalid limiter created")
+	}
+	if len(m.List()) != 2 {
+		t.Fatal("failed limiter registered")
+	}
+}
+
+func TestManagerDefaultsNotShared(t *testing.T) {
+	_, r := testRedis(t)
+	windows := make([]Window, 1, 4)
+	windows[0] = Window{Duration: time.Hour, Limit: 100}
+	m := NewManager(&Config{Redis: r, Duration: time.Minute, BlockTimes: 10, Windows: windows, SyncChannel: "sync"})
+	t.Cleanup(func() { m.CloseAll(context.Background()) })
+
+	a, err := m.Get("a", WithWindows(Window{Duration: 24 * time.Hour, Limit: 1000}), WithListName("shared"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := m.Get("b", WithWindows(Window{Duration: 2 * time.Hour, Limit: 200}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Windows) != 2 || a.Windows[1].Limit != 1000 || len(b.Windows) != 2 || b.Windows[1].Limit != 200 {
+		t.Fatalf("windows a %v, b %v", a.Windows, b.Windows)
+	}
+	if len(m.defaults.Windows) != 1 {
+		t.Fatalf("defaults changed: %v", m.defaults.Windows)
+	}
+	if a.SyncChannel != "sync:shared" || b.SyncChannel != "sync:b" {
+		t.Fatalf("sync channels %q, %q", a.SyncChannel, b.SyncChannel)
+	}
+}
+
+func TestManagerGetConcurrent(t *testing.T) {
+	_, r := testRedis(t)
+	m := NewManager(&Config{Redis: r, Duration: time.Minute, BlockTimes: 10})
+	t.Cleanup(func() { m.CloseAll(context.Background()) })
+
+	limiters := make([]*RateLimiter, 8)
+	var wg sync.WaitGroup
+	for i := range limiters {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rl, err := m.Get("a")
+			if err != nil {
+				t.Error(err)
+			}
+			limiters[i] = rl
+		}(i)
+	}
+	wg.Wait()
+	for _, rl := range limiters[1:] {
+		if rl != limiters[0] {
+			t.Fatal("Get created more than one limiter for a name")
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package rateLimiter
+
+import (
+	stderrors "errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	checks      prometheus.Counter
+	allowed     prometheus.Counter
+	blocked     prometheus.Counter
+	bypassed    prometheus.Counter
+	redisErrors prometheus.Counter
+	latency     prometheus.Observer
+	redis       prometheus.ObserverVec //by command
+}
+
+// newMetrics registers the limiter collectors on reg, labeled by limiter name.
+// Limiters sharing reg share the collectors.
+func newMetrics(reg prometheus.Registerer, name string) (*metrics, error) {
+	counter := func(metric, help string) (*prometheus.CounterVec, error) {
+		return register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "rate_limiter",
+			Name:      metric,
+			Help:      help,
+		}, []string{"limiter"}))
+	}
+	checks, err := counter("checks_total", "Requests checked.")
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := counter("allowed_total", "Requests allowed.")
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := counter("blocked_total", "Requests rejected.")
+	if err != nil {
+		return nil, err
+	}
+	bypassed, err := counter("whitelist_bypass_total", "Requests allowed by the white list or AllowRules without counting.")
+	if err != nil {
+		return nil, err
+	}
+	redisErrors, err := counter("redis_errors_total", "Checks that failed on Redis.")
+	if err != nil {
+		return nil, err
+	}
+	latency, err := register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "rate_limiter",
+		Name:      "check_duration_seconds",
+		Help:      "Latency of checks.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"limiter"}))
+	if err != nil {
+		return nil, err
+	}
+	redisLatency, err := register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "rate_limiter",
+		Name:      "redis_duration_seconds",
+		Help:      "Latency of the Redis calls of the limiter.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"limiter", "command"}))
+	if err != nil {
+		return nil, err
+	}
+	return &metrics{
+		checks:      checks.WithLabelValues(name),
+		allowed:     allowed.WithLabelValues(name),
+		blocked:     blocked.WithLabelValues(name),
+		bypassed:    bypassed.WithLabelValues(name),
+		redisErrors: redisErrors.WithLabelValues(name),
+		latency:     latency.WithLabelValues(name),
+		redis:       redisLatency.MustCurryWith(prometheus.Labels{"limiter": name}),
+	}, nil
+}
+
+func register[T prometheus.Collector](reg prometheus.Registerer, c T) (T, error) {
+	if err := reg.Register(c); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if stderrors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return c, err
+	}
+	return c, nil
+}
+
+// observe records a finished check; m may be nil when metrics are disabled.
+func (m *metrics) observe(d time.Duration, res *CheckResult, err error) {
+	if m == nil {
+		return
+	}
+	m.checks.Inc()
+	m.latency.Observe(d.Seconds())
+	switch {
+	case res != nil && res.Allowed:
+		m.allowed.Inc()
+	case res != nil:
+		m.blocked.Inc()
+	case err != nil:
+		m.redisErrors.Inc()
+	}
+}
+
+func (m *metrics) observeRedis(command string, d time.Duration) {
+	if m != nil {
+		m.redis.WithLabelValues(command).Observe(d.Seconds())
+	}
+}
+
+func (m *metrics) bypass() {
+	if m != nil {
+		m.bypassed.Inc()
+	}
+}
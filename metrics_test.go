@@ -0,0 +1,72 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// gather returns the value of every counter and the sample count of every
+// histogram of reg for limiter.
+func gather(t *testing.T, reg *prometheus.Registry, limiter string) map[string]float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vals := make(map[string]float64)
+	for _, f := range families {
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() != "limiter" || l.GetValue() != limiter {
+					continue
+				}
+				if c := m.GetCounter(); c != nil {
+					vals[f.GetName()] = c.GetValue()
+				}
+				if h := m.GetHistogram(); h != nil {
+					vals[f.GetName()] = float64(h.GetSampleCount())
+				}
+			}
+		}
+	}
+	return vals
+}
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	_, r := testRedis(t)
+	opts := []Option{WithRedis(r), WithDuration(time.Minute), WithBlockTimes(2), WithWhiteList("w"), WithRegisterer(reg)}
+	a, err := NewLimiter("a", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// limiters sharing a registerer share the collectors
+	b, err := NewLimiter("b", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.Allow("x")
+	a.Allow("x")
+	a.Allow("w")
+	b.Allow("x")
+
+	want := map[string]float64{
+		"rate_limiter_checks_total":           3,
+		"rate_limiter_allowed_total":          2,
+		"rate_limiter_blocked_total":          1,
+		"rate_limiter_whitelist_bypass_total": 1,
+		"rate_limiter_redis_errors_total":     0,
+		"rate_limiter_check_duration_seconds": 3,
+	}
+	got := gather(t, reg, "a")
+	for name, v := range want {
+		if got[name] != v {
+			t.Errorf("%s = %v, want %v", name, got[name], v)
+		}
+	}
+	if got := gather(t, reg, "b"); got["rate_limiter_checks_total"] != 1 {
+		t.Errorf("checks of b = %v, want 1", got["rate_limiter_checks_total"])
+	}
+}
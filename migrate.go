@@ -0,0 +1,89 @@
+package rateLimiter
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-estar/redis"
+)
+
+// KeyMapper maps a key of the old key scheme to the new one; ok false leaves the
+// key alone.
+type KeyMapper func(key string) (newKey string, ok bool)
+
+// PrefixMapper moves the keys of the limiter or list name from to to. Only keys
+// continuing with a separator used by this package (":", "-", "@" or "#") are mapped,
+// so "api" does not capture the keys of "apiV2".
+func PrefixMapper(from, to string) KeyMapper {
+	return func(key string) (string, bool) {
+		rest := strings.TrimPrefix(key, from)
+		if len(rest) == len(key) || rest == "" || !strings.ContainsRune(":-@#", rune(rest[0])) {
+			return "", false
+		}
+		return to + rest, true
+	}
+}
+
+type MigrateResult struct {
+	Migrated int
+	Skipped  int //the new key already existed
+}
+
+// Migrate moves every key of the limiter or list name from to to, e.g. after
+// renaming a limiter or changing the application name used by NewWithConfig, so
+// the upgrade keeps counters and lists instead of silently resetting them.
+func Migrate(ctx context.Context, r *redis.Redis, from, to string, keep bool) (MigrateResult, error) {
+	return MigrateKeys(ctx, r, escapeGlob(from)+"*", PrefixMapper(from, to), keep)
+}
+
+// MigrateKeys renames every key matching the SCAN pattern match to the key given by
+// mapKey, keeping its TTL; with keep the key is copied instead, which needs Redis 6.2. New keys that
+// already exist are not overwritten. Keys are collected before anything is copied,
+// so new keys matching the pattern are not migrated twice. Run it while no instance
+// writes to the old keys.
+func MigrateKeys(ctx context.Context, r *redis.Redis, match string, mapKey KeyMapper, keep bool) (MigrateResult, error) {
+	var res MigrateResult
+	moves := make(map[string]string)
+	var cursor uint64
+	for {
+		keys, next, err := r.Scan(ctx, cursor, match, resetScanCount).Result()
+		if err != nil {
+			return res, err
+		}
+		for _, key := range keys {
+			if newKey, ok := mapKey(key); ok && newKey != key {
+				moves[key] = newKey
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+	for key, newKey := range moves {
+		moved, err := migrateKey(ctx, r, key, newKey, keep)
+		if err != nil {
+			return res, err
+		}
+		if moved {
+			res.Migrated++
+		} else {
+			res.Skipped++
+		}
+	}
+	return res, nil
+}
+
+// migrateKey keeps the TTL and never overwrites newKey; it reports false when
+// newKey exists or key has expired meanwhile.
+func migrateKey(ctx context.Context, r *redis.Redis, key, newKey string, keep bool) (bool, error) {
+	if keep {
+		n, err := r.Copy(ctx, key, newKey, 0, false).Result()
+		return n == 1, err
+	}
+	ok, err := r.RenameNX(ctx, key, newKey).Result()
+	if err != nil && strings.Contains(err.Error(), "no such key") {
+		return false, nil
+	}
+	return ok, err
+}
@@ -0,0 +1,63 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPrefixMapper(t *testing.T) {
+	mapKey := PrefixMapper("api", "v2")
+	for key, want := range map[string]string{
+		"api:x":        "v2:x",
+		"api-white":    "v2-white",
+		"api@tenant:x": "v2@tenant:x",
+		"apiV2:x":      "",
+		"api":          "",
+		"other-api:x":  "",
+	} {
+		got, ok := mapKey(key)
+		if ok != (want != "") || got != want {
+			t.Errorf("%s -> %q, %v, want %q", key, got, ok, want)
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	mr, rl := testLimiter(t, "old", WithDuration(time.Minute), WithBlockTimes(10))
+	ctx := context.Background()
+	rl.Allow("x")
+	if err := rl.AddBlockList("b", false); err != nil {
+		t.Fatal(err)
+	}
+	mr.Set("oldV2:x", "1")
+	mr.Set("new-block", "taken")
+
+	res, err := Migrate(ctx, rl.Redis, "old", "new", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Skipped != 1 || res.Migrated == 0 {
+		t.Fatalf("result %+v", res)
+	}
+	if !mr.Exists("new:x") || mr.Exists("old:x") {
+		t.Fatal("counter not moved")
+	}
+	if ttl := mr.TTL("new:x"); ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("counter TTL %v", ttl)
+	}
+	if v, _ := mr.Get("new-block"); v != "taken" || !mr.Exists("old-block") {
+		t.Fatal("existing key overwritten")
+	}
+	if !mr.Exists("oldV2:x") {
+		t.Fatal("key of another limiter moved")
+	}
+
+	res, err = Migrate(ctx, rl.Redis, "new", "copy", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Migrated == 0 || !mr.Exists("copy:x") || !mr.Exists("new:x") {
+		t.Fatalf("copy %+v", res)
+	}
+}
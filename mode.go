@@ -0,0 +1,103 @@
+package rateLimiter
+
+import (
+	"context"
+	stderrors "errors"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Mode is the emergency switch of a limiter, shared by all instances through Redis.
+type Mode int32
+
+const (
+	ModeNormal   Mode = iota
+	ModeAllowAll      //every request is allowed and nothing is counted
+	ModeBlockAll      //every request is rejected, whitelisted ids included
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeAllowAll:
+		return "allowAll"
+	case ModeBlockAll:
+		return "blockAll"
+	default:
+		return "normal"
+	}
+}
+
+// ParseMode is the inverse of Mode.String.
+func ParseMode(s string) (Mode, error) {
+	switch s {
+	case "normal", "":
+		return ModeNormal, nil
+	case "allowAll":
+		return ModeAllowAll, nil
+	case "blockAll":
+		return ModeBlockAll, nil
+	default:
+		return ModeNormal, stderrors.New("unknown mode: " + s)
+	}
+}
+
+func (rl *RateLimiter) loadMode(ctx context.Context) error {
+	val, err := rl.Redis.Get(ctx, rl.modeKey).Result()
+	if err != nil && err != goredis.Nil {
+		return err
+	}
+	mode, err := ParseMode(val)
+	if err != nil {
+		return err
+	}
+	if Mode(rl.mode.Swap(int32(mode))) != mode {
+		rl.Logger.Warn("mode changed", rl.fields("mode", mode.String())...)
+	}
+	return nil
+}
+
+// Mode returns the locally cached mode.
+func (rl *RateLimiter) Mode() Mode {
+	return Mode(rl.mode.Load())
+}
+
+func (rl *RateLimiter) SetMode(mode Mode, pub bool) error {
+	return rl.SetModeCtx(context.Background(), mode, pub)
+}
+
+// SetModeCtx switches the limiter into mode, for incident response; publish the
+// change so that every instance switches at once.
+func (rl *RateLimiter) SetModeCtx(ctx context.Context, mode Mode, pub bool) error {
+	if mode < ModeNormal || mode > ModeBlockAll {
+		return stderrors.New("mode不合法")
+	}
+	var err error
+	if mode == ModeNormal {
+		err = rl.Redis.Del(ctx, rl.modeKey).Err()
+	} else {
+		err = rl.Redis.Set(ctx, rl.modeKey, mode.String(), 0).Err()
+	}
+	if err != nil {
+		return err
+	}
+	rl.mode.Store(int32(mode))
+	rl.Logger.Warn("mode changed", rl.fields("mode", mode.String())...)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: "md"})
+	}
+	return nil
+}
+
+// modeResult decides id by the mode; ok is false in ModeNormal.
+func (rl *RateLimiter) modeResult(id string) (res *CheckResult, ok bool) {
+	switch rl.Mode() {
+	case ModeAllowAll:
+		return rl.whiteListResult(id), true
+	case ModeBlockAll:
+		res := rl.newResult(rl.primaryWindow(id), 0, 0)
+		rl.block(res, -1)
+		return res, true
+	default:
+		return nil, false
+	}
+}
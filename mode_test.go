@@ -0,0 +1,80 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMode(t *testing.T) {
+	_, r := testRedis(t)
+	var b *RateLimiter
+	opts := []Option{WithRedis(r), WithDuration(time.Minute), WithBlockTimes(2), WithWhiteList("w"),
+		WithPub(func(name, message string) error { return b.Sub(message) })}
+	a, err := NewLimiter("mode", append(opts, func(c *Config) { c.InstanceId = "a" })...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.Close(context.Background()) })
+	b, err = NewLimiter("mode", append(opts, func(c *Config) { c.InstanceId = "b" })...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close(context.Background()) })
+
+	if err := a.SetMode(ModeBlockAll, true); err != nil {
+		t.Fatal(err)
+	}
+	if a.Mode() != ModeBlockAll || b.Mode() != ModeBlockAll {
+		t.Fatalf("modes %v and %v", a.Mode(), b.Mode())
+	}
+	for _, id := range []string{"x", "w"} {
+		if res, err := b.Allow(id); res.Allowed || !IsBlocked(err) {
+			t.Fatalf("%s in blockAll: %+v, %v", id, res, err)
+		}
+	}
+
+	if err := a.SetMode(ModeAllowAll, true); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if res, err := b.Allow("x"); !res.Allowed || err != nil {
+			t.Fatalf("request %d in allowAll: %+v, %v", i+1, res, err)
+		}
+	}
+	if ins, _ := b.Inspect("x"); ins.Times != 0 {
+		t.Fatalf("allowAll counted %d requests", ins.Times)
+	}
+
+	// instances started later load the mode
+	c, err := NewLimiter("mode", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close(context.Background()) })
+	if c.Mode() != ModeAllowAll {
+		t.Fatalf("new instance mode %v", c.Mode())
+	}
+
+	if err := a.SetMode(ModeNormal, true); err != nil {
+		t.Fatal(err)
+	}
+	b.Allow("x")
+	if res, _ := b.Allow("x"); res.Allowed {
+		t.Fatal("normal mode not restored")
+	}
+	if err := a.SetMode(Mode(3), false); err == nil {
+		t.Fatal("unknown mode accepted")
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, m := range []Mode{ModeNormal, ModeAllowAll, ModeBlockAll} {
+		if got, err := ParseMode(m.String()); err != nil || got != m {
+			t.Errorf("ParseMode(%q) = %v, %v", m.String(), got, err)
+		}
+	}
+	if _, err := ParseMode("panic"); err == nil {
+		t.Error("unknown mode parsed")
+	}
+}
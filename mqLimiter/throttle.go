@@ -0,0 +1,86 @@
+package mqLimiter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+var (
+	ErrorNoKey     = errors.New("no rate limit key")
+	ErrorThrottled = errors.New("throttle wait exceeded")
+)
+
+type config struct {
+	maxWait time.Duration
+	prefix  string
+}
+
+type Option func(*config)
+
+// WithMaxWait gives up with ErrorThrottled once a message has waited d, so it can
+// be requeued instead of holding the consumer; 0 waits as long as ctx allows.
+func WithMaxWait(d time.Duration) Option {
+	return func(c *config) {
+		c.maxWait = d
+	}
+}
+
+// WithPrefix counts ids under prefix, to share one limiter between several queues.
+func WithPrefix(prefix string) Option {
+	return func(c *config) {
+		c.prefix = prefix
+	}
+}
+
+// Throttler paces message processing per id, e.g. per tenant, with the Wait
+// semantics of the limiter: a consumer over its budget sleeps until the window
+// opens, so the queue drains at the configured rate instead of failing messages.
+type Throttler struct {
+	rl *rateLimiter.RateLimiter
+	c  *config
+}
+
+func New(rl *rateLimiter.RateLimiter, opts ...Option) *Throttler {
+	c := &config{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return &Throttler{rl: rl, c: c}
+}
+
+// Throttle blocks until id may process one more message.
+func (t *Throttler) Throttle(ctx context.Context, id string) error {
+	return t.ThrottleN(ctx, id, 1)
+}
+
+// ThrottleN blocks until id may process n units, e.g. a batch of n messages.
+func (t *Throttler) ThrottleN(ctx context.Context, id string, n int) error {
+	if id == "" {
+		return ErrorNoKey
+	}
+	if t.c.maxWait <= 0 {
+		_, err := t.rl.WaitNCtx(ctx, t.c.prefix+id, n)
+		return err
+	}
+	wctx, cancel := context.WithTimeout(ctx, t.c.maxWait)
+	defer cancel()
+	_, err := t.rl.WaitNCtx(wctx, t.c.prefix+id, n)
+	if err == context.DeadlineExceeded && ctx.Err() == nil {
+		return ErrorThrottled
+	}
+	return err
+}
+
+// Handler wraps a message handler so every message is throttled by the id key
+// returns, whatever the queue client: Kafka, NATS, RabbitMQ and so on.
+func Handler[M any](t *Throttler, key func(M) string, h func(context.Context, M) error) func(context.Context, M) error {
+	return func(ctx context.Context, msg M) error {
+		if err := t.Throttle(ctx, key(msg)); err != nil {
+			return err
+		}
+		return h(ctx, msg)
+	}
+}
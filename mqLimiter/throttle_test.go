@@ -0,0 +1,59 @@
+package mqLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type message struct {
+	tenant string
+}
+
+func TestThrottler(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	rl, err := rateLimiter.NewLimiter("mq", rateLimiter.WithRedis(&redis.Redis{Client: client}),
+		rateLimiter.WithDuration(time.Minute), rateLimiter.WithBlockTimes(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rl.Close(context.Background()) })
+
+	th := New(rl, WithMaxWait(20*time.Millisecond), WithPrefix("orders:"))
+	handled := 0
+	h := Handler(th, func(m message) string { return m.tenant }, func(ctx context.Context, m message) error {
+		handled++
+		return nil
+	})
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := h(ctx, message{tenant: "t1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := h(ctx, message{tenant: "t1"}); err != ErrorThrottled {
+		t.Fatalf("got %v, want ErrorThrottled", err)
+	}
+	if err := h(ctx, message{}); err != ErrorNoKey {
+		t.Fatalf("got %v, want ErrorNoKey", err)
+	}
+	if handled != 2 {
+		t.Fatalf("handled %d messages, want 2", handled)
+	}
+	if !mr.Exists("mq:orders:t1") {
+		t.Fatal("id not counted under the prefix")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := th.Throttle(cancelled, "t1"); err != context.Canceled {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,87 @@
+package rateLimiter
+
+import (
+	"context"
+	stderrors "errors"
+	"strconv"
+	"time"
+)
+
+var ErrorReplay = stderrors.New("replayed nonce")
+
+func IsReplay(err error) bool {
+	return stderrors.Is(err, ErrorReplay)
+}
+
+// nonceKey prefixes id with its length, so that no other id and nonce pair,
+// e.g. "a:b" and "c" against "a" and "b:c", maps to the same key.
+func (rl *RateLimiter) nonceKey(id, nonce string) string {
+	return rl.Name + "-nonce:" + strconv.Itoa(len(id)) + ":" + id + ":" + nonce
+}
+
+func (rl *RateLimiter) nonceTTL() time.Duration {
+	if rl.NonceTTL == 0 {
+		return rl.Limits().Duration
+	}
+	return rl.NonceTTL
+}
+
+// sanitizeNonce validates nonce like an id, but without Normalizer, and always
+// hashes long ones: they are opaque values of the sender.
+func (rl *RateLimiter) sanitizeNonce(nonce string) (string, error) {
+	return sanitizeId(nonce, rl.MaxIdLength, true)
+}
+
+func (rl *RateLimiter) CheckNonce(id, nonce string) error {
+	return rl.CheckNonceCtx(context.Background(), id, nonce)
+}
+
+// CheckNonceCtx succeeds only the first time nonce is presented for id within
+// NonceTTL, on every instance, and returns ErrorReplay afterwards, e.g. to reject
+// redelivered webhooks. Ids rejected by the block list, DenyRules or ModeBlockAll
+// get their BlockedError; the white list doesn't exempt ids from the check.
+func (rl *RateLimiter) CheckNonceCtx(ctx context.Context, id, nonce string) error {
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return err
+	}
+	if nonce, err = rl.sanitizeNonce(nonce); err != nil {
+		return err
+	}
+	if res, ok := rl.modeResult(id); ok && !res.Allowed {
+		return rl.blockedError(id, res)
+	}
+	if rl.inBlockList(id) || matchRule(rl.denyRules, id) != "" {
+		return rl.blockedError(id, rl.blockListResult(id))
+	}
+	ctx, cancel := rl.checkContext(ctx)
+	defer cancel()
+	first, err := rl.Redis.SetNX(ctx, rl.nonceKey(id, nonce), rl.Clock.Now().Unix(), rl.nonceTTL()).Result()
+	if err != nil {
+		rl.Logger.Error("nonce check failed", rl.fields("id", id, "err", err)...)
+		return err
+	}
+	if !first {
+		rl.Logger.Info("nonce replayed", rl.fields("id", id, "nonce", nonce)...)
+		rl.emit(Event{Type: EventReplay, Id: id})
+		return ErrorReplay
+	}
+	return nil
+}
+
+func (rl *RateLimiter) ForgetNonce(id, nonce string) error {
+	return rl.ForgetNonceCtx(context.Background(), id, nonce)
+}
+
+// ForgetNonceCtx lets nonce be presented for id again, e.g. when processing the
+// request it came with failed and the sender should retry.
+func (rl *RateLimiter) ForgetNonceCtx(ctx context.Context, id, nonce string) error {
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return err
+	}
+	if nonce, err = rl.sanitizeNonce(nonce); err != nil {
+		return err
+	}
+	return rl.Redis.Del(ctx, rl.nonceKey(id, nonce)).Err()
+}
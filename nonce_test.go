@@ -0,0 +1,67 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckNonce(t *testing.T) {
+	var replays []string
+	mr, rl := testLimiter(t, "nonce", WithDuration(time.Minute), WithBlockTimes(10), WithNonceTTL(time.Hour),
+		WithWhiteList("w"), WithBlockList("b"), WithOnEvent(func(e Event) {
+			if e.Type == EventReplay {
+				replays = append(replays, e.Id)
+			}
+		}))
+	if err := rl.CheckNonce("a", "n1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.CheckNonce("a", "n1"); !IsReplay(err) {
+		t.Fatalf("replay = %v, want ErrorReplay", err)
+	}
+	if err := rl.CheckNonce("a", "n2"); err != nil {
+		t.Fatalf("new nonce rejected: %v", err)
+	}
+	if len(replays) != 1 || replays[0] != "a" {
+		t.Fatalf("replay events %v", replays)
+	}
+	if err := rl.ForgetNonce("a", "n2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.CheckNonce("a", "n2"); err != nil {
+		t.Fatalf("forgotten nonce rejected: %v", err)
+	}
+
+	// the white list doesn't exempt ids, the block list rejects them
+	rl.CheckNonce("w", "n1")
+	if err := rl.CheckNonce("w", "n1"); !IsReplay(err) {
+		t.Fatalf("replay of a white listed id = %v", err)
+	}
+	if err := rl.CheckNonce("b", "n1"); !IsBlocked(err) {
+		t.Fatalf("nonce of a block listed id = %v", err)
+	}
+
+	mr.FastForward(time.Hour)
+	if err := rl.CheckNonce("a", "n1"); err != nil {
+		t.Fatalf("nonce rejected after NonceTTL: %v", err)
+	}
+}
+
+func TestNonceKeysDontCollide(t *testing.T) {
+	_, rl := testLimiter(t, "nonce", WithDuration(time.Minute), WithBlockTimes(10))
+	if err := rl.CheckNonce("a:b", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.CheckNonce("a", "b:c"); err != nil {
+		t.Fatalf("nonce of another id rejected: %v", err)
+	}
+	if err := rl.CheckNonce("a:b", "c"); !IsReplay(err) {
+		t.Fatalf("replay = %v, want ErrorReplay", err)
+	}
+	if err := rl.ForgetNonce("a:b", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.CheckNonce("a:b", "c"); err != nil {
+		t.Fatalf("forgotten nonce rejected: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package rateLimiter
+
+import (
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// Normalizer canonicalizes an id before it is validated and keyed, so that
+// spellings of the same client share one budget and one list entry.
+type Normalizer func(id string) string
+
+// DefaultNormalizer trims spaces, strips the port of ip:port ids and rewrites IPs
+// and emails in their canonical form.
+var DefaultNormalizer = ChainNormalizers(NormalizeSpace, NormalizeHostPort, NormalizeIP, NormalizeEmail)
+
+// ChainNormalizers applies normalizers in order.
+func ChainNormalizers(normalizers ...Normalizer) Normalizer {
+	return func(id string) string {
+		for _, n := range normalizers {
+			id = n(id)
+		}
+		return id
+	}
+}
+
+func NormalizeSpace(id string) string {
+	return strings.TrimSpace(id)
+}
+
+// NormalizeHostPort strips the port of "ip:port" and "[ipv6]:port" ids. Other
+// ids containing ':' are left alone, so "user:42" keeps its suffix.
+func NormalizeHostPort(id string) string {
+	host, _, err := net.SplitHostPort(id)
+	if err != nil {
+		return id
+	}
+	if _, err := netip.ParseAddr(host); err != nil {
+		return id
+	}
+	return host
+}
+
+// NormalizeIP rewrites IP addresses in their canonical form: IPv6 compressed and
+// lowercase, IPv4-mapped IPv6 as IPv4.
+func NormalizeIP(id string) string {
+	addr, err := netip.ParseAddr(id)
+	if err != nil {
+		return id
+	}
+	return addr.Unmap().String()
+}
+
+// NormalizeEmail lowercases ids that look like an email address.
+func NormalizeEmail(id string) string {
+	at := strings.LastIndexByte(id, '@')
+	if at <= 0 || at == len(id)-1 || strings.ContainsAny(id, " \t") {
+		return id
+	}
+	return strings.ToLower(id)
+}
@@ -0,0 +1,46 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDefaultNormalizer(t *testing.T) {
+	for id, want := range map[string]string{
+		" 1.2.3.4 ":           "1.2.3.4",
+		"1.2.3.4:8080":        "1.2.3.4",
+		"[2001:DB8::1]:443":   "2001:db8::1",
+		"2001:0db8:0:0::1":    "2001:db8::1",
+		"::ffff:10.0.0.1":     "10.0.0.1",
+		"Alice@Example.COM":   "alice@example.com",
+		"user:42":             "user:42",
+		"host.example.com:80": "host.example.com:80",
+		"Bob":                 "Bob",
+	} {
+		if got := DefaultNormalizer(id); got != want {
+			t.Errorf("DefaultNormalizer(%q) = %q, want %q", id, got, want)
+		}
+	}
+}
+
+func TestNormalizer(t *testing.T) {
+	mr, rl := testLimiter(t, "normalize", WithDuration(time.Minute), WithBlockTimes(10),
+		WithNormalizer(DefaultNormalizer), WithBlockList("::ffff:10.0.0.9"))
+	for _, id := range []string{"1.2.3.4", "1.2.3.4:1234", " 1.2.3.4"} {
+		if _, err := rl.Check(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := mr.Get("normalize:1.2.3.4"); got != "3" {
+		t.Fatalf("spellings of one IP counted %q times in its key, want 3", got)
+	}
+	if _, err := rl.Check("10.0.0.9:80"); !IsBlocked(err) {
+		t.Fatalf("check of a normalized seed entry: %v", err)
+	}
+	if err := rl.AddWhiteList("Alice@Example.com", false); err != nil {
+		t.Fatal(err)
+	}
+	if !rl.whiteIds.has("alice@example.com") {
+		t.Fatalf("white list %v", rl.whiteIds.list())
+	}
+}
@@ -0,0 +1,65 @@
+package rateLimiter
+
+import (
+	"context"
+	stderrors "errors"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const DefaultOffendersPeriod = time.Hour
+
+type Offender struct {
+	Id     string
+	Blocks int //rejected requests in the current period
+	Times  int //current count of the primary window
+}
+
+// offendersKey is the sorted set of the period containing t.
+func (rl *RateLimiter) offendersKey(t time.Time) string {
+	period := rl.OffendersPeriod.Milliseconds()
+	return rl.Name + "-offenders:" + strconv.FormatInt(t.UnixMilli()/period*period, 10)
+}
+
+// recordOffender counts a rejection of id in the current period; see rejectionLog.
+func (rl *RateLimiter) recordOffender(id string) {
+	rl.rejections.addOffender(rl.offendersKey(rl.Clock.Now()), id)
+}
+
+func (rl *RateLimiter) TopOffenders(n int) ([]Offender, error) {
+	return rl.TopOffendersCtx(context.Background(), n)
+}
+
+// TopOffendersCtx returns the n ids rejected most often in the current period,
+// most rejected first. It needs Config.TrackOffenders.
+func (rl *RateLimiter) TopOffendersCtx(ctx context.Context, n int) ([]Offender, error) {
+	if !rl.TrackOffenders {
+		return nil, stderrors.New("TrackOffenders必须开启")
+	}
+	if n <= 0 {
+		return nil, stderrors.New("n必须大于0")
+	}
+	zs, err := rl.Redis.ZRevRangeWithScores(ctx, rl.offendersKey(rl.Clock.Now()), 0, int64(n-1)).Result()
+	if err != nil || len(zs) == 0 {
+		return nil, err
+	}
+	keys := make([]string, len(zs))
+	offenders := make([]Offender, len(zs))
+	for i, z := range zs {
+		id, _ := z.Member.(string)
+		offenders[i] = Offender{Id: id, Blocks: int(z.Score)}
+		keys[i] = rl.counterKey(id)
+	}
+	vals, err := rl.Redis.MGet(ctx, keys...).Result()
+	if err != nil && err != goredis.Nil {
+		return nil, err
+	}
+	for i, v := range vals {
+		if s, ok := v.(string); ok {
+			offenders[i].Times, _ = strconv.Atoi(s)
+		}
+	}
+	return offenders, nil
+}
@@ -0,0 +1,40 @@
+package rateLimiter
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTopOffenders(t *testing.T) {
+	_, rl := testLimiter(t, "offenders", WithDuration(time.Minute), WithBlockTimes(2), WithTopOffenders(time.Hour))
+	for id, n := range map[string]int{"a": 5, "b": 3, "c": 1} {
+		for i := 0; i < n; i++ {
+			rl.Allow(id)
+		}
+	}
+	if err := rl.flushRejections(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := rl.TopOffenders(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Offender{{Id: "a", Blocks: 4, Times: 2}, {Id: "b", Blocks: 2, Times: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("TopOffenders(10) = %+v, want %+v", got, want)
+	}
+	if got, _ := rl.TopOffenders(1); len(got) != 1 || got[0].Id != "a" {
+		t.Fatalf("TopOffenders(1) = %+v", got)
+	}
+	if _, err := rl.TopOffenders(0); err == nil {
+		t.Fatal("TopOffenders(0) accepted")
+	}
+
+	_, untracked := testLimiter(t, "untracked", WithDuration(time.Minute), WithBlockTimes(2))
+	if _, err := untracked.TopOffenders(1); err == nil {
+		t.Fatal("TopOffenders without TrackOffenders accepted")
+	}
+}
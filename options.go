@@ -0,0 +1,370 @@
+package rateLimiter
+
+import (
+	"time"
+
+	"github.com/go-estar/redis"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Option func(*Config)
+
+func NewLimiter(name string, opts ...Option) (*RateLimiter, error) {
+	c := &Config{Name: name}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return newRateLimiter(c)
+}
+
+func WithRedis(r *redis.Redis) Option {
+	return func(c *Config) {
+		c.Redis = r
+	}
+}
+
+func WithDuration(d time.Duration) Option {
+	return func(c *Config) {
+		c.Duration = d
+	}
+}
+
+func WithBlockTimes(times int) Option {
+	return func(c *Config) {
+		c.BlockTimes = times
+	}
+}
+
+func WithBlockDuration(d time.Duration) Option {
+	return func(c *Config) {
+		c.BlockDuration = d
+	}
+}
+
+func WithBlockError(err error) Option {
+	return func(c *Config) {
+		c.BlockError = err
+	}
+}
+
+func WithWhiteList(ids ...string) Option {
+	return func(c *Config) {
+		c.WhiteList = append(c.WhiteList, ids...)
+	}
+}
+
+func WithBlockList(ids ...string) Option {
+	return func(c *Config) {
+		c.BlockList = append(c.BlockList, ids...)
+	}
+}
+
+func WithPub(pub func(string, string) error) Option {
+	return func(c *Config) {
+		c.Pub = pub
+	}
+}
+
+func WithCustomHandler(handler func(int) error) Option {
+	return func(c *Config) {
+		c.CustomHandler = handler
+	}
+}
+
+func WithMaxIdLength(n int, hash bool) Option {
+	return func(c *Config) {
+		c.MaxIdLength = n
+		c.HashLongId = hash
+	}
+}
+
+func WithNormalizer(n Normalizer) Option {
+	return func(c *Config) {
+		c.Normalizer = n
+	}
+}
+
+func WithWindows(windows ...Window) Option {
+	return func(c *Config) {
+		c.Windows = append(c.Windows, windows...)
+	}
+}
+
+func WithAlignedWindows(loc *time.Location) Option {
+	return func(c *Config) {
+		c.AlignWindows = true
+		c.AlignLocation = loc
+	}
+}
+
+func WithClientTimestamps() Option {
+	return func(c *Config) {
+		c.ClientTimestamps = true
+	}
+}
+
+func WithDryRun(handler func(id string, res *CheckResult)) Option {
+	return func(c *Config) {
+		c.DryRun = true
+		c.DryRunHandler = handler
+	}
+}
+
+func WithClock(clock Clock) Option {
+	return func(c *Config) {
+		c.Clock = clock
+	}
+}
+
+func WithAllowRules(exprs ...string) Option {
+	return func(c *Config) {
+		c.AllowRules = append(c.AllowRules, exprs...)
+	}
+}
+
+func WithDenyRules(exprs ...string) Option {
+	return func(c *Config) {
+		c.DenyRules = append(c.DenyRules, exprs...)
+	}
+}
+
+func WithAuditLog(size int64) Option {
+	return func(c *Config) {
+		c.AuditLogSize = size
+	}
+}
+
+func WithResync(interval time.Duration) Option {
+	return func(c *Config) {
+		c.ResyncInterval = interval
+	}
+}
+
+func WithSyncChannel(channel string) Option {
+	return func(c *Config) {
+		c.SyncChannel = channel
+	}
+}
+
+func WithLegacySync() Option {
+	return func(c *Config) {
+		c.LegacySync = true
+	}
+}
+
+func WithListChangeHooks(white, block func(ListChange)) Option {
+	return func(c *Config) {
+		c.OnWhiteListChange = white
+		c.OnBlockListChange = block
+	}
+}
+
+func WithTrustedList(multiplier int, ids ...string) Option {
+	return func(c *Config) {
+		c.TrustedMultiplier = multiplier
+		c.TrustedList = append(c.TrustedList, ids...)
+	}
+}
+
+func WithEscalation(window time.Duration, durations ...time.Duration) Option {
+	return func(c *Config) {
+		c.EscalationWindow = window
+		c.Escalation = durations
+	}
+}
+
+func WithOnUnblock(fn func(id string)) Option {
+	return func(c *Config) {
+		c.OnUnblock = fn
+	}
+}
+
+func WithKeyspaceSync() Option {
+	return func(c *Config) {
+		c.KeyspaceSync = true
+	}
+}
+
+func WithListName(name string) Option {
+	return func(c *Config) {
+		c.ListName = name
+	}
+}
+
+func WithRegisterer(reg prometheus.Registerer) Option {
+	return func(c *Config) {
+		c.Registerer = reg
+	}
+}
+
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *Config) {
+		c.TracerProvider = tp
+	}
+}
+
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *Config) {
+		c.MeterProvider = mp
+	}
+}
+
+func WithLogger(l Logger) Option {
+	return func(c *Config) {
+		c.Logger = l
+	}
+}
+
+func WithOnEvent(fn func(Event)) Option {
+	return func(c *Config) {
+		c.OnEvent = fn
+	}
+}
+
+func WithTopOffenders(period time.Duration) Option {
+	return func(c *Config) {
+		c.TrackOffenders = true
+		c.OffendersPeriod = period
+	}
+}
+
+func WithExpvar(prefix string) Option {
+	return func(c *Config) {
+		c.ExpvarPrefix = prefix
+	}
+}
+
+func WithDecisionLog(size int64) Option {
+	return func(c *Config) {
+		c.DecisionLogSize = size
+	}
+}
+
+func WithTiers(resolver func(id string) string, tiers map[string]Tier) Option {
+	return func(c *Config) {
+		c.TierResolver = resolver
+		c.Tiers = tiers
+	}
+}
+
+func WithGeoResolver(resolver GeoResolver, countryTiers map[string]string) Option {
+	return func(c *Config) {
+		c.GeoResolver = resolver
+		c.CountryTiers = countryTiers
+	}
+}
+
+func WithSchedules(schedules ...Schedule) Option {
+	return func(c *Config) {
+		c.Schedules = append(c.Schedules, schedules...)
+	}
+}
+
+func WithBlockCache() Option {
+	return func(c *Config) {
+		c.BlockCache = true
+	}
+}
+
+func WithWriteBehind(interval time.Duration) Option {
+	return func(c *Config) {
+		c.WriteBehind = interval
+	}
+}
+
+func WithTimeouts(check, list time.Duration) Option {
+	return func(c *Config) {
+		c.CheckTimeout = check
+		c.ListTimeout = list
+	}
+}
+
+func WithFailOpen() Option {
+	return func(c *Config) {
+		c.FailOpen = true
+	}
+}
+
+func WithShards(shards int, ids ...string) Option {
+	return func(c *Config) {
+		c.Shards = shards
+		c.ShardedIds = append(c.ShardedIds, ids...)
+	}
+}
+
+func WithAsyncBlockList() Option {
+	return func(c *Config) {
+		c.AsyncBlockList = true
+	}
+}
+
+func WithBloomBlockList(falsePositive float64, interval time.Duration) Option {
+	return func(c *Config) {
+		c.BloomBlockList = true
+		c.BloomFalsePositive = falsePositive
+		c.BloomInterval = interval
+	}
+}
+
+func WithJanitor(interval time.Duration, maxEntries int) Option {
+	return func(c *Config) {
+		c.JanitorInterval = interval
+		c.MaxCacheEntries = maxEntries
+	}
+}
+
+func WithAnomalyDetector(d AnomalyDetector) Option {
+	return func(c *Config) {
+		c.Anomaly = &d
+	}
+}
+
+func WithGreyList(ratio float64, handler ChallengeHandler) Option {
+	return func(c *Config) {
+		c.GreyListRatio = ratio
+		c.ChallengeHandler = handler
+	}
+}
+
+func WithChallengeTTL(pass, block time.Duration) Option {
+	return func(c *Config) {
+		c.ChallengeTTL = pass
+		c.ChallengeBlockTTL = block
+	}
+}
+
+func WithSubnetBan(threshold int, window, duration time.Duration) Option {
+	return func(c *Config) {
+		c.SubnetBanThreshold = threshold
+		c.SubnetBanWindow = window
+		c.SubnetBanDuration = duration
+	}
+}
+
+func WithTarpit(delay, max time.Duration) Option {
+	return func(c *Config) {
+		c.TarpitDelay = delay
+		c.TarpitMaxDelay = max
+	}
+}
+
+func WithBypassKeys(maxTTL time.Duration, keys ...[]byte) Option {
+	return func(c *Config) {
+		c.BypassMaxTTL = maxTTL
+		c.BypassKeys = append(c.BypassKeys, keys...)
+	}
+}
+
+func WithNonceTTL(ttl time.Duration) Option {
+	return func(c *Config) {
+		c.NonceTTL = ttl
+	}
+}
+
+func WithSlowRedisThreshold(d time.Duration) Option {
+	return func(c *Config) {
+		c.SlowRedisThreshold = d
+	}
+}
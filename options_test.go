@@ -0,0 +1,37 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewLimiterErrors(t *testing.T) {
+	_, r := testRedis(t)
+	for name, opts := range map[string][]Option{
+		"no duration":       {WithRedis(r)},
+		"no redis":          {WithDuration(time.Minute)},
+		"negative block":    {WithRedis(r), WithDuration(time.Minute), WithBlockDuration(-time.Second)},
+		"invalid whitelist": {WithRedis(r), WithDuration(time.Minute), WithWhiteList("")},
+	} {
+		if rl, err := NewLimiter("opts", opts...); err == nil || rl != nil {
+			t.Errorf("%s: got %v, %v", name, rl, err)
+		}
+	}
+	if _, err := NewLimiter("", WithRedis(r), WithDuration(time.Minute)); err == nil {
+		t.Error("empty name accepted")
+	}
+}
+
+func TestNewLimiterOptions(t *testing.T) {
+	_, rl := testLimiter(t, "opts", WithDuration(time.Minute), WithBlockTimes(5),
+		WithBlockDuration(time.Hour), WithWhiteList("w"), WithMaxIdLength(8, true))
+	if rl.Name != "opts" || rl.Duration != time.Minute || rl.BlockTimes != 5 || rl.BlockDuration != time.Hour {
+		t.Fatalf("config %+v", rl.Config)
+	}
+	if !rl.HashLongId || rl.MaxIdLength != 8 {
+		t.Fatalf("id options not applied: %+v", rl.Config)
+	}
+	if res, err := rl.Allow("w"); err != nil || res.Used != 0 {
+		t.Fatalf("white listed id counted: %+v, %v", res, err)
+	}
+}
@@ -0,0 +1,81 @@
+package rateLimiter
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const meterName = "github.com/go-estar/rate-limiter"
+
+// otelMetrics mirrors metrics on the OTel metrics API.
+type otelMetrics struct {
+	attrs       metric.MeasurementOption
+	checks      metric.Int64Counter
+	allowed     metric.Int64Counter
+	blocked     metric.Int64Counter
+	bypassed    metric.Int64Counter
+	redisErrors metric.Int64Counter
+	latency     metric.Float64Histogram
+	redis       metric.Float64Histogram
+	name        string
+}
+
+func newOtelMetrics(mp metric.MeterProvider, name string) (*otelMetrics, error) {
+	meter := mp.Meter(meterName)
+	m := &otelMetrics{attrs: metric.WithAttributes(attribute.String("limiter", name)), name: name}
+	var err error
+	if m.checks, err = meter.Int64Counter("rate_limiter.checks", metric.WithDescription("Requests checked.")); err != nil {
+		return nil, err
+	}
+	if m.allowed, err = meter.Int64Counter("rate_limiter.allowed", metric.WithDescription("Requests allowed.")); err != nil {
+		return nil, err
+	}
+	if m.blocked, err = meter.Int64Counter("rate_limiter.blocked", metric.WithDescription("Requests rejected.")); err != nil {
+		return nil, err
+	}
+	if m.bypassed, err = meter.Int64Counter("rate_limiter.whitelist_bypass", metric.WithDescription("Requests allowed by the white list or AllowRules without counting.")); err != nil {
+		return nil, err
+	}
+	if m.redisErrors, err = meter.Int64Counter("rate_limiter.redis_errors", metric.WithDescription("Checks that failed on Redis.")); err != nil {
+		return nil, err
+	}
+	if m.latency, err = meter.Float64Histogram("rate_limiter.check.duration", metric.WithDescription("Latency of checks."), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if m.redis, err = meter.Float64Histogram("rate_limiter.redis.duration", metric.WithDescription("Latency of the Redis calls of the limiter."), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// observe records a finished check; m may be nil when OTel metrics are disabled.
+func (m *otelMetrics) observe(ctx context.Context, d time.Duration, res *CheckResult, err error) {
+	if m == nil {
+		return
+	}
+	m.checks.Add(ctx, 1, m.attrs)
+	m.latency.Record(ctx, d.Seconds(), m.attrs)
+	switch {
+	case res != nil && res.Allowed:
+		m.allowed.Add(ctx, 1, m.attrs)
+	case res != nil:
+		m.blocked.Add(ctx, 1, m.attrs)
+	case err != nil:
+		m.redisErrors.Add(ctx, 1, m.attrs)
+	}
+}
+
+func (m *otelMetrics) observeRedis(ctx context.Context, command string, d time.Duration) {
+	if m != nil {
+		m.redis.Record(ctx, d.Seconds(), metric.WithAttributes(attribute.String("limiter", m.name), attribute.String("command", command)))
+	}
+}
+
+func (m *otelMetrics) bypass(ctx context.Context) {
+	if m != nil {
+		m.bypassed.Add(ctx, 1, m.attrs)
+	}
+}
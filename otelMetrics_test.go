@@ -0,0 +1,90 @@
+package rateLimiter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// testMeter counts the measurements made on its instruments by name.
+type testMeter struct {
+	noop.Meter
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+type testMeterProvider struct {
+	noop.MeterProvider
+	meter *testMeter
+}
+
+func (mp testMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return mp.meter
+}
+
+func (m *testMeter) add(name string, n int64) {
+	m.mu.Lock()
+	m.counts[name] += n
+	m.mu.Unlock()
+}
+
+func (m *testMeter) count(name string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[name]
+}
+
+func (m *testMeter) Int64Counter(name string, _ ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	return testCounter{meter: m, name: name}, nil
+}
+
+func (m *testMeter) Float64Histogram(name string, _ ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	return testHistogram{meter: m, name: name}, nil
+}
+
+type testCounter struct {
+	noop.Int64Counter
+	meter *testMeter
+	name  string
+}
+
+func (c testCounter) Add(_ context.Context, n int64, _ ...metric.AddOption) { c.meter.add(c.name, n) }
+
+type testHistogram struct {
+	noop.Float64Histogram
+	meter *testMeter
+	name  string
+}
+
+func (h testHistogram) Record(context.Context, float64, ...metric.RecordOption) {
+	h.meter.add(h.name, 1)
+}
+
+func TestOtelMetrics(t *testing.T) {
+	tm := &testMeter{counts: make(map[string]int64)}
+	_, rl := testLimiter(t, "otel", WithDuration(time.Minute), WithBlockTimes(3), WithMeterProvider(testMeterProvider{meter: tm}))
+	for i := 0; i < 3; i++ {
+		rl.Allow("a")
+	}
+	if err := rl.AddWhiteList("w", false); err != nil {
+		t.Fatal(err)
+	}
+	rl.Allow("w")
+
+	for name, want := range map[string]int64{
+		"rate_limiter.checks":           4,
+		"rate_limiter.allowed":          3,
+		"rate_limiter.blocked":          1,
+		"rate_limiter.whitelist_bypass": 1,
+		"rate_limiter.redis_errors":     0,
+		"rate_limiter.check.duration":   4,
+	} {
+		if got := tm.count(name); got != want {
+			t.Errorf("%s = %d, want %d", name, got, want)
+		}
+	}
+}
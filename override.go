@@ -0,0 +1,144 @@
+package rateLimiter
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Override replaces the limiter defaults for a single id; zero fields keep the default.
+type Override struct {
+	BlockTimes int           `json:"blockTimes,omitempty"`
+	Duration   time.Duration `json:"duration,omitempty"`
+}
+
+// loadOverrides replaces the local overrides with those in Redis and drops the
+// cached blocks of the ids whose override changed.
+func (rl *RateLimiter) loadOverrides(ctx context.Context) error {
+	vals, err := rl.Redis.HGetAll(ctx, rl.overrideKey).Result()
+	if err != nil {
+		return err
+	}
+	overrides := make(map[string]Override, len(vals))
+	for id, val := range vals {
+		var o Override
+		if err := json.Unmarshal([]byte(val), &o); err != nil {
+			continue
+		}
+		overrides[id] = o
+	}
+	rl.overrideMu.Lock()
+	old := rl.overrides
+	rl.overrides = overrides
+	rl.overrideMu.Unlock()
+	for id, o := range old {
+		if n, ok := overrides[id]; !ok || n != o {
+			rl.blocked.del(id)
+		}
+	}
+	for id := range overrides {
+		if _, ok := old[id]; !ok {
+			rl.blocked.del(id)
+		}
+	}
+	return nil
+}
+
+func (rl *RateLimiter) SetOverride(id string, o Override, pub bool) error {
+	return rl.SetOverrideCtx(context.Background(), id, o, pub)
+}
+
+func (rl *RateLimiter) SetOverrideCtx(ctx context.Context, id string, o Override, pub bool) error {
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return err
+	}
+	if o.BlockTimes < 0 || o.Duration < 0 {
+		return stderrors.New("Override的BlockTimes和Duration不能小于0")
+	}
+	data, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+	if _, err := rl.Redis.HSet(ctx, rl.overrideKey, id, data).Result(); err != nil {
+		return err
+	}
+	rl.overrideMu.Lock()
+	rl.overrides[id] = o
+	rl.overrideMu.Unlock()
+	rl.blocked.del(id)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: "so", Id: id})
+	}
+	return nil
+}
+
+func (rl *RateLimiter) RemoveOverride(id string, pub bool) error {
+	return rl.RemoveOverrideCtx(context.Background(), id, pub)
+}
+
+func (rl *RateLimiter) RemoveOverrideCtx(ctx context.Context, id string, pub bool) error {
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return err
+	}
+	if _, err := rl.Redis.HDel(ctx, rl.overrideKey, id).Result(); err != nil {
+		return err
+	}
+	rl.overrideMu.Lock()
+	delete(rl.overrides, id)
+	rl.overrideMu.Unlock()
+	rl.blocked.del(id)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: "ro", Id: id})
+	}
+	return nil
+}
+
+func (rl *RateLimiter) GetOverride(id string) (*Override, error) {
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return nil, err
+	}
+	rl.overrideMu.RLock()
+	defer rl.overrideMu.RUnlock()
+	o, ok := rl.overrides[id]
+	if !ok {
+		return nil, nil
+	}
+	return &o, nil
+}
+
+// syncOverride refreshes the local copy of id after another instance changed it.
+func (rl *RateLimiter) syncOverride(ctx context.Context, id string) error {
+	val, err := rl.Redis.HGet(ctx, rl.overrideKey, id).Result()
+	if err == goredis.Nil {
+		rl.overrideMu.Lock()
+		delete(rl.overrides, id)
+		rl.overrideMu.Unlock()
+		rl.blocked.del(id)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var o Override
+	if err := json.Unmarshal([]byte(val), &o); err != nil {
+		return err
+	}
+	rl.overrideMu.Lock()
+	rl.overrides[id] = o
+	rl.overrideMu.Unlock()
+	rl.blocked.del(id)
+	return nil
+}
+
+func (rl *RateLimiter) override(id string) (Override, bool) {
+	rl.overrideMu.RLock()
+	defer rl.overrideMu.RUnlock()
+	o, ok := rl.overrides[id]
+	return o, ok
+}
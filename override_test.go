@@ -0,0 +1,51 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOverride(t *testing.T) {
+	_, r := testRedis(t)
+	opts := []Option{WithRedis(r), WithDuration(time.Minute), WithBlockTimes(2), WithBlockDuration(time.Hour)}
+	rl, err := NewLimiter("override", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.SetOverride("vip", Override{BlockTimes: 4}, false); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := rl.Check("vip"); err != nil {
+			t.Fatalf("check %d of the overridden id: %v", i, err)
+		}
+	}
+	if res, err := rl.Allow("vip"); !IsBlocked(err) || res.Limit != 4 {
+		t.Fatalf("overridden limit: %+v, %v", res, err)
+	}
+	rl.Check("a")
+	if _, err := rl.Check("a"); !IsBlocked(err) {
+		t.Fatalf("default limit not applied: %v", err)
+	}
+
+	// overrides live in Redis, so other instances pick them up
+	other, err := NewLimiter("override", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o, _ := other.GetOverride("vip"); o == nil || o.BlockTimes != 4 {
+		t.Fatalf("override not loaded: %+v", o)
+	}
+	if err := rl.RemoveOverride("vip", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Sub("ro-vip"); err != nil {
+		t.Fatal(err)
+	}
+	if o, _ := other.GetOverride("vip"); o != nil {
+		t.Fatalf("removed override still cached: %+v", o)
+	}
+	if err := rl.SetOverride("vip", Override{BlockTimes: -1}, false); err == nil {
+		t.Fatal("negative override accepted")
+	}
+}
@@ -0,0 +1,81 @@
+package rateLimiter
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/go-estar/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const syncRetryDelay = time.Second
+
+// publisher returns a Pub that publishes sync messages to channel.
+func publisher(r *redis.Redis, channel string) func(string, string) error {
+	return func(name string, message string) error {
+		return r.Publish(context.Background(), channel, message).Err()
+	}
+}
+
+// StartSync subscribes to Config.SyncChannel and applies the changes published by
+// other instances until ctx is done or the limiter is closed. Lost connections are
+// re-established and the lists are resynced, since messages may have been missed.
+func (rl *RateLimiter) StartSync(ctx context.Context) error {
+	if rl.SyncChannel == "" {
+		return stderrors.New("SyncChannel必须设置")
+	}
+	ps := rl.Redis.Subscribe(ctx, rl.SyncChannel)
+	if _, err := ps.Receive(ctx); err != nil {
+		ps.Close()
+		return err
+	}
+	rl.goBackground(func(closed context.Context) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() {
+			//Receive does not watch ctx, closing ps unblocks it
+			select {
+			case <-closed.Done():
+			case <-ctx.Done():
+			}
+			cancel()
+			ps.Close()
+		}()
+		rl.syncLoop(ctx, ps)
+	})
+	return nil
+}
+
+func (rl *RateLimiter) syncLoop(ctx context.Context, ps *goredis.PubSub) {
+	missed := false
+	for {
+		msg, err := ps.Receive(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if !missed {
+				rl.Logger.Error("sync subscription lost", rl.fields("err", err)...)
+			}
+			missed = true
+			timer := time.NewTimer(syncRetryDelay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			continue
+		}
+		switch m := msg.(type) {
+		case *goredis.Subscription:
+			if missed {
+				rl.ResyncCtx(ctx)
+				missed = false
+			}
+		case *goredis.Message:
+			rl.SubCtx(ctx, m.Payload)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStartSync(t *testing.T) {
+	mr, r := testRedis(t)
+	opts := []Option{WithRedis(r), WithDuration(time.Minute), WithBlockTimes(10), WithSyncChannel("sync")}
+	a, err := NewLimiter("sync", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { a.Close(context.Background()) })
+	b, err := NewLimiter("sync", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close(context.Background()) })
+	if err := b.StartSync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := mr.PubSubNumSub("sync")["sync"]; n != 1 {
+		t.Fatalf("%d subscribers, want 1", n)
+	}
+
+	if err := a.AddBlockList("x", true); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for !b.blockIds.has("x") {
+		if time.Now().After(deadline) {
+			t.Fatal("published change not applied by the subscriber")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	b.Close(context.Background())
+	deadline = time.Now().Add(time.Second)
+	for mr.PubSubNumSub("sync")["sync"] != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("subscription kept after Close")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestStartSyncNoChannel(t *testing.T) {
+	_, rl := testLimiter(t, "sync", WithDuration(time.Minute), WithBlockTimes(10))
+	if err := rl.StartSync(context.Background()); err == nil {
+		t.Fatal("StartSync without SyncChannel accepted")
+	}
+}
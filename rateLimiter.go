@@ -5,8 +5,13 @@ import (
 	stderrors "errors"
 	"github.com/go-estar/config"
 	"github.com/go-estar/redis"
-	"github.com/thoas/go-funk"
-	"strings"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"regexp"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -17,16 +22,84 @@ var (
 )
 
 type Config struct {
-	Name          string
-	Duration      time.Duration
-	BlockTimes    int
-	BlockDuration time.Duration //0=ever
-	BlockError    error
-	Redis         *redis.Redis
-	WhiteList     []string
-	BlockList     []string
-	Pub           func(string, string) error
-	CustomHandler func(int) error
+	Name               string
+	Duration           time.Duration
+	BlockTimes         int
+	BlockDuration      time.Duration //0=ever
+	BlockError         error
+	Redis              *redis.Redis
+	WhiteList          []string
+	BlockList          []string
+	Pub                func(string, string) error
+	CustomHandler      func(int) error
+	MaxIdLength        int            //0=DefaultMaxIdLength
+	HashLongId         bool           //hash ids longer than MaxIdLength instead of rejecting them
+	Normalizer         Normalizer     //canonicalize ids before keying, nil=ids are used as given; see DefaultNormalizer
+	Windows            []Window       //extra windows evaluated together with Duration/BlockTimes
+	AlignWindows       bool           //reset every window at multiples of its Duration on the clock, e.g. at the top of each hour, instead of Duration after its first request
+	AlignLocation      *time.Location //time zone of aligned windows, nil=UTC
+	ClientTimestamps   bool           //time aligned windows and subnet bans by Clock instead of Redis TIME, e.g. to drive them with a FakeClock
+	DryRun             bool           //count and report blocks but never enforce them
+	DryRunHandler      func(id string, res *CheckResult)
+	Clock              Clock                  //nil=system clock
+	AllowRules         []string               //regular expressions; matching ids bypass the limiter like WhiteList
+	DenyRules          []string               //regular expressions; matching ids are rejected like BlockList
+	InstanceId         string                 //""=hostname-pid, recorded in the audit log
+	AuditLogSize       int64                  //approximate cap of the list mutation audit stream, 0=disabled
+	ResyncInterval     time.Duration          //reload the lists from Redis periodically, 0=never
+	KeyspaceSync       bool                   //reload a list, the mode, the limits or the overrides on Redis keyspace notifications, needs notify-keyspace-events "Kg$hsz"
+	SyncChannel        string                 //Redis channel used by StartSync, nil Pub publishes to it
+	LegacySync         bool                   //publish the old "op-id" messages for instances that only understand them
+	OnWhiteListChange  func(ListChange)       //called after local and replicated white list changes, must not block
+	OnBlockListChange  func(ListChange)       //called after local and replicated block list changes, must not block
+	TrustedList        []string               //ids limited at TrustedMultiplier times the normal limits instead of bypassing them
+	TrustedMultiplier  int                    //0=DefaultTrustedMultiplier
+	Escalation         []time.Duration        //block durations for the 1st, 2nd, ... violation instead of BlockDuration, 0=ever
+	EscalationWindow   time.Duration          //violations are forgotten after this long without a new one, 0=DefaultEscalationWindow
+	OnUnblock          func(id string)        //called once when a temporary block of id ends, must not block
+	ListName           string                 //""=Name, limiters with the same ListName share their white, block and trusted lists
+	Registerer         prometheus.Registerer  //nil=no metrics
+	TracerProvider     trace.TracerProvider   //nil=no tracing
+	MeterProvider      metric.MeterProvider   //nil=no OTel metrics
+	Logger             Logger                 //nil=discard
+	OnEvent            func(Event)            //called for every decision, unblock and list change, must not block
+	TrackOffenders     bool                   //count rejected requests per id for TopOffenders
+	OffendersPeriod    time.Duration          //TopOffenders ranks this period, 0=DefaultOffendersPeriod
+	ExpvarPrefix       string                 //publish counters in the expvar map of this name, ""=disabled
+	DecisionLogSize    int64                  //approximate cap of the block decision stream, 0=disabled
+	SlowRedisThreshold time.Duration          //log Redis calls slower than this, 0=never
+	TierResolver       func(id string) string //maps an id to a key of Tiers, called on every check so it must be fast
+	Tiers              map[string]Tier        //limits per tier, ids of unknown tiers get the defaults
+	GeoResolver        GeoResolver            //resolves the country of IP ids for CountryTiers and "country:XX" list entries, nil=disabled
+	CountryTiers       map[string]string      //country code, or CountryDefault, to a key of Tiers or "" for the defaults, for ids TierResolver leaves without a tier
+	Schedules          []Schedule             //the first schedule matching the current time replaces Duration/BlockTimes
+	BlockCache         bool                   //reject ids blocked by this instance from memory until the block ends, without asking Redis
+	WriteBehind        time.Duration          //count checks locally and flush them to Redis at this interval, 0=every check goes to Redis; not with Windows
+	CheckTimeout       time.Duration          //bound the Redis calls of each check, 0=only the caller's context; needs a client with ContextTimeoutEnabled
+	ListTimeout        time.Duration          //bound each white, block or trusted list change, 0=only the caller's context; needs a client with ContextTimeoutEnabled
+	FailOpen           bool                   //allow checks that fail or time out in Redis instead of returning the error
+	AsyncBlockList     bool                   //add ids reaching BlockTimes with BlockDuration 0 to the block list in the background, retrying failures
+	BloomBlockList     bool                   //hold only a Bloom filter of the permanent block list entries, verifying hits in Redis; GetBlockList and Stats miss them
+	BloomFalsePositive float64                //false positive rate of the filter, 0=DefaultBloomFalsePositive
+	BloomInterval      time.Duration          //rebuild the filter from Redis this often, 0=DefaultBloomInterval
+	JanitorInterval    time.Duration          //evict expired entries from local caches this often, 0=DefaultJanitorInterval when BlockCache or WriteBehind is set
+	MaxCacheEntries    int                    //cap of the ids held by BlockCache and WriteBehind each, 0=DefaultMaxCacheEntries
+	Shards             int                    //split the counters of ShardedIds over this many keys, each enforcing its share of the limits, 0 or 1=no sharding
+	ShardedIds         []string               //hot ids whose counters are sharded; Inspect, RetryAfter and TopOffenders don't see their shards
+	Anomaly            *AnomalyDetector       //flag, and optionally block, ids with abusive patterns below the limits, nil=disabled
+	GreyListRatio      float64                //challenge ids that used more than this part of the primary window limit, 0=disabled
+	ChallengeHandler   ChallengeHandler       //decides requests of greylisted ids, nil=reject them with a ChallengeError
+	ChallengeTTL       time.Duration          //a passed challenge exempts the id from the greylist this long, 0=its primary window Duration
+	ChallengeBlockTTL  time.Duration          //block ids failing a challenge this long, 0=BlockDuration, permanently if that is 0 too
+	SubnetBanThreshold int                    //block the /24 or /64 of IP ids once this many of its ids were blocked within SubnetBanWindow, 0=disabled
+	SubnetBanWindow    time.Duration          //0=DefaultSubnetBanWindow
+	SubnetBanDuration  time.Duration          //0=DefaultSubnetBanDuration
+	TarpitDelay        time.Duration          //hold requests over the limits this long per such request in the window and then allow them, instead of rejecting or blocking the id, 0=reject
+	TarpitMaxDelay     time.Duration          //cap of the hold, 0=DefaultTarpitMaxDelay
+	BypassKeys         [][]byte               //HMAC keys of bypass tokens, the first signs new ones and all verify them, nil=disabled
+	BypassMaxTTL       time.Duration          //reject bypass tokens valid for longer than this, 0=DefaultBypassMaxTTL
+	NonceTTL           time.Duration          //CheckNonce remembers nonces this long, 0=Duration
+	tenant             string                 //set on ForTenant views
 }
 
 func NewWithConfig(conf *config.Config, c *Config) *RateLimiter {
@@ -35,197 +108,653 @@ func NewWithConfig(conf *config.Config, c *Config) *RateLimiter {
 }
 
 func New(c *Config) *RateLimiter {
-	if c == nil {
-		panic("config必须设置")
-	}
-	if c.Name == "" {
-		panic("Name必须设置")
+	rl, err := newRateLimiter(c)
+	if err != nil {
+		panic(err.Error())
 	}
-	if c.Duration == 0 {
-		panic("Duration必须设置")
+	return rl
+}
+
+func newRateLimiter(c *Config) (*RateLimiter, error) {
+	warnings, err := c.validate()
+	if err != nil {
+		return nil, err
 	}
-	if c.BlockDuration < 0 {
-		panic("BlockDuration不能小于0")
+	if c.MaxIdLength == 0 {
+		c.MaxIdLength = DefaultMaxIdLength
 	}
-
 	if c.BlockError == nil {
 		c.BlockError = ErrorBlock
 	}
+	if c.Clock == nil {
+		c.Clock = systemClock{}
+	}
+	if c.Logger == nil {
+		c.Logger = nopLogger{}
+	}
+	if c.InstanceId == "" {
+		c.InstanceId = defaultInstanceId()
+	}
+	if c.SyncChannel != "" && c.Pub == nil {
+		c.Pub = publisher(c.Redis, c.SyncChannel)
+	}
+	if c.TrustedMultiplier == 0 {
+		c.TrustedMultiplier = DefaultTrustedMultiplier
+	}
+	if c.EscalationWindow == 0 {
+		c.EscalationWindow = DefaultEscalationWindow
+	}
+	if c.OffendersPeriod == 0 {
+		c.OffendersPeriod = DefaultOffendersPeriod
+	}
 
 	rl := RateLimiter{
-		Config: c,
+		Config:    c,
+		whiteIds:  newIdList(),
+		blockIds:  newIdList(),
+		trustIds:  newIdList(),
+		overrides: make(map[string]Override),
 	}
-	rl.whiteListKey = rl.Name + "-white"
-	rl.blockListKey = rl.Name + "-block"
-	for _, val := range c.WhiteList {
-		rl.whiteList = append(rl.whiteList, val)
+	rl.sender = newSender()
+	listName := rl.Name
+	if c.ListName != "" {
+		listName = c.ListName
 	}
-	for _, val := range c.BlockList {
-		rl.blockList = append(rl.blockList, val)
+	rl.counterPrefix = rl.Name + ":"
+	rl.globalKey = rl.Name + "-global"
+	rl.keySuffixes = keySuffixes(c.Windows)
+	rl.shardPrefixes = shardPrefixes(rl.Name, c.Shards)
+	rl.whiteListKey = listName + "-white"
+	rl.blockListKey = listName + "-block"
+	rl.trustListKey = listName + "-trust"
+	rl.overrideKey = rl.Name + "-override"
+	rl.limitsKey = rl.Name + "-config"
+	rl.modeKey = rl.Name + "-mode"
+	rl.whiteTTLKey = rl.whiteListKey + "-ttl"
+	rl.blockTTLKey = rl.blockListKey + "-ttl"
+	rl.whiteMetaKey = rl.whiteListKey + "-meta"
+	rl.blockMetaKey = rl.blockListKey + "-meta"
+	rl.auditKey = rl.Name + "-audit"
+	rl.decisionKey = rl.Name + "-decisions"
+	rl.lc.init()
+	rl.tracer = newTracer(c.TracerProvider)
+	rl.spanOption = newSpanOption(c.Name)
+	for _, w := range warnings {
+		rl.Logger.Warn("config warning", rl.fields("warning", w.Error())...)
 	}
-	whiteList, err := rl.Redis.SMembers(context.Background(), rl.whiteListKey).Result()
-	if err == nil {
-		for _, val := range whiteList {
-			if !funk.ContainsString(rl.whiteList, val) {
-				rl.whiteList = append(rl.whiteList, val)
-			}
+	if c.Registerer != nil {
+		if rl.metrics, err = newMetrics(c.Registerer, c.Name); err != nil {
+			return nil, err
 		}
 	}
-	blockList, err := rl.Redis.SMembers(context.Background(), rl.blockListKey).Result()
-	if err == nil {
-		for _, val := range blockList {
-			if !funk.ContainsString(rl.blockList, val) {
-				rl.blockList = append(rl.blockList, val)
-			}
+	if c.MeterProvider != nil {
+		if rl.otelMetrics, err = newOtelMetrics(c.MeterProvider, c.Name); err != nil {
+			return nil, err
+		}
+	}
+	if c.ExpvarPrefix != "" {
+		if rl.expvarMetrics, err = newExpvarMetrics(c.ExpvarPrefix, c.Name); err != nil {
+			return nil, err
 		}
 	}
-	return &rl
+	rl.schedules, _ = compileSchedules(c.Schedules)
+	if rl.metrics != nil || rl.otelMetrics != nil || c.SlowRedisThreshold > 0 {
+		rl.installRedisHook()
+	}
+	rl.allowRules, _ = compileRules(c.AllowRules)
+	rl.denyRules, _ = compileRules(c.DenyRules)
+	whiteList, _ := rl.sanitizeIds(c.WhiteList)
+	blockList, _ := rl.sanitizeIds(c.BlockList)
+	trustedList, _ := rl.sanitizeIds(c.TrustedList)
+	shardedIds, _ := rl.sanitizeIds(c.ShardedIds)
+	rl.shardedIds = make(map[string]struct{}, len(shardedIds))
+	for _, id := range shardedIds {
+		rl.shardedIds[id] = struct{}{}
+	}
+	rl.whiteIds.reset(whiteList)
+	rl.blockIds.reset(blockList)
+	rl.trustIds.reset(trustedList)
+	if err := rl.loadWhiteList(context.Background()); err != nil {
+		rl.Logger.Error("load white list failed", rl.fields("err", err)...)
+	}
+	if err := rl.loadBlockList(context.Background()); err != nil {
+		rl.Logger.Error("load block list failed", rl.fields("err", err)...)
+	}
+	if err := rl.loadTrustedList(context.Background()); err != nil {
+		rl.Logger.Error("load trusted list failed", rl.fields("err", err)...)
+	}
+	if err := rl.loadOverrides(context.Background()); err != nil {
+		rl.Logger.Error("load overrides failed", rl.fields("err", err)...)
+	}
+	if err := rl.loadLimits(context.Background()); err != nil {
+		rl.limits.Store(&Limits{Duration: c.Duration, BlockTimes: c.BlockTimes, BlockDuration: c.BlockDuration})
+		rl.Logger.Error("load config failed", rl.fields("err", err)...)
+	}
+	if err := rl.loadMode(context.Background()); err != nil {
+		rl.Logger.Error("load mode failed", rl.fields("err", err)...)
+	}
+	if c.ResyncInterval > 0 {
+		rl.goBackground(rl.resyncLoop)
+	}
+	if c.KeyspaceSync {
+		rl.goBackground(rl.keyspaceLoop)
+	}
+	if rl.tracksUnblocks() {
+		rl.goBackground(rl.unblockLoop)
+	}
+	if c.BloomBlockList {
+		rl.goBackground(rl.bloomLoop)
+	}
+	if rl.runsJanitor() {
+		rl.goBackground(rl.janitorLoop)
+	}
+	rl.listPurge.init()
+	rl.goBackground(rl.listPurgeLoop)
+	rl.onClose(rl.purgeLists)
+	if rl.queuesBlocks() {
+		rl.blockQueue.init()
+		rl.goBackground(rl.blockQueueLoop)
+		rl.onClose(func(ctx context.Context) error {
+			return rl.drainBlockQueue(ctx, true)
+		})
+	}
+	if rl.logsRejections() {
+		rl.rejections.init()
+		rl.goBackground(rl.rejectionLogLoop)
+		rl.onClose(rl.flushRejections)
+	}
+	if c.WriteBehind > 0 {
+		rl.goBackground(rl.writeBehindLoop)
+		rl.onClose(rl.flushCounters)
+	}
+	return &rl, nil
 }
 
 type RateLimiter struct {
 	*Config
-	whiteList    []string
-	blockList    []string
-	whiteListKey string
-	blockListKey string
+	whiteIds      *idList
+	blockIds      *idList
+	trustIds      *idList
+	allowRules    []*regexp.Regexp
+	denyRules     []*regexp.Regexp
+	schedules     []schedule
+	sender        string //random id published as SyncMessage.Sender
+	counterPrefix string
+	globalKey     string
+	keySuffixes   []string
+	shardPrefixes []string
+	shardedIds    map[string]struct{}
+	whiteListKey  string
+	blockListKey  string
+	trustListKey  string
+	whiteTTLKey   string
+	blockTTLKey   string
+	whiteMetaKey  string
+	blockMetaKey  string
+	auditKey      string
+	decisionKey   string
+	overrideKey   string
+	limitsKey     string
+	limits        atomic.Pointer[Limits]
+	modeKey       string
+	mode          atomic.Int32
+	overrideMu    sync.RWMutex
+	overrides     map[string]Override
+	unblocks      expirySet
+	blocked       blockCache
+	buffer        writeBuffer
+	anomalies     anomalyTracker
+	blockQueue    blockQueue
+	listPurge     listPurge
+	rejections    rejectionLog
+	bloom         atomic.Pointer[bloomFilter]
+	lastSync      atomic.Int64 //unix milliseconds
+	metrics       *metrics
+	otelMetrics   *otelMetrics
+	expvarMetrics *expvarMetrics
+	tracer        trace.Tracer
+	spanOption    trace.SpanStartOption
+	tenants       tenantViews
+	lc            lifecycle
 }
 
 func (rl *RateLimiter) Check(id string) (int, error) {
-	if funk.Contains(rl.whiteList, id) {
-		return 0, nil
+	return rl.CheckCtx(context.Background(), id)
+}
+
+func (rl *RateLimiter) CheckCtx(ctx context.Context, id string) (int, error) {
+	res, err := rl.AllowCtx(ctx, id)
+	if res == nil {
+		return 0, err
+	}
+	return res.Used, err
+}
+
+func (rl *RateLimiter) Allow(id string) (*CheckResult, error) {
+	return rl.AllowCtx(context.Background(), id)
+}
+
+func (rl *RateLimiter) AllowCtx(ctx context.Context, id string) (*CheckResult, error) {
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return nil, err
+	}
+	return rl.checked(ctx, id, func(ctx context.Context) (*CheckResult, error) {
+		return rl.allow(ctx, id, 1)
+	})
+}
+
+// enforce applies DryRun to the outcome of a check.
+func (rl *RateLimiter) enforce(res *CheckResult, err error) (*CheckResult, error) {
+	var blocked *BlockedError
+	if rl.DryRun && stderrors.As(err, &blocked) {
+		res.Allowed = true
+		res.DryRunBlocked = true
+		if rl.DryRunHandler != nil {
+			rl.DryRunHandler(blocked.Id, res)
+		}
+		return res, nil
+	}
+	return res, err
+}
+
+func (rl *RateLimiter) allow(ctx context.Context, id string, n int) (*CheckResult, error) {
+	if res, ok := rl.modeResult(id); ok {
+		if res.Allowed {
+			return res, nil
+		}
+		return res, rl.blockedError(id, res)
+	}
+	if rl.inWhiteList(id) || matchRule(rl.allowRules, id) != "" {
+		rl.metrics.bypass()
+		rl.otelMetrics.bypass(ctx)
+		rl.expvarMetrics.bypass()
+		return rl.whiteListResult(id), nil
 	}
-	if funk.Contains(rl.blockList, id) {
-		return 0, rl.BlockError
+	if t, ok := rl.bypassToken(ctx, id); ok {
+		if t.Multiplier == 0 {
+			rl.metrics.bypass()
+			rl.otelMetrics.bypass(ctx)
+			rl.expvarMetrics.bypass()
+			return rl.whiteListResult(id), nil
+		}
+		ctx = context.WithValue(ctx, bypassMultiplierKey{}, t.Multiplier)
+	}
+	if rl.inBlockList(id) || matchRule(rl.denyRules, id) != "" {
+		res := rl.blockListResult(id)
+		return res, rl.blockedError(id, res)
+	}
+	res, err := rl.count(ctx, id, n)
+	if err != nil || res == nil {
+		return res, err
+	}
+	if res.Delay > 0 {
+		if err := rl.hold(ctx, res); err != nil {
+			return nil, err
+		}
+		return res, nil
 	}
+	return rl.greylist(ctx, id, res)
+}
 
-	ctx := context.Background()
-	times, err := rl.Redis.FrequencyLimit(ctx, rl.Name+":"+id, rl.BlockTimes, rl.Duration)
+func (rl *RateLimiter) count(ctx context.Context, id string, n int) (*CheckResult, error) {
+	if res, ok := rl.cachedBlockResult(id); ok {
+		return res, rl.blockedError(id, res)
+	}
+	if res, ok := rl.buffered(id, n); ok {
+		if rl.CustomHandler != nil {
+			return res, rl.CustomHandler(res.Used)
+		}
+		return res, nil
+	}
+	ctx, cancel := rl.checkContext(ctx)
+	defer cancel()
+	if err := rl.flushId(ctx, id); err != nil {
+		rl.Logger.Error("flush counter failed", rl.fields("id", id, "err", err)...)
+	}
+	res, err := rl.countRedis(ctx, id, n)
+	if res != nil {
+		rl.bufferSynced(id, res)
+	} else if res, ok := rl.failOpen(id, err); ok {
+		return res, nil
+	}
+	return res, err
+}
+
+func (rl *RateLimiter) countRedis(ctx context.Context, id string, n int) (*CheckResult, error) {
+	windows := rl.checkWindows(ctx, id)
+	c, err := rl.incr(ctx, id, windows, n)
 	if err != nil {
-		if err.Error() == "reach limit" {
-			return 0, rl.BlockError
+		rl.Logger.Error("check failed", rl.fields("id", id, "err", err)...)
+		return nil, err
+	}
+	w := windows[0]
+	if c.window > 0 {
+		w = windows[c.window]
+	}
+	res := rl.newResult(w, c.times, c.ttl)
+	if rl.TarpitDelay > 0 && !rl.DryRun && id != globalId && (c.reached || c.window >= 0) {
+		// tarpitted ids are held by allow instead of being rejected or blocked
+		if res.Delay, err = rl.tarpitDelay(ctx, id, c.ttl); err != nil {
+			return nil, err
 		}
-		return 0, err
+		res.Remaining = 0
+		return res, nil
+	}
+	if c.reached || c.window > 0 || (c.window == 0 && id == globalId) {
+		rl.block(res, c.ttl)
+		rl.cacheBlock(id, res, c.ttl)
+		rl.Logger.Debug("request rejected", rl.fields("id", id, "times", c.times, "retryAfter", c.ttl)...)
+		return res, rl.blockedError(id, res)
 	}
-	if rl.BlockTimes > 0 && times >= rl.BlockTimes {
-		if rl.BlockDuration == 0 {
-			rl.AddBlockList(id, true)
+	if c.window == 0 {
+		blockDuration := rl.Limits().BlockDuration
+		if len(rl.Escalation) > 0 {
+			if blockDuration, err = rl.escalate(ctx, id); err != nil {
+				return nil, err
+			}
+		}
+		if blockDuration == 0 {
+			if !rl.DryRun {
+				rl.autoBlock(ctx, id, c.times)
+			}
+			rl.block(res, -1)
 		} else {
-			rl.Redis.Expire(ctx, rl.Name+":"+id, rl.BlockDuration)
+			if !rl.DryRun {
+				rl.blockCounter(ctx, id, blockDuration)
+				if rl.tracksUnblocks() {
+					rl.unblocks.set(id, rl.Clock.Now().Add(blockDuration))
+				}
+			}
+			rl.block(res, blockDuration)
+			rl.cacheBlock(id, res, blockDuration)
+			rl.Logger.Info("id blocked", rl.fields("id", id, "times", c.times, "duration", blockDuration)...)
 		}
-		return times, rl.BlockError
+		rl.recordSubnetOffense(ctx, id)
+		return res, rl.blockedError(id, res)
 	}
 	if rl.CustomHandler != nil {
-		return times, rl.CustomHandler(times)
+		return res, rl.CustomHandler(c.times)
 	}
-	return times, nil
+	return res, nil
 }
 
 func (rl *RateLimiter) CheckReset(id string) error {
-	_, err := rl.Redis.Del(context.Background(), rl.Name+":"+id).Result()
+	return rl.CheckResetCtx(context.Background(), id)
+}
+
+func (rl *RateLimiter) CheckResetCtx(ctx context.Context, id string) error {
+	id, err := rl.sanitizeStoredId(id)
+	if err != nil {
+		return err
+	}
+	return rl.checkReset(ctx, id)
+}
+
+func (rl *RateLimiter) checkReset(ctx context.Context, id string) error {
+	_, err := rl.Redis.Del(ctx, rl.allCounterKeys(id)...).Result()
+	rl.resetLocal(id)
 	return err
 }
 
+// resetLocal forgets the local state of id kept alongside its counters.
+func (rl *RateLimiter) resetLocal(id string) {
+	rl.unblocks.del(id)
+	rl.blocked.del(id)
+	rl.dropBuffered(id)
+}
+
 func (rl *RateLimiter) Sub(message string) error {
-	str := strings.Split(message, "-")
-	if len(str) != 2 {
+	return rl.SubCtx(context.Background(), message)
+}
+
+// SubCtx applies a change published by another instance. It accepts SyncMessage
+// JSON as well as the legacy "op-id" format. The publisher already wrote the
+// change to Redis, so only the local state is updated.
+func (rl *RateLimiter) SubCtx(ctx context.Context, message string) (err error) {
+	ctx, span := rl.startSpan(ctx, "Sub", "")
+	defer func() { endSpan(span, err) }()
+	msg, ok, err := parseSyncMessage(message)
+	if err != nil {
+		rl.Logger.Warn("invalid sync message", rl.fields("message", message, "err", err)...)
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	span.SetAttributes(attribute.String("rate_limiter.op", msg.Op))
+	if msg.Version > SyncVersion || msg.Sender != "" && msg.Sender == rl.sender {
 		return nil
 	}
-	switch str[0] {
+	if msg.Tenant != rl.tenant {
+		if view, ok := rl.tenantView(msg.Tenant); ok && rl.tenant == "" {
+			return view.SubCtx(ctx, message)
+		}
+		return nil
+	}
+	ctx = replicated(ctx)
+	rl.synced()
+	rl.Logger.Debug("sync message applied", rl.fields("op", msg.Op, "id", msg.Id, "origin", msg.Origin)...)
+	if isBatchOp(msg.Op) {
+		return rl.syncBatch(ctx, msg.Op, msg.Ids)
+	}
+	switch msg.Op {
 	case "rw":
-		return rl.RemoveWhiteList(str[1], false)
+		if removeFromLocalList(rl.whiteIds, msg.Id) {
+			rl.whiteListChanged(ctx, ListRemove, []string{msg.Id}, 0)
+		}
+		return nil
 	case "rb":
-		return rl.RemoveBlockList(str[1], false)
+		if removeFromLocalList(rl.blockIds, msg.Id) {
+			rl.blockListChanged(ctx, ListRemove, []string{msg.Id}, 0)
+		}
+		rl.resetLocal(msg.Id)
+		return nil
 	case "aw":
-		return rl.AddWhiteList(str[1], false)
+		if addToLocalList(rl.whiteIds, msg.Id) {
+			rl.whiteListChanged(ctx, ListAdd, []string{msg.Id}, 0)
+		}
+		return nil
 	case "ab":
-		return rl.AddBlockList(str[1], false)
+		if addToLocalList(rl.blockIds, msg.Id) {
+			rl.blockListChanged(ctx, ListAdd, []string{msg.Id}, 0)
+		}
+		return nil
+	case "tw":
+		return rl.syncWhiteListTTL(ctx, msg.Id)
+	case "tb":
+		return rl.syncBlockListTTL(ctx, msg.Id)
+	case "so", "ro":
+		return rl.syncOverride(ctx, msg.Id)
+	case "uc":
+		return rl.loadLimits(ctx)
+	case "md":
+		return rl.loadMode(ctx)
+	case "cb":
+		rl.resetLocalBlockList(ctx)
+		return nil
+	case "cw":
+		rl.resetLocalWhiteList(ctx)
+		return nil
+	case "at":
+		return rl.trustIds.apply(func() error {
+			rl.trustIds.add(msg.Id)
+			return nil
+		})
+	case "rt":
+		return rl.trustIds.apply(func() error {
+			rl.trustIds.remove(msg.Id)
+			return nil
+		})
 	default:
 		return nil
 	}
 }
 
 func (rl *RateLimiter) RemoveWhiteList(id string, pub bool) error {
-	_, err := rl.Redis.SRem(context.Background(), rl.whiteListKey, id).Result()
+	return rl.RemoveWhiteListCtx(context.Background(), id, pub)
+}
+
+func (rl *RateLimiter) RemoveWhiteListCtx(ctx context.Context, id string, pub bool) error {
+	id, err := rl.sanitizeStoredId(id)
 	if err != nil {
 		return err
 	}
-	idx := funk.IndexOfString(rl.whiteList, id)
-	if idx != -1 {
-		rl.whiteList = append(rl.whiteList[:idx], rl.whiteList[idx+1:]...)
+	return rl.removeWhiteList(ctx, id, pub)
+}
+
+func (rl *RateLimiter) removeWhiteList(ctx context.Context, id string, pub bool) error {
+	ctx, cancel := rl.listContext(ctx)
+	defer cancel()
+	removed, err := rl.removeFromList(ctx, rl.whiteIds, rl.whiteListKey, rl.whiteTTLKey, rl.whiteMetaKey, id)
+	if err != nil {
+		return err
+	}
+	if removed {
+		rl.whiteListChanged(ctx, ListRemove, []string{id}, 0)
 	}
-	if pub && rl.Pub != nil {
-		rl.Pub(rl.Name, "rw-"+id)
+	rl.audit(ctx, AuditRemoveWhiteList, id, 0)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: "rw", Id: id})
 	}
 	return nil
 }
 
 func (rl *RateLimiter) RemoveBlockList(id string, pub bool) error {
-	_, err := rl.Redis.SRem(context.Background(), rl.blockListKey, id).Result()
+	return rl.RemoveBlockListCtx(context.Background(), id, pub)
+}
+
+func (rl *RateLimiter) RemoveBlockListCtx(ctx context.Context, id string, pub bool) error {
+	id, err := rl.sanitizeStoredId(id)
 	if err != nil {
 		return err
 	}
-	idx := funk.IndexOfString(rl.blockList, id)
-	if idx != -1 {
-		rl.blockList = append(rl.blockList[:idx], rl.blockList[idx+1:]...)
+	return rl.removeBlockList(ctx, id, pub)
+}
+
+func (rl *RateLimiter) removeBlockList(ctx context.Context, id string, pub bool) error {
+	ctx, cancel := rl.listContext(ctx)
+	defer cancel()
+	removed, err := rl.removeFromList(ctx, rl.blockIds, rl.blockListKey, rl.blockTTLKey, rl.blockMetaKey, id)
+	if err != nil {
+		return err
 	}
-	if pub && rl.Pub != nil {
-		rl.Pub(rl.Name, "rb-"+id)
+	if removed {
+		rl.blockListChanged(ctx, ListRemove, []string{id}, 0)
 	}
-	return rl.CheckReset(id)
+	rl.audit(ctx, AuditRemoveBlockList, id, 0)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: "rb", Id: id})
+	}
+	return rl.checkReset(ctx, id)
 }
 
 func (rl *RateLimiter) AddWhiteList(id string, pub bool) error {
-	rl.whiteList = append(rl.whiteList, id)
-	_, err := rl.Redis.SAdd(context.Background(), rl.whiteListKey, id).Result()
+	return rl.AddWhiteListCtx(context.Background(), id, pub)
+}
+
+func (rl *RateLimiter) AddWhiteListCtx(ctx context.Context, id string, pub bool) error {
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return err
+	}
+	return rl.addWhiteList(ctx, id, pub)
+}
+
+// addWhiteList is AddWhiteListCtx for an id that is already sanitized, e.g. one
+// of a list entry.
+func (rl *RateLimiter) addWhiteList(ctx context.Context, id string, pub bool) error {
+	ctx, cancel := rl.listContext(ctx)
+	defer cancel()
+	changed, err := rl.addToList(ctx, rl.whiteIds, rl.whiteListKey, rl.whiteTTLKey, id)
 	if err != nil {
 		return err
 	}
-	idx := funk.IndexOfString(rl.whiteList, id)
-	if idx != -1 {
+	if !changed {
 		return ErrorWhiteListExists
 	}
-	if pub && rl.Pub != nil {
-		rl.Pub(rl.Name, "aw-"+id)
+	rl.audit(ctx, AuditAddWhiteList, id, 0)
+	rl.whiteListChanged(ctx, ListAdd, []string{id}, 0)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: "aw", Id: id})
 	}
 	return nil
 }
 
 func (rl *RateLimiter) AddBlockList(id string, pub bool) error {
-	rl.blockList = append(rl.blockList, id)
-	_, err := rl.Redis.SAdd(context.Background(), rl.blockListKey, id).Result()
+	return rl.AddBlockListCtx(context.Background(), id, pub)
+}
+
+func (rl *RateLimiter) AddBlockListCtx(ctx context.Context, id string, pub bool) error {
+	id, err := rl.sanitizeId(id)
 	if err != nil {
 		return err
 	}
-	idx := funk.IndexOfString(rl.blockList, id)
-	if idx != -1 {
+	return rl.addBlockList(ctx, id, pub)
+}
+
+// addBlockList is AddBlockListCtx for an id that is already sanitized, e.g. one
+// reaching BlockTimes.
+func (rl *RateLimiter) addBlockList(ctx context.Context, id string, pub bool) (err error) {
+	ctx, cancel := rl.listContext(ctx)
+	defer cancel()
+	ctx, span := rl.startSpan(ctx, "AddBlockList", id)
+	defer func() { endSpan(span, err) }()
+	changed, err := rl.addToList(ctx, rl.blockIds, rl.blockListKey, rl.blockTTLKey, id)
+	if err != nil {
+		return err
+	}
+	if !changed {
 		return ErrorBlockListExists
 	}
-	if pub && rl.Pub != nil {
-		rl.Pub(rl.Name, "ab-"+id)
+	rl.audit(ctx, AuditAddBlockList, id, 0)
+	rl.blockListChanged(ctx, ListAdd, []string{id}, 0)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: "ab", Id: id})
 	}
 	return nil
 }
 
+func (rl *RateLimiter) inWhiteList(id string) bool {
+	rl.expireWhiteList()
+	return rl.whiteIds.match(id) || rl.countryMatch(rl.whiteIds, id)
+}
+
+func (rl *RateLimiter) inBlockList(id string) bool {
+	rl.expireBlockList()
+	return rl.blockIds.match(id) || rl.countryMatch(rl.blockIds, id) || rl.inBloomBlockList(id)
+}
+
 func (rl *RateLimiter) GetWhiteList(id interface{}) ([]string, error) {
 	if id != nil {
-		has := funk.ContainsString(rl.whiteList, id.(string))
+		sid, err := rl.sanitizeStoredId(id.(string))
+		if err != nil {
+			return nil, err
+		}
+		has := rl.inWhiteList(sid)
 		if has {
-			return []string{id.(string)}, nil
+			return []string{sid}, nil
 		} else {
 			return nil, nil
 		}
 	}
-	return rl.whiteList, nil
+	rl.expireWhiteList()
+	return rl.whiteIds.list(), nil
 }
 
 func (rl *RateLimiter) GetBlockList(id interface{}) ([]string, error) {
 	if id != nil {
-		has := funk.ContainsString(rl.blockList, id.(string))
+		sid, err := rl.sanitizeStoredId(id.(string))
+		if err != nil {
+			return nil, err
+		}
+		has := rl.inBlockList(sid)
 		if has {
-			return []string{id.(string)}, nil
+			return []string{sid}, nil
 		} else {
 			return nil, nil
 		}
 	}
-	return rl.blockList, nil
+	rl.expireBlockList()
+	return rl.blockIds.list(), nil
 }
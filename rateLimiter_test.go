@@ -0,0 +1,76 @@
+package rateLimiter
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+)
+
+func TestContextVariants(t *testing.T) {
+	_, r := testRedis(t)
+	rl := New(&Config{Name: "ctx", Duration: time.Minute, BlockTimes: 2, Redis: r})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := rl.AllowCtx(ctx, "a"); !stderrors.Is(err, context.Canceled) {
+		t.Fatalf("AllowCtx with a cancelled ctx: %v", err)
+	}
+	if err := rl.AddBlockListCtx(ctx, "b", false); !stderrors.Is(err, context.Canceled) {
+		t.Fatalf("AddBlockListCtx with a cancelled ctx: %v", err)
+	}
+
+	ctx = context.Background()
+	if n, err := rl.CheckCtx(ctx, "a"); err != nil || n != 1 {
+		t.Fatalf("CheckCtx = %d, %v", n, err)
+	}
+	if err := rl.CheckResetCtx(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := rl.CheckCtx(ctx, "a"); err != nil || n != 1 {
+		t.Fatalf("CheckCtx after reset = %d, %v", n, err)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	var reported []string
+	_, rl := testLimiter(t, "dry", WithDuration(time.Minute), WithBlockTimes(2),
+		WithDryRun(func(id string, res *CheckResult) { reported = append(reported, id) }))
+	for i := 0; i < 4; i++ {
+		res, err := rl.Allow("a")
+		if err != nil || !res.Allowed {
+			t.Fatalf("check %d enforced: %+v, %v", i, res, err)
+		}
+		if blocked := i >= 1; res.DryRunBlocked != blocked {
+			t.Fatalf("check %d: DryRunBlocked = %v", i, res.DryRunBlocked)
+		}
+	}
+	if len(reported) != 3 || reported[0] != "a" {
+		t.Fatalf("reported %v", reported)
+	}
+	// BlockDuration 0 would block for good; a dry run must not
+	if ids, _ := rl.GetBlockList(nil); len(ids) != 0 {
+		t.Fatalf("dry run added %v to the block list", ids)
+	}
+}
+
+func BenchmarkCheck(b *testing.B) {
+	_, rl := testLimiter(b, "bench", WithDuration(time.Hour), WithBlockTimes(1<<30))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := rl.Check("user-1234"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCheckBlocked(b *testing.B) {
+	_, rl := testLimiter(b, "bench", WithDuration(time.Hour), WithBlockTimes(100), WithBlockList("user-1234"))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := rl.Check("user-1234"); !IsBlocked(err) {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package rateLimiter
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// redisHooks holds the single hook installed on each client. Hooks can't be
+// removed from a go-redis client, so limiters register with the hook of their
+// client instead and unregister on Close.
+var redisHooks struct {
+	mu    sync.Mutex
+	hooks map[*goredis.Client]*redisHook
+}
+
+// redisHook times the Redis calls made on the keys of the limiters registered
+// with it. The client may be shared, so calls on other keys pass through
+// untouched.
+type redisHook struct {
+	mu       sync.RWMutex
+	limiters map[string]*RateLimiter //by name
+	keys     map[string]*RateLimiter //fixed keys of the limiters
+}
+
+func (rl *RateLimiter) fixedKeys() []string {
+	return []string{rl.whiteListKey, rl.blockListKey, rl.trustListKey, rl.whiteTTLKey, rl.blockTTLKey,
+		rl.whiteMetaKey, rl.blockMetaKey, rl.overrideKey, rl.auditKey, rl.decisionKey, rl.counterKey(globalId)}
+}
+
+func (rl *RateLimiter) installRedisHook() {
+	redisHooks.mu.Lock()
+	if redisHooks.hooks == nil {
+		redisHooks.hooks = make(map[*goredis.Client]*redisHook)
+	}
+	h, ok := redisHooks.hooks[rl.Redis.Client]
+	if !ok {
+		h = &redisHook{limiters: make(map[string]*RateLimiter), keys: make(map[string]*RateLimiter)}
+		redisHooks.hooks[rl.Redis.Client] = h
+		rl.Redis.AddHook(h)
+	}
+	redisHooks.mu.Unlock()
+
+	h.mu.Lock()
+	h.limiters[rl.Name] = rl
+	for _, key := range rl.fixedKeys() {
+		h.keys[key] = rl
+	}
+	h.mu.Unlock()
+	rl.onClose(func(ctx context.Context) error {
+		h.remove(rl)
+		return nil
+	})
+}
+
+func (h *redisHook) remove(rl *RateLimiter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.limiters[rl.Name] == rl {
+		delete(h.limiters, rl.Name)
+	}
+	for _, key := range rl.fixedKeys() {
+		if h.keys[key] == rl {
+			delete(h.keys, key)
+		}
+	}
+}
+
+// owner returns the limiter whose key cmd works on, nil if there is none.
+func (h *redisHook) owner(cmd goredis.Cmder) *RateLimiter {
+	args := cmd.Args()
+	i := 1
+	switch cmd.Name() {
+	case "eval", "evalsha":
+		i = 3
+	}
+	if len(args) <= i {
+		return nil
+	}
+	key, ok := args[i].(string)
+	if !ok {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if rl, ok := h.keys[key]; ok {
+		return rl
+	}
+	//counter keys are name+":", the longest registered name wins
+	for j := strings.LastIndexByte(key, ':'); j > 0; j = strings.LastIndexByte(key[:j], ':') {
+		name := key[:j]
+		if rl, ok := h.limiters[name]; ok {
+			return rl
+		}
+		for _, suffix := range []string{"-offenders", "-violations"} {
+			if base, ok := strings.CutSuffix(name, suffix); ok {
+				if rl, ok := h.limiters[base]; ok {
+					return rl
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (h *redisHook) DialHook(next goredis.DialHook) goredis.DialHook {
+	return next
+}
+
+func (h *redisHook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		rl := h.owner(cmd)
+		if rl == nil {
+			return next(ctx, cmd)
+		}
+		start := time.Now()
+		err := next(ctx, cmd)
+		rl.observeRedis(ctx, cmd.Name(), time.Since(start))
+		return err
+	}
+}
+
+func (h *redisHook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		if len(cmds) == 0 {
+			return next(ctx, cmds)
+		}
+		rl := h.owner(cmds[0])
+		if rl == nil {
+			return next(ctx, cmds)
+		}
+		start := time.Now()
+		err := next(ctx, cmds)
+		rl.observeRedis(ctx, "pipeline", time.Since(start))
+		return err
+	}
+}
+
+func (rl *RateLimiter) observeRedis(ctx context.Context, command string, d time.Duration) {
+	rl.metrics.observeRedis(command, d)
+	rl.otelMetrics.observeRedis(ctx, command, d)
+	if rl.SlowRedisThreshold > 0 && d >= rl.SlowRedisThreshold {
+		rl.Logger.Warn("slow redis call", rl.fields("command", command, "duration", d)...)
+	}
+}
@@ -0,0 +1,123 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// redisCalls returns the Redis call count of limiter by command.
+func redisCalls(t *testing.T, reg *prometheus.Registry, limiter string) map[string]uint64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := make(map[string]uint64)
+	for _, f := range families {
+		if f.GetName() != "rate_limiter_redis_duration_seconds" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["limiter"] == limiter {
+				calls[labels["command"]] += m.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return calls
+}
+
+func TestRedisHook(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	l := newTestLogger()
+	_, r := testRedis(t)
+	rl, err := NewLimiter("hooked", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(5),
+		WithRegisterer(reg), WithLogger(l), WithSlowRedisThreshold(time.Nanosecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rl.Close(context.Background()) })
+	before := redisCalls(t, reg, "hooked")
+	rl.Allow("a")
+	if err := rl.RemoveBlockList("b", false); err != nil {
+		t.Fatal(err)
+	}
+	after := redisCalls(t, reg, "hooked")
+	if after["evalsha"]+after["eval"] <= before["evalsha"]+before["eval"] {
+		t.Fatalf("check not timed: %v -> %v", before, after)
+	}
+	if after["srem"] != before["srem"]+1 {
+		t.Fatalf("block list change not timed: %v -> %v", before, after)
+	}
+	if !l.logged("warn", "slow redis call") {
+		t.Fatal("slow call not logged")
+	}
+
+	// other users of a shared client are not timed
+	r.Set(context.Background(), "unrelated", "1", 0)
+	if got := redisCalls(t, reg, "hooked"); got["set"] != after["set"] {
+		t.Fatalf("unrelated call timed: %v", got)
+	}
+}
+
+func redisSamples(t *testing.T, reg *prometheus.Registry) uint64 {
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n uint64
+	for _, mf := range mfs {
+		if mf.GetName() != "rate_limiter_redis_duration_seconds" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			n += m.GetHistogram().GetSampleCount()
+		}
+	}
+	return n
+}
+
+func TestRedisHookSharedClient(t *testing.T) {
+	_, r := testRedis(t)
+	regA, regB := prometheus.NewRegistry(), prometheus.NewRegistry()
+	a, err := NewLimiter("hookA", WithRedis(r), WithDuration(time.Minute), WithRegisterer(regA))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewLimiter("hookB", WithRedis(r), WithDuration(time.Minute), WithRegisterer(regB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	redisHooks.mu.Lock()
+	h := redisHooks.hooks[r.Client]
+	redisHooks.mu.Unlock()
+	if h == nil || len(h.limiters) != 2 {
+		t.Fatalf("hook %+v, want one hook with both limiters", h)
+	}
+
+	beforeA, beforeB := redisSamples(t, regA), redisSamples(t, regB)
+	if _, err := a.Check("x"); err != nil {
+		t.Fatal(err)
+	}
+	if redisSamples(t, regA) == beforeA {
+		t.Fatal("check of a not timed")
+	}
+	if redisSamples(t, regB) != beforeB {
+		t.Fatal("check of a timed by b")
+	}
+
+	ctx := context.Background()
+	a.Close(ctx)
+	b.Close(ctx)
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.limiters) != 0 || len(h.keys) != 0 {
+		t.Fatalf("%d limiters and %d keys still registered after Close", len(h.limiters), len(h.keys))
+	}
+}
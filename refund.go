@@ -0,0 +1,38 @@
+package rateLimiter
+
+import (
+	"context"
+	stderrors "errors"
+	"math/rand"
+)
+
+func (rl *RateLimiter) Refund(id string) (int, error) {
+	return rl.RefundNCtx(context.Background(), id, 1)
+}
+
+func (rl *RateLimiter) RefundN(id string, n int) (int, error) {
+	return rl.RefundNCtx(context.Background(), id, n)
+}
+
+func (rl *RateLimiter) RefundCtx(ctx context.Context, id string) (int, error) {
+	return rl.RefundNCtx(ctx, id, 1)
+}
+
+// RefundNCtx gives n units back to the current window of id and returns the new count.
+// A sharded id gets them back on one of its shards, whose own count is returned.
+func (rl *RateLimiter) RefundNCtx(ctx context.Context, id string, n int) (int, error) {
+	if n <= 0 {
+		return 0, stderrors.New("n必须大于0")
+	}
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return 0, err
+	}
+	rl.blocked.del(id)
+	rl.dropBuffered(id)
+	keys := rl.counterKeys(id)
+	if rl.isSharded(id) {
+		keys = rl.shardKeys(id, rand.Intn(rl.Shards))
+	}
+	return refundScript.Run(ctx, rl.Redis, keys, n).Int()
+}
@@ -0,0 +1,28 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRefund(t *testing.T) {
+	_, rl := testLimiter(t, "refund", WithDuration(time.Minute), WithBlockTimes(10))
+	for i := 0; i < 3; i++ {
+		rl.Check("a")
+	}
+	if n, err := rl.Refund("a"); err != nil || n != 2 {
+		t.Fatalf("Refund = %d, %v, want 2", n, err)
+	}
+	if n, err := rl.RefundN("a", 5); err != nil || n != 0 {
+		t.Fatalf("RefundN past zero = %d, %v, want 0", n, err)
+	}
+	if n, err := rl.Refund("unknown"); err != nil || n != 0 {
+		t.Fatalf("Refund of an unknown id = %d, %v", n, err)
+	}
+	if _, err := rl.RefundN("a", 0); err == nil {
+		t.Fatal("RefundN accepted n = 0")
+	}
+	if n, _ := rl.Check("a"); n != 1 {
+		t.Fatalf("count after refunds = %d, want 1", n)
+	}
+}
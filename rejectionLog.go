@@ -0,0 +1,116 @@
+package rateLimiter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	rejectionBatchSize  = 512
+	rejectionMaxPending = 10000
+)
+
+var rejectionFlushInterval = 100 * time.Millisecond
+
+// rejectionLog buffers the offender counts and decision records of rejected
+// checks, so that rejections, BlockCache hits among them, don't wait on Redis.
+// Offenders are summed per period and id; records past rejectionMaxPending are
+// dropped until the next flush.
+type rejectionLog struct {
+	mu        sync.Mutex
+	offenders map[string]map[string]float64 //period key -> id -> rejections
+	decisions []*goredis.XAddArgs
+	dropped   int
+	wake      chan struct{}
+}
+
+func (l *rejectionLog) init() {
+	l.offenders = make(map[string]map[string]float64)
+	l.wake = make(chan struct{}, 1)
+}
+
+func (l *rejectionLog) addOffender(key, id string) {
+	l.mu.Lock()
+	ids, ok := l.offenders[key]
+	if !ok {
+		ids = make(map[string]float64)
+		l.offenders[key] = ids
+	}
+	ids[id]++
+	l.mu.Unlock()
+}
+
+func (l *rejectionLog) addDecision(args *goredis.XAddArgs) {
+	l.mu.Lock()
+	if len(l.decisions) >= rejectionMaxPending {
+		l.dropped++
+		l.mu.Unlock()
+		return
+	}
+	l.decisions = append(l.decisions, args)
+	full := len(l.decisions) >= rejectionBatchSize
+	l.mu.Unlock()
+	if full {
+		select {
+		case l.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (l *rejectionLog) take() (map[string]map[string]float64, []*goredis.XAddArgs, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	offenders, decisions, dropped := l.offenders, l.decisions, l.dropped
+	l.offenders = make(map[string]map[string]float64)
+	l.decisions = nil
+	l.dropped = 0
+	return offenders, decisions, dropped
+}
+
+func (rl *RateLimiter) logsRejections() bool {
+	return rl.TrackOffenders || rl.DecisionLogSize > 0
+}
+
+func (rl *RateLimiter) rejectionLogLoop(ctx context.Context) {
+	ticker := time.NewTicker(rejectionFlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-rl.rejections.wake:
+		case <-ticker.C:
+		}
+		rl.flushRejections(ctx)
+	}
+}
+
+// flushRejections writes the buffered offenders and decisions in one pipeline.
+func (rl *RateLimiter) flushRejections(ctx context.Context) error {
+	offenders, decisions, dropped := rl.rejections.take()
+	if dropped > 0 {
+		rl.Logger.Warn("rejection log full, decisions dropped", rl.fields("dropped", dropped)...)
+	}
+	if len(offenders) == 0 && len(decisions) == 0 {
+		return nil
+	}
+	pipe := rl.Redis.Pipeline()
+	for key, ids := range offenders {
+		for id, n := range ids {
+			pipe.ZIncrBy(ctx, key, n, id)
+		}
+		pipe.PExpire(ctx, key, 2*rl.OffendersPeriod)
+	}
+	for _, args := range decisions {
+		pipe.XAdd(ctx, args)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		rl.Logger.Error("flush rejection log failed", rl.fields("offenders", len(offenders), "decisions", len(decisions), "err", err)...)
+		return err
+	}
+	return nil
+}
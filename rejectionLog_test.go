@@ -0,0 +1,40 @@
+package rateLimiter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRejectionsAreWrittenInBackground(t *testing.T) {
+	interval := rejectionFlushInterval
+	rejectionFlushInterval = time.Hour
+	t.Cleanup(func() { rejectionFlushInterval = interval })
+	mr, rl := testLimiter(t, "rejections", WithDuration(time.Minute), WithBlockTimes(2), WithBlockDuration(time.Minute), WithTopOffenders(time.Hour), WithDecisionLog(100))
+	for i := 0; i < 4; i++ {
+		rl.Check("a")
+	}
+	for _, key := range mr.Keys() {
+		if strings.Contains(key, "-offenders:") || strings.Contains(key, "decision") {
+			t.Fatalf("%s written on the check path", key)
+		}
+	}
+	if err := rl.flushRejections(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	offenders, err := rl.TopOffenders(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(offenders) != 1 || offenders[0].Id != "a" || offenders[0].Blocks != 3 {
+		t.Fatalf("offenders %+v, want a blocked 3 times", offenders)
+	}
+	records, err := rl.GetDecisionLog(time.Time{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("%d decisions logged, want 3", len(records))
+	}
+}
@@ -0,0 +1,135 @@
+package rateLimiter
+
+import (
+	"context"
+	"strings"
+)
+
+const resetScanCount = 1000
+
+func (rl *RateLimiter) ResetAll(clearBlockList bool, pub bool) (int64, error) {
+	return rl.ResetAllCtx(context.Background(), clearBlockList, pub)
+}
+
+// ResetAllCtx deletes every counter of the limiter using SCAN. When clearBlockList
+// is set the dynamic block list is dropped too, leaving only Config.BlockList.
+func (rl *RateLimiter) ResetAllCtx(ctx context.Context, clearBlockList bool, pub bool) (int64, error) {
+	matches := []string{escapeGlob(rl.Name) + ":*"}
+	if len(rl.shardPrefixes) > 0 {
+		matches = append(matches, escapeGlob(rl.Name)+"#*")
+	}
+	var deleted int64
+	for _, match := range matches {
+		n, err := rl.deleteMatching(ctx, match)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	rl.blocked.reset()
+	rl.buffer.mu.Lock()
+	rl.buffer.counts = nil
+	rl.buffer.mu.Unlock()
+	if clearBlockList {
+		if err := rl.clearBlockList(ctx); err != nil {
+			return deleted, err
+		}
+		if pub {
+			rl.publish(ctx, SyncMessage{Op: "cb"})
+		}
+	}
+	return deleted, nil
+}
+
+func (rl *RateLimiter) deleteMatching(ctx context.Context, match string) (int64, error) {
+	var deleted int64
+	var cursor uint64
+	for {
+		keys, next, err := rl.Redis.Scan(ctx, cursor, match, resetScanCount).Result()
+		if err != nil {
+			return deleted, err
+		}
+		if len(keys) > 0 {
+			n, err := rl.Redis.Del(ctx, keys...).Result()
+			if err != nil {
+				return deleted, err
+			}
+			deleted += n
+		}
+		cursor = next
+		if cursor == 0 {
+			return deleted, nil
+		}
+	}
+}
+
+func (rl *RateLimiter) clearBlockList(ctx context.Context) error {
+	err := rl.blockIds.apply(func() error {
+		if err := rl.Redis.Del(ctx, rl.blockListKey, rl.blockTTLKey, rl.blockMetaKey).Err(); err != nil {
+			return err
+		}
+		rl.blockIds.reset(rl.seedIds(rl.Config.BlockList))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	rl.blockListChanged(ctx, ListClear, nil, 0)
+	rl.audit(ctx, AuditClearBlockList, "", 0)
+	return nil
+}
+
+func (rl *RateLimiter) resetLocalBlockList(ctx context.Context) {
+	rl.blockIds.apply(func() error {
+		rl.blockIds.reset(rl.seedIds(rl.Config.BlockList))
+		return nil
+	})
+	rl.blockListChanged(ctx, ListClear, nil, 0)
+}
+
+func (rl *RateLimiter) clearWhiteList(ctx context.Context) error {
+	err := rl.whiteIds.apply(func() error {
+		if err := rl.Redis.Del(ctx, rl.whiteListKey, rl.whiteTTLKey, rl.whiteMetaKey).Err(); err != nil {
+			return err
+		}
+		rl.whiteIds.reset(rl.seedIds(rl.Config.WhiteList))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	rl.whiteListChanged(ctx, ListClear, nil, 0)
+	rl.audit(ctx, AuditClearWhiteList, "", 0)
+	return nil
+}
+
+func (rl *RateLimiter) resetLocalWhiteList(ctx context.Context) {
+	rl.whiteIds.apply(func() error {
+		rl.whiteIds.reset(rl.seedIds(rl.Config.WhiteList))
+		return nil
+	})
+	rl.whiteListChanged(ctx, ListClear, nil, 0)
+}
+
+// seedIds returns the valid ids of a Config list.
+func (rl *RateLimiter) seedIds(list []string) []string {
+	ids := make([]string, 0, len(list))
+	for _, val := range list {
+		if id, err := rl.sanitizeId(val); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,41 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResetAll(t *testing.T) {
+	mr, rl := testLimiter(t, "reset", WithDuration(time.Minute), WithBlockTimes(2), WithBlockList("static"))
+	mr.Set("other:a", "5")
+	// with BlockDuration 0 reaching the limit adds the id to the block list
+	rl.Check("a")
+	rl.Check("a")
+	rl.Check("b")
+	n, err := rl.ResetAll(false, false)
+	if err != nil || n != 2 {
+		t.Fatalf("ResetAll deleted %d, %v, want 2", n, err)
+	}
+	if !mr.Exists("other:a") {
+		t.Fatal("ResetAll deleted another limiter's counter")
+	}
+	if _, err := rl.Check("a"); !IsBlocked(err) {
+		t.Fatalf("block list cleared without clearBlockList: %v", err)
+	}
+
+	if _, err := rl.ResetAll(true, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rl.Check("a"); IsBlocked(err) {
+		t.Fatal("dynamic block list entry kept")
+	}
+	if _, err := rl.Check("static"); !IsBlocked(err) {
+		t.Fatalf("configured block list entry dropped: %v", err)
+	}
+}
+
+func TestEscapeGlob(t *testing.T) {
+	if got := escapeGlob(`a*b?[c]\`); got != `a\*b\?\[c\]\\` {
+		t.Fatalf("escapeGlob = %q", got)
+	}
+}
@@ -0,0 +1,68 @@
+package rateLimiter
+
+import "time"
+
+type CheckResult struct {
+	Allowed    bool
+	Window     time.Duration //the window Limit and Used refer to
+	Limit      int           //0=unlimited
+	Used       int
+	Remaining  int       //-1=unlimited
+	ResetAt    time.Time //zero when the id is blocked forever or never counted
+	RetryAfter time.Duration
+	// DryRunBlocked is set when the request would have been blocked but Config.DryRun let it through.
+	DryRunBlocked bool
+	// FailedOpen is set when Redis failed and Config.FailOpen let the request through.
+	FailedOpen bool
+	// Greylisted is set when the id is past Config.GreyListRatio without a passed challenge.
+	Greylisted bool
+	// Delay is how long Config.TarpitDelay held the request instead of rejecting it.
+	Delay time.Duration
+}
+
+func (rl *RateLimiter) newResult(w Window, used int, ttl time.Duration) *CheckResult {
+	res := &CheckResult{
+		Allowed:   true,
+		Window:    w.Duration,
+		Limit:     w.Limit,
+		Used:      used,
+		Remaining: -1,
+	}
+	if w.Limit > 0 {
+		res.Remaining = w.Limit - used
+		if res.Remaining < 0 {
+			res.Remaining = 0
+		}
+	}
+	if ttl > 0 {
+		res.ResetAt = rl.Clock.Now().Add(ttl)
+	}
+	return res
+}
+
+// block marks the result as rejected; a negative ttl means the block never expires.
+func (rl *RateLimiter) block(res *CheckResult, ttl time.Duration) {
+	res.Allowed = false
+	res.Remaining = 0
+	if ttl < 0 {
+		res.ResetAt = time.Time{}
+		res.RetryAfter = -1
+		return
+	}
+	res.ResetAt = rl.Clock.Now().Add(ttl)
+	res.RetryAfter = ttl
+}
+
+func (rl *RateLimiter) whiteListResult(id string) *CheckResult {
+	return rl.newResult(rl.primaryWindow(id), 0, 0)
+}
+
+func (rl *RateLimiter) blockListResult(id string) *CheckResult {
+	res := rl.newResult(rl.primaryWindow(id), 0, 0)
+	if at, ok := rl.blockIds.expiry.get(id); ok {
+		rl.block(res, at.Sub(rl.Clock.Now()))
+	} else {
+		rl.block(res, -1)
+	}
+	return res
+}
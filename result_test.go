@@ -0,0 +1,42 @@
+package rateLimiter
+
+import (
+	stderrors "errors"
+	"testing"
+	"time"
+)
+
+func TestAllowResult(t *testing.T) {
+	_, r := testRedis(t)
+	rl := New(&Config{Name: "result", Duration: time.Minute, BlockTimes: 3, BlockDuration: time.Hour, Redis: r})
+	for i := 1; i < 3; i++ {
+		res, err := rl.Allow("a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed || res.Limit != 3 || res.Used != i || res.Remaining != 3-i || res.ResetAt.IsZero() {
+			t.Fatalf("check %d: %+v", i, res)
+		}
+	}
+	res, err := rl.Allow("a")
+	if !stderrors.Is(err, ErrorBlock) {
+		t.Fatalf("got %v, want ErrorBlock", err)
+	}
+	if res.Allowed || res.Remaining != 0 || res.RetryAfter != time.Hour {
+		t.Fatalf("blocked result %+v", res)
+	}
+}
+
+func TestAllowResultLists(t *testing.T) {
+	_, r := testRedis(t)
+	rl := New(&Config{Name: "result", Duration: time.Minute, BlockTimes: 3, Redis: r,
+		WhiteList: []string{"w"}, BlockList: []string{"b"}})
+	res, err := rl.Allow("w")
+	if err != nil || !res.Allowed || res.Used != 0 {
+		t.Fatalf("white listed: %+v, %v", res, err)
+	}
+	res, err = rl.Allow("b")
+	if !stderrors.Is(err, ErrorBlock) || res.Allowed || res.RetryAfter != -1 || !res.ResetAt.IsZero() {
+		t.Fatalf("block listed: %+v, %v", res, err)
+	}
+}
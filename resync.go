@@ -0,0 +1,93 @@
+package rateLimiter
+
+import (
+	"context"
+	"time"
+)
+
+func (rl *RateLimiter) Resync() error {
+	return rl.ResyncCtx(context.Background())
+}
+
+// ResyncCtx reloads both lists from Redis and replaces the local cache with them,
+// keeping Config.WhiteList and Config.BlockList, and reloads the mode, the limits
+// set by UpdateConfig and the overrides. It repairs drift caused by missed sync
+// messages.
+func (rl *RateLimiter) ResyncCtx(ctx context.Context) error {
+	if err := rl.loadMode(ctx); err != nil {
+		return err
+	}
+	if err := rl.loadLimits(ctx); err != nil {
+		return err
+	}
+	if err := rl.loadOverrides(ctx); err != nil {
+		return err
+	}
+	if err := rl.resyncList(ctx, rl.whiteIds, rl.Config.WhiteList, rl.whiteListKey, rl.whiteTTLKey); err != nil {
+		return err
+	}
+	if err := rl.resyncBlockList(ctx); err != nil {
+		return err
+	}
+	return rl.resyncList(ctx, rl.trustIds, rl.Config.TrustedList, rl.trustListKey, "")
+}
+
+// resyncList replaces l with seeds and the members of setKey. ttlKey is empty for
+// lists without temporary entries. Redis is read inside l.apply, so a concurrent
+// change can't be undone by an older snapshot.
+func (rl *RateLimiter) resyncList(ctx context.Context, l *idList, seeds []string, setKey, ttlKey string) error {
+	var added, removed, purged []string
+	err := l.apply(func() error {
+		expiry := make(map[string]time.Time)
+		if ttlKey != "" {
+			var err error
+			if purged, err = rl.purgeExpiredIds(ctx, setKey, ttlKey); err != nil {
+				return err
+			}
+			vals, err := rl.Redis.ZRangeWithScores(ctx, ttlKey, 0, -1).Result()
+			if err != nil {
+				return err
+			}
+			for _, z := range vals {
+				expiry[z.Member.(string)] = time.UnixMilli(int64(z.Score))
+			}
+		}
+		members, err := rl.Redis.SMembers(ctx, setKey).Result()
+		if err != nil {
+			return err
+		}
+		added, removed = l.replace(append(rl.seedIds(seeds), members...), expiry)
+		return nil
+	})
+	rl.reportPurged(setKey, purged)
+	if err != nil {
+		return err
+	}
+	rl.synced()
+	ctx = replicated(ctx)
+	changed := rl.blockListChanged
+	switch l {
+	case rl.whiteIds:
+		changed = rl.whiteListChanged
+	case rl.trustIds:
+		return nil
+	}
+	changed(ctx, ListAdd, added, 0)
+	changed(ctx, ListRemove, removed, 0)
+	return nil
+}
+
+func (rl *RateLimiter) resyncLoop(ctx context.Context) {
+	ticker := time.NewTicker(rl.ResyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := rl.ResyncCtx(ctx); err != nil && ctx.Err() == nil {
+				rl.Logger.Error("resync failed", rl.fields("err", err)...)
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package rateLimiter
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestResync(t *testing.T) {
+	mr, rl := testLimiter(t, "resync", WithDuration(time.Minute), WithBlockTimes(10), WithBlockList("static"))
+	// simulate changes whose sync messages were missed
+	mr.SAdd("resync-block", "a", "b")
+	mr.SAdd("resync-white", "c")
+	if _, err := rl.Check("a"); err != nil {
+		t.Fatalf("unexpected block before resync: %v", err)
+	}
+	if err := rl.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b", "static"} {
+		if _, err := rl.Check(id); !IsBlocked(err) {
+			t.Fatalf("%s: expected block after resync, got %v", id, err)
+		}
+	}
+	if !rl.whiteIds.has("c") {
+		t.Fatal("white list not reloaded")
+	}
+
+	mr.SRem("resync-block", "a")
+	if err := rl.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rl.Check("a"); err != nil {
+		t.Fatalf("removed entry still applies after resync: %v", err)
+	}
+	if _, err := rl.Check("static"); !IsBlocked(err) {
+		t.Fatalf("static entry dropped by resync: %v", err)
+	}
+}
+
+func TestResyncLoop(t *testing.T) {
+	mr, rl := testLimiter(t, "resyncLoop", WithDuration(time.Minute), WithBlockTimes(10), WithResync(10*time.Millisecond))
+	mr.SAdd("resyncLoop-block", "a")
+	deadline := time.Now().Add(time.Second)
+	for !rl.blockIds.has("a") {
+		if time.Now().After(deadline) {
+			t.Fatal("background resync did not reload the block list")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestResyncReloads changes the shared state in Redis without publishing, as if
+// the sync message was lost, and checks that Resync picks it up.
+func TestResyncReloads(t *testing.T) {
+	mr, rl := testLimiter(t, "resync", WithDuration(time.Minute), WithBlockTimes(10))
+
+	mr.Set(rl.modeKey, ModeBlockAll.String())
+	if err := rl.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	if rl.Mode() != ModeBlockAll {
+		t.Fatalf("mode %v after resync, want blockAll", rl.Mode())
+	}
+	mr.Del(rl.modeKey)
+	if err := rl.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	if rl.Mode() != ModeNormal {
+		t.Fatalf("mode %v after resync, want normal", rl.Mode())
+	}
+
+	l := Limits{Duration: time.Hour, BlockTimes: 3}
+	data, _ := json.Marshal(l)
+	mr.Set(rl.limitsKey, string(data))
+	if err := rl.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	if rl.Limits() != l {
+		t.Fatalf("limits %+v after resync, want %+v", rl.Limits(), l)
+	}
+	mr.Del(rl.limitsKey)
+	if err := rl.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := rl.Limits(); got.Duration != time.Minute || got.BlockTimes != 10 {
+		t.Fatalf("limits %+v after resync, want the config", got)
+	}
+
+	rl.SetOverride("a", Override{BlockTimes: 1}, false)
+	mr.HSet(rl.overrideKey, "b", `{"blockTimes":2}`)
+	mr.HDel(rl.overrideKey, "a")
+	if err := rl.Resync(); err != nil {
+		t.Fatal(err)
+	}
+	if o, _ := rl.GetOverride("a"); o != nil {
+		t.Fatalf("removed override still set: %+v", o)
+	}
+	if o, _ := rl.GetOverride("b"); o == nil || o.BlockTimes != 2 {
+		t.Fatalf("override %+v after resync, want blockTimes 2", o)
+	}
+}
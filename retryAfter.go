@@ -0,0 +1,64 @@
+package rateLimiter
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func (rl *RateLimiter) RetryAfter(id string) (time.Duration, error) {
+	return rl.RetryAfterCtx(context.Background(), id)
+}
+
+// RetryAfterCtx reports how long id has to wait before its next request is allowed,
+// without consuming one: 0 if it may proceed now, -1 if it is blocked permanently,
+// otherwise the remaining block or window time.
+func (rl *RateLimiter) RetryAfterCtx(ctx context.Context, id string) (time.Duration, error) {
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return 0, err
+	}
+	if res, ok := rl.modeResult(id); ok {
+		return res.RetryAfter, nil
+	}
+	if rl.inWhiteList(id) || matchRule(rl.allowRules, id) != "" {
+		return 0, nil
+	}
+	if rl.inBlockList(id) || matchRule(rl.denyRules, id) != "" {
+		if at, ok := rl.blockIds.expiry.get(id); ok {
+			return at.Sub(rl.Clock.Now()), nil
+		}
+		return -1, nil
+	}
+	windows := rl.windows(id)
+	keys := rl.counterKeys(id)
+	pipe := rl.Redis.Pipeline()
+	gets := make([]*goredis.StringCmd, len(keys))
+	ttls := make([]*goredis.DurationCmd, len(keys))
+	for i, key := range keys {
+		gets[i] = pipe.Get(ctx, key)
+		ttls[i] = pipe.PTTL(ctx, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != goredis.Nil {
+		return 0, err
+	}
+	var wait time.Duration
+	for i, w := range windows {
+		times, err := gets[i].Int()
+		if err == goredis.Nil || w.Limit <= 0 || times < w.Limit {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		ttl := ttls[i].Val()
+		if ttl < 0 {
+			return -1, nil
+		}
+		if ttl > wait {
+			wait = ttl
+		}
+	}
+	return wait, nil
+}
@@ -0,0 +1,33 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	clock := NewFakeClock(time.UnixMilli(time.Now().UnixMilli()))
+	_, rl := testLimiter(t, "retry", WithDuration(time.Minute), WithBlockTimes(2), WithBlockDuration(time.Hour),
+		WithClock(clock), WithWhiteList("w"), WithBlockList("b"))
+	for id, want := range map[string]time.Duration{"w": 0, "b": -1, "a": 0} {
+		if got, err := rl.RetryAfter(id); err != nil || got != want {
+			t.Fatalf("RetryAfter(%s) = %v, %v, want %v", id, got, err, want)
+		}
+	}
+
+	rl.Check("a")
+	if got, _ := rl.RetryAfter("a"); got != 0 {
+		t.Fatalf("RetryAfter below the limit = %v, want 0", got)
+	}
+	if _, err := rl.Check("a"); !IsBlocked(err) {
+		t.Fatalf("expected block, got %v", err)
+	}
+	if got, _ := rl.RetryAfter("a"); got <= 59*time.Minute || got > time.Hour {
+		t.Fatalf("RetryAfter while blocked = %v, want about 1h", got)
+	}
+
+	rl.AddBlockListTTL("t", 10*time.Minute, false)
+	if got, _ := rl.RetryAfter("t"); got != 10*time.Minute {
+		t.Fatalf("RetryAfter of a temporary entry = %v, want 10m", got)
+	}
+}
@@ -0,0 +1,25 @@
+package rateLimiter
+
+import "regexp"
+
+func compileRules(exprs []string) ([]*regexp.Regexp, error) {
+	rules := make([]*regexp.Regexp, 0, len(exprs))
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, re)
+	}
+	return rules, nil
+}
+
+// matchRule returns the first rule matching id, or "" if none does.
+func matchRule(rules []*regexp.Regexp, id string) string {
+	for _, re := range rules {
+		if re.MatchString(id) {
+			return re.String()
+		}
+	}
+	return ""
+}
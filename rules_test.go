@@ -0,0 +1,30 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRules(t *testing.T) {
+	_, rl := testLimiter(t, "rules", WithDuration(time.Minute), WithBlockTimes(10),
+		WithAllowRules(`^health-`), WithDenyRules(`^bot-\d+$`, `(?i)crawler`))
+	if res, err := rl.Allow("health-probe"); err != nil || res.Used != 0 {
+		t.Fatalf("allow rule: %+v, %v", res, err)
+	}
+	for _, id := range []string{"bot-1", "MyCrawler"} {
+		if _, err := rl.Check(id); !IsBlocked(err) {
+			t.Errorf("deny rule didn't match %q: %v", id, err)
+		}
+	}
+	if n, err := rl.Check("bot-x"); err != nil || n != 1 {
+		t.Fatalf("unmatched id: %d, %v", n, err)
+	}
+	if ins, _ := rl.Inspect("bot-1"); ins.DenyRule != `^bot-\d+$` || ins.AllowRule != "" {
+		t.Fatalf("Inspect rules: %+v", ins)
+	}
+
+	_, r := testRedis(t)
+	if _, err := NewLimiter("rules", WithRedis(r), WithDuration(time.Minute), WithDenyRules("(")); err == nil {
+		t.Fatal("invalid rule accepted")
+	}
+}
@@ -0,0 +1,141 @@
+package rateLimiter
+
+import (
+	stderrors "errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule replaces Duration and BlockTimes while the current minute matches Cron,
+// e.g. "* 0-5 * * *" for the night, "* 9-17 * * 1-5" for business hours or
+// "* * 11 11 *" for a sale day. Zero fields keep the limiter defaults.
+type Schedule struct {
+	Cron       string         //minute hour day-of-month month day-of-week, with * , - and /
+	Location   *time.Location //nil=UTC, like AlignLocation
+	Duration   time.Duration
+	BlockTimes int
+}
+
+type schedule struct {
+	Schedule
+	cron *cronExpr
+}
+
+func compileSchedules(schedules []Schedule) ([]schedule, error) {
+	out := make([]schedule, 0, len(schedules))
+	for _, s := range schedules {
+		if s.Duration < 0 || s.BlockTimes < 0 {
+			return nil, stderrors.New("Schedules的Duration和BlockTimes不能小于0")
+		}
+		expr, err := parseCron(s.Cron)
+		if err != nil {
+			return nil, stderrors.New("Schedules包含非法表达式: " + s.Cron + ": " + err.Error())
+		}
+		if s.Location == nil {
+			s.Location = time.UTC
+		}
+		out = append(out, schedule{Schedule: s, cron: expr})
+	}
+	return out, nil
+}
+
+// schedule returns the first Schedule matching the current time.
+func (rl *RateLimiter) schedule() (Schedule, bool) {
+	if len(rl.schedules) == 0 {
+		return Schedule{}, false
+	}
+	now := rl.Clock.Now()
+	for _, s := range rl.schedules {
+		if s.cron.match(now.In(s.Location)) {
+			return s.Schedule, true
+		}
+	}
+	return Schedule{}, false
+}
+
+type cronExpr struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+func parseCron(s string) (*cronExpr, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 5 {
+		return nil, stderrors.New("需要5个字段")
+	}
+	var e cronExpr
+	var err error
+	if e.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if e.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if e.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if e.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if e.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	if e.dow&(1<<7) != 0 {
+		e.dow |= 1 //7 is Sunday too
+	}
+	e.domStar = fields[2] == "*"
+	e.dowStar = fields[4] == "*"
+	return &e, nil
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, stderrors.New("非法步长: " + part)
+			}
+			step = n
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = strconv.Atoi(loStr); err != nil {
+				return 0, stderrors.New("非法值: " + part)
+			}
+			hi = lo
+			if isRange {
+				if hi, err = strconv.Atoi(hiStr); err != nil {
+					return 0, stderrors.New("非法值: " + part)
+				}
+			} else if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, stderrors.New("超出范围: " + part)
+		}
+		for i := lo; i <= hi; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+// match follows cron: when both day fields are restricted either may match.
+func (e *cronExpr) match(t time.Time) bool {
+	if e.minute&(1<<uint(t.Minute())) == 0 || e.hour&(1<<uint(t.Hour())) == 0 || e.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	dom := e.dom&(1<<uint(t.Day())) != 0
+	dow := e.dow&(1<<uint(t.Weekday())) != 0
+	if e.domStar || e.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
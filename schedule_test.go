@@ -0,0 +1,86 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	at := func(s string) time.Time {
+		v, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	for _, c := range []struct {
+		cron  string
+		time  string
+		match bool
+	}{
+		{"* * * * *", "2024-11-11 12:30", true},
+		{"* 0-5 * * *", "2024-11-11 03:00", true},
+		{"* 0-5 * * *", "2024-11-11 06:00", false},
+		{"* 9-17 * * 1-5", "2024-11-11 10:00", true},  //Monday
+		{"* 9-17 * * 1-5", "2024-11-10 10:00", false}, //Sunday
+		{"* * * * 7", "2024-11-10 10:00", true},
+		{"*/15 * * * *", "2024-11-11 10:45", true},
+		{"*/15 * * * *", "2024-11-11 10:46", false},
+		{"0,30 * * * *", "2024-11-11 10:30", true},
+		{"* * 11 11 *", "2024-11-11 23:59", true},
+		{"* * 11 11 *", "2024-11-12 00:00", false},
+		{"* * 1 * 1", "2024-11-11 10:00", true}, //either day field
+		{"* * 1 * 1", "2024-11-12 10:00", false},
+	} {
+		e, err := parseCron(c.cron)
+		if err != nil {
+			t.Fatalf("%q: %v", c.cron, err)
+		}
+		if got := e.match(at(c.time)); got != c.match {
+			t.Errorf("%q at %s = %v, want %v", c.cron, c.time, got, c.match)
+		}
+	}
+	for _, cron := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := parseCron(cron); err == nil {
+			t.Errorf("%q accepted", cron)
+		}
+	}
+}
+
+func TestSchedules(t *testing.T) {
+	clock := NewFakeClock(time.Date(2024, 11, 11, 3, 0, 0, 0, time.UTC))
+	_, rl := testLimiter(t, "schedule", WithDuration(time.Minute), WithBlockTimes(10), WithClock(clock),
+		WithSchedules(Schedule{Cron: "* 0-5 * * *", Location: time.UTC, BlockTimes: 2}))
+	if res, _ := rl.Allow("a"); res.Limit != 2 {
+		t.Fatalf("night limit %d, want 2", res.Limit)
+	}
+	clock.Advance(3 * time.Hour)
+	if res, _ := rl.Allow("b"); res.Limit != 10 {
+		t.Fatalf("day limit %d, want 10", res.Limit)
+	}
+	if _, err := NewLimiter("bad", WithRedis(rl.Redis), WithDuration(time.Minute), WithBlockTimes(2),
+		WithSchedules(Schedule{Cron: "* *"})); err == nil {
+		t.Fatal("invalid cron accepted")
+	}
+}
+
+func TestScheduleDefaultsToUTC(t *testing.T) {
+	schedules, err := compileSchedules([]Schedule{{Cron: "* 23 * * *", BlockTimes: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedules[0].Location != time.UTC {
+		t.Fatalf("default location %v, want UTC", schedules[0].Location)
+	}
+
+	clock := NewFakeClock(time.Date(2026, 1, 1, 23, 30, 0, 0, time.FixedZone("UTC+8", 8*3600)))
+	_, rl := testLimiter(t, "schedule", WithDuration(time.Minute), WithBlockTimes(10), WithClock(clock),
+		WithSchedules(Schedule{Cron: "* 23 * * *", BlockTimes: 1}))
+	if _, ok := rl.schedule(); ok {
+		t.Fatal("schedule active at 15:30 UTC")
+	}
+	clock.Advance(8 * time.Hour)
+	if s, ok := rl.schedule(); !ok || s.BlockTimes != 1 {
+		t.Fatalf("schedule at 23:30 UTC = %+v, %v", s, ok)
+	}
+}
@@ -0,0 +1,71 @@
+package rateLimiter
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// shardPrefixes returns the counter key prefix of every shard. Shard keys don't
+// start with the "Name:" prefix of the other counters, so a sharded id can't
+// collide with a real one.
+func shardPrefixes(name string, shards int) []string {
+	if shards <= 1 {
+		return nil
+	}
+	prefixes := make([]string, shards)
+	for i := range prefixes {
+		prefixes[i] = name + "#" + strconv.Itoa(i) + ":"
+	}
+	return prefixes
+}
+
+func (rl *RateLimiter) isSharded(id string) bool {
+	if len(rl.shardPrefixes) == 0 {
+		return false
+	}
+	_, ok := rl.shardedIds[id]
+	return ok
+}
+
+// shardKeys returns the keys of every window of a shard of id.
+func (rl *RateLimiter) shardKeys(id string, shard int) []string {
+	return rl.windowKeys(rl.shardPrefixes[shard], id)
+}
+
+// allCounterKeys returns the keys of every window of id, and of every shard if id
+// is sharded.
+func (rl *RateLimiter) allCounterKeys(id string) []string {
+	keys := rl.counterKeys(id)
+	if rl.isSharded(id) {
+		for i := range rl.shardPrefixes {
+			keys = append(keys, rl.shardKeys(id, i)...)
+		}
+	}
+	return keys
+}
+
+// shareOf returns the part of limit enforced by each of shards counters, rounded
+// up so that the shards together never allow less than limit.
+func shareOf(limit, shards int) int {
+	if limit <= 0 || shards <= 1 {
+		return limit
+	}
+	return (limit + shards - 1) / shards
+}
+
+// blockCounter keeps id blocked for d by extending its primary counter. The
+// shards of a sharded id are all filled, or the ones that weren't full yet would
+// keep allowing requests.
+func (rl *RateLimiter) blockCounter(ctx context.Context, id string, d time.Duration) error {
+	if !rl.isSharded(id) {
+		return rl.Redis.Expire(ctx, rl.counterKey(id), d).Err()
+	}
+	limit := shareOf(rl.primaryWindow(id).Limit, rl.Shards)
+	pipe := rl.Redis.Pipeline()
+	for _, prefix := range rl.shardPrefixes {
+		pipe.Set(ctx, prefix+id, limit, d)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
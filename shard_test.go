@@ -0,0 +1,63 @@
+package rateLimiter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestShards(t *testing.T) {
+	mr, rl := testLimiter(t, "shard", WithDuration(time.Minute), WithBlockTimes(8),
+		WithBlockDuration(time.Hour), WithShards(4, "hot"))
+	blocked := 0
+	for i := 0; i < 5; i++ {
+		if _, err := rl.Check("hot"); IsBlocked(err) {
+			blocked = i + 1
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// each shard enforces 2 of the 8, so the fifth check hits a full shard at the latest
+	if blocked == 0 {
+		t.Fatal("sharded id not blocked after 5 checks")
+	}
+	for i := 0; i < 4; i++ {
+		key := fmt.Sprintf("shard#%d:hot", i)
+		if got, _ := mr.Get(key); got != "2" || mr.TTL(key) != time.Hour {
+			t.Fatalf("%s = %q with ttl %v after the block", key, got, mr.TTL(key))
+		}
+	}
+	if mr.Exists("shard:hot") {
+		t.Fatal("sharded id counted in its unsharded key")
+	}
+	if _, err := rl.Check("hot"); !IsBlocked(err) {
+		t.Fatalf("check of blocked sharded id: %v", err)
+	}
+
+	if err := rl.CheckReset("hot"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if key := fmt.Sprintf("shard#%d:hot", i); mr.Exists(key) {
+			t.Fatalf("%s left after CheckReset", key)
+		}
+	}
+
+	rl.Check("hot")
+	rl.Check("cold")
+	if !mr.Exists("shard:cold") {
+		t.Fatal("unsharded id not counted in its own key")
+	}
+	if n, err := rl.ResetAll(false, false); err != nil || n != 2 {
+		t.Fatalf("ResetAll deleted %d keys, %v", n, err)
+	}
+}
+
+func TestShareOf(t *testing.T) {
+	for _, c := range [][3]int{{8, 4, 2}, {9, 4, 3}, {1, 4, 1}, {5, 1, 5}, {0, 4, 0}} {
+		if got := shareOf(c[0], c[1]); got != c[2] {
+			t.Errorf("shareOf(%d, %d) = %d, want %d", c[0], c[1], got, c[2])
+		}
+	}
+}
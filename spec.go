@@ -0,0 +1,72 @@
+package rateLimiter
+
+import (
+	"context"
+	"time"
+)
+
+// LimitSpec is the part of a Config that differs between endpoints; zero fields
+// keep the Manager defaults.
+type LimitSpec struct {
+	Duration      time.Duration
+	BlockTimes    int
+	BlockDuration time.Duration
+	Windows       []Window
+}
+
+func (s LimitSpec) options() []Option {
+	var opts []Option
+	if s.Duration > 0 {
+		opts = append(opts, WithDuration(s.Duration))
+	}
+	if s.BlockTimes > 0 {
+		opts = append(opts, WithBlockTimes(s.BlockTimes))
+	}
+	if s.BlockDuration > 0 {
+		opts = append(opts, WithBlockDuration(s.BlockDuration))
+	}
+	if len(s.Windows) > 0 {
+		opts = append(opts, WithWindows(s.Windows...))
+	}
+	return opts
+}
+
+// NewManagerWithSpecs creates a limiter for every endpoint key of specs at once.
+// They share defaults, and unless defaults.ListName says otherwise their lists are
+// shared under defaults.Name, so blocking an id blocks it on every endpoint.
+func NewManagerWithSpecs(defaults *Config, specs map[string]LimitSpec) (*Manager, error) {
+	m := NewManager(defaults)
+	if m.defaults.ListName == "" {
+		m.defaults.ListName = m.defaults.Name
+	}
+	for key, spec := range specs {
+		if _, err := m.Get(key, spec.options()...); err != nil {
+			m.CloseAll(context.Background())
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Lookup returns the limiter registered under key without creating it.
+func (m *Manager) Lookup(key string) (*RateLimiter, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rl, ok := m.limiters[key]
+	return rl, ok
+}
+
+func (m *Manager) Sub(message string) error {
+	return m.SubCtx(context.Background(), message)
+}
+
+// SubCtx applies a published change to every limiter, for families sharing one Pub.
+func (m *Manager) SubCtx(ctx context.Context, message string) error {
+	var errs []error
+	for _, rl := range m.List() {
+		if err := rl.SubCtx(ctx, message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return joinErrors(errs)
+}
@@ -0,0 +1,62 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewManagerWithSpecs(t *testing.T) {
+	_, r := testRedis(t)
+	var msgs []string
+	pub := func(channel, msg string) error {
+		msgs = append(msgs, msg)
+		return nil
+	}
+	m, err := NewManagerWithSpecs(&Config{Name: "api", Redis: r, Duration: time.Minute, BlockTimes: 10, Pub: pub}, map[string]LimitSpec{
+		"login":  {BlockTimes: 3, BlockDuration: time.Hour},
+		"search": {Duration: time.Second},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.CloseAll(context.Background()) })
+	login, ok := m.Lookup("login")
+	if !ok || login.Name != "api-login" || login.BlockTimes != 3 || login.Duration != time.Minute || login.BlockDuration != time.Hour {
+		t.Fatalf("login limiter %+v", login)
+	}
+	search, ok := m.Lookup("search")
+	if !ok || search.BlockTimes != 10 || search.Duration != time.Second {
+		t.Fatalf("search limiter %+v", search)
+	}
+	if _, ok := m.Lookup("other"); ok {
+		t.Fatal("Lookup created a limiter")
+	}
+
+	// the lists are shared under the Manager name
+	if err := login.AddBlockList("a", true); err != nil {
+		t.Fatal(err)
+	}
+	// login, which published the message, reports the id as already listed
+	m.Sub(msgs[0])
+	if _, err := search.Check("a"); !IsBlocked(err) {
+		t.Fatalf("id blocked on one endpoint not blocked on the other: %v", err)
+	}
+	other, err := NewLimiter("other", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(10), WithListName("api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Check("a"); !IsBlocked(err) {
+		t.Fatalf("ListName doesn't load the shared list: %v", err)
+	}
+}
+
+func TestNewManagerWithSpecsError(t *testing.T) {
+	_, r := testRedis(t)
+	_, err := NewManagerWithSpecs(&Config{Name: "api", Redis: r, Duration: time.Minute, BlockTimes: 10}, map[string]LimitSpec{
+		"bad": {Windows: []Window{{Duration: time.Minute, Limit: -1}}},
+	})
+	if err == nil {
+		t.Fatal("invalid spec accepted")
+	}
+}
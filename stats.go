@@ -0,0 +1,148 @@
+package rateLimiter
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Stats struct {
+	WhiteList        int
+	StaticWhiteList  int //entries from Config.WhiteList
+	DynamicWhiteList int //entries added at runtime
+	BlockList        int
+	StaticBlockList  int
+	DynamicBlockList int //entries added at runtime, including auto-blocks
+	TrustedList      int
+	BlockedCounters  int       //counters that have reached their limit
+	LastSync         time.Time //last time the local lists were loaded or a sync message was applied
+}
+
+func (rl *RateLimiter) synced() {
+	rl.lastSync.Store(rl.Clock.Now().UnixMilli())
+}
+
+func (rl *RateLimiter) Stats() (*Stats, error) {
+	return rl.StatsCtx(context.Background())
+}
+
+// StatsCtx reports list sizes from the local cache and scans Redis for blocked
+// counters, which is O(number of counters).
+func (rl *RateLimiter) StatsCtx(ctx context.Context) (*Stats, error) {
+	rl.expireWhiteList()
+	rl.expireBlockList()
+	s := &Stats{
+		WhiteList:   rl.whiteIds.size(),
+		BlockList:   rl.blockIds.size(),
+		TrustedList: rl.trustIds.size(),
+	}
+	s.StaticWhiteList = rl.countStatic(rl.whiteIds, rl.Config.WhiteList)
+	s.DynamicWhiteList = s.WhiteList - s.StaticWhiteList
+	s.StaticBlockList = rl.countStatic(rl.blockIds, rl.Config.BlockList)
+	s.DynamicBlockList = s.BlockList - s.StaticBlockList
+	if ms := rl.lastSync.Load(); ms > 0 {
+		s.LastSync = time.UnixMilli(ms)
+	}
+	n, err := rl.countBlocked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.BlockedCounters = n
+	return s, nil
+}
+
+func (rl *RateLimiter) countStatic(l *idList, seeds []string) int {
+	n := 0
+	seen := make(map[string]struct{}, len(seeds))
+	for _, id := range rl.seedIds(seeds) {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		if l.has(id) {
+			n++
+		}
+	}
+	return n
+}
+
+// countBlocked counts the ids whose primary counter has reached its limit. A
+// sharded id counts once, when one of its shards has reached its share.
+func (rl *RateLimiter) countBlocked(ctx context.Context) (int, error) {
+	blocked, err := rl.scanBlocked(ctx, rl.counterPrefix, 1, nil)
+	if err != nil {
+		return blocked, err
+	}
+	seen := make(map[string]struct{})
+	for _, prefix := range rl.shardPrefixes {
+		n, err := rl.scanBlocked(ctx, prefix, rl.Shards, seen)
+		blocked += n
+		if err != nil {
+			return blocked, err
+		}
+	}
+	return blocked, nil
+}
+
+// scanBlocked counts the primary counters under prefix that have reached their
+// share of the limit over shards. The ids counted are added to seen, and ids
+// already in it skipped, unless it is nil.
+func (rl *RateLimiter) scanBlocked(ctx context.Context, prefix string, shards int, seen map[string]struct{}) (int, error) {
+	blocked := 0
+	var cursor uint64
+	for {
+		keys, next, err := rl.Redis.Scan(ctx, cursor, escapeGlob(prefix)+"*", resetScanCount).Result()
+		if err != nil {
+			return blocked, err
+		}
+		ids := make([]string, 0, len(keys))
+		primary := make([]string, 0, len(keys))
+		for _, key := range keys {
+			if hasAnySuffix(key, rl.keySuffixes) {
+				continue
+			}
+			id := strings.TrimPrefix(key, prefix)
+			if _, ok := seen[id]; ok {
+				continue
+			}
+			ids = append(ids, id)
+			primary = append(primary, key)
+		}
+		if len(primary) > 0 {
+			vals, err := rl.Redis.MGet(ctx, primary...).Result()
+			if err != nil {
+				return blocked, err
+			}
+			for i, v := range vals {
+				s, ok := v.(string)
+				if !ok {
+					continue
+				}
+				times, _ := strconv.Atoi(s)
+				if limit := shareOf(rl.primaryWindow(ids[i]).Limit, shards); limit > 0 && times >= limit {
+					if _, ok := seen[ids[i]]; ok {
+						continue
+					}
+					blocked++
+					if seen != nil {
+						seen[ids[i]] = struct{}{}
+					}
+				}
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			return blocked, nil
+		}
+	}
+}
+
+func hasAnySuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	clock := NewFakeClock(time.UnixMilli(time.Now().UnixMilli()))
+	_, rl := testLimiter(t, "stats", WithDuration(time.Minute), WithBlockTimes(2), WithBlockDuration(time.Minute), WithClock(clock),
+		WithWhiteList("w1", "w2"), WithBlockList("b1"), WithTrustedList(2, "t1"),
+		WithWindows(Window{Duration: time.Hour, Limit: 100}))
+	rl.AddWhiteList("w3", false)
+	rl.AddBlockListTTL("b2", time.Hour, false)
+	rl.Check("a")
+	rl.Check("a")
+	rl.Check("c")
+
+	s, err := rl.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Stats{
+		WhiteList: 3, StaticWhiteList: 2, DynamicWhiteList: 1,
+		BlockList: 2, StaticBlockList: 1, DynamicBlockList: 1,
+		TrustedList:     1,
+		BlockedCounters: 1,
+		LastSync:        clock.Now(),
+	}
+	if *s != want {
+		t.Fatalf("Stats() = %+v, want %+v", *s, want)
+	}
+}
+
+func TestStatsBlockedShards(t *testing.T) {
+	_, rl := testLimiter(t, "stats", WithDuration(time.Minute), WithBlockTimes(2), WithBlockDuration(time.Minute),
+		WithWindows(Window{Duration: time.Hour, Limit: 100}), WithShards(2, "hot"))
+	for i := 0; i < 4; i++ {
+		rl.Check("hot")
+	}
+	rl.Check("a")
+	rl.Check("a")
+	rl.Check("c")
+
+	s, err := rl.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.BlockedCounters != 2 {
+		t.Fatalf("BlockedCounters = %d, want 2", s.BlockedCounters)
+	}
+}
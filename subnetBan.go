@@ -0,0 +1,103 @@
+package rateLimiter
+
+import (
+	"context"
+	"net/netip"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	DefaultSubnetBanWindow   = 10 * time.Minute
+	DefaultSubnetBanDuration = time.Hour
+)
+
+// subnetScript records the block of ARGV[3] in the sorted set KEYS[1] of its subnet
+// at ARGV[1], or at Redis TIME if ARGV[1] is empty, forgets the blocks older than
+// ARGV[2] milliseconds and returns how many ids are left.
+var subnetScript = goredis.NewScript(`
+local now = tonumber(ARGV[1])
+if not now then
+	if redis.replicate_commands then
+		redis.replicate_commands()
+	end
+	local t = redis.call('time')
+	now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+end
+local window = tonumber(ARGV[2])
+redis.call('zadd', KEYS[1], now, ARGV[3])
+redis.call('zremrangebyscore', KEYS[1], '-inf', now - window)
+redis.call('pexpire', KEYS[1], window)
+return redis.call('zcard', KEYS[1])
+`)
+
+// subnetOf returns the /24 of an IPv4 id or the /64 of an IPv6 one.
+func subnetOf(id string) (netip.Prefix, bool) {
+	addr, err := netip.ParseAddr(id)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	addr = addr.Unmap()
+	bits := 64
+	if addr.Is4() {
+		bits = 24
+	}
+	p, err := addr.Prefix(bits)
+	return p, err == nil
+}
+
+// recordSubnetOffense counts the block of an IP id against its subnet, and bans
+// the subnet once SubnetBanThreshold of its ids were blocked within SubnetBanWindow.
+func (rl *RateLimiter) recordSubnetOffense(ctx context.Context, id string) {
+	if rl.SubnetBanThreshold <= 0 || rl.DryRun {
+		return
+	}
+	p, ok := subnetOf(id)
+	if !ok {
+		return
+	}
+	subnet := p.String()
+	window := rl.SubnetBanWindow
+	if window == 0 {
+		window = DefaultSubnetBanWindow
+	}
+	now := ""
+	if rl.ClientTimestamps {
+		now = strconv.FormatInt(rl.Clock.Now().UnixMilli(), 10)
+	}
+	key := rl.Name + "-subnet:" + subnet
+	n, err := subnetScript.Run(ctx, rl.Redis, []string{key}, now, window.Milliseconds(), id).Int()
+	if err != nil {
+		rl.Logger.Error("record subnet offense failed", rl.fields("id", id, "err", err)...)
+		return
+	}
+	if n < rl.SubnetBanThreshold {
+		return
+	}
+	// only the instance deleting the offenses bans the subnet
+	if deleted, err := rl.Redis.Del(ctx, key).Result(); err != nil || deleted == 0 {
+		return
+	}
+	rl.banSubnet(ctx, subnet, n)
+}
+
+func (rl *RateLimiter) banSubnet(ctx context.Context, subnet string, offenders int) {
+	d := rl.SubnetBanDuration
+	if d == 0 {
+		d = DefaultSubnetBanDuration
+	}
+	entry := ListEntry{
+		Id:        subnet,
+		Reason:    strconv.Itoa(offenders) + " ids blocked",
+		Operator:  AutoBlockOperator,
+		CreatedAt: rl.Clock.Now(),
+	}
+	if err := rl.addBlockListEntry(ctx, entry, d, true); err != nil {
+		rl.Logger.Error("subnet ban failed", rl.fields("subnet", subnet, "err", err)...)
+		return
+	}
+	rl.Logger.Info("subnet banned", rl.fields("subnet", subnet, "ids", offenders, "duration", d)...)
+	rl.emit(Event{Type: EventSubnetBan, Id: subnet})
+}
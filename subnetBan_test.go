@@ -0,0 +1,67 @@
+package rateLimiter
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubnetOf(t *testing.T) {
+	for id, want := range map[string]string{
+		"10.1.2.3":        "10.1.2.0/24",
+		"::ffff:10.1.2.3": "10.1.2.0/24",
+		"2001:db8:1:2::9": "2001:db8:1:2::/64",
+	} {
+		if p, ok := subnetOf(id); !ok || p.String() != want {
+			t.Errorf("subnetOf(%q) = %v, %v, want %s", id, p, ok, want)
+		}
+	}
+	if _, ok := subnetOf("user"); ok {
+		t.Error("subnet of a non-IP id")
+	}
+}
+
+func TestSubnetBan(t *testing.T) {
+	var mu sync.Mutex
+	var bans []string
+	clock := NewFakeClock(time.Now())
+	_, rl := testLimiter(t, "subnet", WithDuration(time.Minute), WithBlockTimes(2), WithBlockDuration(time.Minute),
+		WithSubnetBan(3, 10*time.Minute, time.Hour), WithClock(clock), WithClientTimestamps(),
+		WithOnEvent(func(e Event) {
+			if e.Type == EventSubnetBan {
+				mu.Lock()
+				bans = append(bans, e.Id)
+				mu.Unlock()
+			}
+		}))
+	block := func(id string) {
+		rl.Check(id)
+		if _, err := rl.Check(id); !IsBlocked(err) {
+			t.Fatalf("%s not blocked: %v", id, err)
+		}
+	}
+	block("10.0.0.1")
+	block("10.0.0.2")
+	// offenses older than the window are forgotten
+	clock.Advance(11 * time.Minute)
+	block("10.0.0.3")
+	block("10.0.0.4")
+	if _, err := rl.Check("10.0.0.99"); err != nil {
+		t.Fatalf("subnet banned after 2 recent offenses: %v", err)
+	}
+	block("10.0.0.5")
+	if _, err := rl.Check("10.0.0.100"); !IsBlocked(err) {
+		t.Fatalf("check in a banned subnet: %v", err)
+	}
+	if _, err := rl.Check("10.0.1.1"); err != nil {
+		t.Fatalf("check in another subnet: %v", err)
+	}
+	if at, ok := rl.blockIds.expiry.get("10.0.0.0/24"); !ok || at.Sub(clock.Now()) != time.Hour {
+		t.Fatalf("subnet banned until %v", at)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(bans) != 1 || bans[0] != "10.0.0.0/24" {
+		t.Fatalf("ban events %v", bans)
+	}
+}
@@ -0,0 +1,110 @@
+package rateLimiter
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// SyncVersion is the version of the SyncMessage format published by this package.
+// Messages with a newer version are ignored by Sub.
+const SyncVersion = 1
+
+// SyncMessage is the Pub/Sub payload describing a change to be replicated.
+type SyncMessage struct {
+	Version int      `json:"v"`
+	Op      string   `json:"op"`
+	Id      string   `json:"id,omitempty"`
+	Ids     []string `json:"ids,omitempty"` //batch ops
+	TTL     int64    `json:"ttl,omitempty"` //milliseconds, temporary entries only
+	Reason  string   `json:"reason,omitempty"`
+	Origin  string   `json:"origin,omitempty"` //InstanceId of the publisher
+	Sender  string   `json:"sender,omitempty"` //random id of the publishing limiter, which skips its own messages
+	Tenant  string   `json:"tenant,omitempty"` //set by ForTenant views
+}
+
+type reasonKey struct{}
+
+func withReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, reasonKey{}, reason)
+}
+
+func reasonFrom(ctx context.Context) string {
+	reason, _ := ctx.Value(reasonKey{}).(string)
+	return reason
+}
+
+// newSender returns a random SyncMessage.Sender. InstanceId can't tell the
+// limiters of one process apart, so it doesn't do.
+func newSender() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// publish sends msg through Config.Pub, as JSON unless Config.LegacySync is set.
+func (rl *RateLimiter) publish(ctx context.Context, msg SyncMessage) error {
+	if rl.Pub == nil {
+		return nil
+	}
+	if rl.LegacySync {
+		return rl.published(msg, rl.Pub(rl.Name, msg.legacy()))
+	}
+	msg.Version = SyncVersion
+	msg.Origin = rl.InstanceId
+	msg.Sender = rl.sender
+	msg.Tenant = rl.tenant
+	if msg.Reason == "" {
+		msg.Reason = reasonFrom(ctx)
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return rl.published(msg, rl.Pub(rl.Name, string(data)))
+}
+
+func (rl *RateLimiter) published(msg SyncMessage, err error) error {
+	if err != nil {
+		rl.Logger.Error("publish failed", rl.fields("op", msg.Op, "id", msg.Id, "err", err)...)
+	}
+	return err
+}
+
+func (m SyncMessage) legacy() string {
+	switch {
+	case isBatchOp(m.Op):
+		data, _ := json.Marshal(m.Ids)
+		return m.Op + "-" + string(data)
+	case m.Op == "cb" || m.Op == "cw":
+		return m.Op + "-all"
+	default:
+		return m.Op + "-" + m.Id
+	}
+}
+
+// parseSyncMessage decodes a JSON message or a legacy "op-id" one. ok is false for
+// legacy messages that cannot be parsed.
+func parseSyncMessage(message string) (msg SyncMessage, ok bool, err error) {
+	if strings.HasPrefix(message, "{") {
+		if err := json.Unmarshal([]byte(message), &msg); err != nil {
+			return msg, false, err
+		}
+		return msg, true, nil
+	}
+	if op, payload, found := strings.Cut(message, "-"); found && isBatchOp(op) {
+		msg.Op = op
+		if err := json.Unmarshal([]byte(payload), &msg.Ids); err != nil {
+			return msg, false, err
+		}
+		return msg, true, nil
+	}
+	str := strings.Split(message, "-")
+	if len(str) != 2 {
+		return msg, false, nil
+	}
+	msg.Op, msg.Id = str[0], str[1]
+	return msg, true, nil
+}
@@ -0,0 +1,68 @@
+package rateLimiter
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSyncMessage(t *testing.T) {
+	var msgs []string
+	pub := func(channel, msg string) error {
+		msgs = append(msgs, msg)
+		return nil
+	}
+	_, rl := testLimiter(t, "syncMsg", WithDuration(time.Minute), WithBlockTimes(5), WithPub(pub), func(c *Config) { c.InstanceId = "i1" })
+	if err := rl.AddBlockListTTL("a", time.Minute, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("published %q", msgs)
+	}
+	var msg SyncMessage
+	if err := json.Unmarshal([]byte(msgs[0]), &msg); err != nil {
+		t.Fatal(err)
+	}
+	want := SyncMessage{Version: SyncVersion, Op: "tb", Id: "a", TTL: time.Minute.Milliseconds(), Origin: "i1"}
+	if msg.Version != want.Version || msg.Op != want.Op || msg.Id != want.Id || msg.TTL != want.TTL || msg.Origin != want.Origin {
+		t.Fatalf("published %+v, want %+v", msg, want)
+	}
+
+	_, other := testLimiter(t, "syncMsg", WithDuration(time.Minute), WithBlockTimes(5))
+	newer, _ := json.Marshal(SyncMessage{Version: SyncVersion + 1, Op: "ab", Id: "b"})
+	if err := other.Sub(string(newer)); err != nil {
+		t.Fatal(err)
+	}
+	if other.blockIds.has("b") {
+		t.Fatal("message with a newer version applied")
+	}
+	if err := other.Sub("not json"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLegacySync(t *testing.T) {
+	var msgs []string
+	pub := func(channel, msg string) error {
+		msgs = append(msgs, msg)
+		return nil
+	}
+	_, rl := testLimiter(t, "legacy", WithDuration(time.Minute), WithBlockTimes(5), WithPub(pub), WithLegacySync())
+	rl.AddBlockList("a", true)
+	rl.RemoveBlockList("a", true)
+	if len(msgs) != 2 || msgs[0] != "ab-a" || msgs[1] != "rb-a" {
+		t.Fatalf("published %q, want legacy messages", msgs)
+	}
+
+	_, other := testLimiter(t, "legacy", WithDuration(time.Minute), WithBlockTimes(5))
+	for _, m := range []string{"ab-b", `abs-["c","d"]`} {
+		if err := other.Sub(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"b", "c", "d"} {
+		if !other.blockIds.has(id) {
+			t.Fatalf("legacy message for %s not applied", id)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package rateLimiter
+
+import (
+	"context"
+	"time"
+)
+
+const DefaultTarpitMaxDelay = 10 * time.Second
+
+// tarpitDelay counts a request of id over its limits and returns how long to hold
+// it: TarpitDelay for every such request in the current window, up to
+// TarpitMaxDelay. ttl is what is left of the window.
+func (rl *RateLimiter) tarpitDelay(ctx context.Context, id string, ttl time.Duration) (time.Duration, error) {
+	if ttl <= 0 {
+		ttl = rl.primaryWindow(id).Duration
+	}
+	key := rl.Name + "-tarpit:" + id
+	pipe := rl.Redis.Pipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.PExpire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	max := rl.TarpitMaxDelay
+	if max == 0 {
+		max = DefaultTarpitMaxDelay
+	}
+	if n := incr.Val(); n <= int64(max/rl.TarpitDelay) {
+		return time.Duration(n) * rl.TarpitDelay, nil
+	}
+	return max, nil
+}
+
+// hold sleeps for the Delay of a tarpitted result, or until ctx is done.
+func (rl *RateLimiter) hold(ctx context.Context, res *CheckResult) error {
+	t := time.NewTimer(res.Delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
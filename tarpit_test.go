@@ -0,0 +1,35 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTarpit(t *testing.T) {
+	const delay = 20 * time.Millisecond
+	mr, rl := testLimiter(t, "tarpit", WithDuration(time.Minute), WithBlockTimes(2), WithBlockDuration(time.Minute),
+		WithTarpit(delay, 2*delay+delay/2))
+	if res, err := rl.Allow("a"); err != nil || res.Delay != 0 {
+		t.Fatalf("first check: %+v, %v", res, err)
+	}
+	for i, want := range []time.Duration{delay, 2 * delay, 2*delay + delay/2} {
+		start := time.Now()
+		res, err := rl.Allow("a")
+		if err != nil || !res.Allowed || res.Remaining != 0 || res.Delay != want {
+			t.Fatalf("check %d over the limit: %+v, %v", i+1, res, err)
+		}
+		if held := time.Since(start); held < want {
+			t.Fatalf("check %d held %v, want %v", i+1, held, want)
+		}
+	}
+	if got, _ := mr.Get("tarpit-tarpit:a"); got != "3" || mr.TTL("tarpit-tarpit:a") > time.Minute {
+		t.Fatalf("%q held requests counted for %v", got, mr.TTL("tarpit-tarpit:a"))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := rl.AllowCtx(ctx, "a"); err != context.Canceled {
+		t.Fatalf("held check with a canceled context: %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package rateLimiter
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+)
+
+// tenantSeparator joins the limiter name and the tenant in the keys of a view.
+const tenantSeparator = "@"
+
+type tenantViews struct {
+	mu    sync.Mutex
+	views map[string]*RateLimiter
+}
+
+// ForTenant returns the view of rl for tenant: a limiter with the same settings
+// whose counters, lists, overrides and logs live under their own keys, so one
+// tenant's block list or quota never affects another. Views are created once and
+// closed with rl; changes made through a view are synced to the same view on the
+// other instances.
+func (rl *RateLimiter) ForTenant(tenant string) (*RateLimiter, error) {
+	if rl.tenant != "" {
+		return nil, stderrors.New("租户视图不能再调用ForTenant")
+	}
+	if rl.LegacySync {
+		return nil, stderrors.New("LegacySync不支持ForTenant")
+	}
+	tenant, err := rl.sanitizeId(tenant)
+	if err != nil {
+		return nil, err
+	}
+	rl.tenants.mu.Lock()
+	defer rl.tenants.mu.Unlock()
+	if view, ok := rl.tenants.views[tenant]; ok {
+		return view, nil
+	}
+	c := *rl.Config
+	c.Name = rl.Name + tenantSeparator + tenant
+	if c.ListName != "" {
+		c.ListName += tenantSeparator + tenant
+	}
+	c.WhiteList = append([]string(nil), rl.Config.WhiteList...)
+	c.BlockList = append([]string(nil), rl.Config.BlockList...)
+	c.TrustedList = append([]string(nil), rl.Config.TrustedList...)
+	c.tenant = tenant
+	view, err := newRateLimiter(&c)
+	if err != nil {
+		return nil, err
+	}
+	if rl.tenants.views == nil {
+		rl.tenants.views = make(map[string]*RateLimiter)
+		rl.onClose(rl.closeTenants)
+	}
+	rl.tenants.views[tenant] = view
+	return view, nil
+}
+
+// Tenant returns the tenant of a ForTenant view, "" for other limiters.
+func (rl *RateLimiter) Tenant() string {
+	return rl.tenant
+}
+
+func (rl *RateLimiter) tenantView(tenant string) (*RateLimiter, bool) {
+	rl.tenants.mu.Lock()
+	defer rl.tenants.mu.Unlock()
+	view, ok := rl.tenants.views[tenant]
+	return view, ok
+}
+
+func (rl *RateLimiter) closeTenants(ctx context.Context) error {
+	rl.tenants.mu.Lock()
+	views := rl.tenants.views
+	rl.tenants.views = make(map[string]*RateLimiter)
+	rl.tenants.mu.Unlock()
+	var errs []error
+	for _, view := range views {
+		if err := view.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return joinErrors(errs)
+}
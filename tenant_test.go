@@ -0,0 +1,72 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestForTenant(t *testing.T) {
+	_, rl := testLimiter(t, "tenant", WithDuration(time.Minute), WithBlockTimes(2))
+	a, err := rl.ForTenant("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := rl.ForTenant("b")
+	if again, _ := rl.ForTenant("a"); again != a {
+		t.Fatal("ForTenant created a second view")
+	}
+	if a.Tenant() != "a" || a.Name != "tenant@a" || rl.Tenant() != "" {
+		t.Fatalf("tenant %q name %q", a.Tenant(), a.Name)
+	}
+	if _, err := a.ForTenant("x"); err == nil {
+		t.Fatal("ForTenant on a view accepted")
+	}
+
+	a.Allow("x")
+	a.Allow("x")
+	if res, _ := b.Allow("x"); !res.Allowed {
+		t.Fatal("counter shared between tenants")
+	}
+	if err := a.AddBlockList("y", false); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := b.Allow("y"); !res.Allowed {
+		t.Fatal("block list shared between tenants")
+	}
+	if res, _ := rl.Allow("y"); !res.Allowed {
+		t.Fatal("block list shared with the parent")
+	}
+}
+
+func TestForTenantSync(t *testing.T) {
+	_, r := testRedis(t)
+	var remote *RateLimiter
+	local, err := NewLimiter("tenant", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(2),
+		WithPub(func(name, message string) error { return remote.Sub(message) }), func(c *Config) { c.InstanceId = "local" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { local.Close(context.Background()) })
+	remote, err = NewLimiter("tenant", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(2), func(c *Config) { c.InstanceId = "remote" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { remote.Close(context.Background()) })
+	remoteA, _ := remote.ForTenant("a")
+	remoteB, _ := remote.ForTenant("b")
+	localA, _ := local.ForTenant("a")
+
+	if err := localA.AddBlockList("y", true); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := remoteA.Allow("y"); res.Allowed {
+		t.Fatal("change not synced to the tenant view")
+	}
+	if res, _ := remoteB.Allow("y"); !res.Allowed {
+		t.Fatal("change synced to another tenant")
+	}
+	if res, _ := remote.Allow("y"); !res.Allowed {
+		t.Fatal("change synced to the parent")
+	}
+}
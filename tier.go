@@ -0,0 +1,33 @@
+package rateLimiter
+
+import "time"
+
+// Tier holds the limits of a plan, e.g. free or pro; zero fields keep the limiter
+// defaults. Overrides still take precedence for single ids.
+type Tier struct {
+	BlockTimes int
+	Duration   time.Duration
+}
+
+// tierName resolves the tier of id with Config.TierResolver, falling back to the
+// tier of its country, "" if neither applies.
+func (rl *RateLimiter) tierName(id string) string {
+	if id == globalId {
+		return ""
+	}
+	if rl.TierResolver != nil {
+		if name := rl.TierResolver(id); name != "" {
+			return name
+		}
+	}
+	return rl.countryTier(id)
+}
+
+func (rl *RateLimiter) tier(id string) (Tier, bool) {
+	name := rl.tierName(id)
+	if name == "" {
+		return Tier{}, false
+	}
+	tier, ok := rl.Tiers[name]
+	return tier, ok
+}
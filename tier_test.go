@@ -0,0 +1,41 @@
+package rateLimiter
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTiers(t *testing.T) {
+	resolver := func(id string) string {
+		plan, _, _ := strings.Cut(id, ":")
+		return plan
+	}
+	_, rl := testLimiter(t, "tiers", WithDuration(time.Minute), WithBlockTimes(2), WithTrustedList(3, "pro:trusted"),
+		WithTiers(resolver, map[string]Tier{"pro": {BlockTimes: 5, Duration: time.Hour}, "free": {}}))
+	for id, want := range map[string]int{
+		"pro:a":       5,
+		"free:a":      2,
+		"other:a":     2,
+		"pro:trusted": 15,
+	} {
+		res, _ := rl.Allow(id)
+		if res.Limit != want {
+			t.Errorf("limit of %s = %d, want %d", id, res.Limit, want)
+		}
+	}
+	if err := rl.SetOverride("pro:b", Override{BlockTimes: 1}, false); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := rl.Allow("pro:b"); res.Limit != 1 {
+		t.Fatalf("override limit %d, want 1", res.Limit)
+	}
+	if ins, _ := rl.Inspect("pro:a"); ins.Tier != "pro" || ins.Limit != 5 || ins.TTL > time.Hour || ins.TTL <= time.Minute {
+		t.Fatalf("inspection %+v", ins)
+	}
+
+	if _, err := NewLimiter("bad", WithRedis(rl.Redis), WithDuration(time.Minute), WithBlockTimes(2),
+		WithTiers(resolver, map[string]Tier{"pro": {BlockTimes: -1}})); err == nil {
+		t.Fatal("negative tier accepted")
+	}
+}
@@ -0,0 +1,35 @@
+package rateLimiter
+
+import (
+	"context"
+)
+
+func noCancel() {}
+
+// checkContext bounds the Redis calls of a single check by Config.CheckTimeout.
+func (rl *RateLimiter) checkContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if rl.CheckTimeout <= 0 {
+		return ctx, noCancel
+	}
+	return context.WithTimeout(ctx, rl.CheckTimeout)
+}
+
+// listContext bounds a white, block or trusted list mutation by Config.ListTimeout.
+func (rl *RateLimiter) listContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if rl.ListTimeout <= 0 {
+		return ctx, noCancel
+	}
+	return context.WithTimeout(ctx, rl.ListTimeout)
+}
+
+// failOpen returns the result of a check of id that couldn't reach Redis when
+// Config.FailOpen lets it through: allowed, with nothing counted.
+func (rl *RateLimiter) failOpen(id string, err error) (*CheckResult, bool) {
+	if !rl.FailOpen {
+		return nil, false
+	}
+	rl.Logger.Warn("check failed open", rl.fields("id", id, "err", err)...)
+	res := rl.newResult(rl.primaryWindow(id), 0, 0)
+	res.FailedOpen = true
+	return res, true
+}
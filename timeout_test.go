@@ -0,0 +1,54 @@
+package rateLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeouts(t *testing.T) {
+	_, rl := testLimiter(t, "timeout", WithDuration(time.Minute), WithTimeouts(time.Second, time.Minute))
+	for name, f := range map[string]func(context.Context) (context.Context, context.CancelFunc){
+		"check": rl.checkContext,
+		"list":  rl.listContext,
+	} {
+		ctx, cancel := f(context.Background())
+		deadline, ok := ctx.Deadline()
+		cancel()
+		if !ok {
+			t.Fatalf("%s context without deadline", name)
+		}
+		if name == "check" && time.Until(deadline) > time.Second {
+			t.Fatalf("check deadline %v", time.Until(deadline))
+		}
+	}
+
+	_, rl = testLimiter(t, "timeout", WithDuration(time.Minute))
+	ctx, cancel := rl.checkContext(context.Background())
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("deadline without CheckTimeout")
+	}
+}
+
+func TestFailOpen(t *testing.T) {
+	l := newTestLogger()
+	mr, rl := testLimiter(t, "failOpen", WithDuration(time.Minute), WithBlockTimes(2), WithFailOpen(), WithLogger(l))
+	mr.Close()
+	res, err := rl.Allow("a")
+	if err != nil || !res.FailedOpen {
+		t.Fatalf("Allow with Redis down: %+v, %v", res, err)
+	}
+	if res, err = rl.Wait("a"); err != nil || !res.FailedOpen {
+		t.Fatalf("Wait with Redis down: %+v, %v", res, err)
+	}
+	if !l.logged("warn", "check failed open") {
+		t.Fatalf("fail open not logged: %v", l.msgs)
+	}
+
+	mr, rl = testLimiter(t, "failClosed", WithDuration(time.Minute), WithBlockTimes(2))
+	mr.Close()
+	if _, err := rl.Allow("a"); err == nil {
+		t.Fatal("Allow with Redis down succeeded without FailOpen")
+	}
+}
@@ -0,0 +1,94 @@
+package rateLimiter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "github.com/go-estar/rate-limiter"
+
+func newSpanOption(name string) trace.SpanStartOption {
+	return trace.WithAttributes(attribute.String("rate_limiter.name", name))
+}
+
+func newTracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		return noop.NewTracerProvider().Tracer(tracerName)
+	}
+	return tp.Tracer(tracerName)
+}
+
+// noopSpan is returned by startSpan without a TracerProvider, so that checks don't
+// pay for building span options nobody records.
+var noopSpan trace.Span = noop.Span{}
+
+// startSpan starts a child span of ctx; ids are only recorded hashed.
+func (rl *RateLimiter) startSpan(ctx context.Context, op, id string) (context.Context, trace.Span) {
+	if rl.TracerProvider == nil {
+		return ctx, noopSpan
+	}
+	ctx, span := rl.tracer.Start(ctx, "rateLimiter."+op, rl.spanOption)
+	if id != "" && span.IsRecording() {
+		span.SetAttributes(attribute.String("rate_limiter.id_hash", hashId(id)))
+	}
+	return ctx, span
+}
+
+func hashId(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:8])
+}
+
+// endSpan ends span, recording err unless it is a block decision.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !IsBlocked(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// checked runs a check and records its metrics and span.
+func (rl *RateLimiter) checked(ctx context.Context, id string, check func(ctx context.Context) (*CheckResult, error)) (*CheckResult, error) {
+	ctx, span := rl.startSpan(ctx, "Check", id)
+	start := time.Now()
+	res, err := rl.enforce(check(ctx))
+	rl.decided(ctx, span, id, time.Since(start), res, err)
+	return res, err
+}
+
+// decided records the outcome of a check that took d: metrics, events, offenders,
+// the decision log and span, which it ends.
+func (rl *RateLimiter) decided(ctx context.Context, span trace.Span, id string, d time.Duration, res *CheckResult, err error) {
+	rl.metrics.observe(d, res, err)
+	rl.otelMetrics.observe(ctx, d, res, err)
+	rl.expvarMetrics.observe(res, err)
+	rl.emitDecision(id, res)
+	rl.detectAnomaly(id, res)
+	if res != nil && (!res.Allowed || res.DryRunBlocked) {
+		if rl.TrackOffenders && id != globalId {
+			rl.recordOffender(id)
+		}
+		if rl.DecisionLogSize > 0 {
+			rl.logDecision(id, res)
+		}
+	}
+	if span.IsRecording() {
+		switch {
+		case res != nil && res.Allowed:
+			span.SetAttributes(attribute.String("rate_limiter.result", "allowed"), attribute.Int("rate_limiter.times", res.Used))
+		case res != nil:
+			span.SetAttributes(attribute.String("rate_limiter.result", "blocked"), attribute.Int("rate_limiter.times", res.Used))
+		default:
+			span.SetAttributes(attribute.String("rate_limiter.result", "error"))
+		}
+	}
+	endSpan(span, err)
+}
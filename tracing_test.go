@@ -0,0 +1,91 @@
+package rateLimiter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// testTracer records the spans it starts; it stands in for the OpenTelemetry SDK.
+type testTracer struct {
+	noop.Tracer
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+type testTracerProvider struct {
+	noop.TracerProvider
+	tracer *testTracer
+}
+
+func (tp testTracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return tp.tracer
+}
+
+func (tt *testTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	s := &testSpan{name: name, attrs: make(map[attribute.Key]attribute.Value)}
+	cfg := trace.NewSpanStartConfig(opts...)
+	s.SetAttributes(cfg.Attributes()...)
+	tt.mu.Lock()
+	tt.spans = append(tt.spans, s)
+	tt.mu.Unlock()
+	return ctx, s
+}
+
+type testSpan struct {
+	noop.Span
+	name   string
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	ended  bool
+}
+
+func (s *testSpan) IsRecording() bool { return true }
+
+func (s *testSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *testSpan) SetStatus(code codes.Code, _ string) { s.status = code }
+
+func (s *testSpan) End(...trace.SpanEndOption) { s.ended = true }
+
+func TestTracing(t *testing.T) {
+	tt := &testTracer{}
+	_, rl := testLimiter(t, "trace", WithDuration(time.Minute), WithBlockTimes(1), WithTracerProvider(testTracerProvider{tracer: tt}))
+	rl.Allow("secret-id")
+	if err := rl.Sub("ab-x"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tt.spans) < 2 {
+		t.Fatalf("%d spans", len(tt.spans))
+	}
+	check := tt.spans[0]
+	if check.name != "rateLimiter.Check" || !check.ended || check.status == codes.Error {
+		t.Fatalf("check span %+v", check)
+	}
+	if check.attrs["rate_limiter.result"].AsString() != "blocked" || check.attrs["rate_limiter.name"].AsString() != "trace" {
+		t.Fatalf("check span attributes %v", check.attrs)
+	}
+	if h := check.attrs["rate_limiter.id_hash"].AsString(); h == "" || h == "secret-id" || h != hashId("secret-id") {
+		t.Fatalf("id recorded as %q", h)
+	}
+	var sub *testSpan
+	for _, s := range tt.spans {
+		if s.name == "rateLimiter.Sub" {
+			sub = s
+		}
+	}
+	if sub == nil || !sub.ended || sub.attrs["rate_limiter.op"].AsString() != "ab" {
+		t.Fatalf("sub span %+v", sub)
+	}
+}
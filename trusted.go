@@ -0,0 +1,88 @@
+package rateLimiter
+
+import (
+	"context"
+)
+
+// DefaultTrustedMultiplier is applied to the limits of trusted ids when
+// Config.TrustedMultiplier is 0.
+const DefaultTrustedMultiplier = 10
+
+func (rl *RateLimiter) loadTrustedList(ctx context.Context) error {
+	return rl.trustIds.apply(func() error {
+		ids, err := rl.Redis.SMembers(ctx, rl.trustListKey).Result()
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			rl.trustIds.add(id)
+		}
+		return nil
+	})
+}
+
+func (rl *RateLimiter) inTrustedList(id string) bool {
+	return rl.trustIds.match(id)
+}
+
+func (rl *RateLimiter) AddTrustedList(id string, pub bool) error {
+	return rl.AddTrustedListCtx(context.Background(), id, pub)
+}
+
+// AddTrustedListCtx keeps id rate limited, but at TrustedMultiplier times the
+// normal limits.
+func (rl *RateLimiter) AddTrustedListCtx(ctx context.Context, id string, pub bool) error {
+	ctx, cancel := rl.listContext(ctx)
+	defer cancel()
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return err
+	}
+	err = rl.trustIds.apply(func() error {
+		if err := rl.Redis.SAdd(ctx, rl.trustListKey, id).Err(); err != nil {
+			return err
+		}
+		rl.trustIds.add(id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	rl.audit(ctx, AuditAddTrustedList, id, 0)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: "at", Id: id})
+	}
+	return nil
+}
+
+func (rl *RateLimiter) RemoveTrustedList(id string, pub bool) error {
+	return rl.RemoveTrustedListCtx(context.Background(), id, pub)
+}
+
+func (rl *RateLimiter) RemoveTrustedListCtx(ctx context.Context, id string, pub bool) error {
+	ctx, cancel := rl.listContext(ctx)
+	defer cancel()
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return err
+	}
+	err = rl.trustIds.apply(func() error {
+		if err := rl.Redis.SRem(ctx, rl.trustListKey, id).Err(); err != nil {
+			return err
+		}
+		rl.trustIds.remove(id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	rl.audit(ctx, AuditRemoveTrustedList, id, 0)
+	if pub {
+		rl.publish(ctx, SyncMessage{Op: "rt", Id: id})
+	}
+	return nil
+}
+
+func (rl *RateLimiter) GetTrustedList() []string {
+	return rl.trustIds.list()
+}
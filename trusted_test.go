@@ -0,0 +1,63 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrustedList(t *testing.T) {
+	var msgs []string
+	pub := func(channel, msg string) error {
+		msgs = append(msgs, msg)
+		return nil
+	}
+	_, r := testRedis(t)
+	rl, err := NewLimiter("trusted", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(2), WithTrustedList(3, "static"), WithPub(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.AddTrustedList("a", true); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "static"} {
+		for i := 0; i < 5; i++ {
+			if _, err := rl.Check(id); err != nil {
+				t.Fatalf("%s: check %d rejected: %v", id, i+1, err)
+			}
+		}
+		if _, err := rl.Check(id); !IsBlocked(err) {
+			t.Fatalf("%s: expected block at 3x the limit, got %v", id, err)
+		}
+	}
+	in, err := rl.Inspect("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !in.Trusted {
+		t.Fatal("Inspect doesn't report the trusted entry")
+	}
+
+	other, err := NewLimiter("trusted", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !other.inTrustedList("a") {
+		t.Fatal("trusted entry not loaded from Redis")
+	}
+	if err := rl.RemoveTrustedList("a", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.Sub(msgs[len(msgs)-1]); err != nil {
+		t.Fatal(err)
+	}
+	if other.inTrustedList("a") || rl.inTrustedList("a") {
+		t.Fatal("removed trusted entry still applies")
+	}
+}
+
+func TestTrustedMultiplierValidation(t *testing.T) {
+	_, r := testRedis(t)
+	if _, err := NewLimiter("trusted", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(2), WithTrustedList(-1)); err == nil {
+		t.Fatal("negative TrustedMultiplier accepted")
+	}
+}
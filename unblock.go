@@ -0,0 +1,35 @@
+package rateLimiter
+
+import (
+	"context"
+	"time"
+)
+
+const unblockSweepInterval = time.Second
+
+func (rl *RateLimiter) unblocked(ids []string) {
+	for _, id := range ids {
+		if rl.OnUnblock != nil {
+			rl.OnUnblock(id)
+		}
+		rl.emit(Event{Type: EventUnblock, Id: id})
+	}
+}
+
+// unblockLoop reports ended blocks without waiting for traffic: expired temporary
+// block list entries, and BlockDuration blocks started by this instance. The latter
+// are tracked in memory only and are not reported after a restart.
+func (rl *RateLimiter) unblockLoop(ctx context.Context) {
+	ticker := time.NewTicker(unblockSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rl.expireBlockList()
+			rl.purgeExpired(ctx, rl.blockListKey, rl.blockTTLKey)
+			rl.unblocked(rl.unblocks.due(rl.Clock.Now()))
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package rateLimiter
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestOnUnblock(t *testing.T) {
+	clock := NewFakeClock(time.UnixMilli(time.Now().UnixMilli()))
+	unblocked := make(chan string, 10)
+	_, rl := testLimiter(t, "unblock", WithDuration(time.Minute), WithBlockTimes(1), WithBlockDuration(time.Minute),
+		WithClock(clock), WithOnUnblock(func(id string) { unblocked <- id }))
+	if _, err := rl.Check("a"); !IsBlocked(err) {
+		t.Fatalf("expected block, got %v", err)
+	}
+	if _, err := rl.Check("reset"); !IsBlocked(err) {
+		t.Fatalf("expected block, got %v", err)
+	}
+	if err := rl.CheckReset("reset"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rl.AddBlockListTTL("b", time.Minute, false); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Minute)
+
+	var got []string
+	timeout := time.After(3 * unblockSweepInterval)
+	for len(got) < 2 {
+		select {
+		case id := <-unblocked:
+			got = append(got, id)
+		case <-timeout:
+			t.Fatalf("unblocked %q, want [a b]", got)
+		}
+	}
+	sort.Strings(got)
+	if got[0] != "a" || got[1] != "b" {
+		t.Fatalf("unblocked %q, want [a b]", got)
+	}
+	select {
+	case id := <-unblocked:
+		t.Fatalf("unexpected unblock of %s", id)
+	case <-time.After(unblockSweepInterval + 100*time.Millisecond):
+	}
+}
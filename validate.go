@@ -0,0 +1,244 @@
+package rateLimiter
+
+import (
+	stderrors "errors"
+)
+
+// ConfigWarning is a setting Validate reports as suspicious; unlike the other
+// problems it does not stop the limiter from being created.
+type ConfigWarning struct {
+	Field   string
+	Message string
+}
+
+func (w *ConfigWarning) Error() string {
+	return w.Field + ": " + w.Message
+}
+
+func IsConfigWarning(err error) bool {
+	var w *ConfigWarning
+	return stderrors.As(err, &w)
+}
+
+// Validate checks every field of c and returns all problems at once as a
+// MultiError, ConfigWarnings included, for config linters in CI. The
+// constructors reject a Config for any problem that is not a ConfigWarning.
+func (c *Config) Validate() error {
+	warnings, err := c.validate()
+	var errs []error
+	if err != nil {
+		errs = append(errs, err.(MultiError)...)
+	}
+	return joinErrors(append(errs, warnings...))
+}
+
+func (c *Config) validate() (warnings []error, err error) {
+	if c == nil {
+		return nil, joinErrors([]error{stderrors.New("config必须设置")})
+	}
+	var errs []error
+	fail := func(msg string) {
+		errs = append(errs, stderrors.New(msg))
+	}
+	warn := func(field, msg string) {
+		warnings = append(warnings, &ConfigWarning{Field: field, Message: msg})
+	}
+	if c.Name == "" {
+		fail("Name必须设置")
+	}
+	if c.Duration == 0 {
+		fail("Duration必须设置")
+	}
+	if c.Duration < 0 {
+		fail("Duration不能小于0")
+	}
+	if c.BlockTimes < 0 {
+		fail("BlockTimes不能小于0")
+	}
+	if c.BlockDuration < 0 {
+		fail("BlockDuration不能小于0")
+	}
+	if c.MaxIdLength < 0 {
+		fail("MaxIdLength不能小于0")
+	}
+	if c.Redis == nil {
+		fail("Redis必须设置")
+	}
+	for _, w := range c.Windows {
+		if w.Duration <= 0 || w.Limit <= 0 {
+			fail("Windows的Duration和Limit必须大于0")
+			break
+		}
+	}
+	for _, check := range []struct {
+		negative bool
+		msg      string
+	}{
+		{c.AuditLogSize < 0, "AuditLogSize不能小于0"},
+		{c.DecisionLogSize < 0, "DecisionLogSize不能小于0"},
+		{c.ResyncInterval < 0, "ResyncInterval不能小于0"},
+		{c.TrustedMultiplier < 0, "TrustedMultiplier不能小于0"},
+		{c.EscalationWindow < 0, "EscalationWindow不能小于0"},
+		{c.OffendersPeriod < 0, "OffendersPeriod不能小于0"},
+		{c.SlowRedisThreshold < 0, "SlowRedisThreshold不能小于0"},
+		{c.WriteBehind < 0, "WriteBehind不能小于0"},
+		{c.CheckTimeout < 0, "CheckTimeout不能小于0"},
+		{c.ListTimeout < 0, "ListTimeout不能小于0"},
+		{c.Shards < 0, "Shards不能小于0"},
+		{c.BloomInterval < 0, "BloomInterval不能小于0"},
+		{c.JanitorInterval < 0, "JanitorInterval不能小于0"},
+		{c.MaxCacheEntries < 0, "MaxCacheEntries不能小于0"},
+		{c.ChallengeTTL < 0, "ChallengeTTL不能小于0"},
+		{c.ChallengeBlockTTL < 0, "ChallengeBlockTTL不能小于0"},
+		{c.SubnetBanThreshold < 0, "SubnetBanThreshold不能小于0"},
+		{c.SubnetBanWindow < 0, "SubnetBanWindow不能小于0"},
+		{c.SubnetBanDuration < 0, "SubnetBanDuration不能小于0"},
+		{c.TarpitDelay < 0, "TarpitDelay不能小于0"},
+		{c.TarpitMaxDelay < 0, "TarpitMaxDelay不能小于0"},
+		{c.BypassMaxTTL < 0, "BypassMaxTTL不能小于0"},
+		{c.NonceTTL < 0, "NonceTTL不能小于0"},
+	} {
+		if check.negative {
+			fail(check.msg)
+		}
+	}
+	if c.WriteBehind > 0 && len(c.Windows) > 0 {
+		// buffered checks only know the count of the primary window
+		fail("WriteBehind不能和Windows同时使用")
+	}
+	if c.BloomFalsePositive < 0 || c.BloomFalsePositive >= 1 {
+		fail("BloomFalsePositive必须在0和1之间")
+	}
+	if c.GreyListRatio < 0 || c.GreyListRatio >= 1 {
+		fail("GreyListRatio必须在0和1之间")
+	}
+	if a := c.Anomaly; a != nil {
+		if a.SpikeFactor != 0 && a.SpikeFactor <= 1 {
+			fail("Anomaly.SpikeFactor必须大于1")
+		}
+		if a.CeilingRatio < 0 || a.CeilingRatio > 1 {
+			fail("Anomaly.CeilingRatio必须在0和1之间")
+		}
+		if a.CeilingWindows < 0 || a.MinRequests < 0 || a.BlockDuration < 0 {
+			fail("Anomaly的CeilingWindows, MinRequests和BlockDuration不能小于0")
+		}
+	}
+	for _, key := range c.BypassKeys {
+		if len(key) == 0 {
+			fail("BypassKeys不能包含空key")
+			break
+		}
+		if len(key) < 32 {
+			warn("BypassKeys", "BypassKeys包含短于32字节的key, 容易被暴力破解")
+			break
+		}
+	}
+	for _, d := range c.Escalation {
+		if d < 0 {
+			fail("Escalation不能小于0")
+			break
+		}
+	}
+	for _, tier := range c.Tiers {
+		if tier.BlockTimes < 0 || tier.Duration < 0 {
+			fail("Tiers的BlockTimes和Duration不能小于0")
+			break
+		}
+	}
+	for country, name := range c.CountryTiers {
+		if _, ok := c.Tiers[name]; !ok && name != "" {
+			fail("CountryTiers的" + country + "对应的" + name + "不在Tiers中")
+		}
+	}
+	if _, err := compileSchedules(c.Schedules); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := compileRules(c.AllowRules); err != nil {
+		fail("AllowRules包含非法正则: " + err.Error())
+	}
+	if _, err := compileRules(c.DenyRules); err != nil {
+		fail("DenyRules包含非法正则: " + err.Error())
+	}
+	maxIdLength := c.MaxIdLength
+	if maxIdLength <= 0 {
+		maxIdLength = DefaultMaxIdLength
+	}
+	seeds := make(map[string]map[string]bool)
+	for _, list := range []struct {
+		name string
+		ids  []string
+	}{
+		{"WhiteList", c.WhiteList},
+		{"BlockList", c.BlockList},
+		{"TrustedList", c.TrustedList},
+		{"ShardedIds", c.ShardedIds},
+	} {
+		seeds[list.name] = make(map[string]bool, len(list.ids))
+		for _, id := range list.ids {
+			if c.Normalizer != nil {
+				id = c.Normalizer(id)
+			}
+			id, err := sanitizeId(id, maxIdLength, c.HashLongId)
+			if err != nil {
+				fail(list.name + "包含非法id: " + err.Error())
+				break
+			}
+			seeds[list.name][id] = true
+		}
+	}
+	for id := range seeds["BlockList"] {
+		if seeds["WhiteList"][id] {
+			warn("BlockList", id+"同时在WhiteList中, WhiteList优先, 不会被封禁")
+		}
+	}
+
+	if c.BlockTimes == 0 && len(c.Windows) == 0 && len(c.Tiers) == 0 && len(c.Schedules) == 0 {
+		warn("BlockTimes", "BlockTimes为0, 不会限制任何请求")
+	}
+	if c.BlockDuration > 0 && c.BlockDuration < c.Duration {
+		warn("BlockDuration", "BlockDuration短于Duration, 封禁会比计数窗口先结束")
+	}
+	if c.DryRunHandler != nil && !c.DryRun {
+		warn("DryRunHandler", "DryRun未开启, DryRunHandler不会被调用")
+	}
+	if c.LegacySync && c.Pub == nil && c.SyncChannel == "" {
+		warn("LegacySync", "Pub和SyncChannel都未设置, LegacySync无效")
+	}
+	if !c.TrackOffenders && c.OffendersPeriod > 0 {
+		warn("OffendersPeriod", "TrackOffenders未开启, OffendersPeriod无效")
+	}
+	if len(c.Tiers) > 0 && c.TierResolver == nil && len(c.CountryTiers) == 0 {
+		warn("Tiers", "TierResolver未设置, Tiers无效")
+	}
+	if c.BloomBlockList && c.KeyspaceSync {
+		warn("KeyspaceSync", "BloomBlockList开启时, 每次黑名单变更都会重建过滤器")
+	}
+	if len(c.ShardedIds) > 0 && c.Shards <= 1 {
+		warn("ShardedIds", "Shards不大于1, ShardedIds无效")
+	}
+	if c.AlignLocation != nil && !c.AlignWindows {
+		warn("AlignLocation", "AlignWindows未开启, AlignLocation无效")
+	}
+	if c.ClientTimestamps && !c.AlignWindows && c.SubnetBanThreshold == 0 {
+		warn("ClientTimestamps", "AlignWindows和SubnetBanThreshold都未开启, ClientTimestamps无效")
+	}
+	if a := c.Anomaly; a != nil && a.BlockDuration == 0 && a.OnAnomaly == nil && c.OnEvent == nil {
+		warn("Anomaly", "BlockDuration为0且OnAnomaly和OnEvent都未设置, 异常只会记录日志")
+	}
+	if len(c.CountryTiers) > 0 && c.GeoResolver == nil {
+		warn("CountryTiers", "GeoResolver未设置, CountryTiers无效")
+	}
+	if c.ChallengeHandler != nil && c.GreyListRatio == 0 {
+		warn("ChallengeHandler", "GreyListRatio为0, ChallengeHandler不会被调用")
+	}
+	if c.TarpitMaxDelay > 0 && c.TarpitDelay == 0 {
+		warn("TarpitMaxDelay", "TarpitDelay为0, TarpitMaxDelay无效")
+	}
+	if c.BypassMaxTTL > 0 && len(c.BypassKeys) == 0 {
+		warn("BypassMaxTTL", "BypassKeys未设置, BypassMaxTTL无效")
+	}
+	if c.BlockCache && c.DryRun {
+		warn("BlockCache", "DryRun模式下不会封禁, BlockCache无效")
+	}
+	return warnings, joinErrors(errs)
+}
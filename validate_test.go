@@ -0,0 +1,52 @@
+package rateLimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	_, r := testRedis(t)
+	c := &Config{Name: "validate", Redis: r, Duration: time.Minute, BlockTimes: 10}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+
+	c = &Config{
+		Duration:       -time.Minute,
+		BlockTimes:     10,
+		ResyncInterval: -time.Second,
+		AllowRules:     []string{"("},
+		WhiteList:      []string{"a"},
+		BlockList:      []string{"a"},
+		DryRunHandler:  func(string, *CheckResult) {},
+	}
+	err := c.Validate()
+	errs, ok := err.(MultiError)
+	if !ok {
+		t.Fatalf("Validate() = %v, want a MultiError", err)
+	}
+	var problems, warnings int
+	for _, e := range errs {
+		if IsConfigWarning(e) {
+			warnings++
+		} else {
+			problems++
+		}
+	}
+	// Name, Duration, Redis, ResyncInterval and AllowRules; the white and block
+	// list overlap and DryRunHandler without DryRun
+	if problems != 5 || warnings != 2 {
+		t.Fatalf("%d problems and %d warnings, want 5 and 2: %v", problems, warnings, err)
+	}
+	if _, err := NewLimiter("invalid", WithRedis(r), WithDuration(-time.Minute)); err == nil {
+		t.Fatal("invalid config accepted")
+	}
+
+	// warnings alone don't stop a limiter from being created
+	l := newTestLogger()
+	_, rl := testLimiter(t, "warned", WithDuration(time.Minute), WithBlockTimes(10), WithWhiteList("a"), WithBlockList("a"), WithLogger(l))
+	if rl == nil || !l.logged("warn", "config warning") {
+		t.Fatalf("warning not logged: %v", l.msgs)
+	}
+}
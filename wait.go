@@ -0,0 +1,139 @@
+package rateLimiter
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+)
+
+// waitRetryDelay is how long Wait sleeps when a full window reports no ttl, which
+// only happens while its key is expiring.
+const waitRetryDelay = 10 * time.Millisecond
+
+func (rl *RateLimiter) Wait(id string) (*CheckResult, error) {
+	return rl.WaitNCtx(context.Background(), id, 1)
+}
+
+func (rl *RateLimiter) WaitCtx(ctx context.Context, id string) (*CheckResult, error) {
+	return rl.WaitNCtx(ctx, id, 1)
+}
+
+func (rl *RateLimiter) WaitN(id string, n int) (*CheckResult, error) {
+	return rl.WaitNCtx(context.Background(), id, n)
+}
+
+// WaitNCtx takes n units for id, sleeping until they fit in every window instead
+// of rejecting. Reaching a limit only paces id, it never blocks it; an id that is
+// already blocked returns its BlockedError at once. It returns ctx.Err() if ctx is
+// done first.
+func (rl *RateLimiter) WaitNCtx(ctx context.Context, id string, n int) (*CheckResult, error) {
+	if n <= 0 {
+		return nil, stderrors.New("n必须大于0")
+	}
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return nil, err
+	}
+	// the outcome is recorded once, timed without the sleeps
+	ctx, span := rl.startSpan(ctx, "Wait", id)
+	var busy time.Duration
+	for {
+		start := time.Now()
+		res, wait, err := rl.take(ctx, id, n)
+		busy += time.Since(start)
+		if err != nil || wait == 0 {
+			res, err = rl.enforce(res, err)
+			rl.decided(ctx, span, id, busy, res, err)
+			return res, err
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			rl.decided(ctx, span, id, busy, nil, ctx.Err())
+			return nil, ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+func (rl *RateLimiter) Take(id string) (*CheckResult, error) {
+	return rl.TakeNCtx(context.Background(), id, 1)
+}
+
+func (rl *RateLimiter) TakeCtx(ctx context.Context, id string) (*CheckResult, error) {
+	return rl.TakeNCtx(ctx, id, 1)
+}
+
+func (rl *RateLimiter) TakeN(id string, n int) (*CheckResult, error) {
+	return rl.TakeNCtx(context.Background(), id, n)
+}
+
+// TakeNCtx is WaitNCtx without the wait: units that don't fit are rejected with a
+// BlockedError whose RetryAfter is the wait, but id itself is never blocked.
+func (rl *RateLimiter) TakeNCtx(ctx context.Context, id string, n int) (*CheckResult, error) {
+	if n <= 0 {
+		return nil, stderrors.New("n必须大于0")
+	}
+	id, err := rl.sanitizeId(id)
+	if err != nil {
+		return nil, err
+	}
+	return rl.checked(ctx, id, func(ctx context.Context) (*CheckResult, error) {
+		res, wait, err := rl.take(ctx, id, n)
+		if err != nil || wait == 0 {
+			return res, err
+		}
+		rl.block(res, wait)
+		return res, rl.blockedError(id, res)
+	})
+}
+
+// take counts n units if they fit, otherwise it reports how long to wait for them.
+// Its callers apply DryRun with enforce.
+func (rl *RateLimiter) take(ctx context.Context, id string, n int) (*CheckResult, time.Duration, error) {
+	if res, ok := rl.modeResult(id); ok {
+		if res.Allowed {
+			return res, 0, nil
+		}
+		return res, 0, rl.blockedError(id, res)
+	}
+	if rl.inWhiteList(id) || matchRule(rl.allowRules, id) != "" {
+		return rl.whiteListResult(id), 0, nil
+	}
+	if rl.inBlockList(id) || matchRule(rl.denyRules, id) != "" {
+		res := rl.blockListResult(id)
+		return res, 0, rl.blockedError(id, res)
+	}
+	windows := rl.windows(id)
+	for _, w := range windows {
+		if w.Limit > 0 && n > w.Limit {
+			return nil, 0, stderrors.New("n不能大于窗口的Limit")
+		}
+	}
+	checkCtx, cancel := rl.checkContext(ctx)
+	c, err := rl.incr(checkCtx, id, windows, n)
+	cancel()
+	if err != nil {
+		if res, ok := rl.failOpen(id, err); ok {
+			return res, 0, nil
+		}
+		return nil, 0, err
+	}
+	w := windows[0]
+	if c.window > 0 {
+		w = windows[c.window]
+	}
+	res := rl.newResult(w, c.times, c.ttl)
+	if !c.reached {
+		return res, 0, nil
+	}
+	if rl.DryRun {
+		rl.block(res, c.ttl)
+		return res, 0, rl.blockedError(id, res)
+	}
+	if c.ttl <= 0 {
+		return res, waitRetryDelay, nil
+	}
+	return res, c.ttl, nil
+}
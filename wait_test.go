@@ -0,0 +1,87 @@
+package rateLimiter
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWait(t *testing.T) {
+	mr, rl := testLimiter(t, "wait", WithDuration(100*time.Millisecond), WithBlockTimes(2), WithBlockDuration(time.Hour))
+	for i := 0; i < 2; i++ {
+		if _, err := rl.Wait("a"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := rl.WaitN("a", 3); err == nil {
+		t.Fatal("n above the limit accepted")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := rl.WaitCtx(ctx, "a"); err != context.DeadlineExceeded {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+
+	// miniredis only expires keys when its clock is moved
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		mr.FastForward(100 * time.Millisecond)
+	}()
+	start := time.Now()
+	res, err := rl.Wait("a")
+	if err != nil || res.Used != 1 {
+		t.Fatalf("Wait after the window = %+v, %v", res, err)
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Fatalf("Wait returned after %v without waiting for the window", d)
+	}
+	if rl.blockIds.has("a") {
+		t.Fatal("Wait blocked the id")
+	}
+}
+
+func TestTake(t *testing.T) {
+	_, rl := testLimiter(t, "take", WithDuration(time.Minute), WithBlockTimes(1), WithBlockDuration(time.Hour))
+	if _, err := rl.Take("a"); err != nil {
+		t.Fatal(err)
+	}
+	res, err := rl.Take("a")
+	if !IsBlocked(err) || res.RetryAfter <= 0 || res.RetryAfter > time.Minute {
+		t.Fatalf("Take over the limit = %+v, %v, want a wait within the window", res, err)
+	}
+	if rl.blockIds.has("a") {
+		t.Fatal("Take blocked the id")
+	}
+}
+
+func TestTakeAndWaitEmitDecisions(t *testing.T) {
+	var mu sync.Mutex
+	var events []EventType
+	_, rl := testLimiter(t, "take", WithDuration(time.Minute), WithBlockTimes(2), WithOnEvent(func(e Event) {
+		mu.Lock()
+		events = append(events, e.Type)
+		mu.Unlock()
+	}))
+	if _, err := rl.Wait("a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rl.Take("a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rl.Take("a"); !IsBlocked(err) {
+		t.Fatalf("third take: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := []EventType{EventAllow, EventAllow, EventBlock}
+	if len(events) != len(want) {
+		t.Fatalf("events %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("events %v, want %v", events, want)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+const (
+	KindAutoBlock = "autoBlock" //the id was added to the block list automatically
+	KindThreshold = "threshold" //the id was rejected Threshold times within ThresholdWindow
+)
+
+// DefaultTemplate posts {"text": ...}, which Slack and Teams incoming webhooks accept.
+const DefaultTemplate = `{"text":{{json .Text}}}`
+
+const (
+	DefaultMinInterval = time.Minute
+	queueSize          = 64
+)
+
+type Alert struct {
+	Kind       string
+	Limiter    string
+	Id         string
+	Blocks     int //rejections counted in the threshold window
+	Suppressed int //alerts dropped by MinInterval since the previous one
+	Time       time.Time
+}
+
+// Text is a one line summary of the alert.
+func (a Alert) Text() string {
+	text := "rate limiter " + a.Limiter + ": "
+	if a.Kind == KindAutoBlock {
+		text += a.Id + " was added to the block list"
+	} else {
+		text += a.Id + " was rejected " + strconv.Itoa(a.Blocks) + " times"
+	}
+	if a.Suppressed > 0 {
+		text += " (" + strconv.Itoa(a.Suppressed) + " more alerts suppressed)"
+	}
+	return text
+}
+
+type config struct {
+	template        string
+	client          *http.Client
+	header          http.Header
+	threshold       int
+	thresholdWindow time.Duration
+	minInterval     time.Duration
+	onError         func(error)
+}
+
+type Option func(*config)
+
+// WithTemplate sets the text/template of the request body, executed with an Alert.
+// It may use the json function to quote a value.
+func WithTemplate(text string) Option {
+	return func(c *config) {
+		c.template = text
+	}
+}
+
+func WithClient(client *http.Client) Option {
+	return func(c *config) {
+		c.client = client
+	}
+}
+
+func WithHeader(key, value string) Option {
+	return func(c *config) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithThreshold also alerts when an id is rejected n times within window; by
+// default only automatic block list additions are reported.
+func WithThreshold(n int, window time.Duration) Option {
+	return func(c *config) {
+		c.threshold = n
+		c.thresholdWindow = window
+	}
+}
+
+// WithMinInterval sets the minimum time between two webhook calls; alerts in
+// between are dropped and counted. The default is DefaultMinInterval.
+func WithMinInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.minInterval = d
+	}
+}
+
+func WithErrorHandler(fn func(error)) Option {
+	return func(c *config) {
+		c.onError = fn
+	}
+}
+
+// Notifier posts an alert to a webhook URL for significant abuse events. Plug
+// OnEvent into Config.OnEvent; the calls are made in the background.
+type Notifier struct {
+	url  string
+	c    *config
+	tmpl *template.Template
+
+	mu          sync.Mutex
+	blocks      map[string]int
+	windowStart time.Time
+	lastSent    time.Time
+	suppressed  int
+
+	queue chan Alert
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+func New(url string, opts ...Option) (*Notifier, error) {
+	if url == "" {
+		return nil, errors.New("webhook url is empty")
+	}
+	c := &config{
+		template:    DefaultTemplate,
+		client:      http.DefaultClient,
+		header:      http.Header{},
+		minInterval: DefaultMinInterval,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": toJSON}).Parse(c.template)
+	if err != nil {
+		return nil, err
+	}
+	n := &Notifier{
+		url:    url,
+		c:      c,
+		tmpl:   tmpl,
+		blocks: make(map[string]int),
+		queue:  make(chan Alert, queueSize),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go n.loop()
+	return n, nil
+}
+
+func toJSON(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// OnEvent turns limiter events into alerts; it never blocks.
+func (n *Notifier) OnEvent(e rateLimiter.Event) {
+	switch {
+	case e.Type == rateLimiter.EventListChange && e.List == "block" && e.Change.Op == rateLimiter.ListAdd &&
+		e.Change.Operator == rateLimiter.AutoBlockOperator && !e.Change.Replicated:
+		for _, id := range e.Change.Ids {
+			n.alert(Alert{Kind: KindAutoBlock, Limiter: e.Limiter, Id: id, Time: e.Time})
+		}
+	case e.Type == rateLimiter.EventBlock && n.c.threshold > 0:
+		if blocks, ok := n.count(e.Id, e.Time); ok {
+			n.alert(Alert{Kind: KindThreshold, Limiter: e.Limiter, Id: e.Id, Blocks: blocks, Time: e.Time})
+		}
+	}
+}
+
+// count adds a rejection of id and reports when it reaches the threshold, once
+// per window.
+func (n *Notifier) count(id string, now time.Time) (int, bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if now.Sub(n.windowStart) >= n.c.thresholdWindow {
+		n.blocks = make(map[string]int)
+		n.windowStart = now
+	}
+	n.blocks[id]++
+	return n.blocks[id], n.blocks[id] == n.c.threshold
+}
+
+func (n *Notifier) alert(a Alert) {
+	n.mu.Lock()
+	if !n.lastSent.IsZero() && a.Time.Sub(n.lastSent) < n.c.minInterval {
+		n.suppressed++
+		n.mu.Unlock()
+		return
+	}
+	a.Suppressed = n.suppressed
+	n.suppressed = 0
+	n.lastSent = a.Time
+	n.mu.Unlock()
+	select {
+	case n.queue <- a:
+	default:
+	}
+}
+
+func (n *Notifier) loop() {
+	defer close(n.done)
+	for {
+		select {
+		case <-n.stop:
+			return
+		case a := <-n.queue:
+			if err := n.Send(context.Background(), a); err != nil && n.c.onError != nil {
+				n.c.onError(err)
+			}
+		}
+	}
+}
+
+// Send posts a to the webhook right away.
+func (n *Notifier) Send(ctx context.Context, a Alert) error {
+	var body bytes.Buffer
+	if err := n.tmpl.Execute(&body, a); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, vals := range n.c.header {
+		req.Header[key] = vals
+	}
+	res, err := n.c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode >= 300 {
+		return errors.New("webhook responded " + res.Status)
+	}
+	return nil
+}
+
+// Close stops sending; queued alerts are dropped.
+func (n *Notifier) Close() {
+	close(n.stop)
+	<-n.done
+}
@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+// testServer returns the URL of a webhook receiving the posted bodies.
+func testServer(t *testing.T) (string, chan map[string]string) {
+	bodies := make(chan map[string]string, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]string
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("body %q: %v", data, err)
+		}
+		body["auth"] = r.Header.Get("Authorization")
+		bodies <- body
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, bodies
+}
+
+func receive(t *testing.T, bodies chan map[string]string) map[string]string {
+	t.Helper()
+	select {
+	case body := <-bodies:
+		return body
+	case <-time.After(time.Second):
+		t.Fatal("webhook not called")
+		return nil
+	}
+}
+
+func autoBlock(id string, at time.Time) rateLimiter.Event {
+	return rateLimiter.Event{Type: rateLimiter.EventListChange, Limiter: "api", Time: at, List: "block",
+		Change: &rateLimiter.ListChange{Op: rateLimiter.ListAdd, Ids: []string{id}, Operator: rateLimiter.AutoBlockOperator}}
+}
+
+func TestNotifierAutoBlock(t *testing.T) {
+	url, bodies := testServer(t)
+	n, err := New(url, WithHeader("Authorization", "Bearer x"), WithMinInterval(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close()
+	now := time.Now()
+	n.OnEvent(autoBlock("a", now))
+	if body := receive(t, bodies); body["text"] != "rate limiter api: a was added to the block list" || body["auth"] != "Bearer x" {
+		t.Fatalf("body %v", body)
+	}
+
+	// manual and replicated additions are not reported
+	manual := autoBlock("m", now.Add(2*time.Minute))
+	manual.Change.Operator = "ops"
+	n.OnEvent(manual)
+	replicated := autoBlock("r", now.Add(2*time.Minute))
+	replicated.Change.Replicated = true
+	n.OnEvent(replicated)
+
+	n.OnEvent(autoBlock("b", now.Add(30*time.Second)))
+	n.OnEvent(autoBlock("c", now.Add(2*time.Minute)))
+	if body := receive(t, bodies); body["text"] != "rate limiter api: c was added to the block list (1 more alerts suppressed)" {
+		t.Fatalf("body %v", body)
+	}
+	select {
+	case body := <-bodies:
+		t.Fatalf("unexpected call %v", body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNotifierThreshold(t *testing.T) {
+	url, bodies := testServer(t)
+	n, err := New(url, WithThreshold(3, time.Minute), WithTemplate(`{"text":{{json .Text}},"kind":{{json .Kind}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close()
+	now := time.Now()
+	for i := 0; i < 4; i++ {
+		n.OnEvent(rateLimiter.Event{Type: rateLimiter.EventBlock, Limiter: "api", Id: "a", Time: now})
+	}
+	if body := receive(t, bodies); body["kind"] != KindThreshold || body["text"] != "rate limiter api: a was rejected 3 times" {
+		t.Fatalf("body %v", body)
+	}
+}
+
+func TestNotifierErrors(t *testing.T) {
+	if _, err := New(""); err == nil {
+		t.Fatal("empty url accepted")
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	errs := make(chan error, 1)
+	n, err := New(srv.URL, WithErrorHandler(func(err error) { errs <- err }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close()
+	n.OnEvent(autoBlock("a", time.Now()))
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatal("nil error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("error not reported")
+	}
+}
@@ -0,0 +1,215 @@
+package rateLimiter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// flushScript adds ARGV[#KEYS+1] units to every window counter of an id, starting
+// the TTL of new counters, and returns {count, pttl} of each window. Unlike
+// counterScript it never refuses: the units were already allowed.
+var flushScript = goredis.NewScript(`
+local inc = tonumber(ARGV[#KEYS + 1])
+local offsetArg = #KEYS + 2
+` + alignedTTL + `
+local result = {}
+for i = 1, #KEYS do
+	local count = redis.call('incrby', KEYS[i], inc)
+	local ttl = redis.call('pttl', KEYS[i])
+	if count == inc or ttl < 0 then
+		ttl = lifetime(ARGV[i])
+		redis.call('pexpire', KEYS[i], ttl)
+	end
+	result[i * 2 - 1], result[i * 2] = count, ttl
+end
+return result
+`)
+
+// bufferedCount is the local view of the counters of an id in write-behind mode.
+type bufferedCount struct {
+	used     int //primary window count at the last sync with Redis
+	pending  int //units allowed locally and not flushed yet
+	resetAt  time.Time
+	exceeded bool //a window was full at the last flush
+}
+
+type writeBuffer struct {
+	mu     sync.Mutex
+	counts map[string]*bufferedCount
+}
+
+// buffered counts n units of id locally if Redis last reported enough room for
+// them in the primary window. The check that would fill the window, the first
+// check of an id and every check of a sharded id go to Redis.
+func (rl *RateLimiter) buffered(id string, n int) (*CheckResult, bool) {
+	if rl.WriteBehind <= 0 || rl.isSharded(id) {
+		return nil, false
+	}
+	w := rl.primaryWindow(id)
+	now := rl.Clock.Now()
+	rl.buffer.mu.Lock()
+	e := rl.buffer.counts[id]
+	if e == nil || e.exceeded || !now.Before(e.resetAt) || (w.Limit > 0 && e.used+e.pending+n >= w.Limit) {
+		rl.buffer.mu.Unlock()
+		return nil, false
+	}
+	e.pending += n
+	used := e.used + e.pending
+	ttl := e.resetAt.Sub(now)
+	rl.buffer.mu.Unlock()
+	return rl.newResult(w, used, ttl), true
+}
+
+// takePending removes and returns the units of id not flushed yet.
+func (rl *RateLimiter) takePending(id string) int {
+	rl.buffer.mu.Lock()
+	defer rl.buffer.mu.Unlock()
+	e := rl.buffer.counts[id]
+	if e == nil {
+		return 0
+	}
+	n := e.pending
+	e.pending = 0
+	return n
+}
+
+// bufferSynced records the outcome of a check that went to Redis. Only allowed results
+// describe the primary window; after a rejection the next check goes to Redis again.
+func (rl *RateLimiter) bufferSynced(id string, res *CheckResult) {
+	if rl.WriteBehind <= 0 || rl.isSharded(id) {
+		return
+	}
+	rl.buffer.mu.Lock()
+	defer rl.buffer.mu.Unlock()
+	if !res.Allowed || res.ResetAt.IsZero() {
+		delete(rl.buffer.counts, id)
+		return
+	}
+	if rl.buffer.counts == nil {
+		rl.buffer.counts = make(map[string]*bufferedCount)
+	}
+	e := rl.buffer.counts[id]
+	if e == nil {
+		if len(rl.buffer.counts) >= rl.maxCacheEntries() {
+			return
+		}
+		e = &bufferedCount{}
+		rl.buffer.counts[id] = e
+	}
+	e.used, e.resetAt, e.exceeded = res.Used, res.ResetAt, false
+}
+
+func (rl *RateLimiter) writeBehindLoop(ctx context.Context) {
+	ticker := time.NewTicker(rl.WriteBehind)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := rl.flushCounters(ctx); err != nil {
+				rl.Logger.Error("flush counters failed", rl.fields("err", err)...)
+			}
+		}
+	}
+}
+
+// flushCounters writes the pending units of every id to Redis in one pipeline and
+// refreshes the local counts with the result. Ids without pending units are
+// dropped, so their next check goes to Redis.
+func (rl *RateLimiter) flushCounters(ctx context.Context) error {
+	rl.buffer.mu.Lock()
+	pending := make(map[string]int)
+	for id, e := range rl.buffer.counts {
+		if e.pending == 0 {
+			delete(rl.buffer.counts, id)
+			continue
+		}
+		pending[id] = e.pending
+		e.pending = 0
+	}
+	rl.buffer.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+	pipe := rl.Redis.Pipeline()
+	cmds := make(map[string]*goredis.Cmd, len(pending))
+	for id, n := range pending {
+		cmds[id] = rl.flushCmd(ctx, pipe, id, n)
+	}
+	_, err := pipe.Exec(ctx)
+	now := rl.Clock.Now()
+	for id, cmd := range cmds {
+		vals, cmdErr := cmd.Int64Slice()
+		if cmdErr != nil {
+			// the units are lost, like a failed check; the next check of id goes to Redis
+			rl.buffer.mu.Lock()
+			delete(rl.buffer.counts, id)
+			rl.buffer.mu.Unlock()
+			continue
+		}
+		rl.updateBuffered(id, vals, now)
+	}
+	return err
+}
+
+func (rl *RateLimiter) flushCmd(ctx context.Context, c goredis.Scripter, id string, n int) *goredis.Cmd {
+	windows := rl.windows(id)
+	args := make([]interface{}, 0, len(windows)+2)
+	for _, w := range windows {
+		args = append(args, rl.windowTTL(w))
+	}
+	args = append(args, n)
+	args = rl.appendAlignOffset(args)
+	return flushScript.Eval(ctx, c, rl.counterKeys(id), args...)
+}
+
+// flushId writes the pending units of id before a check of it goes to Redis.
+func (rl *RateLimiter) flushId(ctx context.Context, id string) error {
+	if rl.WriteBehind <= 0 {
+		return nil
+	}
+	n := rl.takePending(id)
+	if n == 0 {
+		return nil
+	}
+	return rl.flushCmd(ctx, rl.Redis, id, n).Err()
+}
+
+func (rl *RateLimiter) updateBuffered(id string, vals []int64, now time.Time) {
+	windows := rl.windows(id)
+	rl.buffer.mu.Lock()
+	defer rl.buffer.mu.Unlock()
+	e := rl.buffer.counts[id]
+	if e == nil {
+		return
+	}
+	e.used = int(vals[0])
+	e.resetAt = now.Add(time.Duration(vals[1]) * time.Millisecond)
+	for i, w := range windows {
+		if i*2 < len(vals) && w.Limit > 0 && int(vals[i*2]) >= w.Limit {
+			e.exceeded = true
+		}
+	}
+}
+
+// sweep drops the counts of ids whose window ended with nothing pending.
+func (b *writeBuffer) sweep(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for id, e := range b.counts {
+		if e.pending == 0 && !now.Before(e.resetAt) {
+			delete(b.counts, id)
+		}
+	}
+}
+
+// dropBuffered forgets the local counts of id after its counters were reset.
+func (rl *RateLimiter) dropBuffered(id string) {
+	rl.buffer.mu.Lock()
+	delete(rl.buffer.counts, id)
+	rl.buffer.mu.Unlock()
+}
@@ -0,0 +1,64 @@
+package rateLimiter
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestWriteBehind(t *testing.T) {
+	mr, rl := testLimiter(t, "writeBehind", WithDuration(time.Minute), WithBlockTimes(5), WithWriteBehind(time.Hour))
+	stored := func() int {
+		t.Helper()
+		v, _ := mr.Get(rl.counterKey("a"))
+		n, _ := strconv.Atoi(v)
+		return n
+	}
+	for i := 1; i <= 3; i++ {
+		if res, _ := rl.Allow("a"); !res.Allowed || res.Used != i {
+			t.Fatalf("request %d: %+v", i, res)
+		}
+	}
+	if n := stored(); n != 1 {
+		t.Fatalf("Redis counter %d before the flush, want 1", n)
+	}
+	if err := rl.flushCounters(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := stored(); n != 3 {
+		t.Fatalf("Redis counter %d after the flush, want 3", n)
+	}
+
+	// the check that would fill the window goes to Redis
+	rl.Allow("a")
+	if res, _ := rl.Allow("a"); res.Allowed || stored() != 5 {
+		t.Fatalf("fifth request %+v, Redis counter %d", res, stored())
+	}
+}
+
+func TestWriteBehindFlushOnClose(t *testing.T) {
+	mr, r := testRedis(t)
+	rl, err := NewLimiter("writeBehind", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(10), WithWriteBehind(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		rl.Allow("a")
+	}
+	if err := rl.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := mr.Get(rl.counterKey("a")); v != "4" {
+		t.Fatalf("Redis counter %q after Close, want 4", v)
+	}
+}
+
+func TestWriteBehindRejectsWindows(t *testing.T) {
+	_, r := testRedis(t)
+	_, err := NewLimiter("writeBehind", WithRedis(r), WithDuration(time.Minute), WithBlockTimes(10),
+		WithWindows(Window{Duration: time.Hour, Limit: 100}), WithWriteBehind(time.Second))
+	if err == nil {
+		t.Fatal("WriteBehind with Windows accepted")
+	}
+}
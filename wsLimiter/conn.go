@@ -0,0 +1,222 @@
+package wsLimiter
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	rateLimiter "github.com/go-estar/rate-limiter"
+)
+
+const DefaultSyncInterval = time.Second
+
+type config struct {
+	syncInterval time.Duration
+	warnRatio    float64
+	onWarn       func(id string, used, limit int)
+	onExceeded   func(id string, used, limit int)
+}
+
+type Option func(*config)
+
+// WithSyncInterval sets how often local counts are reconciled with Redis; the
+// default is DefaultSyncInterval.
+func WithSyncInterval(d time.Duration) Option {
+	return func(c *config) {
+		c.syncInterval = d
+	}
+}
+
+// WithOnWarn calls fn once per window when the id has used ratio of its limit,
+// e.g. to send the client a slow-down notice.
+func WithOnWarn(ratio float64, fn func(id string, used, limit int)) Option {
+	return func(c *config) {
+		c.warnRatio = ratio
+		c.onWarn = fn
+	}
+}
+
+// WithOnExceeded calls fn once per window when the id goes over its limit, e.g. to
+// close the connection with a policy violation.
+func WithOnExceeded(fn func(id string, used, limit int)) Option {
+	return func(c *config) {
+		c.onExceeded = fn
+	}
+}
+
+// Conn counts the messages of one long-lived connection locally, so Allow never
+// touches Redis, and reconciles the count with the shared budget of id every sync
+// interval. Several connections of the same user may share an id.
+type Conn struct {
+	rl *rateLimiter.RateLimiter
+	id string
+	c  *config
+
+	mu        sync.Mutex
+	unlimited bool
+	limit     int
+	used      int //count in Redis at the last sync
+	pending   int //messages not flushed to Redis yet
+	resetAt   time.Time
+	warned    bool
+	exceeded  bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New loads the current state of id and starts reconciling it in the background
+// until Close. Ids on the block list or matching DenyRules get a BlockedError
+// unless they are white listed, as with Check.
+func New(ctx context.Context, rl *rateLimiter.RateLimiter, id string, opts ...Option) (*Conn, error) {
+	c := &config{syncInterval: DefaultSyncInterval}
+	for _, opt := range opts {
+		opt(c)
+	}
+	ins, err := rl.InspectCtx(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !ins.WhiteListed && (ins.BlockListed || ins.DenyRule != "") {
+		return nil, &rateLimiter.BlockedError{Err: rl.BlockError, Id: ins.Id, Times: ins.Times, Limit: ins.Limit, Permanent: true}
+	}
+	conn := &Conn{
+		rl:        rl,
+		id:        ins.Id,
+		c:         c,
+		unlimited: ins.WhiteListed || ins.Limit <= 0,
+		limit:     ins.Limit,
+		used:      ins.Times,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if ins.TTL > 0 {
+		conn.resetAt = rl.Clock.Now().Add(ins.TTL)
+	}
+	go conn.loop()
+	return conn, nil
+}
+
+// Allow records one message and reports whether the id is still within its limit.
+func (c *Conn) Allow() bool {
+	c.mu.Lock()
+	if c.unlimited {
+		c.mu.Unlock()
+		return true
+	}
+	if !c.resetAt.IsZero() && !c.rl.Clock.Now().Before(c.resetAt) {
+		c.used, c.resetAt, c.warned, c.exceeded = 0, time.Time{}, false, false
+	}
+	c.pending++
+	used := c.used + c.pending
+	warn, exceeded := c.check(used)
+	c.mu.Unlock()
+	c.notify(warn, exceeded, used)
+	return used <= c.limit
+}
+
+// check must be called with mu held; it reports which callbacks are due.
+func (c *Conn) check(used int) (warn, exceeded bool) {
+	if used > c.limit && !c.exceeded {
+		c.exceeded = true
+		exceeded = true
+	}
+	if c.c.onWarn != nil && !c.warned && float64(used) >= c.c.warnRatio*float64(c.limit) {
+		c.warned = true
+		warn = true
+	}
+	return warn, exceeded
+}
+
+func (c *Conn) notify(warn, exceeded bool, used int) {
+	if warn {
+		c.c.onWarn(c.id, used, c.limit)
+	}
+	if exceeded && c.c.onExceeded != nil {
+		c.c.onExceeded(c.id, used, c.limit)
+	}
+}
+
+// Sync flushes the local count to Redis and picks up what other connections of
+// the id have used. Messages that can't be flushed, because Redis fails or rejects
+// them, stay pending for the next Sync, and so do the ones beyond the limit, since
+// a flush never takes more than the whole budget.
+func (c *Conn) Sync(ctx context.Context) error {
+	c.mu.Lock()
+	n := c.pending
+	if n > c.limit {
+		n = c.limit
+	}
+	c.pending -= n
+	c.mu.Unlock()
+	used, resetAt, rejected, err := c.flush(ctx, n)
+	if err != nil {
+		c.mu.Lock()
+		c.pending += n
+		c.mu.Unlock()
+		return err
+	}
+	c.mu.Lock()
+	c.used = used
+	if rejected {
+		c.pending += n
+	}
+	if !resetAt.IsZero() {
+		c.resetAt = resetAt
+	}
+	used += c.pending
+	warn, exceeded := c.check(used)
+	c.mu.Unlock()
+	c.notify(warn, exceeded, used)
+	return nil
+}
+
+// flush adds n messages to the shared count and returns it; with nothing to add it
+// only reads it. A flush that doesn't fit is rejected and reports the id's budget
+// as used up.
+func (c *Conn) flush(ctx context.Context, n int) (int, time.Time, bool, error) {
+	if n == 0 {
+		ins, err := c.rl.InspectCtx(ctx, c.id)
+		if err != nil {
+			return 0, time.Time{}, false, err
+		}
+		if ins.TTL <= 0 {
+			return ins.Times, time.Time{}, false, nil
+		}
+		return ins.Times, c.rl.Clock.Now().Add(ins.TTL), false, nil
+	}
+	res, err := c.rl.TakeNCtx(ctx, c.id, n)
+	if res == nil {
+		return 0, time.Time{}, false, err
+	}
+	if !res.Allowed {
+		return c.limit, res.ResetAt, true, nil
+	}
+	return res.Used, res.ResetAt, false, nil
+}
+
+func (c *Conn) loop() {
+	defer close(c.done)
+	t := time.NewTicker(c.c.syncInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-t.C:
+			if !c.unlimited {
+				c.Sync(context.Background())
+			}
+		}
+	}
+}
+
+// Close stops reconciling and flushes what is left.
+func (c *Conn) Close(ctx context.Context) error {
+	close(c.stop)
+	<-c.done
+	if c.unlimited {
+		return nil
+	}
+	return c.Sync(ctx)
+}
@@ -0,0 +1,165 @@
+package wsLimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	rateLimiter "github.com/go-estar/rate-limiter"
+	"github.com/go-estar/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+func testLimiter(t *testing.T, opts ...rateLimiter.Option) *rateLimiter.RateLimiter {
+	mr := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	opts = append([]rateLimiter.Option{rateLimiter.WithRedis(&redis.Redis{Client: client})}, opts...)
+	rl, err := rateLimiter.NewLimiter("ws", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rl.Close(context.Background()) })
+	return rl
+}
+
+func TestNewRejectsBlockedIds(t *testing.T) {
+	rl := testLimiter(t, rateLimiter.WithDuration(time.Minute), rateLimiter.WithBlockList("bad"), rateLimiter.WithDenyRules("^deny-"))
+	ctx := context.Background()
+	for _, id := range []string{"bad", "deny-1"} {
+		if _, err := New(ctx, rl, id); !rateLimiter.IsBlocked(err) {
+			t.Fatalf("New(%q) = %v, want blocked", id, err)
+		}
+	}
+	conn, err := New(ctx, rl, "good")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close(ctx)
+}
+
+func TestSyncKeepsPendingWhenRejected(t *testing.T) {
+	rl := testLimiter(t, rateLimiter.WithBlockTimes(2), rateLimiter.WithDuration(time.Minute))
+	ctx := context.Background()
+	conn, err := New(ctx, rl, "user", WithSyncInterval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+	conn.Allow()
+	conn.Allow()
+	if _, err := rl.TakeNCtx(ctx, "user", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if conn.pending != 2 {
+		t.Fatalf("pending = %d after rejected sync, want 2", conn.pending)
+	}
+	if conn.Allow() {
+		t.Fatal("Allow succeeded over the limit")
+	}
+
+	if err := rl.CheckResetCtx(ctx, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	ins, err := rl.InspectCtx(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the message Allow refused is over the limit and waits for the next window
+	if ins.Times != 2 || conn.pending != 1 {
+		t.Fatalf("times = %d pending = %d, want 2 and 1", ins.Times, conn.pending)
+	}
+}
+
+func TestSyncKeepsExcessPending(t *testing.T) {
+	rl := testLimiter(t, rateLimiter.WithBlockTimes(4), rateLimiter.WithDuration(time.Minute))
+	ctx := context.Background()
+	conn, err := New(ctx, rl, "user", WithSyncInterval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close(ctx)
+	for i := 0; i < 6; i++ {
+		conn.Allow()
+	}
+	if err := conn.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	ins, err := rl.InspectCtx(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ins.Times != 4 || conn.pending != 2 {
+		t.Fatalf("times = %d pending = %d, want 4 and 2", ins.Times, conn.pending)
+	}
+
+	if err := rl.CheckResetCtx(ctx, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ins, _ = rl.InspectCtx(ctx, "user"); ins.Times != 2 || conn.pending != 0 {
+		t.Fatalf("times = %d pending = %d after the next window, want 2 and 0", ins.Times, conn.pending)
+	}
+}
+
+func TestConn(t *testing.T) {
+	rl := testLimiter(t, rateLimiter.WithBlockTimes(4), rateLimiter.WithDuration(time.Minute))
+	ctx := context.Background()
+	var warned, exceeded []int
+	conn, err := New(ctx, rl, "user", WithSyncInterval(time.Hour),
+		WithOnWarn(0.5, func(id string, used, limit int) { warned = append(warned, used) }),
+		WithOnExceeded(func(id string, used, limit int) { exceeded = append(exceeded, used) }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= 4; i++ {
+		if !conn.Allow() {
+			t.Fatalf("message %d rejected", i)
+		}
+	}
+	if conn.Allow() {
+		t.Fatal("message over the limit allowed")
+	}
+	conn.Allow()
+	if len(warned) != 1 || warned[0] != 2 || len(exceeded) != 1 || exceeded[0] != 5 {
+		t.Fatalf("warned at %v, exceeded at %v", warned, exceeded)
+	}
+	ins, err := rl.InspectCtx(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ins.Times != 0 {
+		t.Fatalf("Allow touched Redis: times = %d", ins.Times)
+	}
+
+	if err := conn.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ins, _ = rl.InspectCtx(ctx, "user"); ins.Times != 4 {
+		t.Fatalf("times = %d after Close, want 4", ins.Times)
+	}
+}
+
+func TestConnWhiteListed(t *testing.T) {
+	rl := testLimiter(t, rateLimiter.WithBlockTimes(1), rateLimiter.WithDuration(time.Minute), rateLimiter.WithWhiteList("vip"))
+	ctx := context.Background()
+	conn, err := New(ctx, rl, "vip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if !conn.Allow() {
+			t.Fatal("white listed connection limited")
+		}
+	}
+	conn.Close(ctx)
+}